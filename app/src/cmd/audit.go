@@ -0,0 +1,120 @@
+package cmd
+
+import (
+	"bwsf/src/config"
+	"bwsf/src/core"
+	"bwsf/src/infra"
+	"bwsf/src/utils"
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+var auditCmd = &cobra.Command{
+	Use:   "audit",
+	Short: "Show the local audit log",
+	Long:  "Show who pulled, pushed, removed, renamed, copied or rotated which project and when. The log records key names and content hashes, never values",
+	Args:  cobra.NoArgs,
+	Run:   runAudit,
+}
+
+func init() {
+	auditCmd.Flags().String("project", "", "Only show entries for this project")
+	auditCmd.Flags().String("op", "", "Only show this operation: pull, push, rm, mv, cp or rotate")
+	auditCmd.Flags().String("user", "", "Only show entries by this OS user")
+	auditCmd.Flags().String("key", "", "Only show entries that changed this key")
+	auditCmd.Flags().String("since", "", "Only show entries at or after this time (2026-01-02, RFC3339, or a duration such as 7d)")
+	auditCmd.Flags().String("until", "", "Only show entries before this time")
+	auditCmd.Flags().Int("limit", 0, "Show only the last n matching entries")
+	auditCmd.Flags().Bool("json", false, "Print matching entries as JSON lines")
+	rootCmd.AddCommand(auditCmd)
+}
+
+func runAudit(cmd *cobra.Command, args []string) {
+	project, _ := cmd.Flags().GetString("project")
+	op, _ := cmd.Flags().GetString("op")
+	user, _ := cmd.Flags().GetString("user")
+	key, _ := cmd.Flags().GetString("key")
+	since, _ := cmd.Flags().GetString("since")
+	until, _ := cmd.Flags().GetString("until")
+	limit, _ := cmd.Flags().GetInt("limit")
+	asJSON, _ := cmd.Flags().GetBool("json")
+
+	filter := core.AuditFilter{Project: project, Op: op, User: user, Key: key}
+	now := time.Now()
+	var err error
+	if since != "" {
+		if filter.Since, err = core.ParseAuditTime(since, now); err != nil {
+			utils.Errorln("[ERROR] --since:", err)
+			os.Exit(1)
+		}
+	}
+	if until != "" {
+		if filter.Until, err = core.ParseAuditTime(until, now); err != nil {
+			utils.Errorln("[ERROR] --until:", err)
+			os.Exit(1)
+		}
+	}
+
+	cfg := mustLoadConfig()
+	path, err := config.GetAuditPath(cfg)
+	if err != nil {
+		utils.Errorln("[ERROR]", err)
+		os.Exit(1)
+	}
+	entries, err := core.ReadAuditLog(infra.NewFileSystem(), path)
+	if err != nil {
+		utils.Errorln("[ERROR]", err)
+		os.Exit(1)
+	}
+
+	entries = core.FilterAuditEntries(entries, filter)
+	if limit > 0 && len(entries) > limit {
+		entries = entries[len(entries)-limit:]
+	}
+
+	if asJSON {
+		for _, entry := range entries {
+			line, _ := json.Marshal(entry)
+			fmt.Println(string(line))
+		}
+		return
+	}
+
+	if len(entries) == 0 {
+		fmt.Println("No audit entries found in", path)
+		return
+	}
+	for _, entry := range entries {
+		fmt.Println(formatAuditEntry(entry))
+	}
+}
+
+// formatAuditEntry renders one entry as a single line, listing changed keys per file
+func formatAuditEntry(entry core.AuditEntry) string {
+	project := entry.Project
+	if entry.Target != "" {
+		project += " -> " + entry.Target
+	}
+	line := fmt.Sprintf("%s  %-5s %-24s %s@%s",
+		entry.Timestamp.Local().Format("2006-01-02 15:04:05"), entry.Op, project, entry.User, entry.Host)
+
+	var files []string
+	for _, file := range entry.Files {
+		if keys := entry.Keys[file]; len(keys) > 0 {
+			files = append(files, fmt.Sprintf("%s [%s]", file, strings.Join(keys, ", ")))
+		} else {
+			files = append(files, file)
+		}
+	}
+	sort.Strings(files)
+	if len(files) > 0 {
+		line += "  " + strings.Join(files, "; ")
+	}
+	return line
+}
@@ -0,0 +1,75 @@
+package cmd
+
+import (
+	"bwsf/src/config"
+	"bwsf/src/core"
+	"bwsf/src/infra"
+	"bwsf/src/utils"
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+)
+
+var checkCmd = &cobra.Command{
+	Use:   "check",
+	Short: "Validate .env files against .env.example",
+	Long:  "Compare .env* files with the committed .env*.example files. Reports keys missing from or not declared in the example, and values that do not match '# type: ...' hints. Exits non-zero when a key marked '# required' is missing or empty",
+	Run:   runCheck,
+}
+
+func init() {
+	checkCmd.Flags().String("dir", ".", "Directory containing .env and .env.example files")
+	checkCmd.Flags().Bool("remote", false, "Check the files stored in Bitwarden instead of the local files")
+	rootCmd.AddCommand(checkCmd)
+}
+
+func runCheck(cmd *cobra.Command, args []string) {
+	dir, err := cmd.Flags().GetString("dir")
+	if err != nil {
+		utils.Errorln("[ERROR] Failed to get --dir flag:", err)
+		os.Exit(1)
+	}
+
+	remote, err := cmd.Flags().GetBool("remote")
+	if err != nil {
+		utils.Errorln("[ERROR] Failed to get --remote flag:", err)
+		os.Exit(1)
+	}
+
+	fs := infra.NewFileSystem()
+
+	var issues []core.LintIssue
+	if remote {
+		mustCheckBwCommand()
+		cfg := mustLoadConfig()
+		projectName := mustCurrentProjectName()
+		issues, err = core.CheckRemoteEnvCore(dir, projectName, fs, newBwClient(cmd, cfg), cfg, utils.InputPassword, infra.NewLogger())
+	} else {
+		issues, err = core.CheckEnvCore(dir, fs)
+	}
+	if err != nil {
+		utils.Errorln("[ERROR]", err)
+		os.Exit(1)
+	}
+
+	if len(issues) == 0 {
+		utils.Successln("[INFO] ✅ All env files match their .example files")
+		return
+	}
+
+	errorCount := 0
+	for _, issue := range issues {
+		if issue.Severity == config.SeverityError {
+			errorCount++
+			utils.Errorln("[ERROR]", issue.String())
+		} else {
+			utils.Warningln("[WARN]", issue.String())
+		}
+	}
+
+	fmt.Printf("%d issue(s), %d error(s)\n", len(issues), errorCount)
+	if errorCount > 0 {
+		os.Exit(1)
+	}
+}
@@ -1,9 +1,20 @@
 package cmd
 
 import (
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
 	"testing"
 
+	"bwsf/src/config"
+	"bwsf/src/core"
+
+	"github.com/spf13/cobra"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/yaml.v3"
 )
 
 // =============================================================================
@@ -90,6 +101,35 @@ func TestSetupCmd_Registered(t *testing.T) {
 	assert.True(t, found, "setup command should be registered")
 }
 
+// 正常系: status コマンドが登録されている
+func TestStatusCmd_Registered(t *testing.T) {
+	assert.NotNil(t, statusCmd)
+	assert.Equal(t, "status", statusCmd.Use)
+
+	found := false
+	for _, cmd := range rootCmd.Commands() {
+		if cmd.Use == "status" {
+			found = true
+			break
+		}
+	}
+	assert.True(t, found, "status command should be registered")
+}
+
+// 正常系: rm / mv / cp コマンドが登録されている
+func TestProjectLifecycleCmds_Registered(t *testing.T) {
+	for _, name := range []string{"rm", "mv", "cp"} {
+		found := false
+		for _, cmd := range rootCmd.Commands() {
+			if cmd.Name() == name {
+				found = true
+				break
+			}
+		}
+		assert.True(t, found, name+" command should be registered")
+	}
+}
+
 // =============================================================================
 // フラグのテスト
 // =============================================================================
@@ -136,5 +176,731 @@ func TestSetupCmd_Description(t *testing.T) {
 	assert.NotEmpty(t, setupCmd.Long)
 }
 
+// 正常系: rm コマンドに --permanent フラグがある
+func TestRmCmd_PermanentFlag(t *testing.T) {
+	flag := rmCmd.Flags().Lookup("permanent")
+	assert.NotNil(t, flag)
+	assert.Equal(t, "false", flag.DefValue)
+}
+
+// 正常系: cp コマンドに --file フラグがある
+func TestCpCmd_FileFlag(t *testing.T) {
+	flag := cpCmd.Flags().Lookup("file")
+	assert.NotNil(t, flag)
+}
+
+// 正常系: lint コマンドが登録されている
+func TestLintCmd_Registered(t *testing.T) {
+	found := false
+	for _, cmd := range rootCmd.Commands() {
+		if cmd.Name() == "lint" {
+			found = true
+			break
+		}
+	}
+	assert.True(t, found, "lint command should be registered")
+}
+
+// 正常系: push コマンドに --no-lint フラグがある
+func TestPushCmd_NoLintFlag(t *testing.T) {
+	flag := pushCmd.Flags().Lookup("no-lint")
+	assert.NotNil(t, flag)
+	assert.Equal(t, "false", flag.DefValue)
+}
+
+// 正常系: pull コマンドに --git-guard フラグがある
+func TestPullCmd_GitGuardFlag(t *testing.T) {
+	flag := pullCmd.Flags().Lookup("git-guard")
+	assert.NotNil(t, flag)
+	assert.Equal(t, "", flag.DefValue)
+}
+
+// 正常系: check コマンドが登録され、--remote フラグがある
+func TestCheckCmd_Registered(t *testing.T) {
+	found := false
+	for _, cmd := range rootCmd.Commands() {
+		if cmd.Name() == "check" {
+			found = true
+			break
+		}
+	}
+	assert.True(t, found, "check command should be registered")
+
+	flag := checkCmd.Flags().Lookup("remote")
+	assert.NotNil(t, flag)
+	assert.Equal(t, "false", flag.DefValue)
+}
+
+// 正常系: pull コマンドに --no-check フラグがある
+func TestPullCmd_NoCheckFlag(t *testing.T) {
+	flag := pullCmd.Flags().Lookup("no-check")
+	assert.NotNil(t, flag)
+	assert.Equal(t, "false", flag.DefValue)
+}
+
+// 正常系: pull コマンドに --interpolate フラグがある
+func TestPullCmd_InterpolateFlag(t *testing.T) {
+	flag := pullCmd.Flags().Lookup("interpolate")
+	assert.NotNil(t, flag)
+	assert.Equal(t, "false", flag.DefValue)
+}
+
+// 正常系: sources コマンドが登録され、--file フラグがある
+func TestSourcesCmd_Registered(t *testing.T) {
+	found := false
+	for _, cmd := range rootCmd.Commands() {
+		if cmd.Name() == "sources" {
+			found = true
+			break
+		}
+	}
+	assert.True(t, found, "sources command should be registered")
+
+	flag := sourcesCmd.Flags().Lookup("file")
+	assert.NotNil(t, flag)
+	assert.Equal(t, "", flag.DefValue)
+}
+
+// 正常系: pull コマンドにファイル選択のフラグがある
+func TestPullCmd_SelectionFlags(t *testing.T) {
+	for _, name := range []string{"env", "file", "as", "layer", "merge-into"} {
+		flag := pullCmd.Flags().Lookup(name)
+		if assert.NotNil(t, flag, name) {
+			assert.Contains(t, []string{"", "[]"}, flag.DefValue, name)
+		}
+	}
+}
+
+// 正常系: pull / push コマンドに --workspace と --jobs フラグがある
+func TestWorkspaceFlags(t *testing.T) {
+	for _, c := range []*cobra.Command{pullCmd, pushCmd} {
+		flag := c.Flags().Lookup("workspace")
+		if assert.NotNil(t, flag, c.Name()) {
+			assert.Equal(t, "", flag.DefValue)
+		}
+		flag = c.Flags().Lookup("jobs")
+		if assert.NotNil(t, flag, c.Name()) {
+			assert.Equal(t, "4", flag.DefValue)
+		}
+	}
+
+	flag := pullCmd.Flags().Lookup("force")
+	assert.NotNil(t, flag)
+	assert.Equal(t, "false", flag.DefValue)
+}
+
+// 正常系: offline コマンドとサブコマンド、pull/push の --offline フラグがある
+func TestOfflineCmd_Registered(t *testing.T) {
+	var names []string
+	for _, c := range offlineCmd.Commands() {
+		names = append(names, c.Name())
+	}
+	assert.ElementsMatch(t, []string{"enable", "disable", "status", "sync", "discard"}, names)
+	assert.NotNil(t, offlineEnableCmd.Flags().Lookup("keyring"))
+	assert.NotNil(t, offlineSyncCmd.Flags().Lookup("force"))
+
+	for _, c := range []*cobra.Command{pullCmd, pushCmd} {
+		flag := c.Flags().Lookup("offline")
+		if assert.NotNil(t, flag, c.Name()) {
+			assert.Equal(t, "false", flag.DefValue)
+		}
+	}
+}
+
+// 正常系: audit コマンドが登録され、絞り込みのフラグがある
+func TestAuditCmd_Registered(t *testing.T) {
+	found := false
+	for _, c := range rootCmd.Commands() {
+		if c.Name() == "audit" {
+			found = true
+			break
+		}
+	}
+	assert.True(t, found, "audit command should be registered")
+
+	for _, name := range []string{"project", "op", "user", "key", "since", "until", "limit", "json"} {
+		assert.NotNil(t, auditCmd.Flags().Lookup(name), name)
+	}
+}
+
+// 正常系: rotate コマンドが登録されている
+func TestRotateCmd_Registered(t *testing.T) {
+	found := false
+	for _, c := range rootCmd.Commands() {
+		if c.Name() == "rotate" {
+			found = true
+			break
+		}
+	}
+	assert.True(t, found, "rotate command should be registered")
+
+	for _, name := range []string{"file", "generator", "length", "dry-run", "yes"} {
+		assert.NotNil(t, rotateCmd.Flags().Lookup(name), name)
+	}
+}
+
+// 正常系: meta / report コマンドが登録されている
+func TestMetaReportCmd_Registered(t *testing.T) {
+	names := map[string]bool{}
+	for _, c := range rootCmd.Commands() {
+		names[c.Name()] = true
+	}
+	assert.True(t, names["meta"], "meta command should be registered")
+	assert.True(t, names["report"], "report command should be registered")
+
+	for _, name := range []string{"file", "owner", "description", "tag", "expires", "json"} {
+		assert.NotNil(t, metaCmd.Flags().Lookup(name), name)
+	}
+	for _, name := range []string{"stale", "expiring", "json"} {
+		assert.NotNil(t, reportCmd.Flags().Lookup(name), name)
+	}
+}
+
+// 正常系: hook / export コマンドが登録されている
+func TestHookExportCmd_Registered(t *testing.T) {
+	names := map[string]bool{}
+	for _, c := range rootCmd.Commands() {
+		names[c.Name()] = true
+	}
+	assert.True(t, names["hook"], "hook command should be registered")
+	assert.True(t, names["export"], "export command should be registered")
+
+	for _, name := range []string{"dir", "env", "file", "interpolate", "unload"} {
+		assert.NotNil(t, exportCmd.Flags().Lookup(name), name)
+	}
+}
+
+// 正常系: hooks コマンドが登録されている
+func TestHooksCmd_Registered(t *testing.T) {
+	names := map[string]bool{}
+	for _, c := range hooksCmd.Commands() {
+		names[c.Name()] = true
+	}
+	for _, name := range []string{"install", "uninstall", "run"} {
+		assert.True(t, names[name], name)
+	}
+	assert.True(t, hooksRunCmd.Hidden)
+	assert.NotNil(t, hooksInstallCmd.Flags().Lookup("status-check"))
+}
+
+// 正常系: pull コマンドに --ephemeral のフラグがある
+func TestPullCmd_EphemeralFlags(t *testing.T) {
+	for name, def := range map[string]string{"ephemeral": "false", "fifo": "false", "reads": "1", "ttl": "0s"} {
+		flag := pullCmd.Flags().Lookup(name)
+		if assert.NotNil(t, flag, name) {
+			assert.Equal(t, def, flag.DefValue, name)
+		}
+	}
+}
+
+// 正常系: watch コマンドが登録され、フラグがある
+func TestWatchCmd_Registered(t *testing.T) {
+	found := false
+	for _, c := range rootCmd.Commands() {
+		if c.Name() == "watch" {
+			found = true
+			break
+		}
+	}
+	assert.True(t, found, "watch command should be registered")
+
+	for name, def := range map[string]string{"from": ".", "auto": "false", "debounce": "500ms", "no-lint": "false"} {
+		flag := watchCmd.Flags().Lookup(name)
+		if assert.NotNil(t, flag, name) {
+			assert.Equal(t, def, flag.DefValue, name)
+		}
+	}
+}
+
+// 正常系: sync コマンドと --follow のフラグが登録されている
+func TestSyncCmd_Registered(t *testing.T) {
+	found := false
+	for _, c := range rootCmd.Commands() {
+		if c.Name() == "sync" {
+			found = true
+			break
+		}
+	}
+	assert.True(t, found, "sync command should be registered")
+
+	for name, def := range map[string]string{"follow": "false", "output": ".", "interval": "30s", "max-backoff": "5m0s", "reload": "restart"} {
+		flag := syncCmd.Flags().Lookup(name)
+		if assert.NotNil(t, flag, name) {
+			assert.Equal(t, def, flag.DefValue, name)
+		}
+	}
+}
+
+// 正常系: compose コマンドと render サブコマンドが登録されている
+func TestComposeCmd_Registered(t *testing.T) {
+	found := false
+	for _, c := range rootCmd.Commands() {
+		if c.Name() == "compose" {
+			found = true
+			break
+		}
+	}
+	assert.True(t, found, "compose command should be registered")
+	assert.Equal(t, "render", composeRenderCmd.Name())
+	assert.Equal(t, composeCmd, composeRenderCmd.Parent())
+	assert.NotNil(t, composeCmd.Flags().Lookup("fifo"))
+	assert.NotNil(t, composeRenderCmd.InheritedFlags().Lookup("compose-file"), "inherited from compose")
+
+	// arguments after -- go to docker compose, even when they name a subcommand
+	c, _, err := rootCmd.Find([]string{"compose", "--", "render"})
+	require.NoError(t, err)
+	assert.Equal(t, composeCmd, c)
+}
+
+// 正常系: k8s コマンドと apply / import サブコマンドが登録されている
+func TestK8sCmd_Registered(t *testing.T) {
+	found := false
+	for _, c := range rootCmd.Commands() {
+		if c.Name() == "k8s" {
+			found = true
+			break
+		}
+	}
+	assert.True(t, found, "k8s command should be registered")
+
+	var names []string
+	for _, c := range k8sCmd.Commands() {
+		names = append(names, c.Name())
+	}
+	assert.ElementsMatch(t, []string{"apply", "import"}, names)
+	for name, def := range map[string]string{"namespace": "", "name": "", "file": ".env", "output": "", "dry-run": "false"} {
+		flag := k8sApplyCmd.Flags().Lookup(name)
+		if assert.NotNil(t, flag, name) {
+			assert.Equal(t, def, flag.DefValue, name)
+		}
+	}
+}
+
+// 正常系: completion コマンドと補完関数が登録されている
+func TestCompletionCmd_Registered(t *testing.T) {
+	names := map[string]bool{}
+	for _, c := range completionCmd.Commands() {
+		names[c.Name()] = true
+	}
+	for _, name := range []string{"bash", "zsh", "fish", "powershell", "install"} {
+		assert.True(t, names[name], name)
+	}
+
+	for _, c := range []*cobra.Command{rmCmd, mvCmd, cpCmd, rotateCmd, metaCmd, offlineDiscardCmd} {
+		assert.NotNil(t, c.ValidArgsFunction, c.Name())
+	}
+	for _, flag := range []string{"env", "file", "layer"} {
+		_, ok := pullCmd.GetFlagCompletionFunc(flag)
+		assert.True(t, ok, flag)
+	}
+}
+
+// 正常系: completion install はシェルの補完ディレクトリに書き出す
+func TestCompletionInstall(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	configHome := t.TempDir()
+	t.Setenv("XDG_CONFIG_HOME", configHome)
+
+	rootCmd.SetArgs([]string{"completion", "install", "fish"})
+	require.NoError(t, rootCmd.Execute())
+
+	script, err := os.ReadFile(filepath.Join(configHome, "fish", "completions", "bwsf.fish"))
+	require.NoError(t, err)
+	assert.Contains(t, string(script), "complete -c bwsf")
+}
+
+// 正常系: backend "file" では bw なしで push / pull / rm が動く
+func TestFileBackend_PushPull(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("BWSF_FILE_PASSPHRASE", "correct horse battery staple")
+	require.NoError(t, config.SaveConfig(&config.Config{Backend: config.BackendFile}))
+
+	project := filepath.Join(t.TempDir(), "file-backend-app")
+	require.NoError(t, os.MkdirAll(project, 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(project, ".env"), []byte("API_KEY=secret-value\n"), 0600))
+	t.Chdir(project)
+
+	run := func(args ...string) {
+		t.Helper()
+		rootCmd.SetArgs(args)
+		require.NoError(t, rootCmd.Execute())
+	}
+
+	run("push")
+	store, err := os.ReadFile(filepath.Join(home, ".config", "bwsf", "store.age"))
+	require.NoError(t, err)
+	assert.NotContains(t, string(store), "secret-value")
+
+	require.NoError(t, os.Remove(filepath.Join(project, ".env")))
+	run("pull")
+	content, err := os.ReadFile(filepath.Join(project, ".env"))
+	require.NoError(t, err)
+	assert.Equal(t, "API_KEY=secret-value", strings.TrimSpace(string(content)))
+
+	run("rm", "file-backend-app", "--yes")
+	bw := newFileBwClient(&config.Config{Backend: config.BackendFile})
+	folderID, err := bw.GetDotenvsFolderID()
+	require.NoError(t, err)
+	items, err := bw.ListItemsInFolder(folderID)
+	require.NoError(t, err)
+	assert.Empty(t, items)
+}
+
+// 正常系: rotate で保存された値だけが置き換わり、pull で反映される
+func TestFileBackend_Rotate(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	t.Setenv("BWSF_FILE_PASSPHRASE", "correct horse battery staple")
+	require.NoError(t, config.SaveConfig(&config.Config{Backend: config.BackendFile}))
+
+	project := filepath.Join(t.TempDir(), "rotate-app")
+	require.NoError(t, os.MkdirAll(project, 0755))
+	envPath := filepath.Join(project, ".env")
+	require.NoError(t, os.WriteFile(envPath, []byte("DB_PASSWORD=old-secret\nPORT=3000\n"), 0600))
+	t.Chdir(project)
+
+	run := func(args ...string) {
+		t.Helper()
+		rootCmd.SetArgs(args)
+		require.NoError(t, rootCmd.Execute())
+	}
+
+	run("push")
+	run("rotate", "rotate-app", "DB_PASSWORD", "--generator", "hex", "--length", "16", "--yes")
+	require.NoError(t, os.Remove(envPath))
+	run("pull")
+
+	content, err := os.ReadFile(envPath)
+	require.NoError(t, err)
+	assert.Regexp(t, `^DB_PASSWORD=[0-9a-f]{16}\nPORT=3000`, string(content))
+}
+
+// 正常系: meta で設定したメタデータが保存され、その後の push でも残る
+func TestFileBackend_Meta(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	t.Setenv("BWSF_FILE_PASSPHRASE", "correct horse battery staple")
+	cfg := &config.Config{Backend: config.BackendFile}
+	require.NoError(t, config.SaveConfig(cfg))
+
+	project := filepath.Join(t.TempDir(), "meta-app")
+	require.NoError(t, os.MkdirAll(project, 0755))
+	envPath := filepath.Join(project, ".env")
+	require.NoError(t, os.WriteFile(envPath, []byte("STRIPE_KEY=sk_test\n"), 0600))
+	t.Chdir(project)
+
+	run := func(args ...string) {
+		t.Helper()
+		rootCmd.SetArgs(args)
+		require.NoError(t, rootCmd.Execute())
+	}
+
+	run("push")
+	run("meta", "meta-app", "STRIPE_KEY", "--owner", "payments-team", "--tag", "pci", "--expires", "2026-12-31T00:00:00Z")
+	require.NoError(t, os.WriteFile(envPath, []byte("STRIPE_KEY=sk_test\nNEW_KEY=1\n"), 0600))
+	run("push")
+
+	bw := newFileBwClient(cfg)
+	folderID, err := bw.GetDotenvsFolderID()
+	require.NoError(t, err)
+	item, err := bw.GetItemByName(folderID, "meta-app")
+	require.NoError(t, err)
+	assert.Contains(t, item.Notes, `"owner": "payments-team"`)
+	assert.Contains(t, item.Notes, `"expires_at": "2026-12-31`)
+	assert.Contains(t, item.Notes, `"NEW_KEY": {`)
+}
+
+// 正常系: export は保存された変数を export するコードを出力し、.env を書き出さない
+func TestFileBackend_Export(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	t.Setenv("BWSF_FILE_PASSPHRASE", "correct horse battery staple")
+	require.NoError(t, config.SaveConfig(&config.Config{Backend: config.BackendFile, ShellCacheTTL: "0"}))
 
+	project := filepath.Join(t.TempDir(), "export-app")
+	require.NoError(t, os.MkdirAll(project, 0755))
+	envPath := filepath.Join(project, ".env")
+	require.NoError(t, os.WriteFile(envPath, []byte("GREETING=it's fine\n"), 0600))
+	t.Chdir(project)
 
+	rootCmd.SetArgs([]string{"push"})
+	require.NoError(t, rootCmd.Execute())
+	require.NoError(t, os.Remove(envPath))
+
+	script := captureStdout(t, func() {
+		rootCmd.SetArgs([]string{"export", "bash", "--dir", project})
+		require.NoError(t, rootCmd.Execute())
+	})
+
+	assert.Equal(t, "export GREETING='it'\\''s fine';\nexport BWSF_SHELL_DIR='"+project+"';\nexport BWSF_SHELL_KEYS='GREETING';\n", script)
+	assert.NoFileExists(t, envPath)
+}
+
+// captureStdout は fn が標準出力に書き出した内容を返します。
+func captureStdout(t *testing.T, fn func()) string {
+	t.Helper()
+	r, w, err := os.Pipe()
+	require.NoError(t, err)
+	original := os.Stdout
+	os.Stdout = w
+	defer func() { os.Stdout = original }()
+
+	fn()
+	require.NoError(t, w.Close())
+	out, err := io.ReadAll(r)
+	require.NoError(t, err)
+	return string(out)
+}
+
+// 正常系: push で名前キャッシュが更新され、補完は保管庫にアクセスせずキャッシュから返す
+func TestFileBackend_CompletionFromNameCache(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	t.Setenv("BWSF_FILE_PASSPHRASE", "correct horse battery staple")
+	require.NoError(t, config.SaveConfig(&config.Config{Backend: config.BackendFile}))
+
+	project := filepath.Join(t.TempDir(), "complete-app")
+	require.NoError(t, os.MkdirAll(project, 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(project, ".env"), []byte("A=1\n"), 0600))
+	require.NoError(t, os.WriteFile(filepath.Join(project, ".env.staging"), []byte("A=2\n"), 0600))
+	t.Chdir(project)
+
+	rootCmd.SetArgs([]string{"push"})
+	require.NoError(t, rootCmd.Execute())
+
+	// 補完では保管庫を開けないことを確かめるため、パスフレーズを外す
+	t.Setenv("BWSF_FILE_PASSPHRASE", "")
+	complete := func(args ...string) string {
+		t.Helper()
+		var out strings.Builder
+		rootCmd.SetOut(&out)
+		defer rootCmd.SetOut(nil)
+		rootCmd.SetArgs(append([]string{"__complete"}, args...))
+		require.NoError(t, rootCmd.Execute())
+		return out.String()
+	}
+
+	assert.Contains(t, complete("rm", "comp"), "complete-app\n")
+	assert.Contains(t, complete("pull", "--env", ""), "staging\n")
+	assert.Contains(t, complete("rotate", "complete-app", "K", "--file", ".env."), ".env.staging\n")
+}
+
+// 正常系: 既存のフックを退避してインストールし、アンインストールで元に戻す
+func TestHooksInstallUninstall(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git is not installed")
+	}
+	repo := t.TempDir()
+	require.NoError(t, exec.Command("git", "init", "-q", repo).Run())
+	project := filepath.Join(repo, "apps", "web")
+	require.NoError(t, os.MkdirAll(project, 0755))
+	hooksDir := filepath.Join(repo, ".git", "hooks")
+	require.NoError(t, os.MkdirAll(hooksDir, 0755))
+	preCommit := filepath.Join(hooksDir, "pre-commit")
+	require.NoError(t, os.WriteFile(preCommit, []byte("#!/bin/sh\nlint\n"), 0755))
+	t.Chdir(project)
+
+	rootCmd.SetArgs([]string{"hooks", "install", "--status-check"})
+	require.NoError(t, rootCmd.Execute())
+
+	script, err := os.ReadFile(preCommit)
+	require.NoError(t, err)
+	assert.Contains(t, string(script), "--project 'web' --dir 'apps/web'")
+	assert.FileExists(t, preCommit+".bwsf-chained")
+	assert.FileExists(t, filepath.Join(hooksDir, "post-merge"))
+
+	rootCmd.SetArgs([]string{"hooks", "uninstall"})
+	require.NoError(t, rootCmd.Execute())
+
+	script, err = os.ReadFile(preCommit)
+	require.NoError(t, err)
+	assert.Equal(t, "#!/bin/sh\nlint\n", string(script))
+	assert.NoFileExists(t, preCommit+".bwsf-chained")
+	assert.NoFileExists(t, filepath.Join(hooksDir, "post-merge"))
+}
+
+// 正常系: --ephemeral では一時ディレクトリのファイルをコマンドに渡し、終了後に削除する
+func TestFileBackend_PullEphemeral(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	t.Setenv("BWSF_FILE_PASSPHRASE", "correct horse battery staple")
+	require.NoError(t, config.SaveConfig(&config.Config{Backend: config.BackendFile}))
+
+	project := filepath.Join(t.TempDir(), "ephemeral-app")
+	require.NoError(t, os.MkdirAll(project, 0755))
+	envPath := filepath.Join(project, ".env")
+	require.NoError(t, os.WriteFile(envPath, []byte("TOKEN=abc\n"), 0600))
+	t.Chdir(project)
+
+	rootCmd.SetArgs([]string{"push"})
+	require.NoError(t, rootCmd.Execute())
+	require.NoError(t, os.Remove(envPath))
+
+	t.Cleanup(func() {
+		_ = pullCmd.Flags().Set("ephemeral", "false")
+		_ = pullCmd.Flags().Set("fifo", "false")
+	})
+	for _, fifo := range []string{"--fifo=false", "--fifo=true"} {
+		out := filepath.Join(t.TempDir(), "out")
+		rootCmd.SetArgs([]string{"pull", "--ephemeral", fifo, "--", "sh", "-c", `cat "$BWSF_EPHEMERAL_DIR/.env" > "$0"; echo >> "$0"; echo "$BWSF_EPHEMERAL_DIR" >> "$0"`, out})
+		require.NoError(t, rootCmd.Execute())
+
+		data, err := os.ReadFile(out)
+		require.NoError(t, err)
+		lines := strings.Split(strings.TrimSpace(string(data)), "\n")
+		require.Len(t, lines, 2, fifo)
+		assert.Equal(t, "TOKEN=abc", lines[0], fifo)
+		assert.NoDirExists(t, lines[1], fifo)
+	}
+	assert.NoFileExists(t, envPath)
+}
+
+// 正常系: sync --follow は .env を書き出してからコマンドを起動し、コマンドの終了で戻る
+func TestFileBackend_SyncFollow(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	t.Setenv("BWSF_FILE_PASSPHRASE", "correct horse battery staple")
+	require.NoError(t, config.SaveConfig(&config.Config{Backend: config.BackendFile}))
+
+	project := filepath.Join(t.TempDir(), "follow-app")
+	require.NoError(t, os.MkdirAll(project, 0755))
+	envPath := filepath.Join(project, ".env")
+	require.NoError(t, os.WriteFile(envPath, []byte("TOKEN=abc\n"), 0600))
+	t.Chdir(project)
+
+	rootCmd.SetArgs([]string{"push"})
+	require.NoError(t, rootCmd.Execute())
+	require.NoError(t, os.Remove(envPath))
+
+	t.Cleanup(func() { _ = syncCmd.Flags().Set("follow", "false") })
+	out := filepath.Join(t.TempDir(), "out")
+	rootCmd.SetArgs([]string{"sync", "--follow", "--", "sh", "-c", `cat .env > "$0"`, out})
+	require.NoError(t, rootCmd.Execute())
+
+	data, err := os.ReadFile(out)
+	require.NoError(t, err)
+	assert.Equal(t, "TOKEN=abc", string(data))
+	assert.FileExists(t, envPath)
+}
+
+
+// 正常系: compose render は env_file の値を environment に展開した override を書き出す
+func TestFileBackend_ComposeRender(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	t.Setenv("BWSF_FILE_PASSPHRASE", "correct horse battery staple")
+	require.NoError(t, config.SaveConfig(&config.Config{Backend: config.BackendFile}))
+
+	project := filepath.Join(t.TempDir(), "compose-app")
+	require.NoError(t, os.MkdirAll(project, 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(project, ".env"), []byte("TOKEN=abc\n"), 0600))
+	require.NoError(t, os.WriteFile(filepath.Join(project, "compose.yaml"), []byte("services:\n  app:\n    env_file: .env\n"), 0644))
+	t.Chdir(project)
+
+	rootCmd.SetArgs([]string{"push"})
+	require.NoError(t, rootCmd.Execute())
+
+	t.Cleanup(func() { _ = composeRenderCmd.Flags().Set("output", "") })
+	out := filepath.Join(t.TempDir(), "override.yaml")
+	rootCmd.SetArgs([]string{"compose", "render", "--output", out})
+	require.NoError(t, rootCmd.Execute())
+
+	data, err := os.ReadFile(out)
+	require.NoError(t, err)
+	assert.Contains(t, string(data), "env_file: !reset []")
+	assert.Contains(t, string(data), `"TOKEN": "abc"`)
+	info, err := os.Stat(out)
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0600), info.Mode().Perm())
+}
+
+
+// 正常系: --output なしの compose render は標準出力に YAML のみを出力する
+func TestFileBackend_ComposeRenderStdout(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	t.Setenv("BWSF_FILE_PASSPHRASE", "correct horse battery staple")
+	require.NoError(t, config.SaveConfig(&config.Config{Backend: config.BackendFile}))
+
+	project := filepath.Join(t.TempDir(), "compose-stdout-app")
+	require.NoError(t, os.MkdirAll(project, 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(project, ".env"), []byte("TOKEN=abc\n"), 0600))
+	require.NoError(t, os.WriteFile(filepath.Join(project, "compose.yaml"), []byte("services:\n  app:\n    env_file: .env\n"), 0644))
+	t.Chdir(project)
+
+	rootCmd.SetArgs([]string{"push"})
+	require.NoError(t, rootCmd.Execute())
+
+	override := captureStdout(t, func() {
+		rootCmd.SetArgs([]string{"compose", "render"})
+		require.NoError(t, rootCmd.Execute())
+	})
+
+	assert.NotContains(t, override, "[INFO]")
+	assert.NotContains(t, override, "[WARNING]")
+	var parsed struct {
+		Services map[string]struct {
+			Environment map[string]string `yaml:"environment"`
+		} `yaml:"services"`
+	}
+	require.NoError(t, yaml.Unmarshal([]byte(override), &parsed), override)
+	assert.Equal(t, map[string]string{"TOKEN": "abc"}, parsed.Services["app"].Environment)
+}
+
+// 正常系: k8s apply で書き出した Secret を k8s import で .env に戻せる
+func TestFileBackend_K8sApplyImport(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	t.Setenv("BWSF_FILE_PASSPHRASE", "correct horse battery staple")
+	require.NoError(t, config.SaveConfig(&config.Config{Backend: config.BackendFile}))
+
+	project := filepath.Join(t.TempDir(), "k8s-app")
+	require.NoError(t, os.MkdirAll(project, 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(project, ".env.production"), []byte("DB_URL=postgres://db\nGREETING='hello world'\n"), 0600))
+	t.Chdir(project)
+
+	rootCmd.SetArgs([]string{"push"})
+	require.NoError(t, rootCmd.Execute())
+
+	t.Cleanup(func() {
+		_ = k8sApplyCmd.Flags().Set("output", "")
+		_ = k8sApplyCmd.Flags().Set("file", ".env")
+		_ = k8sImportCmd.Flags().Set("file", "")
+	})
+	manifest := filepath.Join(t.TempDir(), "secret.yaml")
+	rootCmd.SetArgs([]string{"k8s", "apply", "--namespace", "prod", "--name", "web-env", "--file", ".env.production", "--output", manifest})
+	require.NoError(t, rootCmd.Execute())
+
+	data, err := os.ReadFile(manifest)
+	require.NoError(t, err)
+	assert.Contains(t, string(data), `namespace: "prod"`)
+	assert.NotContains(t, string(data), "postgres", "values are base64-encoded")
+
+	rootCmd.SetArgs([]string{"k8s", "import", manifest, "--file", ".env.imported"})
+	require.NoError(t, rootCmd.Execute())
+
+	imported, err := os.ReadFile(filepath.Join(project, ".env.imported"))
+	require.NoError(t, err)
+	assert.Equal(t, "DB_URL=postgres://db\nGREETING='hello world'\n", string(imported))
+}
+
+// 正常系: --output なしの k8s apply は標準出力にマニフェストのみを出力する（kubeseal に渡せる）
+func TestFileBackend_K8sApplyStdout(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	t.Setenv("BWSF_FILE_PASSPHRASE", "correct horse battery staple")
+	require.NoError(t, config.SaveConfig(&config.Config{Backend: config.BackendFile}))
+
+	project := filepath.Join(t.TempDir(), "k8s-stdout-app")
+	require.NoError(t, os.MkdirAll(project, 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(project, ".env"), []byte("TOKEN=abc\n"), 0600))
+	t.Chdir(project)
+
+	rootCmd.SetArgs([]string{"push"})
+	require.NoError(t, rootCmd.Execute())
+
+	t.Cleanup(func() { _ = k8sApplyCmd.Flags().Set("namespace", "") })
+	manifest := captureStdout(t, func() {
+		rootCmd.SetArgs([]string{"k8s", "apply", "--namespace", "prod", "--name", "web-env"})
+		require.NoError(t, rootCmd.Execute())
+	})
+
+	assert.True(t, strings.HasPrefix(manifest, "# Generated by bwsf from k8s-stdout-app/.env."), manifest)
+	assert.NotContains(t, manifest, "[INFO]")
+	assert.NotContains(t, manifest, "[WARNING]")
+	secret, err := core.ParseK8sSecret([]byte(manifest))
+	require.NoError(t, err)
+	assert.Equal(t, "web-env", secret.Name)
+	assert.Equal(t, map[string]string{"TOKEN": "abc"}, secret.Data)
+}
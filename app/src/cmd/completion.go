@@ -0,0 +1,206 @@
+package cmd
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"bwsf/src/config"
+	"bwsf/src/core"
+	"bwsf/src/infra"
+	"bwsf/src/utils"
+
+	"github.com/spf13/cobra"
+)
+
+var completionCmd = &cobra.Command{
+	Use:   "completion",
+	Short: "Generate or install shell completion scripts",
+	Long: `Generate the completion script for bash, zsh, fish or powershell, or install it with bwsf completion install.
+Project and env file names are completed from a local cache that list, pull and push refresh; completion never contacts Bitwarden or asks for a password`,
+}
+
+var completionInstallCmd = &cobra.Command{
+	Use:       "install [bash|zsh|fish]",
+	Short:     "Install the completion script for your shell",
+	Long:      "Write the completion script where the shell loads it automatically. The shell is detected from $SHELL when omitted",
+	Args:      cobra.MaximumNArgs(1),
+	ValidArgs: []string{core.ShellBash, core.ShellZsh, core.ShellFish},
+	Run:       runCompletionInstall,
+}
+
+// completionScripts generates the completion script for each shell
+var completionScripts = map[string]func(out *bytes.Buffer) error{
+	"bash":       func(out *bytes.Buffer) error { return rootCmd.GenBashCompletionV2(out, true) },
+	"zsh":        func(out *bytes.Buffer) error { return rootCmd.GenZshCompletion(out) },
+	"fish":       func(out *bytes.Buffer) error { return rootCmd.GenFishCompletion(out, true) },
+	"powershell": func(out *bytes.Buffer) error { return rootCmd.GenPowerShellCompletionWithDesc(out) },
+}
+
+func init() {
+	for _, shell := range []string{"bash", "zsh", "fish", "powershell"} {
+		completionCmd.AddCommand(completionScriptCmd(shell))
+	}
+	completionInstallCmd.Flags().String("path", "", "Write the script to this file instead of the default location")
+	completionCmd.AddCommand(completionInstallCmd)
+	rootCmd.AddCommand(completionCmd)
+}
+
+// completionScriptCmd returns a subcommand that prints the completion script for shell
+func completionScriptCmd(shell string) *cobra.Command {
+	return &cobra.Command{
+		Use:   shell,
+		Short: fmt.Sprintf("Print the %s completion script", shell),
+		Args:  cobra.NoArgs,
+		Run: func(cmd *cobra.Command, args []string) {
+			var out bytes.Buffer
+			if err := completionScripts[shell](&out); err != nil {
+				utils.Errorln("[ERROR]", err)
+				os.Exit(1)
+			}
+			fmt.Print(out.String())
+		},
+	}
+}
+
+func runCompletionInstall(cmd *cobra.Command, args []string) {
+	shell := filepath.Base(os.Getenv("SHELL"))
+	if len(args) > 0 {
+		shell = args[0]
+	}
+
+	switch shell {
+	case core.ShellBash, core.ShellZsh, core.ShellFish:
+	default:
+		utils.Errorln("[ERROR] Unsupported shell:", shell, "(expected bash, zsh or fish)")
+		os.Exit(1)
+	}
+	var out bytes.Buffer
+	if err := completionScripts[shell](&out); err != nil {
+		utils.Errorln("[ERROR]", err)
+		os.Exit(1)
+	}
+
+	path, _ := cmd.Flags().GetString("path")
+	if path == "" {
+		var err error
+		if path, err = completionInstallPath(shell); err != nil {
+			utils.Errorln("[ERROR]", err)
+			os.Exit(1)
+		}
+	}
+
+	fs := infra.NewFileSystem()
+	if err := fs.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		utils.Errorln("[ERROR] Failed to create completion directory:", err)
+		os.Exit(1)
+	}
+	if err := fs.WriteFile(path, out.Bytes(), 0644); err != nil {
+		utils.Errorln("[ERROR] Failed to write completion script:", err)
+		os.Exit(1)
+	}
+
+	utils.Successln("[INFO] ✅ Installed", shell, "completion to", path)
+	switch shell {
+	case core.ShellBash:
+		utils.Infoln("[INFO] Requires the bash-completion package; open a new shell to use it")
+	case core.ShellZsh:
+		utils.Infoln("[INFO] Add this to ~/.zshrc before compinit, then open a new shell:")
+		utils.Infoln("  fpath=(" + filepath.Dir(path) + " $fpath)")
+	case core.ShellFish:
+		utils.Infoln("[INFO] Open a new shell to use it")
+	}
+}
+
+// completionInstallPath returns where each shell loads user completion scripts from
+func completionInstallPath(shell string) (string, error) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to get home directory: %w", err)
+	}
+	switch shell {
+	case core.ShellBash:
+		dataHome := os.Getenv("XDG_DATA_HOME")
+		if dataHome == "" {
+			dataHome = filepath.Join(home, ".local", "share")
+		}
+		return filepath.Join(dataHome, "bash-completion", "completions", "bwsf"), nil
+	case core.ShellZsh:
+		return filepath.Join(home, ".zfunc", "_bwsf"), nil
+	default:
+		configHome := os.Getenv("XDG_CONFIG_HOME")
+		if configHome == "" {
+			configHome = filepath.Join(home, ".config")
+		}
+		return filepath.Join(configHome, "fish", "completions", "bwsf.fish"), nil
+	}
+}
+
+// cachedNames loads the name cache for completion. It returns an empty cache on any error,
+// because completion must never fail loudly or fall back to asking the vault.
+func cachedNames() *core.NameCache {
+	empty := &core.NameCache{}
+	path, err := config.GetNameCachePath()
+	if err != nil {
+		return empty
+	}
+	cache, err := core.LoadNameCache(infra.NewFileSystem(), path)
+	if err != nil {
+		return empty
+	}
+	return cache
+}
+
+// updateNameCache records project and file names for completion after a successful command.
+// Failures are ignored: completion is best effort and must not break the command.
+func updateNameCache(update func(*core.NameCache)) {
+	path, err := config.GetNameCachePath()
+	if err != nil {
+		return
+	}
+	_ = core.UpdateNameCache(infra.NewFileSystem(), path, update)
+}
+
+// completeProjectArgs completes the first n positional arguments with cached project names
+func completeProjectArgs(n int) cobra.CompletionFunc {
+	return func(cmd *cobra.Command, args []string, toComplete string) ([]cobra.Completion, cobra.ShellCompDirective) {
+		if len(args) >= n {
+			return nil, cobra.ShellCompDirectiveNoFileComp
+		}
+		return cachedNames().CompleteProjects(toComplete), cobra.ShellCompDirectiveNoFileComp
+	}
+}
+
+// completeStoredFiles completes a --file (or, with envs, an --env) flag with the cached file names of a project.
+// The project is the first argument when fromArgs is set, otherwise the --dir flag or the current directory name.
+func completeStoredFiles(envs, fromArgs bool) cobra.CompletionFunc {
+	return func(cmd *cobra.Command, args []string, toComplete string) ([]cobra.Completion, cobra.ShellCompDirective) {
+		var project string
+		switch {
+		case fromArgs:
+			if len(args) == 0 {
+				return nil, cobra.ShellCompDirectiveNoFileComp
+			}
+			project = args[0]
+		case cmd.Flags().Lookup("dir") != nil:
+			dir, _ := cmd.Flags().GetString("dir")
+			abs, err := filepath.Abs(dir)
+			if err != nil {
+				return nil, cobra.ShellCompDirectiveNoFileComp
+			}
+			project = filepath.Base(abs)
+		default:
+			wd, err := os.Getwd()
+			if err != nil {
+				return nil, cobra.ShellCompDirectiveNoFileComp
+			}
+			project = filepath.Base(wd)
+		}
+
+		if envs {
+			return cachedNames().CompleteEnvs(project, toComplete), cobra.ShellCompDirectiveNoFileComp
+		}
+		return cachedNames().CompleteFiles(project, toComplete), cobra.ShellCompDirectiveNoFileComp
+	}
+}
@@ -0,0 +1,190 @@
+package cmd
+
+import (
+	"os"
+	"os/exec"
+	"os/signal"
+	"path/filepath"
+	"strings"
+	"syscall"
+
+	"bwsf/src/core"
+	"bwsf/src/infra"
+	"bwsf/src/utils"
+
+	"github.com/spf13/cobra"
+)
+
+var composeCmd = &cobra.Command{
+	Use:   "compose",
+	Short: "Run docker compose with the project's env files from Bitwarden",
+	Long: `Run docker compose -- <args> with the env_file entries of the compose file served from Bitwarden.
+Entries are matched to the stored files by file name. The files are placed in a private temporary directory
+(or named pipes with --fifo) and removed when docker compose exits; a stored .env is also passed as --env-file.
+Requires docker compose 2.24.4 or later`,
+	Example: "  bwsf compose -- up -d\n  bwsf compose render --output compose.ci.yaml",
+	Run:     runCompose,
+}
+
+var composeRenderCmd = &cobra.Command{
+	Use:   "render",
+	Short: "Print a compose override file with the values as environment blocks",
+	Long: `Print a compose override file that replaces the matched env_file entries with environment blocks holding their values,
+for CI jobs that cannot keep files around. Use it with docker compose -f compose.yaml -f <override>.
+The output contains secret values; do not commit it`,
+	Args: cobra.NoArgs,
+	Run:  runComposeRender,
+}
+
+func init() {
+	composeCmd.PersistentFlags().String("compose-file", "", "Compose file to read (default: compose.yaml, compose.yml, docker-compose.yaml or docker-compose.yml)")
+	composeCmd.PersistentFlags().Bool("interpolate", false, "Expand ${KEY} and bw://item/... references in the values")
+	composeCmd.Flags().Bool("fifo", false, "Serve each file through a named pipe instead of a tmpfs file")
+	composeRenderCmd.Flags().String("output", "", "Write the override file here (mode 0600) instead of stdout")
+	composeCmd.AddCommand(composeRenderCmd)
+	rootCmd.AddCommand(composeCmd)
+}
+
+// mustComposeFile resolves --compose-file, or finds the default compose file in the current directory.
+func mustComposeFile(cmd *cobra.Command) string {
+	composeFile, _ := cmd.Flags().GetString("compose-file")
+	if composeFile == "" {
+		wd, err := os.Getwd()
+		if err != nil {
+			utils.Errorln("[ERROR] Failed to get current working directory:", err)
+			os.Exit(1)
+		}
+		if composeFile, err = core.FindComposeFile(infra.NewFileSystem(), wd); err != nil {
+			utils.Errorln("[ERROR]", err)
+			os.Exit(1)
+		}
+	}
+	absComposeFile, err := filepath.Abs(composeFile)
+	if err != nil {
+		utils.Errorln("[ERROR] Failed to resolve --compose-file:", err)
+		os.Exit(1)
+	}
+	return absComposeFile
+}
+
+func runCompose(cmd *cobra.Command, args []string) {
+	if len(args) == 0 {
+		utils.Errorln("[ERROR] Give the docker compose arguments after --, e.g. bwsf compose -- up -d")
+		os.Exit(1)
+	}
+	if _, err := exec.LookPath("docker"); err != nil {
+		utils.Errorln("[ERROR] ❌ docker command is not installed...")
+		os.Exit(1)
+	}
+	mustCheckBwCommand()
+
+	fifo, _ := cmd.Flags().GetBool("fifo")
+	interpolate, _ := cmd.Flags().GetBool("interpolate")
+	composeFile := mustComposeFile(cmd)
+	projectName := mustCurrentProjectName()
+	cfg := mustLoadConfig()
+	wd, err := os.Getwd()
+	if err != nil {
+		utils.Errorln("[ERROR] Failed to get current working directory:", err)
+		os.Exit(1)
+	}
+
+	mode := core.EphemeralTmpfs
+	if fifo {
+		mode = core.EphemeralFIFO
+	}
+	baseDir, inMemory := ephemeralBaseDir()
+	if !inMemory && !fifo {
+		utils.Warningln("[WARNING]", sharedMemoryDir, "is not available; files are written to", baseDir, "which may be on disk (use --fifo to avoid this)")
+	}
+
+	// Catch signals before the files exist so they are always cleaned up
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, os.Interrupt, syscall.SIGTERM, syscall.SIGHUP)
+	defer signal.Stop(signals)
+
+	run, err := core.PrepareComposeCore(wd, projectName, infra.NewFileSystem(), newBwClient(cmd, cfg), cfg, utils.InputPassword, infra.NewLogger(), core.ComposeOptions{
+		ComposeFile: composeFile,
+		Ephemeral: core.EphemeralOptions{
+			Pull:    core.PullOptions{Interpolate: interpolate, Audit: newAuditLog(cfg)},
+			BaseDir: baseDir,
+			Mode:    mode,
+		},
+	})
+	if err != nil {
+		utils.Errorln("[ERROR]", err)
+		os.Exit(1)
+	}
+	cleanup := func() {
+		if err := run.Files.Cleanup(); err != nil {
+			utils.Errorln("[ERROR]", err)
+		}
+	}
+	utils.Infoln("[INFO] Serving", len(run.Files.Paths), "env file(s) to", strings.Join(run.Services, ", "), "from", run.Files.Dir)
+
+	child, err := utils.StartCommand(append(append([]string{"docker", "compose"}, run.Args...), args...), nil)
+	if err != nil {
+		cleanup()
+		utils.Errorln("[ERROR] Failed to start docker compose:", err)
+		os.Exit(1)
+	}
+	exited := make(chan error, 1)
+	go func() { exited <- child.Wait() }()
+
+	for {
+		select {
+		case err := <-exited:
+			cleanup()
+			if code := utils.ExitCode(err); code != 0 {
+				os.Exit(code)
+			}
+			return
+		case sig := <-signals:
+			// Ctrl-C already reaches docker compose through the terminal; sending it again could force-stop it
+			if sig != os.Interrupt {
+				_ = child.Process.Signal(sig)
+			}
+		}
+	}
+}
+
+func runComposeRender(cmd *cobra.Command, args []string) {
+	mustCheckBwCommand()
+
+	output, _ := cmd.Flags().GetString("output")
+	interpolate, _ := cmd.Flags().GetBool("interpolate")
+
+	// Without --output, everything except the override goes to stderr, because stdout is the YAML
+	logger, prompt := infra.NewLogger(), utils.InputPassword
+	if output == "" {
+		logger, prompt = infra.NewStderrLogger(), utils.InputPasswordTo(os.Stderr)
+	}
+
+	composeFile := mustComposeFile(cmd)
+	projectName := mustCurrentProjectName()
+	cfg := mustLoadConfig()
+	wd, err := os.Getwd()
+	if err != nil {
+		utils.Errorln("[ERROR] Failed to get current working directory:", err)
+		os.Exit(1)
+	}
+
+	override, err := core.RenderComposeCore(wd, projectName, infra.NewFileSystem(), newBwClient(cmd, cfg), cfg, prompt, logger, core.ComposeOptions{
+		ComposeFile: composeFile,
+		Ephemeral:   core.EphemeralOptions{Pull: core.PullOptions{Interpolate: interpolate}},
+	})
+	if err != nil {
+		utils.Errorln("[ERROR]", err)
+		os.Exit(1)
+	}
+
+	if output == "" {
+		_, _ = os.Stdout.WriteString(override)
+		return
+	}
+	if err := os.WriteFile(output, []byte(override), 0600); err != nil {
+		utils.Errorln("[ERROR] Failed to write", output+":", err)
+		os.Exit(1)
+	}
+	utils.Infoln("[INFO] Wrote", output, "(contains secret values; do not commit it)")
+}
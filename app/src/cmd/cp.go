@@ -0,0 +1,57 @@
+package cmd
+
+import (
+	"bwsf/src/core"
+	"bwsf/src/infra"
+	"bwsf/src/utils"
+	"os"
+
+	"github.com/spf13/cobra"
+)
+
+var cpCmd = &cobra.Command{
+	Use:   "cp <src> <dst>",
+	Short: "Copy a project in Bitwarden",
+	Long:  "Copy a project item to a new project in the configured Bitwarden folder, optionally only some of its env files",
+	Args:  cobra.ExactArgs(2),
+	Run:   runCp,
+}
+
+func init() {
+	cpCmd.Flags().StringSlice("file", nil, "Env file to copy (repeatable, default: all files)")
+	cpCmd.ValidArgsFunction = completeProjectArgs(1)
+	_ = cpCmd.RegisterFlagCompletionFunc("file", completeStoredFiles(false, true))
+	rootCmd.AddCommand(cpCmd)
+}
+
+func runCp(cmd *cobra.Command, args []string) {
+	mustCheckBwCommand()
+
+	srcName, dstName := args[0], args[1]
+	files, err := cmd.Flags().GetStringSlice("file")
+	if err != nil {
+		utils.Errorln("[ERROR] Failed to get --file flag:", err)
+		os.Exit(1)
+	}
+
+	cfg := mustLoadConfig()
+
+	// Create dependencies
+	bw := newBwClient(cmd, cfg)
+	logger := infra.NewLogger()
+
+	// Call core logic
+	copied, err := core.CopyProjectCore(srcName, dstName, files, bw, cfg, utils.InputPassword, logger)
+	if err != nil {
+		utils.Errorln("[ERROR]", err)
+		os.Exit(1)
+	}
+
+	recordAudit(newAuditLog(cfg), core.AuditEntry{Op: core.AuditOpCopy, Project: srcName, Target: dstName, Files: copied})
+	updateNameCache(func(c *core.NameCache) { c.AddFiles(dstName, copied) })
+
+	utils.Successln("[INFO] ✅", len(copied), "env file(s) copied from", srcName, "to", dstName+":")
+	for _, f := range copied {
+		utils.Infoln("  -", f)
+	}
+}
@@ -0,0 +1,134 @@
+package cmd
+
+import (
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"bwsf/src/config"
+	"bwsf/src/core"
+	"bwsf/src/infra"
+	"bwsf/src/utils"
+
+	"github.com/spf13/cobra"
+)
+
+// sharedMemoryDir is the memory-backed directory used for ephemeral files when it exists (Linux)
+const sharedMemoryDir = "/dev/shm"
+
+// ephemeralBaseDir returns where ephemeral files are created and whether that location is memory-backed
+func ephemeralBaseDir() (string, bool) {
+	if info, err := os.Stat(sharedMemoryDir); err == nil && info.IsDir() {
+		return sharedMemoryDir, true
+	}
+	return os.TempDir(), false
+}
+
+// runPullEphemeral serves the pulled files from a private temporary directory while bwsf supervises them.
+// The files are removed when the wrapped command exits, when --ttl expires, when every pipe has been read,
+// or on Ctrl-C when no command is given.
+func runPullEphemeral(cmd *cobra.Command, args []string, projectDir, projectName string, cfg *config.Config, opts core.PullOptions) {
+	fifo, _ := cmd.Flags().GetBool("fifo")
+	reads, _ := cmd.Flags().GetInt("reads")
+	ttl, _ := cmd.Flags().GetDuration("ttl")
+	if reads < 1 {
+		utils.Errorln("[ERROR] --reads must be at least 1")
+		os.Exit(1)
+	}
+	if ttl < 0 {
+		utils.Errorln("[ERROR] --ttl must not be negative")
+		os.Exit(1)
+	}
+
+	mode := core.EphemeralTmpfs
+	if fifo {
+		mode = core.EphemeralFIFO
+	}
+	baseDir, inMemory := ephemeralBaseDir()
+	if !inMemory && !fifo {
+		utils.Warningln("[WARNING]", sharedMemoryDir, "is not available; files are written to", baseDir, "which may be on disk (use --fifo to avoid this)")
+	}
+
+	// Catch signals before the files exist so they are always cleaned up
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, os.Interrupt, syscall.SIGTERM, syscall.SIGHUP)
+	defer signal.Stop(signals)
+
+	files, err := core.PullEphemeralCore(projectDir, projectName, infra.NewFileSystem(), newBwClient(cmd, cfg), cfg, utils.InputPassword, infra.NewLogger(), core.EphemeralOptions{
+		Pull:    opts,
+		BaseDir: baseDir,
+		Mode:    mode,
+		Reads:   reads,
+	})
+	if err != nil {
+		utils.Errorln("[ERROR]", err)
+		os.Exit(1)
+	}
+	cleanup := func(reason string) {
+		if err := files.Cleanup(); err != nil {
+			utils.Errorln("[ERROR]", err)
+			return
+		}
+		utils.Infoln("[INFO] Removed the ephemeral files", reason)
+	}
+
+	utils.Infoln("[INFO] Serving", len(files.Paths), "env file(s) from", files.Dir, "("+core.EphemeralDirEnv+"):")
+	for _, path := range files.Paths {
+		utils.Infoln("  -", path)
+	}
+
+	var expired <-chan time.Time
+	if ttl > 0 {
+		timer := time.NewTimer(ttl)
+		defer timer.Stop()
+		expired = timer.C
+	}
+	done := files.Done()
+
+	if len(args) == 0 {
+		if ttl == 0 && !fifo {
+			utils.Infoln("[INFO] Press Ctrl-C to remove them")
+		}
+		select {
+		case <-done:
+			cleanup("(every pipe has been read)")
+		case <-expired:
+			cleanup("(ttl expired)")
+		case <-signals:
+			cleanup("")
+		}
+		return
+	}
+
+	child, err := utils.StartCommand(args, []string{core.EphemeralDirEnv + "=" + files.Dir})
+	if err != nil {
+		cleanup("")
+		utils.Errorln("[ERROR] Failed to start", args[0]+":", err)
+		os.Exit(1)
+	}
+	exited := make(chan error, 1)
+	go func() { exited <- child.Wait() }()
+
+	for {
+		select {
+		case err := <-exited:
+			cleanup("")
+			if code := utils.ExitCode(err); code != 0 {
+				os.Exit(code)
+			}
+			return
+		case <-expired:
+			cleanup("(ttl expired)")
+			expired = nil
+		case <-done:
+			cleanup("(every pipe has been read)")
+			done = nil
+		case sig := <-signals:
+			// Ctrl-C already reaches the command through the terminal; sending it again could force-stop it
+			if sig != os.Interrupt {
+				_ = child.Process.Signal(sig)
+			}
+		}
+	}
+}
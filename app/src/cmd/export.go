@@ -0,0 +1,95 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"bwsf/src/config"
+	"bwsf/src/core"
+	"bwsf/src/infra"
+	"bwsf/src/utils"
+
+	"github.com/spf13/cobra"
+)
+
+var exportCmd = &cobra.Command{
+	Use:   "export <bash|zsh|fish|direnv>",
+	Short: "Print shell code that exports a project's variables",
+	Long: `Print shell code that exports the variables of the project in --dir, for use with eval.
+Files are resolved like bwsf pull (extends, --env, --file; several files are layered in order, later keys win) but nothing is written to disk.
+Loaded variables are kept in the OS keyring for shell_cache_ttl (default 1m) so changing directories stays fast.
+With --unload, print code that removes the variables loaded by the last export. This command is normally run by bwsf hook`,
+	Args:      cobra.ExactArgs(1),
+	ValidArgs: []string{core.ShellBash, core.ShellZsh, core.ShellFish, core.ShellDirenv},
+	Run:       runExport,
+}
+
+func init() {
+	exportCmd.Flags().String("dir", ".", "Project directory; its name is the project name")
+	exportCmd.Flags().StringSlice("env", nil, "Load the files of these environments (e.g. staging for .env.staging)")
+	exportCmd.Flags().StringSlice("file", nil, "Load these env files (default: .env)")
+	exportCmd.Flags().Bool("interpolate", false, "Expand ${KEY} and bw://item/... references")
+	exportCmd.Flags().Bool("unload", false, "Print code that unsets the variables loaded by the last export")
+	_ = exportCmd.RegisterFlagCompletionFunc("env", completeStoredFiles(true, false))
+	_ = exportCmd.RegisterFlagCompletionFunc("file", completeStoredFiles(false, false))
+	rootCmd.AddCommand(exportCmd)
+}
+
+func runExport(cmd *cobra.Command, args []string) {
+	shell := args[0]
+
+	unload, _ := cmd.Flags().GetBool("unload")
+	if unload {
+		script, err := core.ShellUnloadScript(shell, strings.Fields(os.Getenv(core.ShellKeysEnv)))
+		if err != nil {
+			utils.Errorln("[ERROR]", err)
+			os.Exit(1)
+		}
+		fmt.Fprint(os.Stdout, script)
+		return
+	}
+
+	dir, _ := cmd.Flags().GetString("dir")
+	envs, _ := cmd.Flags().GetStringSlice("env")
+	files, _ := cmd.Flags().GetStringSlice("file")
+	for _, env := range envs {
+		files = append(files, core.EnvFileName(env))
+	}
+	interpolate, _ := cmd.Flags().GetBool("interpolate")
+
+	absDir, err := filepath.Abs(dir)
+	if err != nil {
+		utils.Errorln("[ERROR] Failed to resolve --dir:", err)
+		os.Exit(1)
+	}
+
+	mustCheckBwCommand()
+	cfg := mustLoadConfig()
+	ttl, err := config.ResolveShellCacheTTL(cfg)
+	if err != nil {
+		utils.Errorln("[ERROR]", err)
+		os.Exit(1)
+	}
+
+	// Everything except the script goes to stderr, because stdout is evaluated by the shell
+	logger := infra.NewStderrLogger()
+	vars, err := core.LoadShellEnvCore(absDir, filepath.Base(absDir), infra.NewFileSystem(), newBwClient(cmd, cfg), cfg, utils.InputPasswordTo(os.Stderr), logger, core.ShellEnvOptions{
+		Files:       files,
+		Interpolate: interpolate,
+		Keyring:     infra.NewKeyring(),
+		TTL:         ttl,
+	})
+	if err != nil {
+		utils.Errorln("[ERROR] bwsf:", err)
+		os.Exit(1)
+	}
+
+	script, err := core.ShellExportScript(shell, absDir, vars, os.LookupEnv, logger)
+	if err != nil {
+		utils.Errorln("[ERROR]", err)
+		os.Exit(1)
+	}
+	fmt.Fprint(os.Stdout, script)
+}
@@ -0,0 +1,194 @@
+package cmd
+
+import (
+	"bwsf/src/config"
+	"bwsf/src/core"
+	"bwsf/src/infra"
+	"bwsf/src/utils"
+	"errors"
+	"fmt"
+	"os"
+	"os/user"
+	"path/filepath"
+
+	"github.com/spf13/cobra"
+)
+
+// mustCheckBwCommand exits when the bw command is not installed.
+// The file backend does not use bw, so nothing is checked for it.
+func mustCheckBwCommand() {
+	if cfg, err := config.LoadConfig(); err == nil && cfg != nil && cfg.Backend == config.BackendFile {
+		return
+	}
+	installed, _ := utils.CheckBwCommand()
+	if !installed {
+		utils.Errorln("[ERROR] ❌ bw command is not installed...")
+		os.Exit(1)
+	}
+}
+
+// mustLoadConfig loads the config file, returning an empty config when it does not exist yet
+func mustLoadConfig() *config.Config {
+	cfg, err := config.LoadConfig()
+	if err != nil {
+		utils.Errorln("[ERROR] Failed to load config:", err)
+		os.Exit(1)
+	}
+	if cfg == nil {
+		cfg = &config.Config{}
+	}
+	return cfg
+}
+
+// mustCurrentProjectName returns the current working directory name, which is used as the project name
+func mustCurrentProjectName() string {
+	wd, err := os.Getwd()
+	if err != nil {
+		utils.Errorln("[ERROR] Failed to get current working directory:", err)
+		os.Exit(1)
+	}
+	return filepath.Base(wd)
+}
+
+// newBwClient returns the Bitwarden client wrapped with the metadata cache and, when enabled, the offline mirror.
+// The on-disk cache is skipped (memoizing within the run only) when it is disabled or no OS keyring holds its key.
+func newBwClient(cmd *cobra.Command, cfg *config.Config) core.BwClient {
+	backend, err := config.ResolveBackend(cfg)
+	if err != nil {
+		utils.Errorln("[ERROR]", err)
+		os.Exit(1)
+	}
+	if backend == config.BackendFile {
+		return newFileBwClient(cfg)
+	}
+
+	forceSync, _ := cmd.Flags().GetBool("sync")
+	opts := core.CacheOptions{FolderName: config.ResolveFolderName(cfg), ForceSync: forceSync}
+
+	ttl, err := config.ResolveCacheTTL(cfg)
+	if err != nil {
+		utils.Errorln("[ERROR]", err)
+		os.Exit(1)
+	}
+	if ttl > 0 {
+		// The key lives in the OS keyring, never next to the cache; without a keyring nothing is cached on disk
+		path, pathErr := config.GetCachePath()
+		key, keyErr := core.LoadOrCreateCacheKey(infra.NewKeyring())
+		// Warn on stderr: compose render, k8s apply and export use stdout for their output
+		switch {
+		case pathErr != nil:
+			utils.WarninglnTo(os.Stderr, "[WARNING] Cache disabled:", pathErr)
+		case keyErr == nil:
+			opts.Path, opts.Key, opts.TTL = path, key, ttl
+		case !errors.Is(keyErr, utils.ErrKeyringUnavailable):
+			utils.WarninglnTo(os.Stderr, "[WARNING] Cache disabled:", keyErr)
+		}
+	}
+	client := core.NewCachingBwClient(infra.NewBwClient(), infra.NewFileSystem(), opts)
+
+	// Wrap with the offline mirror when it is enabled (--offline requires it)
+	offline, _ := cmd.Flags().GetBool("offline")
+	mirror := openMirror(offline)
+	if mirror == nil {
+		return client
+	}
+	if pending := mirror.Pending(); len(pending) > 0 && !offline {
+		utils.Warningln("[WARNING]", len(pending), "push(es) made offline are not in Bitwarden yet; run 'bwsf offline sync'")
+	}
+	return core.NewOfflineBwClient(client, mirror, infra.NewLogger(), offline)
+}
+
+// newFileBwClient returns the encrypted local store used by backend "file".
+// It needs neither the metadata cache nor the offline mirror, since it never talks to a server.
+func newFileBwClient(cfg *config.Config) core.BwClient {
+	path, passphrase, err := config.ResolveFileBackend(cfg)
+	if err != nil {
+		utils.Errorln("[ERROR]", err)
+		os.Exit(1)
+	}
+	return infra.NewFileBwClient(path, config.ResolveFolderName(cfg), passphrase)
+}
+
+// newAuditLog returns the audit log configured in config.json, or nil when it is disabled.
+// A sink that cannot be opened is reported and skipped; the command itself still runs.
+func newAuditLog(cfg *config.Config) *core.AuditLog {
+	auditCfg := cfg.Audit
+	if auditCfg == nil {
+		auditCfg = &config.AuditConfig{}
+	}
+	if auditCfg.Disabled {
+		return nil
+	}
+
+	path, err := config.GetAuditPath(cfg)
+	if err != nil {
+		utils.Warningln("[WARNING] Audit log disabled:", err)
+		return nil
+	}
+	sinks := []core.AuditSink{infra.NewFileAuditSink(path)}
+	if auditCfg.ForwardFile != "" {
+		sinks = append(sinks, infra.NewFileAuditSink(auditCfg.ForwardFile))
+	}
+	if auditCfg.Syslog {
+		sink, err := infra.NewSyslogAuditSink()
+		if err != nil {
+			utils.Warningln("[WARNING]", err)
+		} else {
+			sinks = append(sinks, sink)
+		}
+	}
+	return core.NewAuditLog(auditActor(cfg), nil, sinks...)
+}
+
+// auditActor identifies who runs bwsf: the OS user, the host and the Bitwarden account
+func auditActor(cfg *config.Config) core.AuditActor {
+	actor := core.AuditActor{User: os.Getenv("USER"), Profile: cfg.Email}
+	if u, err := user.Current(); err == nil {
+		actor.User = u.Username
+	}
+	if host, err := os.Hostname(); err == nil {
+		actor.Host = host
+	}
+	if cfg.HostType == "selfhosted" && cfg.SelfhostedURL != "" {
+		actor.Profile += " (" + cfg.SelfhostedURL + ")"
+	}
+	return actor
+}
+
+// recordAudit appends entry to the audit log, warning instead of failing the finished command
+func recordAudit(audit *core.AuditLog, entry core.AuditEntry) {
+	if err := audit.Record(entry); err != nil {
+		utils.Warningln("[WARNING]", err)
+	}
+}
+
+// newPushNotifier returns the post-push hooks configured in config.json, or nil when there are none.
+// It exits on an invalid hook so a typo is not silently ignored on every push.
+func newPushNotifier(cfg *config.Config) *core.PushNotifier {
+	if len(cfg.PostPush) == 0 {
+		return nil
+	}
+	var hooks []core.PostPushHook
+	for i, hookCfg := range cfg.PostPush {
+		timeout, retries, err := config.ValidateHook(hookCfg)
+		if err != nil {
+			utils.Errorln(fmt.Sprintf("[ERROR] post_push[%d]: %v", i, err))
+			os.Exit(1)
+		}
+		var hook core.PostPushHook
+		switch hookCfg.Type {
+		case "slack":
+			hook = infra.NewWebhookHook(hookCfg.URL, infra.WebhookFormatSlack, hookCfg.Headers, timeout, retries)
+		case "webhook":
+			hook = infra.NewWebhookHook(hookCfg.URL, infra.WebhookFormatGeneric, hookCfg.Headers, timeout, retries)
+		case "command":
+			hook = infra.NewCommandHook(hookCfg.Command, timeout)
+		}
+		if hook, err = core.FilterHookProjects(hook, hookCfg.Projects); err != nil {
+			utils.Errorln(fmt.Sprintf("[ERROR] post_push[%d]: %v", i, err))
+			os.Exit(1)
+		}
+		hooks = append(hooks, hook)
+	}
+	return core.NewPushNotifier(auditActor(cfg), nil, hooks...)
+}
@@ -0,0 +1,42 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	"bwsf/src/core"
+	"bwsf/src/utils"
+
+	"github.com/spf13/cobra"
+)
+
+var hookCmd = &cobra.Command{
+	Use:   "hook <bash|zsh|fish|direnv> [-- export flags]",
+	Short: "Print a shell hook that loads project variables on cd",
+	Long: `Print a hook that exports a project's variables when you enter a directory containing .bwsf.json, and unsets them when you leave.
+Add it to your shell's rc file:
+
+  bash:  eval "$(bwsf hook bash)"        in ~/.bashrc
+  zsh:   eval "$(bwsf hook zsh)"         in ~/.zshrc
+  fish:  bwsf hook fish | source         in ~/.config/fish/config.fish
+
+Flags after -- are passed to bwsf export, e.g. bwsf hook zsh -- --env local.
+For direnv, "bwsf hook direnv" prints a use_bwsf function; save it to ~/.config/direnv/lib/bwsf.sh and add "use bwsf" to .envrc.
+Variables are never written to disk; see bwsf export`,
+	Args:      cobra.MinimumNArgs(1),
+	ValidArgs: []string{core.ShellBash, core.ShellZsh, core.ShellFish, core.ShellDirenv},
+	Run:       runHook,
+}
+
+func init() {
+	rootCmd.AddCommand(hookCmd)
+}
+
+func runHook(cmd *cobra.Command, args []string) {
+	script, err := core.ShellHookScript(args[0], args[1:])
+	if err != nil {
+		utils.Errorln("[ERROR]", err)
+		os.Exit(1)
+	}
+	fmt.Print(script)
+}
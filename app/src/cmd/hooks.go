@@ -0,0 +1,189 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+
+	"bwsf/src/config"
+	"bwsf/src/core"
+	"bwsf/src/infra"
+	"bwsf/src/utils"
+
+	"github.com/spf13/cobra"
+)
+
+var hooksCmd = &cobra.Command{
+	Use:   "hooks",
+	Short: "Manage git hooks that keep secrets out of commits",
+}
+
+var hooksInstallCmd = &cobra.Command{
+	Use:   "install",
+	Short: "Install the bwsf git hooks in the current repository",
+	Long: `Install a pre-commit hook that rejects staged env files and files containing values of the stored project.
+With --status-check, post-checkout and post-merge hooks also warn when the vault has newer env files than this directory.
+Existing hooks are kept and run first; bwsf hooks uninstall restores them`,
+	Args: cobra.NoArgs,
+	Run:  runHooksInstall,
+}
+
+var hooksUninstallCmd = &cobra.Command{
+	Use:   "uninstall",
+	Short: "Remove the bwsf git hooks and restore the previous ones",
+	Args:  cobra.NoArgs,
+	Run:   runHooksUninstall,
+}
+
+var hooksRunCmd = &cobra.Command{
+	Use:    "run <hook> [-- git hook arguments]",
+	Short:  "Run a bwsf git hook (called by the installed hooks)",
+	Args:   cobra.MinimumNArgs(1),
+	Hidden: true,
+	Run:    runHooksRun,
+}
+
+func init() {
+	hooksInstallCmd.Flags().Bool("status-check", false, "Also warn after checkout and merge when the vault has newer env files")
+	hooksInstallCmd.Flags().String("project", "", "Project checked by the hooks (default: current directory name)")
+	hooksRunCmd.Flags().String("project", "", "Project name")
+	hooksRunCmd.Flags().String("dir", ".", "Directory of the env files, relative to the work tree root")
+	hooksCmd.AddCommand(hooksInstallCmd, hooksUninstallCmd, hooksRunCmd)
+	rootCmd.AddCommand(hooksCmd)
+}
+
+// mustGitRoot returns the work tree root containing the current directory, along with the current directory
+func mustGitRoot() (string, string) {
+	wd, err := os.Getwd()
+	if err != nil {
+		utils.Errorln("[ERROR] Failed to get current working directory:", err)
+		os.Exit(1)
+	}
+	root, err := infra.NewGitInspector().WorkTreeRoot(wd)
+	if err != nil {
+		utils.Errorln("[ERROR]", err)
+		os.Exit(1)
+	}
+	if root == "" {
+		utils.Errorln("[ERROR] Not inside a git repository")
+		os.Exit(1)
+	}
+	return root, wd
+}
+
+// mustHooksDir returns the hooks directory of the repository containing dir
+func mustHooksDir(dir string) string {
+	hooksDir, err := infra.NewGitInspector().HooksDir(dir)
+	if err != nil {
+		utils.Errorln("[ERROR] Failed to find the git hooks directory:", err)
+		os.Exit(1)
+	}
+	return hooksDir
+}
+
+func runHooksInstall(cmd *cobra.Command, args []string) {
+	root, wd := mustGitRoot()
+	statusCheck, _ := cmd.Flags().GetBool("status-check")
+	project, _ := cmd.Flags().GetString("project")
+	if project == "" {
+		project = filepath.Base(wd)
+	}
+
+	// Resolve symlinks so the directory is relative to the root git reports
+	realWd, err := filepath.EvalSymlinks(wd)
+	if err != nil {
+		realWd = wd
+	}
+	dir, err := filepath.Rel(root, realWd)
+	if err != nil {
+		utils.Errorln("[ERROR] Failed to resolve the directory in the repository:", err)
+		os.Exit(1)
+	}
+
+	hooksDir := mustHooksDir(wd)
+	installed, err := core.InstallGitHooksCore(infra.NewFileSystem(), hooksDir, core.GitHooksOptions{
+		Project:     project,
+		Dir:         dir,
+		StatusCheck: statusCheck,
+	}, infra.NewLogger())
+	if err != nil {
+		utils.Errorln("[ERROR]", err)
+		os.Exit(1)
+	}
+
+	utils.Successln("[INFO] ✅ Installed", strings.Join(installed, ", "), "in", hooksDir, "for project", project)
+}
+
+func runHooksUninstall(cmd *cobra.Command, args []string) {
+	_, wd := mustGitRoot()
+	hooksDir := mustHooksDir(wd)
+
+	removed, err := core.UninstallGitHooksCore(infra.NewFileSystem(), hooksDir, infra.NewLogger())
+	if err != nil {
+		utils.Errorln("[ERROR]", err)
+		os.Exit(1)
+	}
+	if len(removed) == 0 {
+		utils.Infoln("[INFO] No bwsf hooks installed in", hooksDir)
+		return
+	}
+	utils.Successln("[INFO] ✅ Removed", strings.Join(removed, ", "), "from", hooksDir)
+}
+
+func runHooksRun(cmd *cobra.Command, args []string) {
+	hook, hookArgs := args[0], args[1:]
+	root, wd := mustGitRoot()
+	project, _ := cmd.Flags().GetString("project")
+	if project == "" {
+		project = filepath.Base(wd)
+	}
+	dir, _ := cmd.Flags().GetString("dir")
+
+	cfg := mustLoadConfig()
+	bw := newBwClient(cmd, cfg)
+	logger := infra.NewLogger()
+
+	// Hooks run inside git commit/checkout (often from GUI clients), so a locked vault never prompts
+	switch hook {
+	case core.GitHookPreCommit:
+		findings, err := core.PreCommitCore(root, dir, project, infra.NewGitInspector(), infra.NewFileSystem(), bw, cfg, core.HookPasswordPrompt, logger)
+		if err != nil {
+			utils.Errorln("[ERROR] bwsf pre-commit:", err)
+			os.Exit(1)
+		}
+		if len(findings) == 0 {
+			return
+		}
+		utils.Errorln("[ERROR] bwsf: commit blocked, secrets are staged:")
+		for _, f := range findings {
+			utils.Errorln("  -", f.String())
+		}
+		utils.Errorln("Unstage them with `git restore --staged <file>`, or commit with --no-verify if this is intended.")
+		os.Exit(1)
+
+	case core.GitHookPostCheckout, core.GitHookPostMerge:
+		// post-checkout gets "<prev> <new> <branch flag>"; skip file checkouts (flag 0)
+		if hook == core.GitHookPostCheckout && len(hookArgs) >= 3 && hookArgs[2] == "0" {
+			return
+		}
+		statePath, err := config.GetStatePath()
+		if err != nil {
+			utils.Warningln("[WARNING] bwsf:", err)
+			return
+		}
+		absDir := filepath.Join(root, dir)
+		statuses, err := core.StatusEnvCore(absDir, project, statePath, infra.NewFileSystem(), bw, cfg, core.HookPasswordPrompt, logger)
+		if err != nil {
+			// The checkout has already happened; never fail it
+			utils.Warningln("[WARNING] bwsf: skipped the vault status check:", err)
+			return
+		}
+		if newer := core.VaultNewerFiles(statuses); len(newer) > 0 {
+			utils.Warningln("[WARNING] bwsf: the vault has newer env files for", project+":", strings.Join(newer, ", "), "(run `bwsf pull` in", absDir+")")
+		}
+
+	default:
+		utils.Errorln("[ERROR] Unknown hook:", hook)
+		os.Exit(1)
+	}
+}
@@ -0,0 +1,129 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+
+	"bwsf/src/core"
+	"bwsf/src/infra"
+	"bwsf/src/utils"
+
+	"github.com/spf13/cobra"
+)
+
+var k8sCmd = &cobra.Command{
+	Use:   "k8s",
+	Short: "Convert env files to and from Kubernetes Secret manifests",
+	Long: `Convert env files to and from Kubernetes Secret manifests.
+bwsf only reads and writes files and stdout; apply the manifests with kubectl (or seal them with kubeseal) yourself`,
+}
+
+var k8sApplyCmd = &cobra.Command{
+	Use:   "apply",
+	Short: "Render a Secret manifest from a stored env file",
+	Long: `Render a Secret manifest from a stored env file and print it, or with --output, show the changed keys against
+the existing manifest and write it (mode 0600). The manifest is not written when nothing changed or with --dry-run`,
+	Example: "  bwsf k8s apply --namespace prod --name web-env --file .env.production --output k8s/secret.yaml\n" +
+		"  bwsf k8s apply --namespace prod --name web-env --file .env.production | kubeseal -o yaml > sealed.yaml",
+	Args: cobra.NoArgs,
+	Run:  runK8sApply,
+}
+
+var k8sImportCmd = &cobra.Command{
+	Use:   "import <secret.yaml>",
+	Short: "Convert a Secret manifest to an env file",
+	Long: `Convert the data and stringData of a Secret manifest to env file lines and print them, or write them to --file.
+Run bwsf push afterwards to store them in Bitwarden`,
+	Args: cobra.ExactArgs(1),
+	Run:  runK8sImport,
+}
+
+func init() {
+	k8sApplyCmd.Flags().String("namespace", "", "Namespace of the Secret")
+	k8sApplyCmd.Flags().String("name", "", "Name of the Secret (required)")
+	k8sApplyCmd.Flags().String("file", ".env", "Stored env file to render")
+	k8sApplyCmd.Flags().String("output", "", "Manifest file to compare with and write (default: print to stdout)")
+	k8sApplyCmd.Flags().Bool("dry-run", false, "With --output, only show the changed keys")
+	k8sApplyCmd.Flags().Bool("interpolate", false, "Expand ${KEY} and bw://item/... references in the values")
+	_ = k8sApplyCmd.RegisterFlagCompletionFunc("file", completeStoredFiles(false, false))
+	k8sImportCmd.Flags().String("file", "", "Env file to write (default: print to stdout)")
+	k8sImportCmd.Flags().Bool("force", false, "Overwrite --file if it exists")
+	k8sCmd.AddCommand(k8sApplyCmd, k8sImportCmd)
+	rootCmd.AddCommand(k8sCmd)
+}
+
+func runK8sApply(cmd *cobra.Command, args []string) {
+	namespace, _ := cmd.Flags().GetString("namespace")
+	name, _ := cmd.Flags().GetString("name")
+	file, _ := cmd.Flags().GetString("file")
+	output, _ := cmd.Flags().GetString("output")
+	dryRun, _ := cmd.Flags().GetBool("dry-run")
+	interpolate, _ := cmd.Flags().GetBool("interpolate")
+
+	// Without --output, everything except the manifest goes to stderr, so it can be piped to kubeseal
+	logger, prompt := infra.NewLogger(), utils.InputPassword
+	if output == "" {
+		logger, prompt = infra.NewStderrLogger(), utils.InputPasswordTo(os.Stderr)
+	}
+
+	if name == "" {
+		utils.Errorln("[ERROR] --name is required")
+		os.Exit(1)
+	}
+	if dryRun && output == "" {
+		utils.Errorln("[ERROR] --dry-run requires --output")
+		os.Exit(1)
+	}
+	mustCheckBwCommand()
+	projectName := mustCurrentProjectName()
+	cfg := mustLoadConfig()
+	wd, err := os.Getwd()
+	if err != nil {
+		utils.Errorln("[ERROR] Failed to get current working directory:", err)
+		os.Exit(1)
+	}
+	if output != "" {
+		if output, err = filepath.Abs(output); err != nil {
+			utils.Errorln("[ERROR] Failed to resolve --output:", err)
+			os.Exit(1)
+		}
+	}
+
+	result, err := core.ApplyK8sSecretCore(wd, projectName, infra.NewFileSystem(), newBwClient(cmd, cfg), cfg, prompt, logger, core.K8sApplyOptions{
+		Namespace:   namespace,
+		Name:        name,
+		File:        file,
+		Interpolate: interpolate,
+		Manifest:    output,
+		DryRun:      dryRun,
+	})
+	if err != nil {
+		utils.Errorln("[ERROR]", err)
+		os.Exit(1)
+	}
+
+	switch {
+	case output == "":
+		_, _ = os.Stdout.WriteString(result.Manifest)
+	case result.Written:
+		utils.Successln("[INFO] ✅ Wrote Secret", name, "to", output)
+	case dryRun:
+		utils.Infoln("[INFO] Dry run;", output, "was not written")
+	}
+}
+
+func runK8sImport(cmd *cobra.Command, args []string) {
+	file, _ := cmd.Flags().GetString("file")
+	force, _ := cmd.Flags().GetBool("force")
+
+	content, err := core.ImportK8sSecretCore(infra.NewFileSystem(), args[0], infra.NewLogger(), core.K8sImportOptions{Output: file, Force: force})
+	if err != nil {
+		utils.Errorln("[ERROR]", err)
+		os.Exit(1)
+	}
+	if file == "" {
+		_, _ = os.Stdout.WriteString(content)
+		return
+	}
+	utils.Successln("[INFO] ✅ Wrote", file, "; run bwsf push to store it in Bitwarden")
+}
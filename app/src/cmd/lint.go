@@ -0,0 +1,60 @@
+package cmd
+
+import (
+	"bwsf/src/config"
+	"bwsf/src/core"
+	"bwsf/src/infra"
+	"bwsf/src/utils"
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+)
+
+var lintCmd = &cobra.Command{
+	Use:   "lint",
+	Short: "Lint local .env files",
+	Long:  "Check local .env* files for duplicate keys, invalid keys, quoting problems, whitespace, BOMs, CRLF endings, empty required values and placeholders. Rule severities are configured in .bwsf.json",
+	Run:   runLint,
+}
+
+func init() {
+	lintCmd.Flags().String("dir", ".", "Directory containing .env files")
+	rootCmd.AddCommand(lintCmd)
+}
+
+func runLint(cmd *cobra.Command, args []string) {
+	dir, err := cmd.Flags().GetString("dir")
+	if err != nil {
+		utils.Errorln("[ERROR] Failed to get --dir flag:", err)
+		os.Exit(1)
+	}
+
+	fs := infra.NewFileSystem()
+
+	issues, err := core.LintEnvCore(dir, fs)
+	if err != nil {
+		utils.Errorln("[ERROR]", err)
+		os.Exit(1)
+	}
+
+	if len(issues) == 0 {
+		utils.Successln("[INFO] ✅ No lint issues found")
+		return
+	}
+
+	errorCount := 0
+	for _, issue := range issues {
+		if issue.Severity == config.SeverityError {
+			errorCount++
+			utils.Errorln("[ERROR]", issue.String())
+		} else {
+			utils.Warningln("[WARN]", issue.String())
+		}
+	}
+
+	fmt.Printf("%d issue(s), %d error(s)\n", len(issues), errorCount)
+	if errorCount > 0 {
+		os.Exit(1)
+	}
+}
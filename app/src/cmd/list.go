@@ -24,11 +24,7 @@ func init() {
 
 func runList(cmd *cobra.Command, args []string) {
 	// Check if bw command is installed
-	installed, _ := utils.CheckBwCommand()
-	if !installed {
-		utils.Errorln("[ERROR] ❌ bw command is not installed...")
-		os.Exit(1)
-	}
+	mustCheckBwCommand()
 
 	// Load config
 	cfg, err := config.LoadConfig()
@@ -41,7 +37,7 @@ func runList(cmd *cobra.Command, args []string) {
 	}
 
 	// Create dependencies
-	bw := infra.NewBwClient()
+	bw := newBwClient(cmd, cfg)
 	logger := infra.NewLogger()
 
 	// Call core logic
@@ -63,7 +59,10 @@ func runList(cmd *cobra.Command, args []string) {
 		return
 	}
 
+	names := make([]string, 0, len(items))
 	for _, item := range items {
 		fmt.Println(item.Name)
+		names = append(names, item.Name)
 	}
+	updateNameCache(func(c *core.NameCache) { c.SetProjects(names) })
 }
@@ -0,0 +1,170 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"bwsf/src/core"
+	"bwsf/src/infra"
+	"bwsf/src/utils"
+
+	"github.com/spf13/cobra"
+)
+
+var metaCmd = &cobra.Command{
+	Use:   "meta <project> [KEY]",
+	Short: "Show or edit per-key metadata",
+	Long: `Show the owner, description, tags, timestamps and expiry of each key in a stored env file,
+or edit them for KEY with --owner, --description, --tag and --expires. Metadata is stored with the item and never written to .env files`,
+	Example: `  bwsf meta my-app
+  bwsf meta my-app STRIPE_KEY --owner payments-team --tag pci --expires 2026-12-31`,
+	Args: cobra.RangeArgs(1, 2),
+	Run:  runMeta,
+}
+
+func init() {
+	metaCmd.Flags().String("file", ".env", "Env file that contains the keys")
+	metaCmd.Flags().String("owner", "", "Set the owner (empty to clear)")
+	metaCmd.Flags().String("description", "", "Set the description (empty to clear)")
+	metaCmd.Flags().StringSlice("tag", nil, "Set the tags (repeatable, replaces existing tags; --tag \"\" clears)")
+	metaCmd.Flags().String("expires", "", "Set the expiry: a date, an RFC3339 time, or a duration from now such as 90d (empty to clear)")
+	metaCmd.Flags().Bool("json", false, "Print the metadata as JSON")
+	metaCmd.ValidArgsFunction = completeProjectArgs(1)
+	_ = metaCmd.RegisterFlagCompletionFunc("file", completeStoredFiles(false, true))
+	rootCmd.AddCommand(metaCmd)
+}
+
+func runMeta(cmd *cobra.Command, args []string) {
+	mustCheckBwCommand()
+
+	projectName := args[0]
+	file, _ := cmd.Flags().GetString("file")
+	asJSON, _ := cmd.Flags().GetBool("json")
+
+	update, err := metaUpdateFromFlags(cmd)
+	if err != nil {
+		utils.Errorln("[ERROR]", err)
+		os.Exit(1)
+	}
+	editing := update.Owner != nil || update.Description != nil || update.Tags != nil || update.ExpiresAt != nil
+	if editing && len(args) < 2 {
+		utils.Errorln("[ERROR] Specify the KEY to edit")
+		os.Exit(1)
+	}
+
+	cfg := mustLoadConfig()
+
+	// Create dependencies
+	bw := newBwClient(cmd, cfg)
+	logger := infra.NewLogger()
+
+	if editing {
+		key := args[1]
+		meta, err := core.UpdateKeyMetaCore(projectName, file, key, update, bw, cfg, utils.InputPassword, logger)
+		if err != nil {
+			utils.Errorln("[ERROR]", err)
+			os.Exit(1)
+		}
+		if asJSON {
+			printMetaJSON([]core.KeyMetaEntry{{Key: key, KeyMeta: meta}})
+			return
+		}
+		utils.Successln("[INFO] ✅ Metadata updated for", key, "in", projectName+"/"+file)
+		printMetaTable([]core.KeyMetaEntry{{Key: key, KeyMeta: meta}})
+		return
+	}
+
+	entries, err := core.ListKeyMetaCore(projectName, file, bw, cfg, utils.InputPassword, logger)
+	if err != nil {
+		utils.Errorln("[ERROR]", err)
+		os.Exit(1)
+	}
+	if len(args) == 2 {
+		var selected []core.KeyMetaEntry
+		for _, entry := range entries {
+			if entry.Key == args[1] {
+				selected = append(selected, entry)
+			}
+		}
+		if len(selected) == 0 {
+			utils.Errorln("[ERROR] key", args[1], "not found in", projectName+"/"+file)
+			os.Exit(1)
+		}
+		entries = selected
+	}
+
+	if asJSON {
+		printMetaJSON(entries)
+		return
+	}
+	printMetaTable(entries)
+}
+
+// metaUpdateFromFlags collects the edit flags that were given; unset flags leave the metadata unchanged
+func metaUpdateFromFlags(cmd *cobra.Command) (core.KeyMetaUpdate, error) {
+	var update core.KeyMetaUpdate
+	flags := cmd.Flags()
+	if flags.Changed("owner") {
+		owner, _ := flags.GetString("owner")
+		update.Owner = &owner
+	}
+	if flags.Changed("description") {
+		description, _ := flags.GetString("description")
+		update.Description = &description
+	}
+	if flags.Changed("tag") {
+		tags, _ := flags.GetStringSlice("tag")
+		update.Tags = &tags
+	}
+	if flags.Changed("expires") {
+		value, _ := flags.GetString("expires")
+		expires := ""
+		if strings.TrimSpace(value) != "" {
+			t, err := core.ParseExpiry(value, time.Now())
+			if err != nil {
+				return update, err
+			}
+			expires = t.UTC().Format(time.RFC3339)
+		}
+		update.ExpiresAt = &expires
+	}
+	return update, nil
+}
+
+func printMetaJSON(entries []core.KeyMetaEntry) {
+	if entries == nil {
+		entries = []core.KeyMetaEntry{}
+	}
+	data, _ := json.MarshalIndent(entries, "", "  ")
+	fmt.Println(string(data))
+}
+
+func printMetaTable(entries []core.KeyMetaEntry) {
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "KEY\tOWNER\tCREATED\tROTATED\tEXPIRES\tTAGS\tDESCRIPTION")
+	for _, e := range entries {
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\t%s\n",
+			e.Key, orDash(e.Owner), formatMetaDate(e.CreatedAt), formatMetaDate(e.RotatedAt), formatMetaDate(e.ExpiresAt),
+			orDash(strings.Join(e.Tags, ",")), orDash(e.Description))
+	}
+	w.Flush()
+}
+
+// formatMetaDate shows an RFC3339 timestamp as a local date
+func formatMetaDate(value string) string {
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t.Local().Format("2006-01-02")
+	}
+	return orDash(value)
+}
+
+func orDash(value string) string {
+	if value == "" {
+		return "-"
+	}
+	return value
+}
@@ -0,0 +1,46 @@
+package cmd
+
+import (
+	"bwsf/src/core"
+	"bwsf/src/infra"
+	"bwsf/src/utils"
+	"os"
+
+	"github.com/spf13/cobra"
+)
+
+var mvCmd = &cobra.Command{
+	Use:   "mv <old> <new>",
+	Short: "Rename a project in Bitwarden",
+	Long:  "Rename a project item in the configured Bitwarden folder, e.g. after renaming its repository",
+	Args:  cobra.ExactArgs(2),
+	Run:   runMv,
+}
+
+func init() {
+	mvCmd.ValidArgsFunction = completeProjectArgs(1)
+	rootCmd.AddCommand(mvCmd)
+}
+
+func runMv(cmd *cobra.Command, args []string) {
+	mustCheckBwCommand()
+
+	oldName, newName := args[0], args[1]
+	cfg := mustLoadConfig()
+
+	// Create dependencies
+	bw := newBwClient(cmd, cfg)
+	logger := infra.NewLogger()
+
+	// Call core logic
+	err := core.RenameProjectCore(oldName, newName, bw, cfg, utils.InputPassword, logger)
+	if err != nil {
+		utils.Errorln("[ERROR]", err)
+		os.Exit(1)
+	}
+
+	recordAudit(newAuditLog(cfg), core.AuditEntry{Op: core.AuditOpRename, Project: oldName, Target: newName})
+	updateNameCache(func(c *core.NameCache) { c.RenameProject(oldName, newName) })
+
+	utils.Successln("[INFO] ✅", oldName, "renamed to", newName)
+}
@@ -0,0 +1,221 @@
+package cmd
+
+import (
+	"bwsf/src/config"
+	"bwsf/src/core"
+	"bwsf/src/infra"
+	"bwsf/src/utils"
+	"errors"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+var offlineCmd = &cobra.Command{
+	Use:   "offline",
+	Short: "Manage the encrypted offline mirror",
+	Long:  "Keep an encrypted local copy of the projects you push and pull, so that pull and push keep working when the Bitwarden server is unreachable",
+}
+
+var offlineEnableCmd = &cobra.Command{
+	Use:   "enable",
+	Short: "Create the offline mirror",
+	Long:  "Create the offline mirror. Its key is protected by your master password, or stored in the OS keyring with --keyring",
+	Args:  cobra.NoArgs,
+	Run:   runOfflineEnable,
+}
+
+var offlineDisableCmd = &cobra.Command{
+	Use:   "disable",
+	Short: "Delete the offline mirror",
+	Args:  cobra.NoArgs,
+	Run:   runOfflineDisable,
+}
+
+var offlineStatusCmd = &cobra.Command{
+	Use:   "status",
+	Short: "Show mirrored projects, their age and queued pushes",
+	Args:  cobra.NoArgs,
+	Run:   runOfflineStatus,
+}
+
+var offlineSyncCmd = &cobra.Command{
+	Use:   "sync",
+	Short: "Push the changes queued while offline",
+	Long:  "Push the changes queued while offline. A queued push is skipped as a conflict when the project was changed in Bitwarden after it was mirrored, unless --force is given",
+	Args:  cobra.NoArgs,
+	Run:   runOfflineSync,
+}
+
+var offlineDiscardCmd = &cobra.Command{
+	Use:   "discard <project>",
+	Short: "Drop a push queued while offline",
+	Args:  cobra.ExactArgs(1),
+	Run:   runOfflineDiscard,
+}
+
+func init() {
+	offlineEnableCmd.Flags().Bool("keyring", false, "Store the mirror key in the OS keyring instead of protecting it with the master password")
+	offlineDisableCmd.Flags().Bool("force", false, "Delete the mirror even if pushes are still queued")
+	offlineSyncCmd.Flags().Bool("force", false, "Overwrite projects that were changed in Bitwarden since they were mirrored")
+	offlineDiscardCmd.ValidArgsFunction = completeProjectArgs(1)
+	offlineCmd.AddCommand(offlineEnableCmd, offlineDisableCmd, offlineStatusCmd, offlineSyncCmd, offlineDiscardCmd)
+	rootCmd.AddCommand(offlineCmd)
+}
+
+// mirrorPasswordPrompt asks for the master password that protects the offline mirror key
+func mirrorPasswordPrompt() (string, error) {
+	utils.Infoln("[INFO] Enter your master password to unlock the offline mirror")
+	return utils.InputPassword()
+}
+
+// openMirror opens the offline mirror. It returns nil when the mirror is not enabled,
+// and exits when required is true and the mirror cannot be used.
+func openMirror(required bool) *core.Mirror {
+	path, err := config.GetMirrorPath()
+	if err == nil {
+		var mirror *core.Mirror
+		mirror, err = core.OpenMirror(infra.NewFileSystem(), path, core.MirrorOptions{
+			Keyring:        infra.NewKeyring(),
+			PromptPassword: mirrorPasswordPrompt,
+		})
+		if err == nil {
+			return mirror
+		}
+	}
+
+	if required {
+		utils.Errorln("[ERROR]", err)
+		os.Exit(1)
+	}
+	if !errors.Is(err, core.ErrMirrorDisabled) {
+		utils.WarninglnTo(os.Stderr, "[WARNING] Offline mirror unavailable:", err)
+	}
+	return nil
+}
+
+func runOfflineEnable(cmd *cobra.Command, args []string) {
+	useKeyring, _ := cmd.Flags().GetBool("keyring")
+
+	path, err := config.GetMirrorPath()
+	if err != nil {
+		utils.Errorln("[ERROR]", err)
+		os.Exit(1)
+	}
+
+	keySource := core.MirrorKeyPassword
+	password := ""
+	if useKeyring {
+		keySource = core.MirrorKeyKeyring
+	} else {
+		mustCheckBwCommand()
+		utils.Infoln("[INFO] Enter your master password; it will be needed to read the mirror while offline")
+		password, err = utils.InputPassword()
+		if err != nil {
+			utils.Errorln("[ERROR]", err)
+			os.Exit(1)
+		}
+		// Make sure it is the master password, not a typo that would lock the mirror forever
+		if err := infra.NewBwClient().Unlock(password); err != nil {
+			utils.Errorln("[ERROR] Failed to verify the master password:", err)
+			os.Exit(1)
+		}
+	}
+
+	if err := core.EnableMirror(infra.NewFileSystem(), path, keySource, password, infra.NewKeyring()); err != nil {
+		utils.Errorln("[ERROR]", err)
+		os.Exit(1)
+	}
+	utils.Successln("[SUCCESS] Offline mirror enabled:", path)
+	utils.Infoln("[INFO] Projects are mirrored the next time you pull or push them")
+}
+
+func runOfflineDisable(cmd *cobra.Command, args []string) {
+	force, _ := cmd.Flags().GetBool("force")
+	mirror := openMirror(true)
+
+	if pending := mirror.Pending(); len(pending) > 0 && !force {
+		utils.Errorln("[ERROR]", len(pending), "push(es) made offline are not in Bitwarden yet; run 'bwsf offline sync' or use --force")
+		os.Exit(1)
+	}
+
+	if mirror.KeySource() == core.MirrorKeyKeyring {
+		if err := infra.NewKeyring().Delete(core.MirrorKeyringService, core.MirrorKeyringAccount); err != nil {
+			utils.Warningln("[WARNING] Failed to remove the mirror key from the OS keyring:", err)
+		}
+	}
+	path, _ := config.GetMirrorPath()
+	if err := os.Remove(path); err != nil {
+		utils.Errorln("[ERROR] Failed to delete the offline mirror:", err)
+		os.Exit(1)
+	}
+	utils.Successln("[SUCCESS] Offline mirror deleted")
+}
+
+func runOfflineStatus(cmd *cobra.Command, args []string) {
+	mirror := openMirror(true)
+	projects := mirror.Projects()
+
+	fmt.Println("Key:", mirror.KeySource())
+	if len(projects) == 0 {
+		fmt.Println("No projects mirrored yet")
+		return
+	}
+
+	now := time.Now()
+	for _, p := range projects {
+		line := fmt.Sprintf("  %-30s saved %s ago", p.Name, core.FormatAge(now.Sub(p.SavedAt)))
+		if !p.QueuedAt.IsZero() {
+			utils.Warningln(line + fmt.Sprintf(" (push queued %s ago)", core.FormatAge(now.Sub(p.QueuedAt))))
+			continue
+		}
+		fmt.Println(line)
+	}
+}
+
+func runOfflineSync(cmd *cobra.Command, args []string) {
+	mustCheckBwCommand()
+	force, _ := cmd.Flags().GetBool("force")
+	mirror := openMirror(true)
+	cfg := mustLoadConfig()
+
+	if len(mirror.Pending()) == 0 {
+		utils.Infoln("[INFO] No queued pushes")
+		return
+	}
+
+	// Talk to Bitwarden directly: the conflict check needs fresh server data, never the mirror or the cache
+	results, err := core.ReplayOfflinePushesCore(infra.NewBwClient(), mirror, cfg, utils.InputPassword, infra.NewLogger(), force)
+	if err != nil {
+		utils.Errorln("[ERROR]", err)
+		os.Exit(1)
+	}
+
+	failed := false
+	for _, r := range results {
+		switch {
+		case r.Err != nil:
+			failed = true
+			utils.Errorln(fmt.Sprintf("[ERROR] %s: %v", r.Project, r.Err))
+		case r.Conflict:
+			failed = true
+			utils.Warningln(fmt.Sprintf("[WARNING] %s: changed in Bitwarden since it was mirrored; pull and merge, then push again, or use --force", r.Project))
+		default:
+			utils.Successln(fmt.Sprintf("[SUCCESS] %s: pushed", r.Project))
+		}
+	}
+	if failed {
+		os.Exit(1)
+	}
+}
+
+func runOfflineDiscard(cmd *cobra.Command, args []string) {
+	mirror := openMirror(true)
+	if err := mirror.Discard(args[0]); err != nil {
+		utils.Errorln("[ERROR]", err)
+		os.Exit(1)
+	}
+	utils.Successln("[SUCCESS] Discarded the queued push of", args[0])
+}
@@ -8,6 +8,7 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"strings"
 
 	"github.com/spf13/cobra"
 )
@@ -15,21 +16,39 @@ import (
 var pullCmd = &cobra.Command{
 	Use:   "pull",
 	Short: "Pull .env file from Bitwarden",
-	Long:  "Pull .env file from Bitwarden and save it to the current directory or specified directory",
-	Run:   runPull,
+	Long: `Pull .env file from Bitwarden and save it to the current directory or specified directory.
+With --ephemeral [-- command...], the files are served from a private temporary directory ($BWSF_EPHEMERAL_DIR) instead,
+and removed when the command after -- exits or --ttl expires`,
+	Run: runPull,
 }
 
 func init() {
 	pullCmd.Flags().String("output", ".", "Directory to save .env file")
+	pullCmd.Flags().Bool("interpolate", false, "Expand ${KEY} and bw://item/... references in the written files")
+	pullCmd.Flags().Bool("no-check", false, "Skip comparing pulled files with .env*.example")
+	pullCmd.Flags().String("git-guard", "", "Git safety guard mode: abort, warn or off (default: .bwsf.json git_guard, or warn)")
+	pullCmd.Flags().StringSlice("env", nil, "Only pull the files of these environments (e.g. staging for .env.staging)")
+	pullCmd.Flags().StringSlice("file", nil, "Only pull these env files")
+	pullCmd.Flags().String("as", "", "Write the single selected file under this name (e.g. .env)")
+	pullCmd.Flags().StringSlice("layer", nil, "Env files to merge in order; later keys win (requires --merge-into)")
+	pullCmd.Flags().String("merge-into", "", "File name to write the merged --layer files to")
+	pullCmd.Flags().Bool("force", false, "Overwrite existing files without asking")
+	pullCmd.Flags().Bool("offline", false, "Pull from the offline mirror without contacting Bitwarden")
+	pullCmd.Flags().Bool("ephemeral", false, "Serve the files from a private tmpfs directory instead of writing them to --output")
+	pullCmd.Flags().Bool("fifo", false, "With --ephemeral, serve each file through a named pipe instead of a tmpfs file")
+	pullCmd.Flags().Int("reads", 1, "With --fifo, how many times each file can be read")
+	pullCmd.Flags().Duration("ttl", 0, "With --ephemeral, remove the files after this long (default: when the command exits)")
+	addWorkspaceFlags(pullCmd)
+	_ = pullCmd.RegisterFlagCompletionFunc("env", completeStoredFiles(true, false))
+	_ = pullCmd.RegisterFlagCompletionFunc("file", completeStoredFiles(false, false))
+	_ = pullCmd.RegisterFlagCompletionFunc("layer", completeStoredFiles(false, false))
 	rootCmd.AddCommand(pullCmd)
 }
 
 func runPull(cmd *cobra.Command, args []string) {
-	// Check if bw command is installed
-	installed, _ := utils.CheckBwCommand()
-	if !installed {
-		utils.Errorln("[ERROR] ❌ bw command is not installed...")
-		os.Exit(1)
+	// Check if bw command is installed (not needed when serving from the offline mirror)
+	if offline, _ := cmd.Flags().GetBool("offline"); !offline {
+		mustCheckBwCommand()
 	}
 
 	// Get --output flag value
@@ -39,6 +58,78 @@ func runPull(cmd *cobra.Command, args []string) {
 		os.Exit(1)
 	}
 
+	gitGuard := mustGitGuardFlag(cmd)
+
+	noCheck, err := cmd.Flags().GetBool("no-check")
+	if err != nil {
+		utils.Errorln("[ERROR] Failed to get --no-check flag:", err)
+		os.Exit(1)
+	}
+
+	interpolate, err := cmd.Flags().GetBool("interpolate")
+	if err != nil {
+		utils.Errorln("[ERROR] Failed to get --interpolate flag:", err)
+		os.Exit(1)
+	}
+
+	envs, err := cmd.Flags().GetStringSlice("env")
+	if err != nil {
+		utils.Errorln("[ERROR] Failed to get --env flag:", err)
+		os.Exit(1)
+	}
+	files, err := cmd.Flags().GetStringSlice("file")
+	if err != nil {
+		utils.Errorln("[ERROR] Failed to get --file flag:", err)
+		os.Exit(1)
+	}
+	for _, env := range envs {
+		files = append(files, core.EnvFileName(env))
+	}
+
+	as, err := cmd.Flags().GetString("as")
+	if err != nil {
+		utils.Errorln("[ERROR] Failed to get --as flag:", err)
+		os.Exit(1)
+	}
+
+	layer, err := cmd.Flags().GetStringSlice("layer")
+	if err != nil {
+		utils.Errorln("[ERROR] Failed to get --layer flag:", err)
+		os.Exit(1)
+	}
+
+	mergeInto, err := cmd.Flags().GetString("merge-into")
+	if err != nil {
+		utils.Errorln("[ERROR] Failed to get --merge-into flag:", err)
+		os.Exit(1)
+	}
+
+	force, err := cmd.Flags().GetBool("force")
+	if err != nil {
+		utils.Errorln("[ERROR] Failed to get --force flag:", err)
+		os.Exit(1)
+	}
+
+	workspace, jobs := workspaceFlags(cmd)
+	if workspace != "" && cmd.Flags().Changed("output") {
+		utils.Errorln("[ERROR] --output cannot be used with --workspace")
+		os.Exit(1)
+	}
+
+	ephemeral, err := cmd.Flags().GetBool("ephemeral")
+	if err != nil {
+		utils.Errorln("[ERROR] Failed to get --ephemeral flag:", err)
+		os.Exit(1)
+	}
+	if ephemeral && (workspace != "" || cmd.Flags().Changed("output")) {
+		utils.Errorln("[ERROR] --ephemeral cannot be used with --output or --workspace")
+		os.Exit(1)
+	}
+	if !ephemeral && len(args) > 0 {
+		utils.Errorln("[ERROR] A command to run is only accepted with --ephemeral")
+		os.Exit(1)
+	}
+
 	// Get current working directory name as project name
 	wd, err := os.Getwd()
 	if err != nil {
@@ -57,8 +148,50 @@ func runPull(cmd *cobra.Command, args []string) {
 		cfg = &config.Config{}
 	}
 
+	statePath, err := config.GetStatePath()
+	if err != nil {
+		utils.Errorln("[ERROR] Failed to resolve state file:", err)
+		os.Exit(1)
+	}
+
+	opts := core.PullOptions{
+		StatePath:   statePath,
+		GitGuard:    gitGuard,
+		Interpolate: interpolate,
+		Files:       files,
+		As:          as,
+		Layer:       layer,
+		MergeInto:   mergeInto,
+		Audit:       newAuditLog(cfg),
+		ShellCache:  infra.NewKeyring(),
+	}
+
+	if ephemeral {
+		runPullEphemeral(cmd, args, wd, projectName, cfg, opts)
+		return
+	}
+
+	if workspace != "" {
+		// Projects are pulled concurrently, so never prompt: existing files are skipped unless --force
+		opts.Git = infra.NewGitInspector()
+		opts.CheckExamples = !noCheck
+		confirmOverwrite := func(path string) (bool, error) { return force, nil }
+		runWorkspaceOp("pull", workspace, jobs, func(projects []core.WorkspaceProject, wsOpts core.WorkspaceOptions) ([]core.WorkspaceResult, error) {
+			wsOpts.Pull = opts
+			return core.PullWorkspaceCore(projects, infra.NewFileSystem(), newBwClient(cmd, cfg), cfg, utils.InputPassword, confirmOverwrite, infra.NewLogger(), wsOpts)
+		})
+		return
+	}
+
+	// Resolve --output to an absolute path so sync state is keyed consistently
+	absOutputDir, err := filepath.Abs(outputDir)
+	if err != nil {
+		utils.Errorln("[ERROR] Failed to resolve --output directory:", err)
+		os.Exit(1)
+	}
+
 	// Create dependencies
-	bw := infra.NewBwClient()
+	bw := newBwClient(cmd, cfg)
 	fs := infra.NewFileSystem()
 	logger := infra.NewLogger()
 
@@ -74,20 +207,41 @@ func runPull(cmd *cobra.Command, args []string) {
 		os.Exit(1)
 	}
 
+	targets, err := core.PullTargetNames(envFiles, opts)
+	if err != nil {
+		utils.Errorln("[ERROR]", err)
+		os.Exit(1)
+	}
+
 	// Display files to be pulled
-	utils.Infoln("[INFO] Found", len(envFiles), "env file(s) to pull:")
-	for _, f := range envFiles {
-		utils.Infoln("  -", f)
+	utils.Infoln("[INFO] Found", len(envFiles), "env file(s) in Bitwarden")
+	switch {
+	case len(layer) > 0:
+		utils.Infoln("[INFO] Merging", strings.Join(layer, " < "), "into", mergeInto)
+	case as != "":
+		utils.Infoln("[INFO] Pulling", files[0], "as", as)
+	default:
+		utils.Infoln("[INFO] Pulling", len(targets), "env file(s):")
+		for _, f := range targets {
+			utils.Infoln("  -", f)
+		}
 	}
 
 	// confirmOverwrite wrapper
 	confirmOverwrite := func(path string) (bool, error) {
+		if force {
+			return true, nil
+		}
 		return utils.ConfirmOverwrite(fmt.Sprintf("%s already exists. Overwrite? (y/N): ", filepath.Base(path)))
 	}
 
+	opts.ConfirmGitignore = confirmGitignore()
+	opts.Git = infra.NewGitInspector()
+	opts.CheckExamples = !noCheck
+
 	// Call core logic
-	err = core.PullEnvCore(
-		outputDir,
+	err = core.PullEnvCoreWithOptions(
+		absOutputDir,
 		projectName,
 		fs,
 		bw,
@@ -95,11 +249,41 @@ func runPull(cmd *cobra.Command, args []string) {
 		utils.InputPassword,
 		confirmOverwrite,
 		logger,
+		opts,
 	)
 	if err != nil {
 		utils.Errorln("[ERROR]", err)
 		os.Exit(1)
 	}
 
-	utils.Successln("[INFO] ✅", len(envFiles), "env file(s) pulled successfully!")
+	updateNameCache(func(c *core.NameCache) { c.SetFiles(projectName, envFiles) })
+
+	utils.Successln("[INFO] ✅", len(targets), "env file(s) pulled successfully!")
+}
+
+// mustGitGuardFlag returns the --git-guard flag, exiting when it is not abort, warn, off or empty.
+func mustGitGuardFlag(cmd *cobra.Command) string {
+	gitGuard, err := cmd.Flags().GetString("git-guard")
+	if err != nil {
+		utils.Errorln("[ERROR] Failed to get --git-guard flag:", err)
+		os.Exit(1)
+	}
+	switch gitGuard {
+	case "", core.GitGuardAbort, core.GitGuardWarn, core.GitGuardOff:
+	default:
+		utils.Errorln("[ERROR] Invalid --git-guard value:", gitGuard, "(expected abort, warn or off)")
+		os.Exit(1)
+	}
+	return gitGuard
+}
+
+// confirmGitignore asks whether to add the env files to .gitignore.
+// It is only offered on a terminal (nil otherwise), so the guard mode decides without one.
+func confirmGitignore() func(gitignorePath string, patterns []string) (bool, error) {
+	if !utils.StdinIsTerminal() {
+		return nil
+	}
+	return func(gitignorePath string, patterns []string) (bool, error) {
+		return utils.ConfirmYesNo(fmt.Sprintf("Env files are not ignored by git. Add %s to %s? (y/N): ", strings.Join(patterns, " "), gitignorePath))
+	}
 }
@@ -20,15 +20,16 @@ var pushCmd = &cobra.Command{
 
 func init() {
 	pushCmd.Flags().String("from", ".", "Directory containing .env file")
+	pushCmd.Flags().Bool("no-lint", false, "Push even if lint reports errors")
+	pushCmd.Flags().Bool("offline", false, "Queue the push in the offline mirror instead of contacting Bitwarden")
+	addWorkspaceFlags(pushCmd)
 	rootCmd.AddCommand(pushCmd)
 }
 
 func runPush(cmd *cobra.Command, args []string) {
-	// Check if bw command is installed
-	installed, _ := utils.CheckBwCommand()
-	if !installed {
-		utils.Errorln("[ERROR] ❌ bw command is not installed...")
-		os.Exit(1)
+	// Check if bw command is installed (not needed when serving from the offline mirror)
+	if offline, _ := cmd.Flags().GetBool("offline"); !offline {
+		mustCheckBwCommand()
 	}
 
 	// Get --from flag value
@@ -38,6 +39,18 @@ func runPush(cmd *cobra.Command, args []string) {
 		os.Exit(1)
 	}
 
+	noLint, err := cmd.Flags().GetBool("no-lint")
+	if err != nil {
+		utils.Errorln("[ERROR] Failed to get --no-lint flag:", err)
+		os.Exit(1)
+	}
+
+	workspace, jobs := workspaceFlags(cmd)
+	if workspace != "" && cmd.Flags().Changed("from") {
+		utils.Errorln("[ERROR] --from cannot be used with --workspace")
+		os.Exit(1)
+	}
+
 	// Get current working directory name as project name
 	wd, err := os.Getwd()
 	if err != nil {
@@ -56,8 +69,36 @@ func runPush(cmd *cobra.Command, args []string) {
 		cfg = &config.Config{}
 	}
 
+	statePath, err := config.GetStatePath()
+	if err != nil {
+		utils.Errorln("[ERROR] Failed to resolve state file:", err)
+		os.Exit(1)
+	}
+
+	// Queued offline pushes are not in Bitwarden yet, so nobody is notified about them
+	var notifier *core.PushNotifier
+	if offline, _ := cmd.Flags().GetBool("offline"); !offline {
+		notifier = newPushNotifier(cfg)
+	}
+
+	if workspace != "" {
+		opts := core.PushOptions{StatePath: statePath, SkipLint: noLint, Git: infra.NewGitInspector(), Audit: newAuditLog(cfg), Notify: notifier, ShellCache: infra.NewKeyring()}
+		runWorkspaceOp("push", workspace, jobs, func(projects []core.WorkspaceProject, wsOpts core.WorkspaceOptions) ([]core.WorkspaceResult, error) {
+			wsOpts.Push = opts
+			return core.PushWorkspaceCore(projects, infra.NewFileSystem(), newBwClient(cmd, cfg), cfg, utils.InputPassword, infra.NewLogger(), wsOpts)
+		})
+		return
+	}
+
+	// Resolve --from to an absolute path so sync state is keyed consistently
+	absFromDir, err := filepath.Abs(fromDir)
+	if err != nil {
+		utils.Errorln("[ERROR] Failed to resolve --from directory:", err)
+		os.Exit(1)
+	}
+
 	// Create dependencies
-	bw := infra.NewBwClient()
+	bw := newBwClient(cmd, cfg)
 	fs := infra.NewFileSystem()
 	logger := infra.NewLogger()
 
@@ -80,19 +121,22 @@ func runPush(cmd *cobra.Command, args []string) {
 	}
 
 	// Call core logic
-	err = core.PushEnvCore(
-		fromDir,
+	err = core.PushEnvCoreWithOptions(
+		absFromDir,
 		projectName,
 		fs,
 		bw,
 		cfg,
 		utils.InputPassword,
 		logger,
+		core.PushOptions{StatePath: statePath, SkipLint: noLint, Git: infra.NewGitInspector(), Audit: newAuditLog(cfg), Notify: notifier, ShellCache: infra.NewKeyring()},
 	)
 	if err != nil {
 		utils.Errorln("[ERROR]", err)
 		os.Exit(1)
 	}
 
+	updateNameCache(func(c *core.NameCache) { c.SetFiles(projectName, envFiles) })
+
 	utils.Successln("[INFO] ✅", len(envFiles), "env file(s) pushed successfully!")
 }
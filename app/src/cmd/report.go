@@ -0,0 +1,92 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"bwsf/src/core"
+	"bwsf/src/infra"
+	"bwsf/src/utils"
+
+	"github.com/spf13/cobra"
+)
+
+var reportCmd = &cobra.Command{
+	Use:   "report",
+	Short: "Report stale and expiring secrets across all projects",
+	Long: `Scan every project in the configured folder and list keys that have not changed for --stale
+or expire within --expiring. Keys with no recorded timestamps count as stale. Without either flag, every key is listed`,
+	Example: "  bwsf report --stale 90d --expiring 30d",
+	Args:    cobra.NoArgs,
+	Run:     runReport,
+}
+
+func init() {
+	reportCmd.Flags().String("stale", "", "List keys not changed for this long, e.g. 90d")
+	reportCmd.Flags().String("expiring", "", "List keys that expire within this long (and expired keys), e.g. 30d")
+	reportCmd.Flags().Bool("json", false, "Print the report as JSON")
+	rootCmd.AddCommand(reportCmd)
+}
+
+func runReport(cmd *cobra.Command, args []string) {
+	mustCheckBwCommand()
+
+	asJSON, _ := cmd.Flags().GetBool("json")
+	opts := core.ReportOptions{Now: time.Now()}
+	for _, f := range []struct {
+		name string
+		dst  *time.Duration
+	}{{"stale", &opts.Stale}, {"expiring", &opts.Expiring}} {
+		value, _ := cmd.Flags().GetString(f.name)
+		if value == "" {
+			continue
+		}
+		d, err := core.ParseAge(value)
+		if err != nil {
+			utils.Errorln("[ERROR] Invalid --"+f.name+":", err)
+			os.Exit(1)
+		}
+		*f.dst = d
+	}
+
+	cfg := mustLoadConfig()
+
+	// Create dependencies
+	bw := newBwClient(cmd, cfg)
+	logger := infra.NewLogger()
+
+	rows, err := core.ReportCore(opts, bw, cfg, utils.InputPassword, logger)
+	if err != nil {
+		utils.Errorln("[ERROR]", err)
+		os.Exit(1)
+	}
+
+	if asJSON {
+		if rows == nil {
+			rows = []core.ReportRow{}
+		}
+		data, _ := json.MarshalIndent(rows, "", "  ")
+		fmt.Println(string(data))
+		return
+	}
+
+	if len(rows) == 0 {
+		if opts.Stale == 0 && opts.Expiring == 0 {
+			utils.Infoln("[INFO] No keys found")
+		} else {
+			utils.Successln("[INFO] ✅ No stale or expiring keys found")
+		}
+		return
+	}
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "PROJECT\tFILE\tKEY\tOWNER\tLAST CHANGED\tEXPIRES\tSTATUS")
+	for _, r := range rows {
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\t%s\n",
+			r.Project, r.File, r.Key, orDash(r.Owner), formatMetaDate(r.LastChanged), formatMetaDate(r.ExpiresAt), orDash(strings.Join(r.Status, ",")))
+	}
+	w.Flush()
+}
@@ -0,0 +1,72 @@
+package cmd
+
+import (
+	"bwsf/src/core"
+	"bwsf/src/infra"
+	"bwsf/src/utils"
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+)
+
+var rmCmd = &cobra.Command{
+	Use:   "rm <project>",
+	Short: "Remove a project from Bitwarden",
+	Long:  "Move a project item in the configured Bitwarden folder to the trash, or delete it permanently with --permanent",
+	Args:  cobra.ExactArgs(1),
+	Run:   runRm,
+}
+
+func init() {
+	rmCmd.Flags().Bool("permanent", false, "Delete permanently instead of moving to the trash")
+	rmCmd.Flags().BoolP("yes", "y", false, "Skip the confirmation prompt")
+	rmCmd.ValidArgsFunction = completeProjectArgs(1)
+	rootCmd.AddCommand(rmCmd)
+}
+
+func runRm(cmd *cobra.Command, args []string) {
+	mustCheckBwCommand()
+
+	projectName := args[0]
+	permanent, _ := cmd.Flags().GetBool("permanent")
+	yes, _ := cmd.Flags().GetBool("yes")
+
+	if !yes {
+		message := fmt.Sprintf("Move '%s' to the trash? (y/N): ", projectName)
+		if permanent {
+			message = fmt.Sprintf("Permanently delete '%s'? This cannot be undone. (y/N): ", projectName)
+		}
+		confirmed, err := utils.ConfirmYesNo(message)
+		if err != nil {
+			utils.Errorln("[ERROR]", err)
+			os.Exit(1)
+		}
+		if !confirmed {
+			utils.Infoln("[INFO] Cancelled")
+			return
+		}
+	}
+
+	cfg := mustLoadConfig()
+
+	// Create dependencies
+	bw := newBwClient(cmd, cfg)
+	logger := infra.NewLogger()
+
+	// Call core logic
+	err := core.RemoveProjectCore(projectName, permanent, bw, cfg, utils.InputPassword, logger)
+	if err != nil {
+		utils.Errorln("[ERROR]", err)
+		os.Exit(1)
+	}
+
+	recordAudit(newAuditLog(cfg), core.AuditEntry{Op: core.AuditOpRemove, Project: projectName})
+	updateNameCache(func(c *core.NameCache) { c.RemoveProject(projectName) })
+
+	if permanent {
+		utils.Successln("[INFO] ✅", projectName, "deleted permanently")
+	} else {
+		utils.Successln("[INFO] ✅", projectName, "moved to the trash")
+	}
+}
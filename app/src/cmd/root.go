@@ -14,6 +14,10 @@ var rootCmd = &cobra.Command{
 	Version: Version,
 }
 
+func init() {
+	rootCmd.PersistentFlags().Bool("sync", false, "Sync with the Bitwarden server instead of trusting the local metadata cache")
+}
+
 func Execute() {
 	if err := rootCmd.Execute(); err != nil {
 		utils.Error("Error: %v\n", err)
@@ -0,0 +1,96 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	"bwsf/src/core"
+	"bwsf/src/infra"
+	"bwsf/src/utils"
+
+	"github.com/spf13/cobra"
+)
+
+var rotateCmd = &cobra.Command{
+	Use:   "rotate <project> <KEY>",
+	Short: "Replace a stored secret with a generated value",
+	Long: `Generate a new value for KEY and update the stored env line in place.
+The rotation time is kept in the item's key metadata, and the previous value is kept in the item's revision history. Local files are not changed; run bwsf pull to get the new value`,
+	Args: cobra.ExactArgs(2),
+	Run:  runRotate,
+}
+
+func init() {
+	rotateCmd.Flags().String("file", ".env", "Env file that contains the key")
+	rotateCmd.Flags().String("generator", core.GeneratorPassword, "How to generate the value: password, hex, base64 or uuid")
+	rotateCmd.Flags().Int("length", 0, fmt.Sprintf("Characters for password and hex, random bytes for base64 (default: %d)", core.DefaultSecretLength))
+	rotateCmd.Flags().Bool("dry-run", false, "Show what would change without updating Bitwarden")
+	rotateCmd.Flags().BoolP("yes", "y", false, "Skip the confirmation prompt")
+	rotateCmd.ValidArgsFunction = completeProjectArgs(1)
+	_ = rotateCmd.RegisterFlagCompletionFunc("file", completeStoredFiles(false, true))
+	rootCmd.AddCommand(rotateCmd)
+}
+
+func runRotate(cmd *cobra.Command, args []string) {
+	mustCheckBwCommand()
+
+	projectName, key := args[0], args[1]
+	file, _ := cmd.Flags().GetString("file")
+	generator, _ := cmd.Flags().GetString("generator")
+	length, _ := cmd.Flags().GetInt("length")
+	dryRun, _ := cmd.Flags().GetBool("dry-run")
+	yes, _ := cmd.Flags().GetBool("yes")
+
+	// Reject a bad generator before asking for confirmation
+	if _, err := core.GenerateSecret(generator, length); err != nil {
+		utils.Errorln("[ERROR]", err)
+		os.Exit(1)
+	}
+
+	if !yes && !dryRun {
+		message := fmt.Sprintf("Replace %s in %s/%s with a new %s value? (y/N): ", key, projectName, file, generator)
+		confirmed, err := utils.ConfirmYesNo(message)
+		if err != nil {
+			utils.Errorln("[ERROR]", err)
+			os.Exit(1)
+		}
+		if !confirmed {
+			utils.Infoln("[INFO] Cancelled")
+			return
+		}
+	}
+
+	cfg := mustLoadConfig()
+
+	// Create dependencies
+	bw := newBwClient(cmd, cfg)
+	logger := infra.NewLogger()
+
+	// Call core logic
+	opts := core.RotateOptions{File: file, Generator: generator, Length: length, DryRun: dryRun, ShellCache: infra.NewKeyring()}
+	result, err := core.RotateKeyCore(projectName, key, opts, bw, cfg, utils.InputPassword, logger)
+	if err != nil {
+		utils.Errorln("[ERROR]", err)
+		os.Exit(1)
+	}
+
+	lastRotated := result.PreviousRotatedAt
+	if lastRotated == "" {
+		lastRotated = "never"
+	}
+
+	if dryRun {
+		utils.Infoln("[INFO] Would replace", key, "in", projectName+"/"+result.File, fmt.Sprintf("(%d line(s), generator: %s, last rotated: %s)", result.Lines, generator, lastRotated))
+		return
+	}
+
+	recordAudit(newAuditLog(cfg), core.AuditEntry{
+		Op:      core.AuditOpRotate,
+		Project: projectName,
+		Files:   []string{result.File},
+		Keys:    map[string][]string{result.File: {key}},
+	})
+
+	utils.Successln("[INFO] ✅", key, "rotated in", projectName+"/"+result.File, "at", result.RotatedAt)
+	utils.Infoln("[INFO] Run bwsf pull to update local files")
+}
@@ -28,6 +28,12 @@ func init() {
 }
 
 func runSetup(cmd *cobra.Command, args []string) {
+	// The file backend keeps everything in its encrypted store; there is no account to sign in to
+	if cfg, err := config.LoadConfig(); err == nil && cfg != nil && cfg.Backend == config.BackendFile {
+		utils.Errorln(`[ERROR] bwsf setup signs in to Bitwarden, but "backend" is "file"; the file store needs no setup`)
+		os.Exit(1)
+	}
+
 	// Check if bw command is installed
 	installed, _ := utils.CheckBwCommand()
 	if !installed {
@@ -0,0 +1,78 @@
+package cmd
+
+import (
+	"bwsf/src/core"
+	"bwsf/src/infra"
+	"bwsf/src/utils"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+var sourcesCmd = &cobra.Command{
+	Use:   "sources",
+	Short: "Show which item each key comes from",
+	Long:  "Merge the stored project with the parent items it extends and show, for every key, whether it is the project's own, inherited from a parent, or overrides a parent. Values are never printed",
+	Run:   runSources,
+}
+
+func init() {
+	sourcesCmd.Flags().String("dir", ".", "Directory containing .bwsf.json")
+	sourcesCmd.Flags().String("file", "", "Only show keys of this env file")
+	rootCmd.AddCommand(sourcesCmd)
+}
+
+func runSources(cmd *cobra.Command, args []string) {
+	mustCheckBwCommand()
+
+	dir, err := cmd.Flags().GetString("dir")
+	if err != nil {
+		utils.Errorln("[ERROR] Failed to get --dir flag:", err)
+		os.Exit(1)
+	}
+
+	file, err := cmd.Flags().GetString("file")
+	if err != nil {
+		utils.Errorln("[ERROR] Failed to get --file flag:", err)
+		os.Exit(1)
+	}
+
+	projectName := mustCurrentProjectName()
+	cfg := mustLoadConfig()
+
+	sources, err := core.SourcesEnvCore(dir, projectName, infra.NewFileSystem(), newBwClient(cmd, cfg), cfg, utils.InputPassword, infra.NewLogger())
+	if err != nil {
+		utils.Errorln("[ERROR]", err)
+		os.Exit(1)
+	}
+
+	currentFile := ""
+	for _, s := range sources {
+		if file != "" && s.File != file {
+			continue
+		}
+		if s.File != currentFile {
+			currentFile = s.File
+			utils.Infoln(currentFile)
+		}
+
+		switch {
+		case s.Inherited(projectName):
+			line := fmt.Sprintf("  + %-30s %s", s.Key, s.Source)
+			if len(s.Overridden) > 0 {
+				line += " (overrides " + strings.Join(s.Overridden, ", ") + ")"
+			}
+			utils.Successln(line)
+		case len(s.Overridden) > 0:
+			utils.Warningln(fmt.Sprintf("  ~ %-30s %s (overrides %s)", s.Key, s.Source, strings.Join(s.Overridden, ", ")))
+		default:
+			fmt.Printf("    %-30s %s\n", s.Key, s.Source)
+		}
+	}
+
+	if currentFile == "" {
+		fmt.Println("No keys found for project:", projectName)
+	}
+}
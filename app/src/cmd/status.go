@@ -0,0 +1,107 @@
+package cmd
+
+import (
+	"bwsf/src/config"
+	"bwsf/src/core"
+	"bwsf/src/infra"
+	"bwsf/src/utils"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/spf13/cobra"
+)
+
+var statusCmd = &cobra.Command{
+	Use:   "status",
+	Short: "Show sync state of local .env files against Bitwarden",
+	Long:  "Compare each local .env* file with the copy stored in Bitwarden and show whether it is in sync, local-only, remote-only or modified",
+	Run:   runStatus,
+}
+
+func init() {
+	statusCmd.Flags().String("dir", ".", "Directory containing .env files")
+	rootCmd.AddCommand(statusCmd)
+}
+
+func runStatus(cmd *cobra.Command, args []string) {
+	// Check if bw command is installed
+	mustCheckBwCommand()
+
+	// Get --dir flag value
+	dir, err := cmd.Flags().GetString("dir")
+	if err != nil {
+		utils.Errorln("[ERROR] Failed to get --dir flag:", err)
+		os.Exit(1)
+	}
+	absDir, err := filepath.Abs(dir)
+	if err != nil {
+		utils.Errorln("[ERROR] Failed to resolve directory:", err)
+		os.Exit(1)
+	}
+
+	// Get current working directory name as project name
+	wd, err := os.Getwd()
+	if err != nil {
+		utils.Errorln("[ERROR] Failed to get current working directory:", err)
+		os.Exit(1)
+	}
+	projectName := filepath.Base(wd)
+
+	// Load config
+	cfg, err := config.LoadConfig()
+	if err != nil {
+		utils.Errorln("[ERROR] Failed to load config:", err)
+		os.Exit(1)
+	}
+	if cfg == nil {
+		cfg = &config.Config{}
+	}
+
+	statePath, err := config.GetStatePath()
+	if err != nil {
+		utils.Errorln("[ERROR] Failed to resolve state file:", err)
+		os.Exit(1)
+	}
+
+	// Create dependencies
+	bw := newBwClient(cmd, cfg)
+	fs := infra.NewFileSystem()
+	logger := infra.NewLogger()
+
+	// Call core logic
+	statuses, err := core.StatusEnvCore(
+		absDir,
+		projectName,
+		statePath,
+		fs,
+		bw,
+		cfg,
+		utils.InputPassword,
+		logger,
+	)
+	if err != nil {
+		utils.Errorln("[ERROR]", err)
+		os.Exit(1)
+	}
+
+	if len(statuses) == 0 {
+		fmt.Println("No env files found locally or in Bitwarden for project:", projectName)
+		return
+	}
+
+	utils.Infoln("[INFO] Status of", projectName+":")
+	for _, s := range statuses {
+		line := fmt.Sprintf("  %-18s %s", s.Status, s.FileName)
+		switch s.Status {
+		case core.StatusInSync:
+			utils.Successln(line)
+		case core.StatusExpanded:
+			utils.Infoln(line)
+		case core.StatusBothModified:
+			utils.Errorln(line)
+		default:
+			utils.Warningln(line)
+		}
+	}
+}
@@ -0,0 +1,249 @@
+package cmd
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"os/signal"
+	"path/filepath"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+
+	"bwsf/src/config"
+	"bwsf/src/core"
+	"bwsf/src/infra"
+	"bwsf/src/utils"
+
+	"github.com/spf13/cobra"
+)
+
+const (
+	reloadRestart = "restart"
+	reloadHUP     = "hup"
+)
+
+// childStopTimeout is how long a restarted command gets to exit after SIGTERM before it is killed
+const childStopTimeout = 10 * time.Second
+
+var syncCmd = &cobra.Command{
+	Use:   "sync",
+	Short: "Keep .env files in step with Bitwarden while a command runs",
+	Long: `With --follow [-- command...], pull the .env files, start the command and keep checking Bitwarden every --interval.
+When the project changes there, the changed files are written again and the command is restarted (--reload restart)
+or sent SIGHUP (--reload hup). Only key names are logged. Files edited locally since the last sync are never overwritten.
+Checks that fail are retried with a growing interval up to --max-backoff`,
+	Run: runSync,
+}
+
+func init() {
+	syncCmd.Flags().Bool("follow", false, "Keep pulling changes from Bitwarden until stopped")
+	syncCmd.Flags().String("output", ".", "Directory to save .env file")
+	syncCmd.Flags().Duration("interval", core.DefaultFollowInterval, "How often to check Bitwarden for changes")
+	syncCmd.Flags().Duration("max-backoff", core.DefaultFollowMaxBackoff, "Longest interval between checks after failures")
+	syncCmd.Flags().String("git-guard", "", "Git safety guard mode: abort, warn or off (default: .bwsf.json git_guard, or warn)")
+	syncCmd.Flags().String("reload", reloadRestart, "How to apply changes to the command: restart or hup")
+	rootCmd.AddCommand(syncCmd)
+}
+
+func runSync(cmd *cobra.Command, args []string) {
+	follow, _ := cmd.Flags().GetBool("follow")
+	outputDir, _ := cmd.Flags().GetString("output")
+	interval, _ := cmd.Flags().GetDuration("interval")
+	maxBackoff, _ := cmd.Flags().GetDuration("max-backoff")
+	reload, _ := cmd.Flags().GetString("reload")
+	gitGuard := mustGitGuardFlag(cmd)
+
+	if !follow {
+		utils.Errorln("[ERROR] bwsf sync requires --follow (use bwsf pull for a one-off pull)")
+		os.Exit(1)
+	}
+	if interval <= 0 || maxBackoff <= 0 {
+		utils.Errorln("[ERROR] --interval and --max-backoff must be positive")
+		os.Exit(1)
+	}
+	switch reload {
+	case reloadRestart, reloadHUP:
+	default:
+		utils.Errorln("[ERROR] Invalid --reload value:", reload, "(expected restart or hup)")
+		os.Exit(1)
+	}
+
+	mustCheckBwCommand()
+	projectName := mustCurrentProjectName()
+	cfg := mustLoadConfig()
+
+	statePath, err := config.GetStatePath()
+	if err != nil {
+		utils.Errorln("[ERROR] Failed to resolve state file:", err)
+		os.Exit(1)
+	}
+
+	// Resolve --output to an absolute path so sync state is keyed consistently
+	absOutputDir, err := filepath.Abs(outputDir)
+	if err != nil {
+		utils.Errorln("[ERROR] Failed to resolve --output directory:", err)
+		os.Exit(1)
+	}
+
+	ctx, stop := context.WithCancel(context.Background())
+	defer stop()
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, os.Interrupt, syscall.SIGTERM, syscall.SIGHUP)
+	defer signal.Stop(signals)
+
+	child := &followedChild{args: args, reload: reload, exited: make(chan error, 1)}
+	opts := core.FollowOptions{
+		Interval:   interval,
+		MaxBackoff: maxBackoff,
+		Pull: core.PullOptions{
+			StatePath:        statePath,
+			GitGuard:         gitGuard,
+			Git:              infra.NewGitInspector(),
+			ConfirmGitignore: confirmGitignore(),
+			Audit:            newAuditLog(cfg),
+		},
+	}
+	if len(args) > 0 {
+		opts.Start = child.start
+		opts.Reload = child.reloadFiles
+	}
+
+	followed := make(chan error, 1)
+	go func() {
+		followed <- core.FollowEnvCore(ctx, absOutputDir, projectName, infra.NewFileSystem(), newFollowBwClient(cfg), cfg, utils.InputPassword, infra.NewLogger(), opts)
+	}()
+	utils.Infoln("[INFO] Following", projectName, "in Bitwarden every", interval, "(Ctrl-C to stop)")
+
+	for {
+		select {
+		case err := <-followed:
+			if err != nil {
+				child.stop()
+				utils.Errorln("[ERROR]", err)
+				os.Exit(1)
+			}
+			return
+		case err := <-child.exited:
+			stop()
+			if code := utils.ExitCode(err); code != 0 {
+				os.Exit(code)
+			}
+			return
+		case sig := <-signals:
+			if len(args) == 0 {
+				stop()
+				<-followed
+				utils.Infoln("[INFO] Stopped following")
+				return
+			}
+			// Ctrl-C already reaches the command through the terminal; sending it again could force-stop it
+			if sig != os.Interrupt {
+				child.signal(sig)
+			}
+		}
+	}
+}
+
+// newFollowBwClient returns a Bitwarden client without the metadata cache, since every check must see the server's latest revision.
+func newFollowBwClient(cfg *config.Config) core.BwClient {
+	backend, err := config.ResolveBackend(cfg)
+	if err != nil {
+		utils.Errorln("[ERROR]", err)
+		os.Exit(1)
+	}
+	if backend == config.BackendFile {
+		return newFileBwClient(cfg)
+	}
+	return infra.NewBwClient()
+}
+
+// followedChild runs the command given after -- and restarts or signals it when the files change.
+// Only the exit of the current process is reported on exited; processes stopped for a restart are not.
+type followedChild struct {
+	args   []string
+	reload string
+	exited chan error
+
+	mu   sync.Mutex
+	cmd  *exec.Cmd
+	done chan struct{} // closed when cmd has exited
+}
+
+// start runs the command and reports its exit on exited unless it has been replaced by then.
+func (c *followedChild) start() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.startLocked()
+}
+
+func (c *followedChild) startLocked() error {
+	cmd, err := utils.StartCommand(c.args, nil)
+	if err != nil {
+		return err
+	}
+	done := make(chan struct{})
+	c.cmd, c.done = cmd, done
+	go func() {
+		err := cmd.Wait()
+		close(done)
+		c.mu.Lock()
+		current := c.cmd == cmd
+		c.mu.Unlock()
+		if current {
+			c.exited <- err
+		}
+	}()
+	return nil
+}
+
+// reloadFiles applies rewritten files: SIGHUP with --reload hup, otherwise stop the command and start it again.
+func (c *followedChild) reloadFiles(files []string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.cmd == nil {
+		return nil
+	}
+	if c.reload == reloadHUP {
+		utils.Infoln("[INFO] Sending SIGHUP to", c.args[0], "for", strings.Join(files, ", "))
+		return c.cmd.Process.Signal(syscall.SIGHUP)
+	}
+
+	utils.Infoln("[INFO] Restarting", c.args[0], "for", strings.Join(files, ", "))
+	c.stopLocked()
+	return c.startLocked()
+}
+
+// signal forwards sig to the running command.
+func (c *followedChild) signal(sig os.Signal) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.cmd != nil {
+		_ = c.cmd.Process.Signal(sig)
+	}
+}
+
+// stop terminates the running command, if any.
+func (c *followedChild) stop() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.stopLocked()
+}
+
+// stopLocked sends SIGTERM and waits for the command to exit, killing it after childStopTimeout.
+// The process is detached from c first so its exit is not reported as the command ending.
+func (c *followedChild) stopLocked() {
+	if c.cmd == nil {
+		return
+	}
+	cmd, done := c.cmd, c.done
+	c.cmd = nil
+	_ = cmd.Process.Signal(syscall.SIGTERM)
+	select {
+	case <-done:
+	case <-time.After(childStopTimeout):
+		_ = cmd.Process.Kill()
+		<-done
+	}
+}
@@ -0,0 +1,98 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"syscall"
+
+	"bwsf/src/config"
+	"bwsf/src/core"
+	"bwsf/src/infra"
+	"bwsf/src/utils"
+
+	"github.com/spf13/cobra"
+)
+
+var watchCmd = &cobra.Command{
+	Use:   "watch",
+	Short: "Push local .env edits to Bitwarden as you save them",
+	Long: `Watch the .env files and, once the writes settle, lint them and show the changed keys against Bitwarden.
+The change is pushed after you press y, or right away with --auto. Nothing is pushed when lint reports errors
+or when Bitwarden changed since your last pull or push. Press Ctrl-C to stop`,
+	Args: cobra.NoArgs,
+	Run:  runWatch,
+}
+
+func init() {
+	watchCmd.Flags().String("from", ".", "Directory containing .env file")
+	watchCmd.Flags().Bool("auto", false, "Push without asking for confirmation")
+	watchCmd.Flags().Duration("debounce", core.DefaultWatchDebounce, "Wait this long after the last write before pushing")
+	watchCmd.Flags().Bool("no-lint", false, "Push even if lint reports errors")
+	rootCmd.AddCommand(watchCmd)
+}
+
+func runWatch(cmd *cobra.Command, args []string) {
+	mustCheckBwCommand()
+
+	fromDir, _ := cmd.Flags().GetString("from")
+	auto, _ := cmd.Flags().GetBool("auto")
+	debounce, _ := cmd.Flags().GetDuration("debounce")
+	noLint, _ := cmd.Flags().GetBool("no-lint")
+
+	projectName := mustCurrentProjectName()
+	cfg := mustLoadConfig()
+
+	statePath, err := config.GetStatePath()
+	if err != nil {
+		utils.Errorln("[ERROR] Failed to resolve state file:", err)
+		os.Exit(1)
+	}
+
+	// Resolve --from to an absolute path so sync state is keyed consistently
+	absFromDir, err := filepath.Abs(fromDir)
+	if err != nil {
+		utils.Errorln("[ERROR] Failed to resolve --from directory:", err)
+		os.Exit(1)
+	}
+
+	watcher, err := infra.NewDirWatcher(absFromDir)
+	if err != nil {
+		utils.Errorln("[ERROR]", err)
+		os.Exit(1)
+	}
+	defer watcher.Close()
+
+	// The first Ctrl-C stops watching; after that the default handler applies, so a second one exits at once
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	go func() {
+		<-ctx.Done()
+		stop()
+	}()
+
+	// Ctrl-C at the one-key prompt arrives as a key in raw mode, so treat it like the signal
+	confirm := func(summary []string) (bool, error) {
+		ok, err := utils.ConfirmKey("Push these changes to Bitwarden? (y/N): ")
+		if errors.Is(err, utils.ErrInterrupted) {
+			stop()
+			return false, nil
+		}
+		return ok, err
+	}
+
+	utils.Infoln("[INFO] Watching", absFromDir, "for project", projectName, "(Ctrl-C to stop)")
+	err = core.WatchEnvCore(ctx, absFromDir, projectName, watcher, infra.NewFileSystem(), newBwClient(cmd, cfg), cfg, utils.InputPassword, infra.NewLogger(), core.WatchOptions{
+		Debounce: debounce,
+		Auto:     auto,
+		Confirm:  confirm,
+		Push:     core.PushOptions{StatePath: statePath, SkipLint: noLint, Git: infra.NewGitInspector(), Audit: newAuditLog(cfg), Notify: newPushNotifier(cfg)},
+	})
+	if err != nil {
+		utils.Errorln("[ERROR]", err)
+		os.Exit(1)
+	}
+	utils.Infoln("[INFO] Stopped watching")
+}
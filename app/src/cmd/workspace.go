@@ -0,0 +1,138 @@
+package cmd
+
+import (
+	"bwsf/src/core"
+	"bwsf/src/infra"
+	"bwsf/src/utils"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+// addWorkspaceFlags adds the flags shared by pull and push for workspace mode
+func addWorkspaceFlags(c *cobra.Command) {
+	c.Flags().String("workspace", "", "Process every project found under this directory")
+	c.Flags().Int("jobs", core.DefaultWorkspaceWorkers, "Number of projects processed at once with --workspace")
+}
+
+// workspaceTaskStates maps core workspace states to spinner task states
+var workspaceTaskStates = map[core.WorkspaceState]utils.TaskState{
+	core.WorkspacePending: utils.TaskPending,
+	core.WorkspaceRunning: utils.TaskRunning,
+	core.WorkspaceDone:    utils.TaskDone,
+	core.WorkspaceSkipped: utils.TaskSkipped,
+	core.WorkspaceFailed:  utils.TaskFailed,
+}
+
+// workspaceFlags returns the --workspace root (resolved to an absolute path, "" when unset) and --jobs
+func workspaceFlags(cmd *cobra.Command) (string, int) {
+	root, err := cmd.Flags().GetString("workspace")
+	if err != nil {
+		utils.Errorln("[ERROR] Failed to get --workspace flag:", err)
+		os.Exit(1)
+	}
+	jobs, err := cmd.Flags().GetInt("jobs")
+	if err != nil {
+		utils.Errorln("[ERROR] Failed to get --jobs flag:", err)
+		os.Exit(1)
+	}
+	if root == "" {
+		return "", jobs
+	}
+	if jobs < 1 {
+		utils.Errorln("[ERROR] --jobs must be at least 1")
+		os.Exit(1)
+	}
+
+	if root == "~" || strings.HasPrefix(root, "~/") {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			utils.Errorln("[ERROR] Failed to resolve home directory:", err)
+			os.Exit(1)
+		}
+		root = filepath.Join(home, strings.TrimPrefix(root, "~"))
+	}
+	absRoot, err := filepath.Abs(root)
+	if err != nil {
+		utils.Errorln("[ERROR] Failed to resolve --workspace directory:", err)
+		os.Exit(1)
+	}
+	return absRoot, jobs
+}
+
+// runWorkspaceOp discovers the projects under root, runs op over them with a progress table,
+// prints the logs of projects that need attention and a summary, and exits 1 when any project failed
+func runWorkspaceOp(
+	verb, root string,
+	jobs int,
+	run func(projects []core.WorkspaceProject, opts core.WorkspaceOptions) ([]core.WorkspaceResult, error),
+) {
+	projects, err := core.DiscoverProjects(root, infra.NewFileSystem())
+	if err != nil {
+		utils.Errorln("[ERROR]", err)
+		os.Exit(1)
+	}
+	if len(projects) == 0 {
+		utils.Errorln("[ERROR] No projects found under", root)
+		os.Exit(1)
+	}
+	utils.Infoln("[INFO] Found", len(projects), "project(s) under", root)
+
+	labels := make([]string, len(projects))
+	for i, p := range projects {
+		labels[i] = p.Dir
+		if rel, err := filepath.Rel(root, p.Dir); err == nil {
+			labels[i] = rel
+		}
+	}
+
+	// The spinner starts on the first update, after the vault has been unlocked,
+	// so the password prompt is not drawn over
+	var progress *utils.MultiSpinner
+	opts := core.WorkspaceOptions{
+		Workers: jobs,
+		OnUpdate: func(i int, r core.WorkspaceResult) {
+			if progress == nil {
+				progress = utils.StartMultiSpinner(labels)
+			}
+			status := r.Message
+			if r.State == core.WorkspaceRunning {
+				status = verb + "ing..."
+			}
+			progress.Set(i, workspaceTaskStates[r.State], status)
+		},
+	}
+
+	results, err := run(projects, opts)
+	if progress != nil {
+		progress.Stop()
+	}
+	if err != nil {
+		utils.Errorln("[ERROR]", err)
+		os.Exit(1)
+	}
+
+	// Logs are buffered per project so that concurrent output does not interleave
+	for i, r := range results {
+		if len(r.Logs) == 0 {
+			continue
+		}
+		fmt.Println()
+		utils.Infoln("[" + labels[i] + "]")
+		for _, line := range r.Logs {
+			fmt.Println("  " + line)
+		}
+	}
+
+	counts := core.SummarizeWorkspace(results)
+	fmt.Println()
+	summary := fmt.Sprintf("[INFO] %d %sed, %d skipped, %d failed", counts[core.WorkspaceDone], verb, counts[core.WorkspaceSkipped], counts[core.WorkspaceFailed])
+	if counts[core.WorkspaceFailed] > 0 {
+		utils.Errorln(summary)
+		os.Exit(1)
+	}
+	utils.Successln(summary)
+}
@@ -6,21 +6,61 @@ import (
 	"os"
 	"path/filepath"
 	"strings"
+	"time"
 )
 
 type Config struct {
-	HostType      string `json:"host_type"`             // "cloud" or "selfhosted"
-	SelfhostedURL string `json:"selfhosted_url"`        // URL for self-hosted instance
-	Email         string `json:"email"`                 // Email address
-	FolderName    string `json:"folder_name,omitempty"` // Bitwarden folder for .env notes
+	HostType      string             `json:"host_type"`                 // "cloud" or "selfhosted"
+	SelfhostedURL string             `json:"selfhosted_url"`            // URL for self-hosted instance
+	Email         string             `json:"email"`                     // Email address
+	FolderName    string             `json:"folder_name,omitempty"`     // Bitwarden folder for .env notes
+	CacheTTL      string             `json:"cache_ttl,omitempty"`       // How long cached vault metadata is trusted, e.g. "10m"; "0" disables
+	ShellCacheTTL string             `json:"shell_cache_ttl,omitempty"` // How long `bwsf export` reuses loaded variables from the OS keyring; "0" disables
+	Audit         *AuditConfig       `json:"audit,omitempty"`           // Local audit log of pulls and pushes
+	PostPush      []HookConfig       `json:"post_push,omitempty"`       // Webhooks and commands run after a push changes keys
+	Backend       string             `json:"backend,omitempty"`         // "bitwarden" (default) or "file"
+	FileBackend   *FileBackendConfig `json:"file_backend,omitempty"`    // Options for backend "file"
+}
+
+// FileBackendConfig configures the encrypted local store used by backend "file".
+type FileBackendConfig struct {
+	Path       string `json:"path,omitempty"`       // Store file (default: ~/.config/bwsf/store.age)
+	Passphrase string `json:"passphrase,omitempty"` // Store passphrase; BWSF_FILE_PASSPHRASE or the prompt are safer
+}
+
+// AuditConfig controls the local audit log. The log is written unless Disabled is set.
+type AuditConfig struct {
+	Disabled    bool   `json:"disabled,omitempty"`     // Stop writing the audit log
+	Path        string `json:"path,omitempty"`         // Audit log file (default: ~/.config/bwsf/audit.jsonl)
+	Syslog      bool   `json:"syslog,omitempty"`       // Also send each entry to the local syslog
+	ForwardFile string `json:"forward_file,omitempty"` // Also append each entry to this file, e.g. on a shared mount
 }
 
 const (
 	configDir  = ".config/bwsf"
 	configFile = "config.json"
+	stateFile  = "state.json"
+	cacheFile  = "cache.bin"
+	mirrorFile = "mirror.json"
+	auditFile  = "audit.jsonl"
+	storeFile  = "store.age"
+	namesFile  = "names.json"
 
 	// DefaultFolderName is the Bitwarden folder used when folder_name is unset.
 	DefaultFolderName = "dotenvs"
+
+	// BackendBitwarden stores notes in Bitwarden through the bw CLI.
+	BackendBitwarden = "bitwarden"
+	// BackendFile stores notes in an encrypted local file, without Bitwarden.
+	BackendFile = "file"
+
+	// FilePassphraseEnv overrides file_backend.passphrase.
+	FilePassphraseEnv = "BWSF_FILE_PASSPHRASE"
+
+	// DefaultCacheTTL is used when cache_ttl is unset.
+	DefaultCacheTTL = 5 * time.Minute
+	// DefaultShellCacheTTL is used when shell_cache_ttl is unset.
+	DefaultShellCacheTTL = time.Minute
 )
 
 // ResolveFolderName returns the configured folder name, or DefaultFolderName when empty.
@@ -52,6 +92,189 @@ func GetConfigPath() (string, error) {
 	return filepath.Join(homeDir, configDir, configFile), nil
 }
 
+// GetStatePath returns the full path to the local sync state file.
+// It lives next to config.json so it never ends up inside a project repository.
+func GetStatePath() (string, error) {
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to get home directory: %w", err)
+	}
+	return filepath.Join(homeDir, configDir, stateFile), nil
+}
+
+// ResolveCacheTTL returns the configured cache TTL, or DefaultCacheTTL when empty.
+// A zero TTL disables the on-disk cache.
+func ResolveCacheTTL(cfg *Config) (time.Duration, error) {
+	if cfg == nil {
+		return DefaultCacheTTL, nil
+	}
+	return parseTTL("cache_ttl", cfg.CacheTTL, DefaultCacheTTL)
+}
+
+// ResolveShellCacheTTL returns the configured shell cache TTL, or DefaultShellCacheTTL when empty.
+// A zero TTL disables the keyring cache used by `bwsf export`.
+func ResolveShellCacheTTL(cfg *Config) (time.Duration, error) {
+	if cfg == nil {
+		return DefaultShellCacheTTL, nil
+	}
+	return parseTTL("shell_cache_ttl", cfg.ShellCacheTTL, DefaultShellCacheTTL)
+}
+
+// parseTTL parses a TTL setting, where "" means defaultTTL and "0" disables.
+func parseTTL(name, raw string, defaultTTL time.Duration) (time.Duration, error) {
+	value := strings.TrimSpace(raw)
+	if value == "" {
+		return defaultTTL, nil
+	}
+	if value == "0" {
+		return 0, nil
+	}
+	ttl, err := time.ParseDuration(value)
+	if err != nil || ttl < 0 {
+		return 0, fmt.Errorf("invalid %s %q: use a duration such as 10m, or 0 to disable", name, raw)
+	}
+	return ttl, nil
+}
+
+// GetCachePath returns the full path to the encrypted vault metadata cache
+func GetCachePath() (string, error) {
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to get home directory: %w", err)
+	}
+	return filepath.Join(homeDir, configDir, cacheFile), nil
+}
+
+// GetNameCachePath returns the full path to the project and file names used by shell completion
+func GetNameCachePath() (string, error) {
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to get home directory: %w", err)
+	}
+	return filepath.Join(homeDir, configDir, namesFile), nil
+}
+
+// GetMirrorPath returns the full path to the offline mirror
+func GetMirrorPath() (string, error) {
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to get home directory: %w", err)
+	}
+	return filepath.Join(homeDir, configDir, mirrorFile), nil
+}
+
+// GetAuditPath returns the audit log file, honoring audit.path in the config
+func GetAuditPath(cfg *Config) (string, error) {
+	if cfg != nil && cfg.Audit != nil && strings.TrimSpace(cfg.Audit.Path) != "" {
+		return expandHome(strings.TrimSpace(cfg.Audit.Path))
+	}
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to get home directory: %w", err)
+	}
+	return filepath.Join(homeDir, configDir, auditFile), nil
+}
+
+// expandHome replaces a leading "~/" with the home directory
+func expandHome(path string) (string, error) {
+	if !strings.HasPrefix(path, "~/") {
+		return path, nil
+	}
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to get home directory: %w", err)
+	}
+	return filepath.Join(homeDir, path[2:]), nil
+}
+
+// ResolveBackend returns the configured backend, or BackendBitwarden when empty.
+func ResolveBackend(cfg *Config) (string, error) {
+	if cfg == nil || strings.TrimSpace(cfg.Backend) == "" {
+		return BackendBitwarden, nil
+	}
+	switch backend := strings.TrimSpace(cfg.Backend); backend {
+	case BackendBitwarden, BackendFile:
+		return backend, nil
+	default:
+		return "", fmt.Errorf("unknown backend %q: use %q or %q", cfg.Backend, BackendBitwarden, BackendFile)
+	}
+}
+
+// ResolveFileBackend returns the store path and passphrase for backend "file".
+// BWSF_FILE_PASSPHRASE takes precedence over file_backend.passphrase; an empty passphrase means it is prompted for.
+func ResolveFileBackend(cfg *Config) (string, string, error) {
+	var fileCfg FileBackendConfig
+	if cfg != nil && cfg.FileBackend != nil {
+		fileCfg = *cfg.FileBackend
+	}
+
+	passphrase := fileCfg.Passphrase
+	if env := os.Getenv(FilePassphraseEnv); env != "" {
+		passphrase = env
+	}
+
+	if path := strings.TrimSpace(fileCfg.Path); path != "" {
+		expanded, err := expandHome(path)
+		return expanded, passphrase, err
+	}
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		return "", "", fmt.Errorf("failed to get home directory: %w", err)
+	}
+	return filepath.Join(homeDir, configDir, storeFile), passphrase, nil
+}
+
+// HookConfig is a webhook or local command run after a push that changes keys.
+type HookConfig struct {
+	Type     string            `json:"type"`               // "slack", "webhook" or "command"
+	URL      string            `json:"url,omitempty"`      // Endpoint for slack and webhook
+	Headers  map[string]string `json:"headers,omitempty"`  // Extra HTTP headers for webhook, e.g. Authorization
+	Command  string            `json:"command,omitempty"`  // Shell command for command; the event JSON is passed on stdin
+	Projects []string          `json:"projects,omitempty"` // Project name patterns such as "*-prod"; all projects when empty
+	Timeout  string            `json:"timeout,omitempty"`  // Limit for each attempt (default: 10s)
+	Retries  *int              `json:"retries,omitempty"`  // Webhook retries after a failed attempt (default: 2)
+}
+
+const (
+	// DefaultHookTimeout is used when a hook has no timeout.
+	DefaultHookTimeout = 10 * time.Second
+	// DefaultHookRetries is used when a webhook has no retries.
+	DefaultHookRetries = 2
+)
+
+// ValidateHook checks a post-push hook and returns its timeout and retries with defaults applied.
+func ValidateHook(hook HookConfig) (time.Duration, int, error) {
+	switch hook.Type {
+	case "slack", "webhook":
+		if !strings.HasPrefix(hook.URL, "https://") && !strings.HasPrefix(hook.URL, "http://") {
+			return 0, 0, fmt.Errorf("%s hook needs an http(s) url", hook.Type)
+		}
+	case "command":
+		if strings.TrimSpace(hook.Command) == "" {
+			return 0, 0, fmt.Errorf("command hook needs a command")
+		}
+	default:
+		return 0, 0, fmt.Errorf("unknown hook type %q: use slack, webhook or command", hook.Type)
+	}
+
+	timeout := DefaultHookTimeout
+	if strings.TrimSpace(hook.Timeout) != "" {
+		d, err := time.ParseDuration(strings.TrimSpace(hook.Timeout))
+		if err != nil || d <= 0 {
+			return 0, 0, fmt.Errorf("invalid hook timeout %q", hook.Timeout)
+		}
+		timeout = d
+	}
+	retries := DefaultHookRetries
+	if hook.Retries != nil {
+		if *hook.Retries < 0 {
+			return 0, 0, fmt.Errorf("hook retries must not be negative")
+		}
+		retries = *hook.Retries
+	}
+	return timeout, retries, nil
+}
+
 // LoadConfig loads the configuration from file
 func LoadConfig() (*Config, error) {
 	configPath, err := GetConfigPath()
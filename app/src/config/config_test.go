@@ -4,6 +4,7 @@ import (
 	"os"
 	"path/filepath"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 )
@@ -24,6 +25,48 @@ func TestGetConfigPath_Success(t *testing.T) {
 	assert.True(t, filepath.HasPrefix(path, homeDir))
 }
 
+// 正常系: state.json は config.json と同じディレクトリに置かれる
+func TestGetStatePath_Success(t *testing.T) {
+	path, err := GetStatePath()
+
+	assert.NoError(t, err)
+	assert.Contains(t, path, ".config/bwsf/state.json")
+
+	configPath, _ := GetConfigPath()
+	assert.Equal(t, filepath.Dir(configPath), filepath.Dir(path))
+}
+
+// 正常系: キャッシュとオフラインミラーも config.json と同じディレクトリに置かれる
+func TestGetCacheAndMirrorPath_Success(t *testing.T) {
+	configPath, _ := GetConfigPath()
+
+	cachePath, err := GetCachePath()
+	assert.NoError(t, err)
+	assert.Equal(t, filepath.Join(filepath.Dir(configPath), "cache.bin"), cachePath)
+
+	mirrorPath, err := GetMirrorPath()
+	assert.NoError(t, err)
+	assert.Equal(t, filepath.Join(filepath.Dir(configPath), "mirror.json"), mirrorPath)
+}
+
+// 正常系: audit.path が無ければ設定ディレクトリ、あれば ~ を展開して使う
+func TestGetAuditPath_Success(t *testing.T) {
+	configPath, _ := GetConfigPath()
+	homeDir, _ := os.UserHomeDir()
+
+	path, err := GetAuditPath(&Config{})
+	assert.NoError(t, err)
+	assert.Equal(t, filepath.Join(filepath.Dir(configPath), "audit.jsonl"), path)
+
+	path, err = GetAuditPath(&Config{Audit: &AuditConfig{Path: "~/logs/bwsf.jsonl"}})
+	assert.NoError(t, err)
+	assert.Equal(t, filepath.Join(homeDir, "logs/bwsf.jsonl"), path)
+
+	path, err = GetAuditPath(&Config{Audit: &AuditConfig{Path: "/var/log/bwsf.jsonl"}})
+	assert.NoError(t, err)
+	assert.Equal(t, "/var/log/bwsf.jsonl", path)
+}
+
 // =============================================================================
 // LoadConfig のテスト
 // =============================================================================
@@ -171,5 +214,111 @@ func TestSaveConfig_Overwrite(t *testing.T) {
 	assert.Equal(t, "new@example.com", loaded.Email)
 }
 
+// =============================================================================
+// ResolveCacheTTL のテスト
+// =============================================================================
+
+// 正常系: 未設定は既定値、"0" は無効、それ以外は期間として解釈する
+func TestResolveCacheTTL(t *testing.T) {
+	ttl, err := ResolveCacheTTL(nil)
+	assert.NoError(t, err)
+	assert.Equal(t, DefaultCacheTTL, ttl)
+
+	ttl, err = ResolveCacheTTL(&Config{CacheTTL: "0"})
+	assert.NoError(t, err)
+	assert.Equal(t, time.Duration(0), ttl)
+
+	ttl, err = ResolveCacheTTL(&Config{CacheTTL: "1h"})
+	assert.NoError(t, err)
+	assert.Equal(t, time.Hour, ttl)
+}
+
+// 異常系: 期間として解釈できない値
+func TestResolveCacheTTL_Invalid(t *testing.T) {
+	_, err := ResolveCacheTTL(&Config{CacheTTL: "ten minutes"})
+	assert.Error(t, err)
+
+	_, err = ResolveCacheTTL(&Config{CacheTTL: "-5m"})
+	assert.Error(t, err)
+}
+
+// 正常系: shell_cache_ttl は独自の既定値を持つ
+func TestResolveShellCacheTTL(t *testing.T) {
+	ttl, err := ResolveShellCacheTTL(&Config{CacheTTL: "1h"})
+	assert.NoError(t, err)
+	assert.Equal(t, DefaultShellCacheTTL, ttl)
+
+	ttl, err = ResolveShellCacheTTL(&Config{ShellCacheTTL: "0"})
+	assert.NoError(t, err)
+	assert.Equal(t, time.Duration(0), ttl)
 
+	_, err = ResolveShellCacheTTL(&Config{ShellCacheTTL: "soon"})
+	assert.ErrorContains(t, err, "shell_cache_ttl")
+}
+
+// =============================================================================
+// ValidateHook のテスト
+// =============================================================================
+
+// 正常系: 既定のタイムアウトとリトライ回数を補う
+func TestValidateHook_Defaults(t *testing.T) {
+	timeout, retries, err := ValidateHook(HookConfig{Type: "slack", URL: "https://hooks.slack.com/services/x"})
+	assert.NoError(t, err)
+	assert.Equal(t, DefaultHookTimeout, timeout)
+	assert.Equal(t, DefaultHookRetries, retries)
+
+	zero := 0
+	timeout, retries, err = ValidateHook(HookConfig{Type: "command", Command: "notify-send bwsf", Timeout: "3s", Retries: &zero})
+	assert.NoError(t, err)
+	assert.Equal(t, 3*time.Second, timeout)
+	assert.Equal(t, 0, retries)
+}
+
+// 異常系: 種類・URL・コマンド・タイムアウトの不備
+func TestValidateHook_Invalid(t *testing.T) {
+	for _, hook := range []HookConfig{
+		{Type: "email"},
+		{Type: "webhook", URL: "ftp://example.com"},
+		{Type: "command"},
+		{Type: "webhook", URL: "https://example.com", Timeout: "soon"},
+	} {
+		_, _, err := ValidateHook(hook)
+		assert.Error(t, err, hook.Type)
+	}
+}
 
+// =============================================================================
+// ResolveBackend / ResolveFileBackend のテスト
+// =============================================================================
+
+// 正常系: 未設定なら bitwarden、file はそのまま
+func TestResolveBackend(t *testing.T) {
+	backend, err := ResolveBackend(nil)
+	assert.NoError(t, err)
+	assert.Equal(t, BackendBitwarden, backend)
+
+	backend, err = ResolveBackend(&Config{Backend: "file"})
+	assert.NoError(t, err)
+	assert.Equal(t, BackendFile, backend)
+
+	_, err = ResolveBackend(&Config{Backend: "vault"})
+	assert.Error(t, err)
+}
+
+// 正常系: 既定のパスと、環境変数によるパスフレーズの上書き
+func TestResolveFileBackend(t *testing.T) {
+	tmpDir := t.TempDir()
+	t.Setenv("HOME", tmpDir)
+	t.Setenv(FilePassphraseEnv, "")
+
+	path, passphrase, err := ResolveFileBackend(&Config{FileBackend: &FileBackendConfig{Passphrase: "from-config"}})
+	assert.NoError(t, err)
+	assert.Equal(t, filepath.Join(tmpDir, ".config/bwsf/store.age"), path)
+	assert.Equal(t, "from-config", passphrase)
+
+	t.Setenv(FilePassphraseEnv, "from-env")
+	path, passphrase, err = ResolveFileBackend(&Config{FileBackend: &FileBackendConfig{Path: "~/secrets/store.age", Passphrase: "from-config"}})
+	assert.NoError(t, err)
+	assert.Equal(t, filepath.Join(tmpDir, "secrets/store.age"), path)
+	assert.Equal(t, "from-env", passphrase)
+}
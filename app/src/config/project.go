@@ -0,0 +1,70 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"path"
+	"strings"
+)
+
+// ProjectConfigFile is the per-project configuration file placed next to the .env files.
+const ProjectConfigFile = ".bwsf.json"
+
+// Lint severity levels
+const (
+	SeverityError   = "error"
+	SeverityWarning = "warning"
+	SeverityOff     = "off"
+)
+
+// ProjectConfig holds per-project settings that are committed alongside the project.
+type ProjectConfig struct {
+	Lint        LintConfig `json:"lint,omitempty"`
+	GitGuard    string     `json:"git_guard,omitempty"`    // "abort" | "warn" (default) | "off"
+	Interpolate bool       `json:"interpolate,omitempty"`  // expand ${KEY} and bw:// references on pull
+	Extends     []string   `json:"extends,omitempty"`      // parent items whose keys are merged beneath the project's own keys
+	EnvPatterns []string   `json:"env_patterns,omitempty"` // extra globs for files holding secrets, on top of .env*
+}
+
+// LintConfig configures the .env linter for a project.
+type LintConfig struct {
+	Rules    map[string]string `json:"rules,omitempty"`    // rule name -> "error" | "warning" | "off"
+	Required []string          `json:"required,omitempty"` // keys that must not have an empty value
+}
+
+// ParseProjectConfig parses the contents of .bwsf.json.
+func ParseProjectConfig(data []byte) (*ProjectConfig, error) {
+	var cfg ProjectConfig
+	if strings.TrimSpace(string(data)) == "" {
+		return &cfg, nil
+	}
+	if err := json.Unmarshal(data, &cfg); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", ProjectConfigFile, err)
+	}
+
+	for rule, severity := range cfg.Lint.Rules {
+		if err := ValidateSeverity(severity); err != nil {
+			return nil, fmt.Errorf("invalid severity for lint rule %q: %w", rule, err)
+		}
+	}
+	switch cfg.GitGuard {
+	case "", "abort", "warn", "off":
+	default:
+		return nil, fmt.Errorf("invalid git_guard %q (expected abort, warn or off)", cfg.GitGuard)
+	}
+	for _, pattern := range cfg.EnvPatterns {
+		if _, err := path.Match(pattern, ""); err != nil || strings.TrimSpace(pattern) == "" {
+			return nil, fmt.Errorf("invalid env_patterns entry %q", pattern)
+		}
+	}
+	return &cfg, nil
+}
+
+// ValidateSeverity rejects unknown severity levels.
+func ValidateSeverity(severity string) error {
+	switch severity {
+	case SeverityError, SeverityWarning, SeverityOff:
+		return nil
+	}
+	return fmt.Errorf("unknown severity %q (expected error, warning or off)", severity)
+}
@@ -0,0 +1,75 @@
+package config
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// =============================================================================
+// ParseProjectConfig のテスト
+// =============================================================================
+
+// 正常系: lint 設定がパースされる
+func TestParseProjectConfig_Lint(t *testing.T) {
+	data := []byte(`{"lint":{"rules":{"placeholder":"error","crlf":"off"},"required":["DATABASE_URL"]}}`)
+
+	cfg, err := ParseProjectConfig(data)
+
+	require.NoError(t, err)
+	assert.Equal(t, "error", cfg.Lint.Rules["placeholder"])
+	assert.Equal(t, "off", cfg.Lint.Rules["crlf"])
+	assert.Equal(t, []string{"DATABASE_URL"}, cfg.Lint.Required)
+}
+
+// 正常系: 空の内容はゼロ値の設定になる
+func TestParseProjectConfig_Empty(t *testing.T) {
+	cfg, err := ParseProjectConfig([]byte("  \n"))
+
+	require.NoError(t, err)
+	assert.NotNil(t, cfg)
+	assert.Empty(t, cfg.Lint.Rules)
+}
+
+// 異常系: 不明な severity
+func TestParseProjectConfig_InvalidSeverity(t *testing.T) {
+	_, err := ParseProjectConfig([]byte(`{"lint":{"rules":{"crlf":"fatal"}}}`))
+
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "crlf")
+}
+
+// 異常系: 不正な JSON
+func TestParseProjectConfig_InvalidJSON(t *testing.T) {
+	_, err := ParseProjectConfig([]byte(`{`))
+
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), ".bwsf.json")
+}
+
+// 正常系: git_guard を読み込む
+func TestParseProjectConfig_GitGuard(t *testing.T) {
+	cfg, err := ParseProjectConfig([]byte(`{"git_guard":"warn"}`))
+
+	require.NoError(t, err)
+	assert.Equal(t, "warn", cfg.GitGuard)
+}
+
+// 異常系: 不明な git_guard
+func TestParseProjectConfig_InvalidGitGuard(t *testing.T) {
+	_, err := ParseProjectConfig([]byte(`{"git_guard":"maybe"}`))
+
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "git_guard")
+}
+
+// 正常系 / 異常系: env_patterns を読み込み、不正なパターンは拒否する
+func TestParseProjectConfig_EnvPatterns(t *testing.T) {
+	cfg, err := ParseProjectConfig([]byte(`{"env_patterns":["secrets.env","config/*.key"]}`))
+	require.NoError(t, err)
+	assert.Equal(t, []string{"secrets.env", "config/*.key"}, cfg.EnvPatterns)
+
+	_, err = ParseProjectConfig([]byte(`{"env_patterns":["[secrets"]}`))
+	assert.ErrorContains(t, err, "env_patterns")
+}
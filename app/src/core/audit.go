@@ -0,0 +1,296 @@
+package core
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// 監査ログの操作種別
+const (
+	AuditOpPull   = "pull"
+	AuditOpPush   = "push"
+	AuditOpRemove = "rm"
+	AuditOpRename = "mv"
+	AuditOpCopy   = "cp"
+	AuditOpRotate = "rotate"
+)
+
+// AuditSink は監査ログの書き込み先を抽象化するインターフェースです。
+type AuditSink interface {
+	// Append は改行を含まない 1 行分の JSON を追記します。
+	Append(line []byte) error
+}
+
+// AuditActor は操作した利用者を表します。
+type AuditActor struct {
+	User    string
+	Host    string
+	Profile string // Bitwarden のアカウント（config.json の email とサーバー）
+}
+
+// AuditEntry は監査ログの 1 行です。
+// シークレットの値は記録せず、キー名とファイルの内容ハッシュのみを記録します。
+type AuditEntry struct {
+	Timestamp time.Time           `json:"timestamp"`
+	User      string              `json:"user"`
+	Host      string              `json:"host"`
+	Profile   string              `json:"profile,omitempty"`
+	Project   string              `json:"project"`
+	Op        string              `json:"op"`
+	Target    string              `json:"target,omitempty"` // mv / cp の宛先プロジェクト
+	Dir       string              `json:"dir,omitempty"`    // push 元・pull 先のディレクトリ
+	Files     []string            `json:"files,omitempty"`
+	Keys      map[string][]string `json:"keys,omitempty"`   // ファイル名 -> 追加・変更・削除されたキー名
+	Hashes    map[string]string   `json:"hashes,omitempty"` // ファイル名 -> 内容の SHA-256
+}
+
+// AuditLog は監査エントリを各シンクに追記します。
+// ワークスペース処理で並列に使われるため、書き込みは直列化します。
+type AuditLog struct {
+	actor AuditActor
+	sinks []AuditSink
+	now   func() time.Time
+	mu    sync.Mutex
+}
+
+// NewAuditLog は AuditLog を作成します。now が nil の場合は time.Now を使います。
+func NewAuditLog(actor AuditActor, now func() time.Time, sinks ...AuditSink) *AuditLog {
+	if now == nil {
+		now = time.Now
+	}
+	return &AuditLog{actor: actor, sinks: sinks, now: now}
+}
+
+// Record は entry に時刻と利用者を補って全シンクに追記します。
+// 書き込めないシンクがあっても残りのシンクには書き込み、最初のエラーを返します。
+// nil の AuditLog では何もしません。
+func (a *AuditLog) Record(entry AuditEntry) error {
+	if a == nil {
+		return nil
+	}
+	entry.Timestamp = a.now()
+	entry.User = a.actor.User
+	entry.Host = a.actor.Host
+	entry.Profile = a.actor.Profile
+
+	line, err := json.Marshal(entry)
+	if err != nil {
+		return fmt.Errorf("failed to marshal audit entry: %w", err)
+	}
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	var firstErr error
+	for _, sink := range a.sinks {
+		if err := sink.Append(line); err != nil && firstErr == nil {
+			firstErr = fmt.Errorf("failed to write audit log: %w", err)
+		}
+	}
+	return firstErr
+}
+
+// recordAudit は監査エントリを記録し、失敗しても操作自体は成功しているので警告に留めます。
+func recordAudit(audit *AuditLog, entry AuditEntry, logger Logger) {
+	if err := audit.Record(entry); err != nil {
+		logger.Warning(err.Error())
+	}
+}
+
+// auditHashes はファイルごとの内容ハッシュを返します（同期状態と同じハッシュ）。
+func auditHashes(data MultiEnvData) map[string]string {
+	hashes := make(map[string]string, len(data))
+	for fileName, envData := range data {
+		hashes[fileName] = hashEnvData(envData)
+	}
+	return hashes
+}
+
+// changedKeys は before から after で追加・変更・削除されたキー名をファイルごとに返します。
+// after に含まれるファイルのみを比較します。
+func changedKeys(before, after MultiEnvData) map[string][]string {
+	result := make(map[string][]string)
+	for fileName, changes := range diffKeys(before, after) {
+		keys := append(append(append([]string{}, changes.Added...), changes.Changed...), changes.Removed...)
+		sort.Strings(keys)
+		result[fileName] = keys
+	}
+	if len(result) == 0 {
+		return nil
+	}
+	return result
+}
+
+// KeyChanges は 1 ファイル分のキーの変更です（キー名のみ）。
+type KeyChanges struct {
+	Added   []string `json:"added,omitempty"`
+	Changed []string `json:"changed,omitempty"`
+	Removed []string `json:"removed,omitempty"`
+}
+
+// diffKeys は before から after で追加・変更・削除されたキー名をファイルごとに返します。
+// after に含まれるファイルのみを比較し、変更のないファイルは含めません。
+func diffKeys(before, after MultiEnvData) map[string]KeyChanges {
+	result := make(map[string]KeyChanges)
+	for fileName, envData := range after {
+		oldValues := envValues(before[fileName])
+		newValues := envValues(envData)
+
+		var changes KeyChanges
+		for key, value := range newValues {
+			old, ok := oldValues[key]
+			switch {
+			case !ok:
+				changes.Added = append(changes.Added, key)
+			case old != value:
+				changes.Changed = append(changes.Changed, key)
+			}
+		}
+		for key := range oldValues {
+			if _, ok := newValues[key]; !ok {
+				changes.Removed = append(changes.Removed, key)
+			}
+		}
+		if len(changes.Added)+len(changes.Changed)+len(changes.Removed) > 0 {
+			sort.Strings(changes.Added)
+			sort.Strings(changes.Changed)
+			sort.Strings(changes.Removed)
+			result[fileName] = changes
+		}
+	}
+	return result
+}
+
+// envValues は EnvData のキーと生の値の対応を返します（同じキーは後の行が優先）。
+func envValues(data EnvData) map[string]string {
+	values := make(map[string]string)
+	for _, line := range data.Lines {
+		if entry, ok := parseEnvLine(line); ok {
+			values[entry.Key] = entry.RawValue
+		}
+	}
+	return values
+}
+
+// AuditFilter は監査ログの絞り込み条件です。ゼロ値の項目は条件に含めません。
+type AuditFilter struct {
+	Project string
+	Op      string
+	User    string
+	Key     string
+	Since   time.Time
+	Until   time.Time
+}
+
+// ParseAuditTime は絞り込みの時刻を解釈します。
+// "2026-01-02" や RFC3339 の日時のほか、"24h" や "7d" のような now からさかのぼる期間を受け付けます。
+func ParseAuditTime(value string, now time.Time) (time.Time, error) {
+	value = strings.TrimSpace(value)
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t, nil
+	}
+	if t, err := time.ParseInLocation("2006-01-02", value, now.Location()); err == nil {
+		return t, nil
+	}
+	if d, err := ParseAge(value); err == nil {
+		return now.Add(-d), nil
+	}
+	return time.Time{}, fmt.Errorf("invalid time %q: use a date (2026-01-02), an RFC3339 time, or a duration such as 24h or 7d", value)
+}
+
+// ParseAge は "90d" のような日数、または time.ParseDuration の形式の期間を解釈します。
+func ParseAge(value string) (time.Duration, error) {
+	value = strings.TrimSpace(value)
+	if days, ok := strings.CutSuffix(value, "d"); ok {
+		n, err := strconv.Atoi(days)
+		if err != nil || n < 0 {
+			return 0, fmt.Errorf("invalid duration %q", value)
+		}
+		return time.Duration(n) * 24 * time.Hour, nil
+	}
+	d, err := time.ParseDuration(value)
+	if err != nil || d < 0 {
+		return 0, fmt.Errorf("invalid duration %q", value)
+	}
+	return d, nil
+}
+
+// ReadAuditLog は監査ログファイルを読み込みます。ファイルが存在しない場合は空を返します。
+func ReadAuditLog(fs FileSystem, path string) ([]AuditEntry, error) {
+	info, err := fs.Stat(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to stat audit log: %w", err)
+	}
+	if info.IsNotExist() {
+		return nil, nil
+	}
+	data, err := fs.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read audit log: %w", err)
+	}
+
+	var entries []AuditEntry
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	lineNo := 0
+	for scanner.Scan() {
+		lineNo++
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+		var entry AuditEntry
+		if err := json.Unmarshal([]byte(line), &entry); err != nil {
+			return nil, fmt.Errorf("failed to parse audit log line %d: %w", lineNo, err)
+		}
+		entries = append(entries, entry)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read audit log: %w", err)
+	}
+	return entries, nil
+}
+
+// FilterAuditEntries は条件に一致するエントリを記録順のまま返します。
+func FilterAuditEntries(entries []AuditEntry, filter AuditFilter) []AuditEntry {
+	var result []AuditEntry
+	for _, entry := range entries {
+		if filter.Project != "" && entry.Project != filter.Project && entry.Target != filter.Project {
+			continue
+		}
+		if filter.Op != "" && entry.Op != filter.Op {
+			continue
+		}
+		if filter.User != "" && entry.User != filter.User {
+			continue
+		}
+		if filter.Key != "" && !auditEntryHasKey(entry, filter.Key) {
+			continue
+		}
+		if !filter.Since.IsZero() && entry.Timestamp.Before(filter.Since) {
+			continue
+		}
+		if !filter.Until.IsZero() && !entry.Timestamp.Before(filter.Until) {
+			continue
+		}
+		result = append(result, entry)
+	}
+	return result
+}
+
+func auditEntryHasKey(entry AuditEntry, key string) bool {
+	for _, keys := range entry.Keys {
+		for _, k := range keys {
+			if k == key {
+				return true
+			}
+		}
+	}
+	return false
+}
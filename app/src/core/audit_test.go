@@ -0,0 +1,226 @@
+package core
+
+import (
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"bwsf/src/config"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// mockAuditSink は追記された行をメモリに保持するシンクです。
+type mockAuditSink struct {
+	lines []string
+	err   error
+}
+
+func (s *mockAuditSink) Append(line []byte) error {
+	if s.err != nil {
+		return s.err
+	}
+	s.lines = append(s.lines, string(line))
+	return nil
+}
+
+// testAuditLog は固定の利用者・時刻で記録する AuditLog を返します。
+func testAuditLog(sinks ...AuditSink) *AuditLog {
+	now := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	return NewAuditLog(AuditActor{User: "alice", Host: "laptop", Profile: "alice@example.com"}, func() time.Time { return now }, sinks...)
+}
+
+// decodeAudit は 1 行の JSON を AuditEntry に戻します。
+func decodeAudit(t *testing.T, line string) AuditEntry {
+	t.Helper()
+	var entry AuditEntry
+	require.NoError(t, json.Unmarshal([]byte(line), &entry))
+	return entry
+}
+
+// =============================================================================
+// AuditLog のテスト
+// =============================================================================
+
+// 正常系: 利用者と時刻を補って全シンクに書き込む
+func TestAuditLog_Record(t *testing.T) {
+	first, second := &mockAuditSink{}, &mockAuditSink{}
+	audit := testAuditLog(first, second)
+
+	require.NoError(t, audit.Record(AuditEntry{Op: AuditOpRename, Project: "web", Target: "web-old"}))
+
+	require.Len(t, first.lines, 1)
+	assert.Equal(t, first.lines, second.lines)
+	entry := decodeAudit(t, first.lines[0])
+	assert.Equal(t, "alice", entry.User)
+	assert.Equal(t, "laptop", entry.Host)
+	assert.Equal(t, "alice@example.com", entry.Profile)
+	assert.Equal(t, time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC), entry.Timestamp)
+	assert.Equal(t, "web-old", entry.Target)
+}
+
+// 異常系: 書き込めないシンクがあっても残りには書き込み、エラーを返す
+func TestAuditLog_RecordSinkError(t *testing.T) {
+	broken, ok := &mockAuditSink{err: errors.New("disk full")}, &mockAuditSink{}
+
+	err := testAuditLog(broken, ok).Record(AuditEntry{Op: AuditOpPull, Project: "web"})
+
+	assert.ErrorContains(t, err, "disk full")
+	assert.Len(t, ok.lines, 1)
+
+	// nil の AuditLog は何もしない
+	var audit *AuditLog
+	assert.NoError(t, audit.Record(AuditEntry{}))
+}
+
+// =============================================================================
+// PushEnvCore / PullEnvCore の監査ログのテスト
+// =============================================================================
+
+// 正常系: push では保管庫から変わったキー名とハッシュを記録し、値は記録しない
+func TestPushEnvCore_Audit(t *testing.T) {
+	sink := &mockAuditSink{}
+	bw := newTestBwClient(t, MultiEnvData{".env": {Lines: []string{"KEEP=same", "ROTATED=old-secret", "DROPPED=gone"}}})
+	fs := &mockFileSystem{
+		dirEntries:     []DirEntry{&mockDirEntry{name: ".env"}},
+		readContentMap: map[string][]byte{"/web/.env": []byte("KEEP=same\nROTATED=new-secret\nADDED=value")},
+	}
+
+	err := PushEnvCoreWithOptions("/web", "web", fs, bw, &config.Config{}, nil, &mockLogger{}, PushOptions{SkipLint: true, Audit: testAuditLog(sink)})
+
+	require.NoError(t, err)
+	require.Len(t, sink.lines, 1)
+	assert.NotContains(t, sink.lines[0], "secret")
+	entry := decodeAudit(t, sink.lines[0])
+	assert.Equal(t, AuditOpPush, entry.Op)
+	assert.Equal(t, "web", entry.Project)
+	assert.Equal(t, []string{".env"}, entry.Files)
+	assert.Equal(t, map[string][]string{".env": {"ADDED", "DROPPED", "ROTATED"}}, entry.Keys)
+	assert.Len(t, entry.Hashes[".env"], 64)
+}
+
+// 正常系: pull ではローカルの既存ファイルから変わったキー名を記録する
+func TestPullEnvCore_Audit(t *testing.T) {
+	sink := &mockAuditSink{}
+	bw := newTestBwClient(t, MultiEnvData{
+		".env":      {Lines: []string{"A=1", "B=2"}},
+		".env.test": {Lines: []string{"T=1"}},
+	})
+	fs := &mockFileSystem{
+		statInfoMap:    map[string]FileInfo{"/web/.env": &mockFileInfo{notExist: false}},
+		readContentMap: map[string][]byte{"/web/.env": []byte("A=1\nB=1")},
+	}
+	confirm := func(path string) (bool, error) { return true, nil }
+
+	err := PullEnvCoreWithOptions("/web", "web", fs, bw, &config.Config{}, nil, confirm, &mockLogger{}, PullOptions{Audit: testAuditLog(sink)})
+
+	require.NoError(t, err)
+	require.Len(t, sink.lines, 1)
+	entry := decodeAudit(t, sink.lines[0])
+	assert.Equal(t, AuditOpPull, entry.Op)
+	assert.Equal(t, []string{".env", ".env.test"}, entry.Files)
+	assert.Equal(t, map[string][]string{".env": {"B"}, ".env.test": {"T"}}, entry.Keys)
+	assert.Len(t, entry.Hashes, 2)
+}
+
+// 正常系: すべてのファイルを上書きしなかった場合は記録しない
+func TestPullEnvCore_AuditSkipped(t *testing.T) {
+	sink := &mockAuditSink{}
+	bw := newTestBwClient(t, MultiEnvData{".env": {Lines: []string{"A=1"}}})
+	fs := &mockFileSystem{statInfo: &mockFileInfo{notExist: false}}
+	decline := func(path string) (bool, error) { return false, nil }
+
+	err := PullEnvCoreWithOptions("/web", "web", fs, bw, &config.Config{}, nil, decline, &mockLogger{}, PullOptions{Audit: testAuditLog(sink)})
+
+	require.NoError(t, err)
+	assert.Empty(t, sink.lines)
+}
+
+// =============================================================================
+// ReadAuditLog / FilterAuditEntries のテスト
+// =============================================================================
+
+// 正常系: JSONL を読み込み、条件で絞り込む
+func TestReadAndFilterAuditLog(t *testing.T) {
+	lines := []string{
+		`{"timestamp":"2026-01-01T09:00:00Z","user":"alice","op":"pull","project":"web"}`,
+		``,
+		`{"timestamp":"2026-01-02T09:00:00Z","user":"bob","op":"push","project":"web","keys":{".env":["API_KEY"]}}`,
+		`{"timestamp":"2026-01-03T09:00:00Z","user":"alice","op":"mv","project":"api","target":"web"}`,
+	}
+	fs := &mockFileSystem{
+		statInfo:       &mockFileInfo{notExist: false},
+		readContentMap: map[string][]byte{"/cfg/audit.jsonl": []byte(strings.Join(lines, "\n"))},
+	}
+
+	entries, err := ReadAuditLog(fs, "/cfg/audit.jsonl")
+	require.NoError(t, err)
+	require.Len(t, entries, 3)
+
+	assert.Len(t, FilterAuditEntries(entries, AuditFilter{Project: "web"}), 3, "mv の宛先も含む")
+	assert.Len(t, FilterAuditEntries(entries, AuditFilter{User: "alice"}), 2)
+	assert.Len(t, FilterAuditEntries(entries, AuditFilter{Key: "API_KEY"}), 1)
+	filtered := FilterAuditEntries(entries, AuditFilter{
+		Since: time.Date(2026, 1, 2, 0, 0, 0, 0, time.UTC),
+		Until: time.Date(2026, 1, 3, 0, 0, 0, 0, time.UTC),
+	})
+	require.Len(t, filtered, 1)
+	assert.Equal(t, "bob", filtered[0].User)
+}
+
+// 異常系: 壊れた行は行番号付きでエラー、ファイルが無ければ空
+func TestReadAuditLog_Errors(t *testing.T) {
+	fs := &mockFileSystem{
+		statInfo:    &mockFileInfo{notExist: false},
+		readContent: []byte("{\"op\":\"pull\"}\nnot json\n"),
+	}
+	_, err := ReadAuditLog(fs, "/cfg/audit.jsonl")
+	assert.ErrorContains(t, err, "line 2")
+
+	entries, err := ReadAuditLog(&mockFileSystem{}, "/cfg/audit.jsonl")
+	assert.NoError(t, err)
+	assert.Empty(t, entries)
+}
+
+// =============================================================================
+// ParseAuditTime / ParseAge のテスト
+// =============================================================================
+
+// 正常系: 日付・RFC3339・さかのぼる期間
+func TestParseAuditTime(t *testing.T) {
+	now := time.Date(2026, 1, 10, 12, 0, 0, 0, time.UTC)
+
+	got, err := ParseAuditTime("2026-01-02", now)
+	require.NoError(t, err)
+	assert.Equal(t, time.Date(2026, 1, 2, 0, 0, 0, 0, time.UTC), got)
+
+	got, err = ParseAuditTime("2026-01-02T03:04:05Z", now)
+	require.NoError(t, err)
+	assert.Equal(t, time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC), got)
+
+	got, err = ParseAuditTime("7d", now)
+	require.NoError(t, err)
+	assert.Equal(t, time.Date(2026, 1, 3, 12, 0, 0, 0, time.UTC), got)
+
+	_, err = ParseAuditTime("last week", now)
+	assert.Error(t, err)
+}
+
+// 正常系: 日数と Go の期間表記、異常系: 負の値や不正な表記
+func TestParseAge(t *testing.T) {
+	d, err := ParseAge("90d")
+	require.NoError(t, err)
+	assert.Equal(t, 90*24*time.Hour, d)
+
+	d, err = ParseAge("36h")
+	require.NoError(t, err)
+	assert.Equal(t, 36*time.Hour, d)
+
+	_, err = ParseAge("-1d")
+	assert.Error(t, err)
+	_, err = ParseAge("soon")
+	assert.Error(t, err)
+}
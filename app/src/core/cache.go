@@ -0,0 +1,506 @@
+package core
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// CacheKeyringAccount は永続キャッシュの暗号化鍵を保存するキーリングのアカウント名です。
+// サービス名はミラーと同じ MirrorKeyringService を使います。
+const CacheKeyringAccount = "metadata-cache"
+
+// CacheOptions は CachingBwClient の設定です。
+type CacheOptions struct {
+	Path       string           // 永続キャッシュのパス（空の場合は実行中のメモ化のみ）
+	Key        []byte           // 永続キャッシュの暗号化鍵（AES-256、32 バイト）
+	FolderName string           // フォルダ ID を記録する際のフォルダ名
+	TTL        time.Duration    // 最後の同期からキャッシュを信頼する期間（0 以下で永続キャッシュ無効）
+	ForceSync  bool             // キャッシュの鮮度に関わらず最初の参照時に同期する（--sync）
+	Now        func() time.Time // テスト用（nil の場合は time.Now）
+}
+
+// metadataCache は永続キャッシュの内容です。値（ノート本文）は含みません。
+type metadataCache struct {
+	SyncedAt time.Time         `json:"synced_at"`
+	Folders  map[string]string `json:"folders"` // フォルダ名 -> ID
+	Items    map[string][]Item `json:"items"`   // フォルダ ID -> アイテム一覧（ID・名前・更新日時）
+}
+
+// CachingBwClient は BwClient をラップし、bw CLI の呼び出しを減らします。
+//
+// 1 回の実行中はフォルダ ID・アイテム一覧・取得したアイテムをメモ化します。
+// フォルダ ID とアイテムのメタデータは暗号化してディスクにも保存し、TTL 内であれば
+// 同期と一覧取得を省きます。同期はキャッシュが古い場合か ForceSync の場合のみ行います。
+// フォルダ内の GetItemByName は一覧から ID を引いて GetItemByID で取得します。
+type CachingBwClient struct {
+	BwClient
+
+	fs   FileSystem
+	opts CacheOptions
+
+	syncMu sync.Mutex // 同期を 1 回にまとめる
+	mu     sync.Mutex
+
+	loaded    bool
+	disk      metadataCache
+	synced    bool // この実行中に同期を試みたか
+	folderID  string
+	listings  map[string][]Item // この実行中に取得したフォルダ ID -> アイテム一覧
+	fromDisk  map[string]bool   // listings のうち永続キャッシュから読んだもの
+	fullItems map[string]*FullItem
+}
+
+// NewCachingBwClient は bw をラップした CachingBwClient を作成します。
+func NewCachingBwClient(bw BwClient, fs FileSystem, opts CacheOptions) *CachingBwClient {
+	if opts.Now == nil {
+		opts.Now = time.Now
+	}
+	return &CachingBwClient{
+		BwClient:  bw,
+		fs:        fs,
+		opts:      opts,
+		listings:  make(map[string][]Item),
+		fromDisk:  make(map[string]bool),
+		fullItems: make(map[string]*FullItem),
+	}
+}
+
+// persistent は永続キャッシュを使うかを返します。
+func (c *CachingBwClient) persistent() bool {
+	return c.opts.Path != "" && c.opts.TTL > 0 && len(c.opts.Key) == 32
+}
+
+// loadLocked は永続キャッシュを 1 回だけ読み込みます。読めない場合は空のキャッシュとして扱います。
+func (c *CachingBwClient) loadLocked() {
+	if c.loaded {
+		return
+	}
+	c.loaded = true
+	c.disk = metadataCache{Folders: make(map[string]string), Items: make(map[string][]Item)}
+	if !c.persistent() {
+		return
+	}
+
+	info, err := c.fs.Stat(c.opts.Path)
+	if err != nil || info.IsNotExist() {
+		return
+	}
+	data, err := c.fs.ReadFile(c.opts.Path)
+	if err != nil {
+		return
+	}
+	plain, err := decryptCache(c.opts.Key, data)
+	if err != nil {
+		return
+	}
+	var disk metadataCache
+	if err := json.Unmarshal(plain, &disk); err != nil {
+		return
+	}
+	if disk.Folders != nil {
+		c.disk.Folders = disk.Folders
+	}
+	if disk.Items != nil {
+		c.disk.Items = disk.Items
+	}
+	c.disk.SyncedAt = disk.SyncedAt
+}
+
+// saveLocked は永続キャッシュを書き出します。キャッシュは補助的なものなので失敗は無視します。
+func (c *CachingBwClient) saveLocked() {
+	if !c.persistent() {
+		return
+	}
+	plain, err := json.Marshal(c.disk)
+	if err != nil {
+		return
+	}
+	data, err := encryptCache(c.opts.Key, plain)
+	if err != nil {
+		return
+	}
+	if err := c.fs.MkdirAll(filepath.Dir(c.opts.Path), 0700); err != nil {
+		return
+	}
+	_ = c.fs.WriteFile(c.opts.Path, data, 0600)
+}
+
+// freshLocked は永続キャッシュを同期なしで信頼できるかを返します。
+func (c *CachingBwClient) freshLocked() bool {
+	if c.opts.ForceSync || !c.persistent() || c.disk.SyncedAt.IsZero() {
+		return false
+	}
+	return c.opts.Now().Sub(c.disk.SyncedAt) < c.opts.TTL
+}
+
+// GetDotenvsFolderID はメモ化・永続キャッシュの順にフォルダ ID を探し、無ければ取得します。
+func (c *CachingBwClient) GetDotenvsFolderID() (string, error) {
+	c.mu.Lock()
+	c.loadLocked()
+	if c.folderID == "" && c.freshLocked() {
+		c.folderID = c.disk.Folders[c.opts.FolderName]
+	}
+	folderID := c.folderID
+	c.mu.Unlock()
+	if folderID != "" {
+		return folderID, nil
+	}
+
+	folderID, err := c.BwClient.GetDotenvsFolderID()
+	if err != nil {
+		return "", err
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.folderID = folderID
+	if c.disk.Folders[c.opts.FolderName] != folderID {
+		c.disk.Folders[c.opts.FolderName] = folderID
+		c.saveLocked()
+	}
+	return folderID, nil
+}
+
+// CreateDotenvsFolder はフォルダを作成し、記録済みのフォルダ ID を破棄します。
+func (c *CachingBwClient) CreateDotenvsFolder() error {
+	if err := c.BwClient.CreateDotenvsFolder(); err != nil {
+		return err
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.loadLocked()
+	c.folderID = ""
+	delete(c.disk.Folders, c.opts.FolderName)
+	c.saveLocked()
+	return nil
+}
+
+// Sync はキャッシュが古い場合のみサーバーと同期します。1 回の実行で同期するのは 1 回だけです。
+func (c *CachingBwClient) Sync() error {
+	return c.sync(false)
+}
+
+// SyncForWrite はキャッシュの鮮度によらず、この実行でまだ同期していなければサーバーと同期します。
+func (c *CachingBwClient) SyncForWrite() error {
+	return c.sync(true)
+}
+
+// sync はサーバーと同期し、キャッシュした一覧と内容を破棄します。
+// force が false の場合は永続キャッシュが新しければ同期を省きます。
+func (c *CachingBwClient) sync(force bool) error {
+	c.syncMu.Lock()
+	defer c.syncMu.Unlock()
+
+	c.mu.Lock()
+	c.loadLocked()
+	skip := c.synced || (!force && c.freshLocked())
+	c.mu.Unlock()
+	if skip {
+		return nil
+	}
+
+	if err := c.BwClient.Sync(); err != nil {
+		if !IsLockedError(err) {
+			// 同期できなくてもローカルのデータで続行できるため、この実行中は再試行しない
+			c.mu.Lock()
+			c.synced = true
+			c.mu.Unlock()
+		}
+		return err
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.synced = true
+	c.disk.SyncedAt = c.opts.Now()
+	c.disk.Items = make(map[string][]Item)
+	c.listings = make(map[string][]Item)
+	c.fromDisk = make(map[string]bool)
+	c.fullItems = make(map[string]*FullItem)
+	c.saveLocked()
+	return nil
+}
+
+// WriteSyncer は Sync を省くことがあるクライアントが、読み込み・変更・書き込みの前に必ず同期するためのインターフェースです。
+type WriteSyncer interface {
+	SyncForWrite() error
+}
+
+// ensureSynced は必要なら同期します。ロック以外の同期エラーは無視します（従来の GetItemByName と同じ）。
+func (c *CachingBwClient) ensureSynced() error {
+	if err := c.Sync(); err != nil && IsLockedError(err) {
+		return err
+	}
+	return nil
+}
+
+// ListItemsInFolder はキャッシュが新しければ保存済みの一覧を返し、そうでなければ同期してから取得します。
+func (c *CachingBwClient) ListItemsInFolder(folderID string) ([]Item, error) {
+	c.mu.Lock()
+	c.loadLocked()
+	if items, ok := c.listings[folderID]; ok {
+		c.mu.Unlock()
+		return append([]Item(nil), items...), nil
+	}
+	if items, ok := c.disk.Items[folderID]; ok && c.freshLocked() {
+		c.listings[folderID] = items
+		c.fromDisk[folderID] = true
+		c.mu.Unlock()
+		return append([]Item(nil), items...), nil
+	}
+	c.mu.Unlock()
+
+	return c.listItems(folderID)
+}
+
+// listItems は同期してからフォルダ内のアイテム一覧を取得し、キャッシュに記録します。
+func (c *CachingBwClient) listItems(folderID string) ([]Item, error) {
+	if err := c.ensureSynced(); err != nil {
+		return nil, err
+	}
+	items, err := c.BwClient.ListItemsInFolder(folderID)
+	if err != nil {
+		return nil, err
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.listings[folderID] = items
+	delete(c.fromDisk, folderID)
+	c.disk.Items[folderID] = items
+	c.saveLocked()
+	return append([]Item(nil), items...), nil
+}
+
+// refresh は永続キャッシュから読んだ一覧を破棄し、同期して取り直します。
+// この実行中に既に取得済みの一覧であれば何もせず false を返します。
+func (c *CachingBwClient) refresh(folderID string) (bool, error) {
+	c.mu.Lock()
+	stale := c.fromDisk[folderID]
+	if stale {
+		c.disk.SyncedAt = time.Time{}
+		delete(c.listings, folderID)
+		delete(c.fromDisk, folderID)
+	}
+	c.mu.Unlock()
+	if !stale {
+		return false, nil
+	}
+	_, err := c.listItems(folderID)
+	return err == nil, err
+}
+
+// GetItemByName はフォルダ内のアイテムを一覧から ID で引いて取得します。
+// キャッシュの一覧に見つからない場合は、重複して作成しないよう同期して確認し直します。
+// folderID が空（全フォルダから検索）の場合はそのまま委譲します。
+func (c *CachingBwClient) GetItemByName(folderID, name string) (*FullItem, error) {
+	if folderID == "" {
+		return c.BwClient.GetItemByName(folderID, name)
+	}
+
+	for attempt := 0; ; attempt++ {
+		items, err := c.ListItemsInFolder(folderID)
+		if err != nil {
+			return nil, err
+		}
+		var item *FullItem
+		if id := findItemID(items, name); id != "" {
+			item, err = c.GetItemByID(id)
+			if err != nil && (attempt > 0 || IsLockedError(err)) {
+				return nil, err
+			}
+		}
+		if item != nil {
+			return item, nil
+		}
+		if attempt > 0 {
+			return nil, err
+		}
+		// 見つからない・取得できない場合、一覧が永続キャッシュのものであれば同期して確認し直す
+		refreshed, refreshErr := c.refresh(folderID)
+		if refreshErr != nil {
+			return nil, refreshErr
+		}
+		if !refreshed {
+			return nil, err
+		}
+	}
+}
+
+// findItemID は一覧から名前が一致する最初のアイテムの ID を返します。
+func findItemID(items []Item, name string) string {
+	for _, item := range items {
+		if item.Name == name {
+			return item.ID
+		}
+	}
+	return ""
+}
+
+// GetItemByID は取得済みのアイテムがあればそれを返します。
+func (c *CachingBwClient) GetItemByID(id string) (*FullItem, error) {
+	c.mu.Lock()
+	cached, ok := c.fullItems[id]
+	c.mu.Unlock()
+	if ok {
+		item := *cached
+		return &item, nil
+	}
+
+	item, err := c.BwClient.GetItemByID(id)
+	if err != nil || item == nil {
+		return item, err
+	}
+	c.mu.Lock()
+	stored := *item
+	c.fullItems[id] = &stored
+	c.mu.Unlock()
+	return item, nil
+}
+
+// CreateNoteItem はアイテムを作成し、そのフォルダの一覧を破棄します。
+func (c *CachingBwClient) CreateNoteItem(folderID, name, notes string) error {
+	if err := c.BwClient.CreateNoteItem(folderID, name, notes); err != nil {
+		return err
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.loadLocked()
+	delete(c.listings, folderID)
+	delete(c.fromDisk, folderID)
+	delete(c.disk.Items, folderID)
+	c.saveLocked()
+	return nil
+}
+
+// UpdateNoteItem はアイテムを更新し、取得済みの内容を破棄します。
+func (c *CachingBwClient) UpdateNoteItem(id, notes string) error {
+	if err := c.BwClient.UpdateNoteItem(id, notes); err != nil {
+		return err
+	}
+	c.mu.Lock()
+	delete(c.fullItems, id)
+	c.mu.Unlock()
+	return nil
+}
+
+// UpdateNoteItemFields はアイテムを更新し、取得済みの内容を破棄します。
+func (c *CachingBwClient) UpdateNoteItemFields(id, notes string, hidden []ItemField) error {
+	if err := c.BwClient.UpdateNoteItemFields(id, notes, hidden); err != nil {
+		return err
+	}
+	c.mu.Lock()
+	delete(c.fullItems, id)
+	c.mu.Unlock()
+	return nil
+}
+
+// RenameItem はアイテム名を変更し、一覧を破棄します。
+func (c *CachingBwClient) RenameItem(id, newName string) error {
+	if err := c.BwClient.RenameItem(id, newName); err != nil {
+		return err
+	}
+	c.invalidateItem(id)
+	return nil
+}
+
+// DeleteItem はアイテムを削除し、一覧を破棄します。
+func (c *CachingBwClient) DeleteItem(id string, permanent bool) error {
+	if err := c.BwClient.DeleteItem(id, permanent); err != nil {
+		return err
+	}
+	c.invalidateItem(id)
+	return nil
+}
+
+// invalidateItem は id のアイテムと、それを含みうる全フォルダの一覧を破棄します。
+func (c *CachingBwClient) invalidateItem(id string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.loadLocked()
+	delete(c.fullItems, id)
+	c.listings = make(map[string][]Item)
+	c.fromDisk = make(map[string]bool)
+	c.disk.Items = make(map[string][]Item)
+	c.saveLocked()
+}
+
+// Login は別アカウントの可能性があるため、キャッシュをすべて破棄します。
+func (c *CachingBwClient) Login(email, password, serverURL string) error {
+	if err := c.BwClient.Login(email, password, serverURL); err != nil {
+		return err
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.loaded = true
+	c.disk = metadataCache{Folders: make(map[string]string), Items: make(map[string][]Item)}
+	c.folderID = ""
+	c.listings = make(map[string][]Item)
+	c.fromDisk = make(map[string]bool)
+	c.fullItems = make(map[string]*FullItem)
+	c.saveLocked()
+	return nil
+}
+
+// LoadOrCreateCacheKey は永続キャッシュの暗号化鍵をキーリングから読み、無ければ作成して保存します。
+// 鍵をキャッシュと同じディレクトリに置くと暗号化の意味がないため、キーリングが使えない場合は
+// エラーを返します。呼び出し側はその場合、永続キャッシュを使いません（実行中のメモ化のみ）。
+func LoadOrCreateCacheKey(keyring Keyring) ([]byte, error) {
+	stored, err := keyring.Get(MirrorKeyringService, CacheKeyringAccount)
+	if err != nil {
+		return nil, err
+	}
+	if key, err := hex.DecodeString(stored); err == nil && len(key) == 32 {
+		return key, nil
+	}
+
+	key := make([]byte, 32)
+	if _, err := rand.Read(key); err != nil {
+		return nil, fmt.Errorf("failed to generate cache key: %w", err)
+	}
+	if err := keyring.Set(MirrorKeyringService, CacheKeyringAccount, hex.EncodeToString(key)); err != nil {
+		return nil, err
+	}
+	return key, nil
+}
+
+// encryptCache は AES-256-GCM で暗号化します。出力は nonce と暗号文を連結したものです。
+func encryptCache(key, plain []byte) ([]byte, error) {
+	gcm, err := newGCM(key)
+	if err != nil {
+		return nil, err
+	}
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, fmt.Errorf("failed to generate nonce: %w", err)
+	}
+	return gcm.Seal(nonce, nonce, plain, nil), nil
+}
+
+// decryptCache は encryptCache の出力を復号します。
+func decryptCache(key, data []byte) ([]byte, error) {
+	gcm, err := newGCM(key)
+	if err != nil {
+		return nil, err
+	}
+	if len(data) < gcm.NonceSize() {
+		return nil, errors.New("cache file is too short")
+	}
+	nonce, sealed := data[:gcm.NonceSize()], data[gcm.NonceSize():]
+	return gcm.Open(nil, nonce, sealed, nil)
+}
+
+func newGCM(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, fmt.Errorf("invalid cache key: %w", err)
+	}
+	return cipher.NewGCM(block)
+}
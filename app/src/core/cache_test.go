@@ -0,0 +1,353 @@
+package core
+
+import (
+	"bytes"
+	"encoding/hex"
+	"errors"
+	"testing"
+	"time"
+
+	"bwsf/src/config"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var (
+	cacheTestKey  = bytes.Repeat([]byte{7}, 32)
+	cacheTestTime = time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+)
+
+// cacheBwClient は web / api の 2 アイテムを持つモックを返します。
+func cacheBwClient() *mockBwClient {
+	return &mockBwClient{
+		folderID: "folder-123",
+		items: []Item{
+			{ID: "item-1", Name: "web", RevisionDate: "2026-01-01T00:00:00Z"},
+			{ID: "item-2", Name: "api", RevisionDate: "2026-01-01T00:00:00Z"},
+		},
+		itemsByID: map[string]*FullItem{
+			"item-1": {ID: "item-1", Name: "web", Notes: `{".env":{"lines":["APP=web"]}}`},
+			"item-2": {ID: "item-2", Name: "api", Notes: `{".env":{"lines":["APP=api"]}}`},
+		},
+	}
+}
+
+// cacheOptions は now の時点で永続キャッシュを使う設定を返します。
+func cacheOptions(now time.Time) CacheOptions {
+	return CacheOptions{
+		Path:       "/cache/cache.bin",
+		Key:        cacheTestKey,
+		FolderName: "dotenvs",
+		TTL:        10 * time.Minute,
+		Now:        func() time.Time { return now },
+	}
+}
+
+// nextRunFS は前回の実行で書き出されたファイルを読める新しいファイルシステムを返します。
+func nextRunFS(prev *mockFileSystem) *mockFileSystem {
+	fs := &mockFileSystem{readContentMap: make(map[string][]byte), statInfoMap: make(map[string]FileInfo)}
+	for path, data := range prev.writtenFiles {
+		fs.readContentMap[path] = data
+		fs.statInfoMap[path] = &mockFileInfo{notExist: false}
+	}
+	return fs
+}
+
+// lookup はフォルダ ID を取得してから name のアイテムを取得します（pull と同じ呼び出し順）。
+func lookup(t *testing.T, bw BwClient, name string) *FullItem {
+	t.Helper()
+	folderID, err := bw.GetDotenvsFolderID()
+	require.NoError(t, err)
+	item, err := bw.GetItemByName(folderID, name)
+	require.NoError(t, err)
+	return item
+}
+
+// warmCache は 1 回目の実行を行い、そのファイルシステムを返します。
+func warmCache(t *testing.T) *mockFileSystem {
+	t.Helper()
+	fs := &mockFileSystem{}
+	lookup(t, NewCachingBwClient(cacheBwClient(), fs, cacheOptions(cacheTestTime)), "web")
+	require.Contains(t, fs.writtenFiles, "/cache/cache.bin")
+	return fs
+}
+
+// =============================================================================
+// CachingBwClient のテスト
+// =============================================================================
+
+// 正常系: 1 回の実行中は同じ呼び出しを繰り返さない
+func TestCachingBwClient_MemoizesWithinRun(t *testing.T) {
+	mock := cacheBwClient()
+	bw := NewCachingBwClient(mock, &mockFileSystem{}, CacheOptions{})
+
+	for i := 0; i < 2; i++ {
+		item := lookup(t, bw, "web")
+		require.NotNil(t, item)
+		assert.Equal(t, "item-1", item.ID)
+	}
+	require.NotNil(t, lookup(t, bw, "api"))
+
+	assert.Equal(t, []string{
+		"GetDotenvsFolderID",
+		"Sync",
+		"ListItemsInFolder(folder-123)",
+		"GetItemByID(item-1)",
+		"GetItemByID(item-2)",
+	}, mock.calls)
+}
+
+// 正常系: pull（GetPulledEnvFiles + PullEnvCore）でフォルダ ID とアイテムを 1 回ずつ取得する
+func TestCachingBwClient_Pull(t *testing.T) {
+	mock := cacheBwClient()
+	bw := NewCachingBwClient(mock, &mockFileSystem{}, CacheOptions{})
+	fs := &mockFileSystem{}
+	cfg := &config.Config{}
+
+	files, err := GetPulledEnvFiles("web", bw, cfg, nil, &mockLogger{})
+	require.NoError(t, err)
+	assert.Equal(t, []string{".env"}, files)
+	require.NoError(t, PullEnvCore("/web", "web", fs, bw, cfg, nil, noConfirm, &mockLogger{}))
+
+	assert.Equal(t, "APP=web", string(fs.writtenFiles["/web/.env"]))
+	assert.Equal(t, []string{
+		"GetDotenvsFolderID",
+		"Sync",
+		"ListItemsInFolder(folder-123)",
+		"GetItemByID(item-1)",
+	}, mock.calls)
+}
+
+// 正常系: TTL 内の永続キャッシュがあれば同期・一覧取得を省く
+func TestCachingBwClient_FreshDiskCache(t *testing.T) {
+	fs := nextRunFS(warmCache(t))
+	mock := cacheBwClient()
+	bw := NewCachingBwClient(mock, fs, cacheOptions(cacheTestTime.Add(5*time.Minute)))
+
+	item := lookup(t, bw, "api")
+
+	require.NotNil(t, item)
+	assert.Equal(t, "item-2", item.ID)
+	assert.Equal(t, []string{"GetItemByID(item-2)"}, mock.calls)
+}
+
+// 正常系: TTL を過ぎたキャッシュは同期してから使う
+func TestCachingBwClient_StaleDiskCache(t *testing.T) {
+	fs := nextRunFS(warmCache(t))
+	mock := cacheBwClient()
+	bw := NewCachingBwClient(mock, fs, cacheOptions(cacheTestTime.Add(11*time.Minute)))
+
+	require.NotNil(t, lookup(t, bw, "api"))
+
+	assert.Equal(t, []string{
+		"GetDotenvsFolderID",
+		"Sync",
+		"ListItemsInFolder(folder-123)",
+		"GetItemByID(item-2)",
+	}, mock.calls)
+}
+
+// 正常系: ForceSync（--sync）の場合は新しいキャッシュがあっても同期する
+func TestCachingBwClient_ForceSync(t *testing.T) {
+	fs := nextRunFS(warmCache(t))
+	mock := cacheBwClient()
+	opts := cacheOptions(cacheTestTime.Add(time.Minute))
+	opts.ForceSync = true
+	bw := NewCachingBwClient(mock, fs, opts)
+
+	require.NotNil(t, lookup(t, bw, "web"))
+	require.NoError(t, bw.Sync())
+
+	assert.Equal(t, []string{
+		"GetDotenvsFolderID",
+		"Sync",
+		"ListItemsInFolder(folder-123)",
+		"GetItemByID(item-1)",
+	}, mock.calls)
+}
+
+// 正常系: push・rotate・メタデータの変更は、TTL 内のキャッシュがあっても同期してから読み書きする
+func TestCachingBwClient_SyncsBeforeWrite(t *testing.T) {
+	writes := map[string]func(bw BwClient) error{
+		"push": func(bw BwClient) error {
+			fs := &mockFileSystem{
+				dirEntries:     []DirEntry{&mockDirEntry{name: ".env"}},
+				readContentMap: map[string][]byte{"/web/.env": []byte("APP=mine\n")},
+			}
+			return PushEnvCoreWithOptions("/web", "web", fs, bw, &config.Config{}, nil, &mockLogger{}, PushOptions{SkipLint: true})
+		},
+		"rotate": func(bw BwClient) error {
+			_, err := RotateKeyCore("web", "APP", RotateOptions{}, bw, &config.Config{}, nil, &mockLogger{})
+			return err
+		},
+		"meta": func(bw BwClient) error {
+			owner := "alice"
+			_, err := UpdateKeyMetaCore("web", ".env", "APP", KeyMetaUpdate{Owner: &owner}, bw, &config.Config{}, nil, &mockLogger{})
+			return err
+		},
+	}
+	for name, write := range writes {
+		t.Run(name, func(t *testing.T) {
+			fs := nextRunFS(warmCache(t))
+			mock := cacheBwClient()
+			bw := NewCachingBwClient(mock, fs, cacheOptions(cacheTestTime.Add(5*time.Minute)))
+
+			require.NoError(t, write(bw))
+
+			syncs, syncedBeforeRead := 0, false
+			for _, call := range mock.calls {
+				if call == "Sync" {
+					syncs++
+				}
+				if call == "GetItemByID(item-1)" {
+					syncedBeforeRead = syncs == 1
+				}
+			}
+			assert.Equal(t, 1, syncs)
+			assert.True(t, syncedBeforeRead, "the item is read after the sync: %v", mock.calls)
+			assert.NotEmpty(t, mock.updatedNotes["item-1"])
+		})
+	}
+}
+
+// 正常系: 永続キャッシュの一覧に無い名前は同期して確認し直す（重複作成を防ぐ）
+func TestCachingBwClient_DiskMissRefreshes(t *testing.T) {
+	fs := nextRunFS(warmCache(t))
+	mock := cacheBwClient()
+	mock.items = append(mock.items, Item{ID: "item-3", Name: "worker"})
+	mock.itemsByID["item-3"] = &FullItem{ID: "item-3", Name: "worker"}
+	bw := NewCachingBwClient(mock, fs, cacheOptions(cacheTestTime.Add(time.Minute)))
+
+	item := lookup(t, bw, "worker")
+
+	require.NotNil(t, item)
+	assert.Equal(t, "item-3", item.ID)
+	assert.Equal(t, []string{
+		"Sync",
+		"ListItemsInFolder(folder-123)",
+		"GetItemByID(item-3)",
+	}, mock.calls)
+}
+
+// 正常系: 永続キャッシュの一覧にあるアイテムが削除されていた場合も同期して確認し直す
+func TestCachingBwClient_DiskItemGone(t *testing.T) {
+	fs := nextRunFS(warmCache(t))
+	mock := cacheBwClient()
+	mock.items = mock.items[1:]
+	delete(mock.itemsByID, "item-1")
+	bw := NewCachingBwClient(mock, fs, cacheOptions(cacheTestTime.Add(time.Minute)))
+
+	assert.Nil(t, lookup(t, bw, "web"))
+	assert.Equal(t, []string{
+		"GetItemByID(item-1)",
+		"Sync",
+		"ListItemsInFolder(folder-123)",
+	}, mock.calls)
+}
+
+// 正常系: この実行中に取得した一覧に無い名前は取り直さない
+func TestCachingBwClient_MissWithinRun(t *testing.T) {
+	mock := cacheBwClient()
+	bw := NewCachingBwClient(mock, &mockFileSystem{}, CacheOptions{})
+
+	assert.Nil(t, lookup(t, bw, "worker"))
+	assert.Nil(t, lookup(t, bw, "worker"))
+
+	assert.Equal(t, []string{"GetDotenvsFolderID", "Sync", "ListItemsInFolder(folder-123)"}, mock.calls)
+}
+
+// 正常系: 作成・更新・削除でキャッシュを破棄する
+func TestCachingBwClient_MutationsInvalidate(t *testing.T) {
+	mock := cacheBwClient()
+	bw := NewCachingBwClient(mock, &mockFileSystem{}, CacheOptions{})
+	require.NotNil(t, lookup(t, bw, "web"))
+	mock.calls = nil
+
+	require.NoError(t, bw.UpdateNoteItem("item-1", "{}"))
+	require.NotNil(t, lookup(t, bw, "web"))
+	assert.Equal(t, []string{"UpdateNoteItem(item-1)", "GetItemByID(item-1)"}, mock.calls)
+	mock.calls = nil
+
+	require.NoError(t, bw.CreateNoteItem("folder-123", "worker", "{}"))
+	require.NotNil(t, lookup(t, bw, "web"))
+	assert.Equal(t, []string{"CreateNoteItem(folder-123,worker)", "ListItemsInFolder(folder-123)"}, mock.calls)
+	mock.calls = nil
+
+	require.NoError(t, bw.DeleteItem("item-2", false))
+	require.NotNil(t, lookup(t, bw, "api"))
+	assert.Equal(t, []string{"DeleteItem(item-2,false)", "ListItemsInFolder(folder-123)", "GetItemByID(item-2)"}, mock.calls)
+}
+
+// 正常系: 全フォルダからの検索はそのまま委譲する
+func TestCachingBwClient_NoFolderPassesThrough(t *testing.T) {
+	mock := cacheBwClient()
+	mock.itemByName = &FullItem{ID: "shared-1", Name: "shared"}
+	bw := NewCachingBwClient(mock, &mockFileSystem{}, CacheOptions{})
+
+	item, err := bw.GetItemByName("", "shared")
+
+	require.NoError(t, err)
+	assert.Equal(t, "shared-1", item.ID)
+	assert.Equal(t, []string{"GetItemByName(,shared)"}, mock.calls)
+}
+
+// 正常系: 永続キャッシュは暗号化され、鍵が違えば空のキャッシュとして扱う
+func TestCachingBwClient_Encrypted(t *testing.T) {
+	prev := warmCache(t)
+	data := prev.writtenFiles["/cache/cache.bin"]
+	assert.NotContains(t, string(data), "item-1")
+	assert.NotContains(t, string(data), "folder-123")
+
+	mock := cacheBwClient()
+	opts := cacheOptions(cacheTestTime.Add(time.Minute))
+	opts.Key = bytes.Repeat([]byte{8}, 32)
+	require.NotNil(t, lookup(t, NewCachingBwClient(mock, nextRunFS(prev), opts), "web"))
+
+	assert.Equal(t, []string{
+		"GetDotenvsFolderID",
+		"Sync",
+		"ListItemsInFolder(folder-123)",
+		"GetItemByID(item-1)",
+	}, mock.calls)
+}
+
+// 正常系: 鍵はキーリングに作成し、以降は同じ鍵を返す
+func TestLoadOrCreateCacheKey(t *testing.T) {
+	keyring := &mockKeyring{}
+
+	key, err := LoadOrCreateCacheKey(keyring)
+	require.NoError(t, err)
+	assert.Len(t, key, 32)
+	assert.Equal(t, hex.EncodeToString(key), keyring.secrets[MirrorKeyringService+"/"+CacheKeyringAccount])
+
+	again, err := LoadOrCreateCacheKey(keyring)
+	require.NoError(t, err)
+	assert.Equal(t, key, again)
+}
+
+// 異常系: キーリングに保存できなければ鍵を返さない（ファイルには書き出さない）
+func TestLoadOrCreateCacheKey_NoKeyring(t *testing.T) {
+	keyring := &mockKeyring{setErr: errors.New("OS keyring is not available")}
+
+	key, err := LoadOrCreateCacheKey(keyring)
+
+	assert.ErrorContains(t, err, "OS keyring is not available")
+	assert.Nil(t, key)
+}
+
+// 異常系: ロック中の同期エラーは返し、アンロック後に再試行できる
+func TestCachingBwClient_SyncLocked(t *testing.T) {
+	mock := cacheBwClient()
+	mock.syncErr = errors.New("Bitwarden CLI is locked")
+	bw := NewCachingBwClient(mock, &mockFileSystem{}, CacheOptions{})
+
+	_, err := bw.GetItemByName("folder-123", "web")
+	require.Error(t, err)
+
+	mock.syncErr = nil
+	item, err := bw.GetItemByName("folder-123", "web")
+	require.NoError(t, err)
+	assert.Equal(t, "item-1", item.ID)
+	assert.Equal(t, []string{"Sync", "Sync", "ListItemsInFolder(folder-123)", "GetItemByID(item-1)"}, mock.calls)
+}
@@ -0,0 +1,321 @@
+package core
+
+import (
+	"errors"
+	"fmt"
+	"net/mail"
+	"net/url"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"bwsf/src/config"
+)
+
+// check ルール名（LintIssue.Rule として使用し、.bwsf.json の lint.rules で重大度を変更できます）
+const (
+	CheckRuleMissingFile     = "missing-file"
+	CheckRuleMissingKey      = "missing-key"
+	CheckRuleMissingRequired = "missing-required"
+	CheckRuleExtraKey        = "extra-key"
+	CheckRuleTypeMismatch    = "type-mismatch"
+)
+
+// ErrCheckFailed は check でエラーが検出された場合に返されます。
+var ErrCheckFailed = errors.New("check failed")
+
+// exampleTypeHint / exampleRequiredHint は .example のコメントに書かれたヒントに一致します。
+var (
+	exampleTypeHint     = regexp.MustCompile(`(?i)\btype\s*:\s*([a-z]+)`)
+	exampleRequiredHint = regexp.MustCompile(`(?i)\brequired\b`)
+)
+
+// exampleKey は .example ファイルに定義された 1 キー分の契約です。
+type exampleKey struct {
+	Key      string
+	Line     int
+	Type     string // "url", "int", "number", "bool", "email", "port"（空ならチェックしない）
+	Required bool
+}
+
+// exampleSpec は 1 つの .example ファイルから読み取った契約です。
+type exampleSpec struct {
+	ExampleName string // 例: ".env.staging.example"
+	TargetName  string // 例: ".env.staging"
+	Keys        []exampleKey
+}
+
+// CheckEnvCore はディレクトリ内の .env*.example と、対応するローカルの .env* ファイルを比較します。
+func CheckEnvCore(dir string, fs FileSystem) ([]LintIssue, error) {
+	specs, err := loadExampleSpecs(fs, dir)
+	if err != nil {
+		return nil, err
+	}
+
+	envFiles, err := findEnvFilesFromFS(fs, dir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find .env files: %w", err)
+	}
+	local := make(MultiEnvData)
+	for _, envPath := range envFiles {
+		content, err := fs.ReadFile(envPath)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read %s: %w", envPath, err)
+		}
+		local[filepath.Base(envPath)] = *parseEnvContent(content)
+	}
+
+	projectCfg, err := LoadProjectConfig(fs, dir)
+	if err != nil {
+		return nil, err
+	}
+	return checkAgainstExamples(specs, local, projectCfg.Lint), nil
+}
+
+// CheckRemoteEnvCore はディレクトリ内の .env*.example と、Bitwarden に保存されたファイルを比較します。
+func CheckRemoteEnvCore(
+	dir, projectName string,
+	fs FileSystem,
+	bw BwClient,
+	cfg *config.Config,
+	promptPassword func() (string, error),
+	logger Logger,
+) ([]LintIssue, error) {
+	specs, err := loadExampleSpecs(fs, dir)
+	if err != nil {
+		return nil, err
+	}
+
+	_, item, err := fetchProjectItem(projectName, bw, cfg, promptPassword, logger)
+	if err != nil {
+		return nil, err
+	}
+	if item == nil {
+		return nil, fmt.Errorf("item '%s' not found in dotenvs folder", projectName)
+	}
+	remote, err := decodeStoredNotes(item.Notes)
+	if err != nil {
+		return nil, err
+	}
+
+	projectCfg, err := LoadProjectConfig(fs, dir)
+	if err != nil {
+		return nil, err
+	}
+	return checkAgainstExamples(specs, remote, projectCfg.Lint), nil
+}
+
+// ReportCheckIssues は check 結果をログに出力し、エラーがあれば ErrCheckFailed を返します。
+func ReportCheckIssues(issues []LintIssue, logger Logger) error {
+	errorCount := 0
+	for _, issue := range issues {
+		if issue.Severity == config.SeverityError {
+			errorCount++
+			logger.Error("[CHECK] ", issue.String())
+		} else {
+			logger.Warning("[CHECK] ", issue.String())
+		}
+	}
+	if errorCount > 0 {
+		return fmt.Errorf("%w: %d required key(s) missing or empty", ErrCheckFailed, errorCount)
+	}
+	return nil
+}
+
+// loadExampleSpecs はディレクトリ内の .env*.example ファイルを読み込みます。
+func loadExampleSpecs(fs FileSystem, dir string) ([]exampleSpec, error) {
+	entries, err := fs.ReadDir(dir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read directory: %w", err)
+	}
+
+	var names []string
+	for _, entry := range entries {
+		if entry.IsDir() {
+			continue
+		}
+		name := entry.Name()
+		if strings.HasPrefix(name, ".env") && isExampleFile(name) {
+			names = append(names, name)
+		}
+	}
+	sortFileNames(names)
+
+	var specs []exampleSpec
+	for _, name := range names {
+		content, err := fs.ReadFile(filepath.Join(dir, name))
+		if err != nil {
+			return nil, fmt.Errorf("failed to read %s: %w", name, err)
+		}
+		specs = append(specs, parseExampleSpec(name, *parseEnvContent(content)))
+	}
+	return specs, nil
+}
+
+// parseExampleSpec は .example ファイルのキーとコメントのヒントを読み取ります。
+// ヒントはキーの直前のコメント行、または値の後ろのインラインコメントに書きます。
+//
+//	# required
+//	# type: url
+//	DATABASE_URL=
+//	PORT=3000 # type: port
+func parseExampleSpec(exampleName string, data EnvData) exampleSpec {
+	spec := exampleSpec{
+		ExampleName: exampleName,
+		TargetName:  strings.Replace(exampleName, ".example", "", 1),
+	}
+
+	var pending []string
+	for i, line := range data.Lines {
+		trimmed := strings.TrimSpace(line)
+		if trimmed == "" {
+			pending = nil
+			continue
+		}
+		if strings.HasPrefix(trimmed, "#") {
+			pending = append(pending, trimmed)
+			continue
+		}
+
+		entry, ok := parseEnvLine(line)
+		if !ok {
+			pending = nil
+			continue
+		}
+
+		hints := pending
+		if entry.Quote == 0 {
+			if idx := strings.Index(entry.RawValue, "#"); idx >= 0 && (idx == 0 || entry.RawValue[idx-1] == ' ') {
+				hints = append(hints, entry.RawValue[idx:])
+			}
+		}
+
+		key := exampleKey{Key: entry.Key, Line: i + 1}
+		for _, hint := range hints {
+			if m := exampleTypeHint.FindStringSubmatch(hint); m != nil {
+				key.Type = strings.ToLower(m[1])
+			}
+			if exampleRequiredHint.MatchString(hint) {
+				key.Required = true
+			}
+		}
+		spec.Keys = append(spec.Keys, key)
+		pending = nil
+	}
+	return spec
+}
+
+// checkAgainstExamples は data の各ファイルを対応する .example の契約と比較します。
+// .example が無いファイルはチェックしません。
+func checkAgainstExamples(specs []exampleSpec, data MultiEnvData, cfg config.LintConfig) []LintIssue {
+	var issues []LintIssue
+	for _, spec := range specs {
+		envData, ok := data[spec.TargetName]
+		issues = append(issues, checkEnvData(spec, envData, ok, cfg)...)
+	}
+	return issues
+}
+
+// checkEnvData は 1 ファイル分を .example の契約と比較します。
+func checkEnvData(spec exampleSpec, data EnvData, exists bool, cfg config.LintConfig) []LintIssue {
+	var issues []LintIssue
+	add := func(line int, rule, format string, args ...interface{}) {
+		severity := lintSeverity(cfg, rule)
+		if severity == config.SeverityOff {
+			return
+		}
+		issues = append(issues, LintIssue{
+			File:     spec.TargetName,
+			Line:     line,
+			Rule:     rule,
+			Severity: severity,
+			Message:  fmt.Sprintf(format, args...),
+		})
+	}
+
+	if !exists {
+		add(0, CheckRuleMissingFile, "file not found (expected by %s)", spec.ExampleName)
+	}
+
+	type actualValue struct {
+		value string
+		line  int
+	}
+	actual := make(map[string]actualValue)
+	var order []string
+	for i, line := range data.Lines {
+		entry, ok := parseEnvLine(line)
+		if !ok {
+			continue
+		}
+		if _, dup := actual[entry.Key]; !dup {
+			order = append(order, entry.Key)
+		}
+		actual[entry.Key] = actualValue{value: entry.Value, line: i + 1}
+	}
+
+	expected := make(map[string]bool)
+	for _, key := range spec.Keys {
+		expected[key.Key] = true
+
+		got, ok := actual[key.Key]
+		switch {
+		case !ok && key.Required:
+			add(0, CheckRuleMissingRequired, "required key %s is missing (declared in %s:%d)", key.Key, spec.ExampleName, key.Line)
+		case !ok:
+			add(0, CheckRuleMissingKey, "key %s is missing (declared in %s:%d)", key.Key, spec.ExampleName, key.Line)
+		case got.value == "" && key.Required:
+			add(got.line, CheckRuleMissingRequired, "required key %s has an empty value", key.Key)
+		case got.value != "" && key.Type != "":
+			if err := validateTypeHint(key.Type, got.value); err != nil {
+				add(got.line, CheckRuleTypeMismatch, "value of %s is not a valid %s: %v", key.Key, key.Type, err)
+			}
+		}
+	}
+
+	for _, key := range order {
+		if !expected[key] {
+			add(actual[key].line, CheckRuleExtraKey, "key %s is not declared in %s", key, spec.ExampleName)
+		}
+	}
+	return issues
+}
+
+// validateTypeHint は値が型ヒントに合致するかを検証します。未知の型ヒントは無視します。
+func validateTypeHint(typeHint, value string) error {
+	switch typeHint {
+	case "url":
+		u, err := url.Parse(value)
+		if err != nil {
+			return err
+		}
+		if u.Scheme == "" || (u.Host == "" && u.Opaque == "") {
+			return errors.New("missing scheme or host")
+		}
+	case "int", "integer":
+		if _, err := strconv.ParseInt(value, 10, 64); err != nil {
+			return errors.New("not an integer")
+		}
+	case "number", "float":
+		if _, err := strconv.ParseFloat(value, 64); err != nil {
+			return errors.New("not a number")
+		}
+	case "bool", "boolean":
+		switch strings.ToLower(value) {
+		case "true", "false", "1", "0", "yes", "no", "on", "off":
+		default:
+			return errors.New("expected true/false")
+		}
+	case "email":
+		if _, err := mail.ParseAddress(value); err != nil {
+			return errors.New("not an email address")
+		}
+	case "port":
+		port, err := strconv.Atoi(value)
+		if err != nil || port < 1 || port > 65535 {
+			return errors.New("expected 1-65535")
+		}
+	}
+	return nil
+}
@@ -0,0 +1,222 @@
+package core
+
+import (
+	"testing"
+
+	"bwsf/src/config"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// checkIssueKeys は issues の "file:rule" の一覧を返します。
+func checkIssueKeys(issues []LintIssue) []string {
+	var rules []string
+	for _, issue := range issues {
+		rules = append(rules, issue.File+":"+issue.Rule)
+	}
+	return rules
+}
+
+// =============================================================================
+// parseExampleSpec のテスト
+// =============================================================================
+
+// 正常系: 直前のコメントとインラインコメントからヒントを読み取る
+func TestParseExampleSpec_Hints(t *testing.T) {
+	data := EnvData{Lines: []string{
+		"# Database",
+		"# required",
+		"# type: url",
+		"DATABASE_URL=",
+		"",
+		"# Shown on the dashboard",
+		"APP_NAME=demo",
+		"PORT=3000 # type: port, required",
+		`QUOTED="a # type: url"`,
+	}}
+
+	spec := parseExampleSpec(".env.staging.example", data)
+
+	assert.Equal(t, ".env.staging", spec.TargetName)
+	require.Len(t, spec.Keys, 4)
+	assert.Equal(t, exampleKey{Key: "DATABASE_URL", Line: 4, Type: "url", Required: true}, spec.Keys[0])
+	assert.Equal(t, exampleKey{Key: "APP_NAME", Line: 7}, spec.Keys[1])
+	assert.Equal(t, exampleKey{Key: "PORT", Line: 8, Type: "port", Required: true}, spec.Keys[2])
+	assert.Equal(t, exampleKey{Key: "QUOTED", Line: 9}, spec.Keys[3])
+}
+
+// =============================================================================
+// checkEnvData のテスト
+// =============================================================================
+
+// 正常系: 欠落・余分・型不一致・必須の空値を検出する
+func TestCheckEnvData_Issues(t *testing.T) {
+	spec := parseExampleSpec(".env.example", EnvData{Lines: []string{
+		"# required",
+		"DATABASE_URL=",
+		"# required",
+		"API_KEY=",
+		"PORT= # type: port",
+		"DEBUG= # type: bool",
+		"OPTIONAL=",
+	}})
+	data := EnvData{Lines: []string{
+		"DATABASE_URL=postgres://localhost/app",
+		"API_KEY=",
+		"PORT=99999",
+		"DEBUG=true",
+		"LEGACY=1",
+	}}
+
+	issues := checkEnvData(spec, data, true, config.LintConfig{})
+
+	assert.Equal(t, []string{
+		".env:" + CheckRuleMissingRequired,
+		".env:" + CheckRuleTypeMismatch,
+		".env:" + CheckRuleMissingKey,
+		".env:" + CheckRuleExtraKey,
+	}, checkIssueKeys(issues))
+	assert.Equal(t, 2, issues[0].Line)
+	assert.Equal(t, config.SeverityError, issues[0].Severity)
+	assert.Contains(t, issues[2].Message, "OPTIONAL")
+	assert.Equal(t, 5, issues[3].Line)
+}
+
+// 正常系: 必須キーが欠落していればエラー
+func TestCheckEnvData_MissingRequired(t *testing.T) {
+	spec := parseExampleSpec(".env.example", EnvData{Lines: []string{"SECRET= # required"}})
+
+	issues := checkEnvData(spec, EnvData{}, false, config.LintConfig{})
+
+	assert.Equal(t, []string{".env:" + CheckRuleMissingFile, ".env:" + CheckRuleMissingRequired}, checkIssueKeys(issues))
+	assert.True(t, HasLintErrors(issues))
+}
+
+// 正常系: lint.rules で重大度を変更できる
+func TestCheckEnvData_SeverityOverride(t *testing.T) {
+	spec := parseExampleSpec(".env.example", EnvData{Lines: []string{"A="}})
+	cfg := config.LintConfig{Rules: map[string]string{CheckRuleExtraKey: config.SeverityOff}}
+
+	issues := checkEnvData(spec, EnvData{Lines: []string{"A=1", "B=2"}}, true, cfg)
+
+	assert.Empty(t, issues)
+}
+
+// =============================================================================
+// validateTypeHint のテスト
+// =============================================================================
+
+// 正常系/異常系: 型ヒントごとの検証
+func TestValidateTypeHint(t *testing.T) {
+	tests := []struct {
+		typeHint string
+		value    string
+		valid    bool
+	}{
+		{"url", "https://example.com", true},
+		{"url", "example.com", false},
+		{"int", "42", true},
+		{"int", "4.2", false},
+		{"number", "4.2", true},
+		{"bool", "yes", true},
+		{"bool", "maybe", false},
+		{"email", "ops@example.com", true},
+		{"email", "ops", false},
+		{"port", "8080", true},
+		{"port", "0", false},
+		{"unknown", "anything", true},
+	}
+
+	for _, tt := range tests {
+		err := validateTypeHint(tt.typeHint, tt.value)
+		assert.Equal(t, tt.valid, err == nil, "%s=%s", tt.typeHint, tt.value)
+	}
+}
+
+// =============================================================================
+// CheckEnvCore / CheckRemoteEnvCore のテスト
+// =============================================================================
+
+// 正常系: ローカルファイルを .example と比較する（.example の無いファイルは対象外）
+func TestCheckEnvCore_Local(t *testing.T) {
+	fs := &mockFileSystem{
+		dirEntries: []DirEntry{
+			&mockDirEntry{name: ".env"},
+			&mockDirEntry{name: ".env.example"},
+			&mockDirEntry{name: ".env.local"},
+			&mockDirEntry{name: ".env.staging.example"},
+		},
+		readContentMap: map[string][]byte{
+			"/app/.env":                 []byte("A=1\n"),
+			"/app/.env.example":         []byte("A=\n"),
+			"/app/.env.local":           []byte("X=1\n"),
+			"/app/.env.staging.example": []byte("# required\nB=\n"),
+		},
+	}
+
+	issues, err := CheckEnvCore("/app", fs)
+
+	require.NoError(t, err)
+	assert.Equal(t, []string{
+		".env.staging:" + CheckRuleMissingFile,
+		".env.staging:" + CheckRuleMissingRequired,
+	}, checkIssueKeys(issues))
+}
+
+// 正常系: Bitwarden に保存されたファイルを .example と比較する
+func TestCheckRemoteEnvCore(t *testing.T) {
+	fs := &mockFileSystem{
+		dirEntries:     []DirEntry{&mockDirEntry{name: ".env.example"}},
+		readContentMap: map[string][]byte{"/app/.env.example": []byte("A=\nB=\n")},
+	}
+	bw := &mockBwClient{
+		folderID:   "folder-123",
+		itemByName: &FullItem{ID: "item-1", Name: "app", Notes: `{".env":{"lines":["A=1"]}}`},
+	}
+
+	issues, err := CheckRemoteEnvCore("/app", "app", fs, bw, &config.Config{}, nil, &mockLogger{})
+
+	require.NoError(t, err)
+	assert.Equal(t, []string{".env:" + CheckRuleMissingKey}, checkIssueKeys(issues))
+}
+
+// 正常系: pull 後のチェックは警告のみで pull を失敗させない
+func TestPullEnvCore_CheckExamples(t *testing.T) {
+	fs := &mockFileSystem{
+		dirEntries:     []DirEntry{&mockDirEntry{name: ".env.example"}},
+		readContentMap: map[string][]byte{"/app/.env.example": []byte("# required\nSECRET=\n")},
+	}
+	bw := &mockBwClient{
+		folderID:   "folder-123",
+		itemByName: &FullItem{ID: "item-1", Name: "app", Notes: `{".env":{"lines":["A=1"]}}`},
+	}
+	logger := &mockLogger{}
+
+	err := PullEnvCoreWithOptions("/app", "app", fs, bw, &config.Config{}, nil, noConfirm, logger, PullOptions{CheckExamples: true})
+
+	require.NoError(t, err)
+	assert.Contains(t, fs.writtenFiles, "/app/.env")
+	require.Len(t, logger.errors, 1)
+	assert.Contains(t, logger.errors[0], "required key SECRET is missing")
+	assert.Contains(t, logger.warnings, "[CHECK] check failed: 1 required key(s) missing or empty (run `bwsf check` for details)")
+}
+
+// =============================================================================
+// ReportCheckIssues のテスト
+// =============================================================================
+
+// 異常系: エラーがあれば ErrCheckFailed を返す
+func TestReportCheckIssues_Error(t *testing.T) {
+	logger := &mockLogger{}
+	issues := []LintIssue{
+		{File: ".env", Rule: CheckRuleExtraKey, Severity: config.SeverityWarning, Message: "w"},
+		{File: ".env", Rule: CheckRuleMissingRequired, Severity: config.SeverityError, Message: "e"},
+	}
+
+	err := ReportCheckIssues(issues, logger)
+
+	assert.ErrorIs(t, err, ErrCheckFailed)
+	assert.Len(t, logger.warnings, 1)
+	assert.Len(t, logger.errors, 1)
+}
@@ -0,0 +1,373 @@
+package core
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"bwsf/src/config"
+
+	"gopkg.in/yaml.v3"
+)
+
+// ComposeFileNames は docker compose が既定で探す compose ファイルの名前です（優先順）。
+var ComposeFileNames = []string{"compose.yaml", "compose.yml", "docker-compose.yaml", "docker-compose.yml"}
+
+// ComposeEnvFile は compose ファイルの env_file の 1 エントリです。
+type ComposeEnvFile struct {
+	Path     string // compose ファイルに書かれたパス
+	Required bool   // false の場合、ファイルがなくてもエラーにしない（required: false）
+}
+
+// ComposeService は env_file を持つサービスです。
+type ComposeService struct {
+	Name     string
+	EnvFiles []ComposeEnvFile
+	// Environment は environment で定義済みのキーです。env_file より優先されるため render では出力しません。
+	Environment map[string]bool
+}
+
+// ComposeOptions は PrepareComposeCore と RenderComposeCore の設定です。
+type ComposeOptions struct {
+	// ComposeFile は compose ファイルの絶対パスです。
+	ComposeFile string
+	// Ephemeral はファイルの提供方法です（PrepareComposeCore のみ）。Pull は Interpolate と Audit のみ使います。
+	Ephemeral EphemeralOptions
+}
+
+// ComposeRun は PrepareComposeCore で用意した一時ファイルと docker compose の引数です。
+type ComposeRun struct {
+	// Files は提供中の一時ファイルです。docker compose の終了後に Cleanup で削除します。
+	Files *EphemeralFiles
+	// Args は docker compose のサブコマンドの前に付ける引数です（-f <compose> -f <override> [--env-file <.env>]）。
+	Args []string
+	// Services は保管庫のファイルを渡すサービスです。
+	Services []string
+}
+
+// composeDocument は compose ファイルのうち bwsf が読む部分です。
+type composeDocument struct {
+	Services map[string]struct {
+		EnvFile     composeEnvFiles `yaml:"env_file"`
+		Environment yaml.Node       `yaml:"environment"`
+	} `yaml:"services"`
+}
+
+// composeEnvFiles は env_file の短い書式（文字列・文字列の一覧）と長い書式（path / required）を読みます。
+type composeEnvFiles []ComposeEnvFile
+
+func (f *composeEnvFiles) UnmarshalYAML(node *yaml.Node) error {
+	switch node.Kind {
+	case yaml.ScalarNode:
+		*f = composeEnvFiles{{Path: node.Value, Required: true}}
+		return nil
+	case yaml.SequenceNode:
+		for _, item := range node.Content {
+			switch item.Kind {
+			case yaml.ScalarNode:
+				*f = append(*f, ComposeEnvFile{Path: item.Value, Required: true})
+			case yaml.MappingNode:
+				var long struct {
+					Path     string `yaml:"path"`
+					Required *bool  `yaml:"required"`
+				}
+				if err := item.Decode(&long); err != nil {
+					return err
+				}
+				if long.Path == "" {
+					return fmt.Errorf("line %d: env_file entry without path", item.Line)
+				}
+				*f = append(*f, ComposeEnvFile{Path: long.Path, Required: long.Required == nil || *long.Required})
+			default:
+				return fmt.Errorf("line %d: unsupported env_file entry", item.Line)
+			}
+		}
+		return nil
+	default:
+		return fmt.Errorf("line %d: env_file must be a string or a list", node.Line)
+	}
+}
+
+// FindComposeFile は dir で docker compose が既定で使う compose ファイルのパスを返します。
+func FindComposeFile(fs FileSystem, dir string) (string, error) {
+	for _, name := range ComposeFileNames {
+		path := filepath.Join(dir, name)
+		info, err := fs.Stat(path)
+		if err != nil {
+			return "", fmt.Errorf("failed to stat %s: %w", path, err)
+		}
+		if !info.IsNotExist() {
+			return path, nil
+		}
+	}
+	return "", fmt.Errorf("no compose file found in %s (looked for %s)", dir, strings.Join(ComposeFileNames, ", "))
+}
+
+// ParseComposeServices は compose ファイルから env_file を持つサービスを名前順に返します。
+func ParseComposeServices(data []byte) ([]ComposeService, error) {
+	var doc composeDocument
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return nil, fmt.Errorf("failed to parse compose file: %w", err)
+	}
+
+	var services []ComposeService
+	for name, spec := range doc.Services {
+		if len(spec.EnvFile) == 0 {
+			continue
+		}
+		service := ComposeService{Name: name, EnvFiles: spec.EnvFile, Environment: make(map[string]bool)}
+		switch spec.Environment.Kind {
+		case yaml.MappingNode:
+			for i := 0; i+1 < len(spec.Environment.Content); i += 2 {
+				service.Environment[spec.Environment.Content[i].Value] = true
+			}
+		case yaml.SequenceNode:
+			for _, item := range spec.Environment.Content {
+				key, _, _ := strings.Cut(item.Value, "=")
+				service.Environment[key] = true
+			}
+		}
+		services = append(services, service)
+	}
+	sort.Slice(services, func(i, j int) bool { return services[i].Name < services[j].Name })
+	return services, nil
+}
+
+// composePlan は compose ファイルの env_file と保管庫のファイルの対応です。
+type composePlan struct {
+	dir      string           // compose ファイルのディレクトリ（相対パスの基準）
+	services []ComposeService // 保管庫のファイルを 1 つ以上参照するサービス
+	stored   map[string]bool  // 保管庫にあるファイル名
+	refs     map[string]int   // 参照される保管庫のファイル名 -> 参照数
+}
+
+// storedName は env_file のエントリが参照する保管庫のファイル名を返します（ファイル名で照合）。
+func (p *composePlan) storedName(entry ComposeEnvFile) (string, bool) {
+	name := filepath.Base(entry.Path)
+	return name, p.stored[name]
+}
+
+// absPath は env_file のパスを compose ファイルのディレクトリを基準にした絶対パスにします。
+func (p *composePlan) absPath(path string) string {
+	if filepath.IsAbs(path) {
+		return path
+	}
+	return filepath.Join(p.dir, path)
+}
+
+// names は参照される保管庫のファイル名を順に返します。
+func (p *composePlan) names() []string {
+	var names []string
+	for name := range p.refs {
+		names = append(names, name)
+	}
+	sortFileNames(names)
+	return names
+}
+
+// planCompose は compose ファイルを読み、env_file のうち保管庫のファイルと一致するものを調べます。
+func planCompose(
+	projectName string,
+	fs FileSystem,
+	bw BwClient,
+	cfg *config.Config,
+	promptPassword func() (string, error),
+	logger Logger,
+	composeFile string,
+) (*composePlan, error) {
+	data, err := fs.ReadFile(composeFile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", composeFile, err)
+	}
+	services, err := ParseComposeServices(data)
+	if err != nil {
+		return nil, err
+	}
+
+	_, item, err := fetchProjectItem(projectName, bw, cfg, promptPassword, logger)
+	if err != nil {
+		return nil, err
+	}
+	if item == nil {
+		return nil, fmt.Errorf("item '%s' not found in dotenvs folder", projectName)
+	}
+	multiData, err := decodeStoredNotes(item.Notes)
+	if err != nil {
+		return nil, err
+	}
+
+	plan := &composePlan{dir: filepath.Dir(composeFile), stored: make(map[string]bool), refs: make(map[string]int)}
+	var storedNames []string
+	for name := range multiData {
+		plan.stored[name] = true
+		storedNames = append(storedNames, name)
+	}
+	sortFileNames(storedNames)
+
+	for _, service := range services {
+		mapped := false
+		for _, entry := range service.EnvFiles {
+			if name, ok := plan.storedName(entry); ok {
+				plan.refs[name]++
+				mapped = true
+			}
+		}
+		if mapped {
+			plan.services = append(plan.services, service)
+		}
+	}
+	if len(plan.services) == 0 {
+		return nil, fmt.Errorf("no env_file in %s matches a stored file of %s (stored: %s)",
+			filepath.Base(composeFile), projectName, strings.Join(storedNames, ", "))
+	}
+	return plan, nil
+}
+
+// PrepareComposeCore は compose ファイルの env_file が参照する保管庫のファイルを一時ディレクトリに置き、
+// env_file をそのパスに置き換える override ファイルを作ります。保管庫に .env があれば、
+// compose ファイル内の変数展開に使うよう --env-file にも渡します。
+// 呼び出し側は docker compose の終了後に Files.Cleanup を呼びます。
+func PrepareComposeCore(
+	projectDir, projectName string,
+	fs FileSystem,
+	bw BwClient,
+	cfg *config.Config,
+	promptPassword func() (string, error),
+	logger Logger,
+	opts ComposeOptions,
+) (*ComposeRun, error) {
+	plan, err := planCompose(projectName, fs, bw, cfg, promptPassword, logger, opts.ComposeFile)
+	if err != nil {
+		return nil, err
+	}
+	interpolationFile := plan.stored[".env"]
+	if interpolationFile {
+		plan.refs[".env"]++
+	}
+
+	// 名前付きパイプは最も多く参照されるファイルに合わせる（余った読み込みは Cleanup で閉じる）
+	ephemeralOpts := opts.Ephemeral
+	ephemeralOpts.Pull = PullOptions{Interpolate: opts.Ephemeral.Pull.Interpolate, Audit: opts.Ephemeral.Pull.Audit, Files: plan.names()}
+	ephemeralOpts.Reads = 0
+	for _, count := range plan.refs {
+		ephemeralOpts.Reads = max(ephemeralOpts.Reads, count)
+	}
+	files, err := PullEphemeralCore(projectDir, projectName, fs, bw, cfg, promptPassword, logger, ephemeralOpts)
+	if err != nil {
+		return nil, err
+	}
+
+	var b strings.Builder
+	b.WriteString("# Generated by bwsf compose; removed when docker compose exits\nservices:\n")
+	run := &ComposeRun{Files: files}
+	for _, service := range plan.services {
+		run.Services = append(run.Services, service.Name)
+		fmt.Fprintf(&b, "  %s:\n    env_file: !override\n", yamlQuote(service.Name))
+		for _, entry := range service.EnvFiles {
+			path, required := plan.absPath(entry.Path), entry.Required
+			if name, ok := plan.storedName(entry); ok {
+				path, required = filepath.Join(files.Dir, name), true
+			}
+			fmt.Fprintf(&b, "      - path: %s\n        required: %t\n", yamlQuote(path), required)
+		}
+	}
+
+	overridePath, err := files.addFile("compose.override.yaml", []byte(b.String()))
+	if err != nil {
+		files.Cleanup()
+		return nil, err
+	}
+	run.Args = []string{"-f", opts.ComposeFile, "-f", overridePath}
+	if interpolationFile {
+		run.Args = append(run.Args, "--env-file", filepath.Join(files.Dir, ".env"))
+	}
+	return run, nil
+}
+
+// RenderComposeCore は env_file が参照する保管庫のファイルの値を environment に展開した override ファイルの内容を返します。
+// 保管庫のファイルは env_file から外し、environment で定義済みのキーは出力しません。CI など一時ファイルを置けない環境向けです。
+func RenderComposeCore(
+	projectDir, projectName string,
+	fs FileSystem,
+	bw BwClient,
+	cfg *config.Config,
+	promptPassword func() (string, error),
+	logger Logger,
+	opts ComposeOptions,
+) (string, error) {
+	plan, err := planCompose(projectName, fs, bw, cfg, promptPassword, logger, opts.ComposeFile)
+	if err != nil {
+		return "", err
+	}
+
+	memFS := &memoryOutputFS{FileSystem: fs, files: make(map[string][]byte)}
+	pullOpts := PullOptions{Interpolate: opts.Ephemeral.Pull.Interpolate, Files: plan.names(), InMemory: true}
+	overwrite := func(path string) (bool, error) { return true, nil }
+	if err := PullEnvCoreWithOptions(projectDir, projectName, memFS, bw, cfg, promptPassword, overwrite, logger, pullOpts); err != nil {
+		return "", err
+	}
+	entries := make(map[string][]envEntry)
+	for path, content := range memFS.files {
+		for _, line := range parseEnvContent(content).Lines {
+			if entry, ok := parseEnvLine(line); ok {
+				entries[filepath.Base(path)] = append(entries[filepath.Base(path)], entry)
+			}
+		}
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "# Generated by bwsf compose render for %s. It contains secret values; do not commit it.\nservices:\n", projectName)
+	for _, service := range plan.services {
+		fmt.Fprintf(&b, "  %s:\n", yamlQuote(service.Name))
+
+		// env_file は後のファイルが優先されるため、同じ順で重ねる
+		var keys []string
+		env := make(map[string]string)
+		var rest []ComposeEnvFile
+		for _, entry := range service.EnvFiles {
+			name, ok := plan.storedName(entry)
+			if !ok {
+				rest = append(rest, entry)
+				continue
+			}
+			for _, entry := range entries[name] {
+				if service.Environment[entry.Key] {
+					continue
+				}
+				if _, seen := env[entry.Key]; !seen {
+					keys = append(keys, entry.Key)
+				}
+				env[entry.Key] = entry.Value
+			}
+		}
+
+		if len(rest) == 0 {
+			b.WriteString("    env_file: !reset []\n")
+		} else {
+			b.WriteString("    env_file: !override\n")
+			for _, entry := range rest {
+				fmt.Fprintf(&b, "      - path: %s\n        required: %t\n", yamlQuote(plan.absPath(entry.Path)), entry.Required)
+			}
+		}
+		if len(keys) > 0 {
+			b.WriteString("    environment:\n")
+			for _, key := range keys {
+				// compose は environment の $ を展開するため $$ でそのままの値にする
+				fmt.Fprintf(&b, "      %s: %s\n", yamlQuote(key), yamlQuote(strings.ReplaceAll(env[key], "$", "$$")))
+			}
+		}
+	}
+	return b.String(), nil
+}
+
+// yamlQuote は s を YAML のダブルクォート文字列にします（JSON の文字列は YAML としても有効）。
+func yamlQuote(s string) string {
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	enc.SetEscapeHTML(false)
+	_ = enc.Encode(s)
+	return strings.TrimSuffix(buf.String(), "\n")
+}
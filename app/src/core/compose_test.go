@@ -0,0 +1,188 @@
+package core
+
+import (
+	"path/filepath"
+	"testing"
+
+	"bwsf/src/config"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// =============================================================================
+// ParseComposeServices のテスト
+// =============================================================================
+
+// 正常系: env_file の短い書式・一覧・長い書式と environment のキーを読み、env_file のないサービスは除く
+func TestParseComposeServices(t *testing.T) {
+	data := []byte(`
+services:
+  web:
+    image: nginx
+    env_file: .env
+  api:
+    env_file:
+      - ./.env
+      - path: ./config/.env.api
+        required: false
+    environment:
+      PORT: "8080"
+  worker:
+    env_file: [.env.worker]
+    environment:
+      - QUEUE=jobs
+      - DEBUG
+  db:
+    image: postgres
+`)
+
+	services, err := ParseComposeServices(data)
+
+	require.NoError(t, err)
+	require.Len(t, services, 3)
+	assert.Equal(t, "api", services[0].Name)
+	assert.Equal(t, []ComposeEnvFile{{Path: "./.env", Required: true}, {Path: "./config/.env.api", Required: false}}, services[0].EnvFiles)
+	assert.Equal(t, map[string]bool{"PORT": true}, services[0].Environment)
+	assert.Equal(t, "web", services[1].Name)
+	assert.Equal(t, []ComposeEnvFile{{Path: ".env", Required: true}}, services[1].EnvFiles)
+	assert.Equal(t, map[string]bool{"QUEUE": true, "DEBUG": true}, services[2].Environment)
+}
+
+// 異常系: env_file の書式が不正
+func TestParseComposeServices_Invalid(t *testing.T) {
+	_, err := ParseComposeServices([]byte("services:\n  web:\n    env_file:\n      a: b\n"))
+	assert.ErrorContains(t, err, "env_file must be a string or a list")
+
+	_, err = ParseComposeServices([]byte("services: [\n"))
+	assert.ErrorContains(t, err, "failed to parse compose file")
+}
+
+// =============================================================================
+// PrepareComposeCore / RenderComposeCore のテスト
+// =============================================================================
+
+const composeFixture = `services:
+  api:
+    env_file:
+      - .env
+      - path: ./config/.env.api
+        required: false
+      - ./local.env
+    environment:
+      PORT: "8080"
+  web:
+    env_file: .env
+  db:
+    env_file: ./db.env
+`
+
+// composeFS は /work/web/compose.yaml を置いたモックを返します。
+func composeFS() *mockFileSystem {
+	return &mockFileSystem{
+		readContentMap: map[string][]byte{"/work/web/compose.yaml": []byte(composeFixture)},
+		statInfoMap:    map[string]FileInfo{"/work/web/compose.yaml": &mockFileInfo{}},
+	}
+}
+
+// composeFiles は compose のテストで保存しておく .env と .env.api です。
+var composeFiles = MultiEnvData{
+	".env":      {Lines: []string{"PORT=3000", "TOKEN=a$b"}},
+	".env.api":  {Lines: []string{`API_KEY="k#1"`}},
+	".env.test": {Lines: []string{"T=1"}},
+}
+
+// 正常系: compose ファイルを探す
+func TestFindComposeFile(t *testing.T) {
+	path, err := FindComposeFile(composeFS(), "/work/web")
+	require.NoError(t, err)
+	assert.Equal(t, "/work/web/compose.yaml", path)
+
+	_, err = FindComposeFile(&mockFileSystem{}, "/work/web")
+	assert.ErrorContains(t, err, "no compose file found in /work/web")
+}
+
+// 正常系: 参照されるファイルのみ一時ディレクトリに置き、env_file を置き換える override を作る
+func TestPrepareComposeCore(t *testing.T) {
+	fs := composeFS()
+
+	run, err := PrepareComposeCore("/work/web", "web", fs, newTestBwClient(t, composeFiles), &config.Config{}, nil, &mockLogger{}, ComposeOptions{
+		ComposeFile: "/work/web/compose.yaml",
+		Ephemeral:   EphemeralOptions{BaseDir: "/dev/shm"},
+	})
+
+	require.NoError(t, err)
+	dir := run.Files.Dir
+	override := filepath.Join(dir, "compose.override.yaml")
+	assert.Equal(t, []string{"-f", "/work/web/compose.yaml", "-f", override, "--env-file", filepath.Join(dir, ".env")}, run.Args)
+	assert.Equal(t, []string{"api", "web"}, run.Services)
+	assert.Equal(t, []string{filepath.Join(dir, ".env"), filepath.Join(dir, ".env.api")}, run.Files.Paths)
+	assert.Equal(t, `# Generated by bwsf compose; removed when docker compose exits
+services:
+  "api":
+    env_file: !override
+      - path: "`+dir+`/.env"
+        required: true
+      - path: "`+dir+`/.env.api"
+        required: true
+      - path: "/work/web/local.env"
+        required: true
+  "web":
+    env_file: !override
+      - path: "`+dir+`/.env"
+        required: true
+`, string(fs.writtenFiles[override]))
+
+	require.NoError(t, run.Files.Cleanup())
+	assert.Equal(t, append(run.Files.Paths, override, dir), fs.removedPaths)
+}
+
+// 正常系: 名前付きパイプは最も多く参照される回数だけ読めるようにする
+func TestPrepareComposeCore_FIFO(t *testing.T) {
+	fs := composeFS()
+
+	run, err := PrepareComposeCore("/work/web", "web", fs, newTestBwClient(t, composeFiles), &config.Config{}, nil, &mockLogger{}, ComposeOptions{
+		ComposeFile: "/work/web/compose.yaml",
+		Ephemeral:   EphemeralOptions{BaseDir: "/tmp", Mode: EphemeralFIFO},
+	})
+
+	require.NoError(t, err)
+	require.Len(t, fs.pipes, 2)
+	assert.Equal(t, 3, fs.pipes[filepath.Join(run.Files.Dir, ".env")].reads, "api, web and --env-file")
+	assert.Contains(t, fs.writtenFiles, filepath.Join(run.Files.Dir, "compose.override.yaml"))
+}
+
+// 異常系: 保管庫のファイルを参照する env_file がない
+func TestPrepareComposeCore_NoMatch(t *testing.T) {
+	fs := &mockFileSystem{readContentMap: map[string][]byte{"/work/web/compose.yaml": []byte("services:\n  db:\n    env_file: db.env\n")}}
+
+	_, err := PrepareComposeCore("/work/web", "web", fs, newTestBwClient(t, composeFiles), &config.Config{}, nil, &mockLogger{}, ComposeOptions{ComposeFile: "/work/web/compose.yaml"})
+
+	assert.ErrorContains(t, err, "no env_file in compose.yaml matches a stored file of web (stored: .env, .env.api, .env.test)")
+	assert.Empty(t, fs.writtenFiles)
+}
+
+// 正常系: 値を environment に展開し、保管庫のファイルを env_file から外す。environment で定義済みのキーは出さない
+func TestRenderComposeCore(t *testing.T) {
+	fs := composeFS()
+
+	out, err := RenderComposeCore("/work/web", "web", fs, newTestBwClient(t, composeFiles), &config.Config{}, nil, &mockLogger{}, ComposeOptions{ComposeFile: "/work/web/compose.yaml"})
+
+	require.NoError(t, err)
+	assert.Equal(t, `# Generated by bwsf compose render for web. It contains secret values; do not commit it.
+services:
+  "api":
+    env_file: !override
+      - path: "/work/web/local.env"
+        required: true
+    environment:
+      "TOKEN": "a$$b"
+      "API_KEY": "k#1"
+  "web":
+    env_file: !reset []
+    environment:
+      "PORT": "3000"
+      "TOKEN": "a$$b"
+`, out)
+	assert.Empty(t, fs.writtenFiles)
+}
@@ -3,8 +3,10 @@ package core
 import (
 	"encoding/json"
 	"fmt"
+	"path"
 	"path/filepath"
 	"strings"
+	"time"
 
 	"bwsf/src/config"
 )
@@ -15,12 +17,16 @@ type BwClient interface {
 	DotenvsFolderExists() (bool, error)
 	CreateDotenvsFolder() error
 	ListItemsInFolder(folderID string) ([]Item, error)
-	GetItemByName(folderID, name string) (*FullItem, error)
+	GetItemByName(folderID, name string) (*FullItem, error) // folderID が空の場合は全フォルダから検索
 	GetItemByID(id string) (*FullItem, error)
 	CreateNoteItem(folderID, name, notes string) error
 	UpdateNoteItem(id, notes string) error
+	UpdateNoteItemFields(id, notes string, hidden []ItemField) error // notes と非表示のカスタムフィールドを 1 回で更新（同名のフィールドは置き換え）
+	RenameItem(id, newName string) error
+	DeleteItem(id string, permanent bool) error
 	Login(email, password, serverURL string) error
 	Unlock(masterPassword string) error
+	Sync() error
 }
 
 // FileSystem はファイルシステム操作を抽象化するインターフェースです。
@@ -31,6 +37,17 @@ type FileSystem interface {
 	Stat(path string) (FileInfo, error)
 	MkdirAll(path string, perm uint32) error
 	ReadDir(path string) ([]DirEntry, error)
+	Remove(path string) error
+	// ServeNamedPipe は path に名前付きパイプを作り、reads 回読まれるまで data を提供してから削除します。
+	ServeNamedPipe(path string, data []byte, reads int) (NamedPipe, error)
+}
+
+// NamedPipe は ServeNamedPipe で提供中の名前付きパイプです。
+type NamedPipe interface {
+	// Done は指定した回数だけ読まれ、パイプが削除されたときに閉じられます。
+	Done() <-chan struct{}
+	// Close は提供を止めてパイプを削除します。
+	Close() error
 }
 
 // DirEntry はディレクトリエントリを表します。
@@ -47,25 +64,46 @@ type FileInfo interface {
 // Logger はログ出力を抽象化するインターフェースです。
 type Logger interface {
 	Error(args ...interface{})
+	Warning(args ...interface{})
 	Info(args ...interface{})
 }
 
 // Item は dotenvs フォルダ内に保存される Bitwarden アイテムを表します。
 type Item struct {
-	ID   string
-	Name string
+	ID           string
+	Name         string
+	RevisionDate string // 最終更新日時（RFC 3339）
 }
 
 // FullItem は Bitwarden アイテムの完全な情報を表します。
+// Login と Fields は bw:// 参照の解決に使用します（ノートアイテムでは空）。
 type FullItem struct {
-	ID    string
+	ID           string
+	Name         string
+	Notes        string
+	RevisionDate string // 最終更新日時（RFC 3339）
+	Login        *ItemLogin
+	Fields       []ItemField
+}
+
+// ItemLogin はログインアイテムの認証情報を表します。
+type ItemLogin struct {
+	Username string
+	Password string
+	TOTP     string // TOTP シークレット（base32 または otpauth:// URI）
+}
+
+// ItemField はアイテムのカスタムフィールドを表します。
+type ItemField struct {
 	Name  string
-	Notes string
+	Value string
 }
 
 // EnvData は .env ファイルのデータを表します。
 type EnvData struct {
-	Lines []string `json:"lines"`
+	Lines   []string           `json:"lines"`
+	Extends []string           `json:"extends,omitempty"` // 継承する親アイテム名（後のものが優先）
+	Meta    map[string]KeyMeta `json:"meta,omitempty"`    // キーごとのメタデータ（push 時は残っているキーの分を引き継ぐ）
 }
 
 // MultiEnvData は複数の .env ファイルのデータを表します。
@@ -142,6 +180,60 @@ func WithUnlockRetry(
 	return fmt.Errorf("failed to unlock Bitwarden CLI: %w", unlockErr)
 }
 
+// PushOptions は PushEnvCoreWithOptions の追加設定です。
+// ゼロ値は PushEnvCore と同じ挙動になります。
+type PushOptions struct {
+	// StatePath が空でなければ、push 成功後に各ファイルのハッシュを同期状態として記録します。
+	StatePath string
+	// SkipLint が true の場合、push 前の lint を行いません。
+	SkipLint bool
+	// Git が設定されていれば、Git に追跡・履歴に含まれる .env ファイルを警告します。
+	Git GitInspector
+	// Audit が設定されていれば、push 成功後に監査ログを記録します。
+	Audit *AuditLog
+	// Notify が設定されていれば、キーが変わった場合に push 後のフックを実行します。
+	Notify *PushNotifier
+	// ShellCache が設定されていれば、push 成功後にプロジェクトの bwsf export 用キャッシュを削除します。
+	ShellCache Keyring
+	// Now はキーのメタデータに記録する現在時刻です。nil の場合は time.Now を使います。
+	Now func() time.Time
+}
+
+// PullOptions は PullEnvCoreWithOptions の追加設定です。
+// ゼロ値は PullEnvCore と同じ挙動になります。
+type PullOptions struct {
+	// StatePath が空でなければ、書き出したファイルのハッシュを同期状態として記録します。
+	StatePath string
+	// Git が設定されていれば、書き出し前に Git ガードを実行します。
+	Git GitInspector
+	// GitGuard はガードのモードです（"abort" | "warn" | "off"）。空なら .bwsf.json の git_guard に従います。
+	GitGuard string
+	// ConfirmGitignore は .gitignore へのパターン追記を確認します。nil の場合は追記を提案しません。
+	ConfirmGitignore func(gitignorePath string, patterns []string) (bool, error)
+	// CheckExamples が true の場合、書き出し後に .env*.example と比較した結果をログに出力します。
+	CheckExamples bool
+	// Interpolate が true の場合、${KEY} と bw:// 参照を展開して書き出します（.bwsf.json の interpolate でも有効化）。
+	// Bitwarden に保存された内容は参照のまま変更しません。
+	Interpolate bool
+	// Resolve は bw:// 参照の解決方法です。nil の場合は bw から取得します。
+	Resolve VaultResolver
+	// Files が空でなければ、指定したファイルのみ書き出します。
+	Files []string
+	// As は Files で選んだ 1 ファイルを書き出す名前です。
+	As string
+	// Layer が空でなければ、指定したファイルを順に重ねて MergeInto に書き出します（後のキーが優先）。
+	Layer []string
+	// MergeInto は Layer を重ねた結果を書き出すファイル名です。
+	MergeInto string
+	// Audit が設定されていれば、書き出したファイルを監査ログに記録します。
+	Audit *AuditLog
+	// ShellCache が設定されていれば、書き出した後にプロジェクトの bwsf export 用キャッシュを削除します。
+	ShellCache Keyring
+	// InMemory は書き出した内容がディスクに残らない（export・ephemeral・compose・k8s がメモリ上で受け取る）ことを示します。
+	// true の場合、改名・結合したファイルを push しないよう促す警告を出しません。
+	InMemory bool
+}
+
 // PushEnvCore は .env ファイルを Bitwarden にプッシュするコアロジックです。
 // 複数の .env* ファイルを自動検出し、.example ファイルは除外します。
 func PushEnvCore(
@@ -151,6 +243,19 @@ func PushEnvCore(
 	cfg *config.Config,
 	promptPassword func() (string, error),
 	logger Logger,
+) error {
+	return PushEnvCoreWithOptions(fromDir, projectName, fs, bw, cfg, promptPassword, logger, PushOptions{})
+}
+
+// PushEnvCoreWithOptions は PushOptions を指定して PushEnvCore を実行します。
+func PushEnvCoreWithOptions(
+	fromDir, projectName string,
+	fs FileSystem,
+	bw BwClient,
+	cfg *config.Config,
+	promptPassword func() (string, error),
+	logger Logger,
+	opts PushOptions,
 ) error {
 	// .env* ファイルを検出
 	envFiles, err := findEnvFilesFromFS(fs, fromDir)
@@ -174,10 +279,37 @@ func PushEnvCore(
 		multiData[fileName] = *envData
 	}
 
-	// JSON に変換
-	jsonData, err := multiEnvDataToJSON(multiData)
+	projectCfg, err := LoadProjectConfig(fs, fromDir)
 	if err != nil {
-		return fmt.Errorf("failed to convert to JSON: %w", err)
+		return err
+	}
+
+	// pull 時に追加した継承ブロックを取り除き、親アイテムを記録
+	for fileName, envData := range multiData {
+		envData = stripInheritedBlocks(envData)
+		envData.Extends = projectCfg.Extends
+		multiData[fileName] = envData
+	}
+
+	// lint（エラーがあれば push しない）
+	if !opts.SkipLint {
+		if err := reportLintIssues(lintMultiEnvData(multiData, projectCfg.Lint), logger); err != nil {
+			return err
+		}
+	}
+
+	// Git に追跡されているファイルを警告
+	var fileNames []string
+	for _, envPath := range envFiles {
+		fileNames = append(fileNames, filepath.Base(envPath))
+	}
+	warnTrackedPushFiles(fromDir, fileNames, opts.Git, logger)
+
+	// 参照を展開して pull したファイルは push しない
+	if opts.StatePath != "" {
+		if err := checkExpandedFiles(fs, opts.StatePath, fromDir, projectName, fileNames); err != nil {
+			return err
+		}
 	}
 
 	// dotenvs フォルダ ID を取得
@@ -191,6 +323,11 @@ func PushEnvCore(
 		return fmt.Errorf("failed to get dotenvs folder: %w", err)
 	}
 
+	// 既存アイテムを読んで書き戻すため、キャッシュではなく最新の内容を使う
+	if err := syncBeforeWrite(bw, cfg, promptPassword, logger); err != nil {
+		return err
+	}
+
 	// 既存アイテムを検索
 	var existingItem *FullItem
 	err = WithUnlockRetry(bw, cfg, promptPassword, logger, func() error {
@@ -202,6 +339,28 @@ func PushEnvCore(
 		return fmt.Errorf("failed to get item: %w", err)
 	}
 
+	// 保管庫に保存されていた内容（メタデータの引き継ぎ・監査ログ・フックで使用）
+	// 読めない内容を上書きすると、メタデータと監査の差分が黙って失われるため push しない
+	before := make(MultiEnvData)
+	if existingItem != nil {
+		stored, err := decodeStoredNotes(existingItem.Notes)
+		if err != nil {
+			return fmt.Errorf("cannot read the stored notes of '%s' (not overwriting them): %w", projectName, err)
+		}
+		before = stored
+	}
+	now := time.Now
+	if opts.Now != nil {
+		now = opts.Now
+	}
+	mergeKeyMeta(before, multiData, now())
+
+	// JSON に変換
+	jsonData, err := multiEnvDataToJSON(multiData)
+	if err != nil {
+		return fmt.Errorf("failed to convert to JSON: %w", err)
+	}
+
 	// 既存アイテムがあれば更新、なければ新規作成
 	if existingItem != nil {
 		err = WithUnlockRetry(bw, cfg, promptPassword, logger, func() error {
@@ -219,6 +378,31 @@ func PushEnvCore(
 		}
 	}
 
+	// 同期状態を記録（失敗しても push 自体は成功しているので警告に留める）
+	if opts.StatePath != "" {
+		if err := recordSyncState(fs, opts.StatePath, fromDir, projectName, multiData, false); err != nil {
+			logger.Error("Failed to record sync state: ", err.Error())
+		}
+	}
+	if opts.ShellCache != nil {
+		InvalidateShellEnvCache(opts.ShellCache, projectName, logger)
+	}
+
+	// 監査ログと push 後のフック（保管庫に保存されていた内容から変わったキー名のみ）
+	if opts.Audit != nil || opts.Notify != nil {
+		if opts.Audit != nil {
+			recordAudit(opts.Audit, AuditEntry{
+				Op:      AuditOpPush,
+				Project: projectName,
+				Dir:     fromDir,
+				Files:   fileNames,
+				Keys:    changedKeys(before, multiData),
+				Hashes:  auditHashes(multiData),
+			}, logger)
+		}
+		opts.Notify.notify(projectName, fileNames, diffKeys(before, multiData), logger)
+	}
+
 	return nil
 }
 
@@ -240,8 +424,14 @@ func GetPushedEnvFiles(
 	return names, nil
 }
 
-// findEnvFilesFromFS は FileSystem インターフェースを使って .env* ファイルを検出します。
+// findEnvFilesFromFS は FileSystem インターフェースを使って .env* ファイル（と .bwsf.json の env_patterns に一致するファイル）を検出します。
 func findEnvFilesFromFS(fs FileSystem, dirPath string) ([]string, error) {
+	projectCfg, err := LoadProjectConfig(fs, dirPath)
+	if err != nil {
+		return nil, err
+	}
+	patterns := envFilePatterns(projectCfg)
+
 	entries, err := fs.ReadDir(dirPath)
 	if err != nil {
 		return nil, fmt.Errorf("failed to read directory: %w", err)
@@ -254,13 +444,8 @@ func findEnvFilesFromFS(fs FileSystem, dirPath string) ([]string, error) {
 		}
 
 		name := entry.Name()
-		// Check if file starts with ".env"
-		if !strings.HasPrefix(name, ".env") {
-			continue
-		}
-
-		// Skip .example files
-		if isExampleFile(name) {
+		// Check if file starts with ".env" or matches env_patterns (.example files are skipped)
+		if !matchEnvFile(name, patterns) {
 			continue
 		}
 
@@ -273,6 +458,38 @@ func findEnvFilesFromFS(fs FileSystem, dirPath string) ([]string, error) {
 	return envFiles, nil
 }
 
+// DefaultEnvPattern は常に env ファイルとして扱うパターンです。.bwsf.json の env_patterns はこれに追加されます。
+const DefaultEnvPattern = ".env*"
+
+// envFilePatterns は env ファイルとして扱うパターン（既定のパターンと .bwsf.json の env_patterns）を返します。
+// push・pull の Git ガード・pre-commit フックは、すべてこのパターンを使います。
+func envFilePatterns(projectCfg *config.ProjectConfig) []string {
+	patterns := []string{DefaultEnvPattern}
+	if projectCfg != nil {
+		patterns = append(patterns, projectCfg.EnvPatterns...)
+	}
+	return patterns
+}
+
+// matchEnvFile は rel（プロジェクトのディレクトリからの "/" 区切りの相対パス）が env ファイルかを返します。
+// "/" を含まないパターンはファイル名に、含むパターンは相対パス全体に一致させます。.example ファイルと .bwsf.json は対象外です。
+func matchEnvFile(rel string, patterns []string) bool {
+	name := path.Base(rel)
+	if isExampleFile(name) || name == config.ProjectConfigFile {
+		return false
+	}
+	for _, pattern := range patterns {
+		target := name
+		if strings.Contains(pattern, "/") {
+			target, pattern = rel, strings.TrimPrefix(pattern, "/")
+		}
+		if ok, _ := path.Match(pattern, target); ok {
+			return true
+		}
+	}
+	return false
+}
+
 // isExampleFile checks if a filename contains ".example" anywhere in it
 func isExampleFile(filename string) bool {
 	return strings.Contains(filename, ".example")
@@ -312,6 +529,20 @@ func PullEnvCore(
 	promptPassword func() (string, error),
 	confirmOverwrite func(path string) (bool, error),
 	logger Logger,
+) error {
+	return PullEnvCoreWithOptions(outputDir, projectName, fs, bw, cfg, promptPassword, confirmOverwrite, logger, PullOptions{})
+}
+
+// PullEnvCoreWithOptions は PullOptions を指定して PullEnvCore を実行します。
+func PullEnvCoreWithOptions(
+	outputDir, projectName string,
+	fs FileSystem,
+	bw BwClient,
+	cfg *config.Config,
+	promptPassword func() (string, error),
+	confirmOverwrite func(path string) (bool, error),
+	logger Logger,
+	opts PullOptions,
 ) error {
 	// dotenvs フォルダ ID を取得
 	var folderID string
@@ -341,16 +572,75 @@ func PullEnvCore(
 	}
 
 	// JSON から MultiEnvData を復元
-	multiData, err := restoreMultiEnvFromJSON(item.Notes)
+	multiData, err := decodeStoredNotes(item.Notes)
 	if err != nil {
-		// 旧形式の場合は単一ファイルとして処理（下位互換性のため）
-		envContent, legacyErr := restoreEnvFileFromJSON(item.Notes)
-		if legacyErr != nil {
-			return fmt.Errorf("failed to restore .env from JSON: %w", err)
+		return err
+	}
+	return writePulledFiles(outputDir, projectName, multiData, fs, bw, cfg, promptPassword, confirmOverwrite, logger, opts)
+}
+
+// writePulledFiles は保管庫から取得済みの multiData を opts に従って outputDir に書き出します。
+func writePulledFiles(
+	outputDir, projectName string,
+	multiData MultiEnvData,
+	fs FileSystem,
+	bw BwClient,
+	cfg *config.Config,
+	promptPassword func() (string, error),
+	confirmOverwrite func(path string) (bool, error),
+	logger Logger,
+	opts PullOptions,
+) error {
+	projectCfg, err := LoadProjectConfig(fs, outputDir)
+	if err != nil {
+		return err
+	}
+
+	// 書き出すファイルを決定（--env / --file / --as / --layer）
+	var storedNames []string
+	for fileName := range multiData {
+		storedNames = append(storedNames, fileName)
+	}
+	sortFileNames(storedNames)
+	targets, err := planPullTargets(storedNames, opts)
+	if err != nil {
+		return err
+	}
+
+	// Git ガード（追跡中・未無視のファイルがあれば書き出さない）
+	if opts.Git != nil {
+		mode := opts.GitGuard
+		if mode == "" {
+			mode = resolveGitGuardMode(projectCfg)
+		}
+		var fileNames []string
+		for _, target := range targets {
+			fileNames = append(fileNames, target.Name)
 		}
-		// 旧形式を新形式に変換
-		multiData = MultiEnvData{
-			".env": EnvData{Lines: strings.Split(envContent, "\n")},
+		if err := guardPullTargets(outputDir, fileNames, mode, envFilePatterns(projectCfg), opts.Git, fs, opts.ConfirmGitignore, logger); err != nil {
+			return err
+		}
+	}
+
+	// 親アイテムのキーをプロジェクト自身のキーの下に重ねる
+	output := multiData
+	if parentsByFile := projectParents(multiData, projectCfg); len(parentsByFile) > 0 {
+		parents, err := fetchParents(parentsByFile, projectName, bw, cfg, promptPassword, logger)
+		if err != nil {
+			return err
+		}
+		output, _ = mergeParents(projectName, multiData, parentsByFile, parents)
+	}
+
+	// ${KEY} と bw:// 参照を展開（書き出す内容のみ。保存内容は参照のまま）
+	interpolate := opts.Interpolate || projectCfg.Interpolate
+	if interpolate {
+		resolve := opts.Resolve
+		if resolve == nil {
+			resolve = NewVaultResolver(bw, cfg, promptPassword, logger, time.Now)
+		}
+		if output, err = InterpolateMultiEnvData(output, resolve); err != nil {
+			return err
 		}
 	}
 
@@ -363,8 +653,15 @@ func PullEnvCore(
 	}
 
 	// 各ファイルを書き出し
-	for fileName, envData := range multiData {
+	written := make(MultiEnvData)
+	rendered := make(MultiEnvData)
+	previous := make(MultiEnvData)
+	var writtenNames []string
+	mappedWritten := false
+	for _, target := range targets {
+		fileName := target.Name
 		envPath := filepath.Join(outputDir, fileName)
+		rendered[fileName] = renderPullTarget(target, output)
 
 		// ファイルの存在確認
 		info, err := fs.Stat(envPath)
@@ -377,15 +674,71 @@ func PullEnvCore(
 			if !confirmed {
 				continue // このファイルはスキップ
 			}
+			// 監査ログ用に上書き前の内容を控える
+			if opts.Audit != nil {
+				if content, err := fs.ReadFile(envPath); err == nil {
+					previous[fileName] = *parseEnvContent(content)
+				}
+			}
 		}
 
 		// ファイル内容を復元
-		envContent := restoreEnvContentFromData(envData)
+		envContent := restoreEnvContentFromData(rendered[fileName])
 
 		// ファイルを書き出し
 		if err := fs.WriteFile(envPath, []byte(envContent), 0644); err != nil {
 			return fmt.Errorf("failed to write %s file: %w", fileName, err)
 		}
+		writtenNames = append(writtenNames, fileName)
+
+		// 名前を変えた・重ねたファイルは保存内容と対応しないため、同期状態には記録しない
+		if target.mapped() {
+			mappedWritten = true
+			continue
+		}
+		written[fileName] = multiData[fileName]
+	}
+
+	// 書き出したファイルのみ同期状態を記録（スキップしたファイルは前回の状態を維持）
+	if opts.StatePath != "" && len(written) > 0 {
+		if err := recordSyncState(fs, opts.StatePath, outputDir, projectName, written, interpolate); err != nil {
+			logger.Error("Failed to record sync state: ", err.Error())
+		}
+	}
+	if opts.ShellCache != nil && len(written) > 0 {
+		InvalidateShellEnvCache(opts.ShellCache, projectName, logger)
+	}
+	// メモリ上に書き出した場合はディスクに残らないため警告しない
+	if mappedWritten && !opts.InMemory {
+		logger.Warning("Renamed or merged files are not tracked for sync; do not push them back from ", outputDir)
+	}
+
+	// 監査ログ（ローカルの既存ファイルから変わったキー名のみ）
+	if opts.Audit != nil && len(writtenNames) > 0 {
+		files := make(MultiEnvData)
+		for _, fileName := range writtenNames {
+			files[fileName] = rendered[fileName]
+		}
+		recordAudit(opts.Audit, AuditEntry{
+			Op:      AuditOpPull,
+			Project: projectName,
+			Dir:     outputDir,
+			Files:   writtenNames,
+			Keys:    changedKeys(previous, files),
+			Hashes:  auditHashes(files),
+		}, logger)
+	}
+
+	// .example との比較（結果は表示のみで pull は失敗させない）
+	if opts.CheckExamples {
+		specs, err := loadExampleSpecs(fs, outputDir)
+		if err != nil {
+			logger.Warning("[CHECK] ", err.Error())
+		} else if len(specs) > 0 {
+			if err := ReportCheckIssues(checkAgainstExamples(specs, rendered, projectCfg.Lint), logger); err != nil {
+				logger.Warning("[CHECK] ", err.Error(), " (run `bwsf check` for details)")
+			}
+		}
 	}
 
 	return nil
@@ -463,6 +816,72 @@ func sortFileNames(names []string) {
 	}
 }
 
+// LoadProjectConfig はディレクトリ内の .bwsf.json を読み込みます。
+// ファイルが存在しない場合はゼロ値の設定を返します。
+func LoadProjectConfig(fs FileSystem, dir string) (*config.ProjectConfig, error) {
+	path := filepath.Join(dir, config.ProjectConfigFile)
+
+	info, err := fs.Stat(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to stat %s: %w", config.ProjectConfigFile, err)
+	}
+	if info.IsNotExist() {
+		return &config.ProjectConfig{}, nil
+	}
+
+	data, err := fs.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", config.ProjectConfigFile, err)
+	}
+	return config.ParseProjectConfig(data)
+}
+
+// syncBeforeWrite は保管庫の内容を読んで書き戻す前に、キャッシュの鮮度によらず同期します。
+// キャッシュの有効期限内に他の人が push した内容を、古い内容で上書きしないためです。
+// WriteSyncer でないクライアントは GetItemByName のたびに同期するため何もしません。
+func syncBeforeWrite(bw BwClient, cfg *config.Config, promptPassword func() (string, error), logger Logger) error {
+	syncer, ok := bw.(WriteSyncer)
+	if !ok {
+		return nil
+	}
+	if err := WithUnlockRetry(bw, cfg, promptPassword, logger, syncer.SyncForWrite); err != nil && IsLockedError(err) {
+		return fmt.Errorf("failed to sync: %w", err)
+	}
+	return nil
+}
+
+// fetchProjectItem は dotenvs フォルダ ID を取得し、プロジェクト名に一致するアイテムを返します。
+// アイテムが存在しない場合は nil を返します。
+func fetchProjectItem(
+	projectName string,
+	bw BwClient,
+	cfg *config.Config,
+	promptPassword func() (string, error),
+	logger Logger,
+) (string, *FullItem, error) {
+	var folderID string
+	err := WithUnlockRetry(bw, cfg, promptPassword, logger, func() error {
+		var innerErr error
+		folderID, innerErr = bw.GetDotenvsFolderID()
+		return innerErr
+	})
+	if err != nil {
+		return "", nil, fmt.Errorf("failed to get dotenvs folder: %w", err)
+	}
+
+	var item *FullItem
+	err = WithUnlockRetry(bw, cfg, promptPassword, logger, func() error {
+		var innerErr error
+		item, innerErr = bw.GetItemByName(folderID, projectName)
+		return innerErr
+	})
+	if err != nil {
+		return "", nil, fmt.Errorf("failed to get item: %w", err)
+	}
+
+	return folderID, item, nil
+}
+
 // ListDotenvsCore は dotenvs フォルダ内のアイテム一覧を取得するコアロジックです。
 func ListDotenvsCore(
 	bw BwClient,
@@ -637,6 +1056,24 @@ func restoreMultiEnvFromJSON(jsonStr string) (MultiEnvData, error) {
 	return data, nil
 }
 
+// decodeStoredNotes はアイテムのノートから MultiEnvData を復元します。
+// 旧形式（単一 .env の {"lines": [...]}）の場合は .env のみを持つ MultiEnvData に変換します。
+func decodeStoredNotes(notes string) (MultiEnvData, error) {
+	multiData, err := restoreMultiEnvFromJSON(notes)
+	if err == nil {
+		return multiData, nil
+	}
+
+	// 旧形式の場合は単一ファイルとして処理（下位互換性のため）
+	envContent, legacyErr := restoreEnvFileFromJSON(notes)
+	if legacyErr != nil {
+		return nil, fmt.Errorf("failed to restore .env from JSON: %w", err)
+	}
+	return MultiEnvData{
+		".env": EnvData{Lines: strings.Split(envContent, "\n")},
+	}, nil
+}
+
 // restoreEnvContentFromData は EnvData から .env ファイルの内容を復元します。
 func restoreEnvContentFromData(data EnvData) string {
 	return strings.Join(data.Lines, "\n")
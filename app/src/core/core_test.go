@@ -8,6 +8,7 @@ import (
 	"bwsf/src/config"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 // =============================================================================
@@ -39,23 +40,37 @@ type mockBwClient struct {
 
 	// GetItemByName の挙動制御
 	itemByName    *FullItem
+	itemsByName   map[string]*FullItem // 設定されている場合は名前ごとに返す
 	itemByNameErr error
 
 	// GetItemByID の挙動制御
 	itemByID    *FullItem
+	itemsByID   map[string]*FullItem // 設定されている場合は ID ごとに返す
 	itemByIDErr error
 
 	// CreateNoteItem の挙動制御
-	createErr error
+	createErr    error
+	createdNotes map[string]string // name -> notes
 
 	// UpdateNoteItem の挙動制御
-	updateErr error
+	updateErr     error
+	updatedNotes  map[string]string // id -> notes
+	updatedFields []ItemField       // UpdateNoteItemFields で保存したフィールド
+
+	// RenameItem の挙動制御
+	renameErr error
+
+	// DeleteItem の挙動制御
+	deleteErr error
 
 	// Login の挙動制御
 	loginErr error
 
 	// Unlock の挙動制御
 	unlockErr error
+
+	// Sync の挙動制御
+	syncErr error
 }
 
 func (m *mockBwClient) GetDotenvsFolderID() (string, error) {
@@ -95,6 +110,9 @@ func (m *mockBwClient) GetItemByName(folderID, name string) (*FullItem, error) {
 	if m.itemByNameErr != nil {
 		return nil, m.itemByNameErr
 	}
+	if m.itemsByName != nil {
+		return m.itemsByName[name], nil
+	}
 	return m.itemByName, nil
 }
 
@@ -103,19 +121,50 @@ func (m *mockBwClient) GetItemByID(id string) (*FullItem, error) {
 	if m.itemByIDErr != nil {
 		return nil, m.itemByIDErr
 	}
+	if m.itemsByID != nil {
+		return m.itemsByID[id], nil
+	}
 	return m.itemByID, nil
 }
 
 func (m *mockBwClient) CreateNoteItem(folderID, name, notes string) error {
 	m.calls = append(m.calls, fmt.Sprintf("CreateNoteItem(%s,%s)", folderID, name))
+	if m.createdNotes == nil {
+		m.createdNotes = make(map[string]string)
+	}
+	m.createdNotes[name] = notes
 	return m.createErr
 }
 
 func (m *mockBwClient) UpdateNoteItem(id, notes string) error {
 	m.calls = append(m.calls, fmt.Sprintf("UpdateNoteItem(%s)", id))
+	if m.updatedNotes == nil {
+		m.updatedNotes = make(map[string]string)
+	}
+	m.updatedNotes[id] = notes
+	return m.updateErr
+}
+
+func (m *mockBwClient) UpdateNoteItemFields(id, notes string, hidden []ItemField) error {
+	m.calls = append(m.calls, fmt.Sprintf("UpdateNoteItemFields(%s)", id))
+	if m.updatedNotes == nil {
+		m.updatedNotes = make(map[string]string)
+	}
+	m.updatedNotes[id] = notes
+	m.updatedFields = append(m.updatedFields, hidden...)
 	return m.updateErr
 }
 
+func (m *mockBwClient) RenameItem(id, newName string) error {
+	m.calls = append(m.calls, fmt.Sprintf("RenameItem(%s,%s)", id, newName))
+	return m.renameErr
+}
+
+func (m *mockBwClient) DeleteItem(id string, permanent bool) error {
+	m.calls = append(m.calls, fmt.Sprintf("DeleteItem(%s,%t)", id, permanent))
+	return m.deleteErr
+}
+
 func (m *mockBwClient) Login(email, password, serverURL string) error {
 	m.calls = append(m.calls, fmt.Sprintf("Login(%s,%s)", email, serverURL))
 	return m.loginErr
@@ -126,6 +175,26 @@ func (m *mockBwClient) Unlock(masterPassword string) error {
 	return m.unlockErr
 }
 
+func (m *mockBwClient) Sync() error {
+	m.calls = append(m.calls, "Sync")
+	return m.syncErr
+}
+
+// newTestBwClient は dotenvs フォルダ（folder-123）に web プロジェクト（item-1）として files を保存したモックを返します。
+// 名前・ID・フォルダ内の一覧のどれで引いても同じアイテムを返します。
+func newTestBwClient(t *testing.T, files MultiEnvData) *mockBwClient {
+	t.Helper()
+	notes, err := multiEnvDataToJSON(files)
+	require.NoError(t, err)
+	item := &FullItem{ID: "item-1", Name: "web", Notes: notes}
+	return &mockBwClient{
+		folderID:   "folder-123",
+		items:      []Item{{ID: item.ID, Name: item.Name}},
+		itemByName: item,
+		itemsByID:  map[string]*FullItem{item.ID: item},
+	}
+}
+
 // --- mockFileSystem ---
 
 type mockFileInfo struct {
@@ -162,9 +231,18 @@ type mockFileSystem struct {
 	// MkdirAll の挙動制御
 	mkdirErr error
 
+	// Remove の挙動制御
+	removedPaths []string
+	removeErr    error
+
+	// ServeNamedPipe の挙動制御
+	pipes   map[string]*mockNamedPipe // パスごとに作ったパイプ
+	pipeErr error
+
 	// ReadDir の挙動制御
-	dirEntries []DirEntry
-	readDirErr error
+	dirEntries    []DirEntry
+	dirEntriesMap map[string][]DirEntry // ディレクトリパスごとのエントリ
+	readDirErr    error
 }
 
 func (m *mockFileSystem) OpenEnvFile(path string) ([]byte, error) {
@@ -220,11 +298,53 @@ func (m *mockFileSystem) MkdirAll(path string, perm uint32) error {
 	return m.mkdirErr
 }
 
+func (m *mockFileSystem) Remove(path string) error {
+	m.calls = append(m.calls, fmt.Sprintf("Remove(%s)", path))
+	m.removedPaths = append(m.removedPaths, path)
+	return m.removeErr
+}
+
+func (m *mockFileSystem) ServeNamedPipe(path string, data []byte, reads int) (NamedPipe, error) {
+	m.calls = append(m.calls, fmt.Sprintf("ServeNamedPipe(%s, %d)", path, reads))
+	if m.pipeErr != nil {
+		return nil, m.pipeErr
+	}
+	if m.pipes == nil {
+		m.pipes = make(map[string]*mockNamedPipe)
+	}
+	pipe := &mockNamedPipe{data: data, reads: reads, done: make(chan struct{})}
+	m.pipes[path] = pipe
+	return pipe, nil
+}
+
+// mockNamedPipe は NamedPipe インターフェースのモック実装です。
+type mockNamedPipe struct {
+	data   []byte
+	reads  int
+	done   chan struct{}
+	closed bool
+}
+
+func (p *mockNamedPipe) Done() <-chan struct{} {
+	return p.done
+}
+
+func (p *mockNamedPipe) Close() error {
+	if !p.closed {
+		p.closed = true
+		close(p.done)
+	}
+	return nil
+}
+
 func (m *mockFileSystem) ReadDir(path string) ([]DirEntry, error) {
 	m.calls = append(m.calls, fmt.Sprintf("ReadDir(%s)", path))
 	if m.readDirErr != nil {
 		return nil, m.readDirErr
 	}
+	if m.dirEntriesMap != nil {
+		return m.dirEntriesMap[path], nil
+	}
 	return m.dirEntries, nil
 }
 
@@ -1777,7 +1897,7 @@ func TestPushEnvCore_DoubleDotFromDir(t *testing.T) {
 // 正常系: MultiEnvData を JSON に変換して復元
 func TestMultiEnvData_RoundTrip(t *testing.T) {
 	original := MultiEnvData{
-		".env": EnvData{Lines: []string{"KEY1=value1", "KEY2=value2"}},
+		".env":         EnvData{Lines: []string{"KEY1=value1", "KEY2=value2"}},
 		".env.staging": EnvData{Lines: []string{"KEY1=staging1", "KEY2=staging2"}},
 	}
 
@@ -2040,6 +2160,26 @@ func TestGetPushedEnvFiles_Success(t *testing.T) {
 	assert.NotContains(t, files, ".env.example")
 }
 
+// 正常系: .bwsf.json の env_patterns に一致するファイルも push する（.bwsf.json と .example は除外）
+func TestGetPushedEnvFiles_EnvPatterns(t *testing.T) {
+	fs := &mockFileSystem{
+		dirEntries: []DirEntry{
+			&mockDirEntry{name: ".bwsf.json"},
+			&mockDirEntry{name: ".env"},
+			&mockDirEntry{name: "secrets.env"},
+			&mockDirEntry{name: "secrets.example.env"},
+			&mockDirEntry{name: "main.go"},
+		},
+		statInfoMap:    map[string]FileInfo{".bwsf.json": &mockFileInfo{}},
+		readContentMap: map[string][]byte{".bwsf.json": []byte(`{"env_patterns":["*.env","*.json"]}`)},
+	}
+
+	files, err := GetPushedEnvFiles(".", fs)
+
+	require.NoError(t, err)
+	assert.Equal(t, []string{".env", "secrets.env"}, files)
+}
+
 // 正常系: GetPulledEnvFiles でファイル名一覧を取得
 func TestGetPulledEnvFiles_Success(t *testing.T) {
 	multiEnvJSON := `{".env":{"lines":["KEY=base"]},".env.staging":{"lines":["KEY=staging"]},".env.production":{"lines":["KEY=prod"]}}`
@@ -0,0 +1,97 @@
+package core
+
+import (
+	"strings"
+)
+
+// envEntry は .env の 1 行を KEY=VALUE として解釈した結果です。
+type envEntry struct {
+	Key      string
+	Value    string // クォートとインラインコメントを取り除いた値
+	RawValue string // '=' 以降の生の値（前後の空白を除く）
+	Quote    byte   // 0, '"', '\''
+	Export   bool   // "export " プレフィックスの有無
+	Closed   bool   // クォートが閉じているか（Quote が 0 の場合は常に true）
+}
+
+// parseEnvLine は 1 行を KEY=VALUE として解釈します。
+// 空行・コメント行・'=' を含まない行の場合は ok=false を返します。
+func parseEnvLine(line string) (envEntry, bool) {
+	trimmed := strings.TrimSpace(strings.TrimSuffix(line, "\r"))
+	trimmed = strings.TrimPrefix(trimmed, "\ufeff")
+	if trimmed == "" || strings.HasPrefix(trimmed, "#") {
+		return envEntry{}, false
+	}
+
+	var entry envEntry
+	if strings.HasPrefix(trimmed, "export ") {
+		entry.Export = true
+		trimmed = strings.TrimSpace(strings.TrimPrefix(trimmed, "export "))
+	}
+
+	idx := strings.Index(trimmed, "=")
+	if idx < 0 {
+		return envEntry{}, false
+	}
+
+	entry.Key = strings.TrimSpace(trimmed[:idx])
+	entry.RawValue = strings.TrimSpace(trimmed[idx+1:])
+	entry.Value, entry.Quote, entry.Closed = unquoteEnvValue(entry.RawValue)
+	return entry, true
+}
+
+// unquoteEnvValue はクォートを外した値、クォート文字、クォートが閉じているかを返します。
+// ダブルクォート内では \n, \", \\ をエスケープとして扱います。
+// クォートなしの値では " #" 以降をインラインコメントとして取り除きます。
+func unquoteEnvValue(raw string) (string, byte, bool) {
+	if raw == "" {
+		return "", 0, true
+	}
+
+	quote := raw[0]
+	if quote != '"' && quote != '\'' {
+		value := raw
+		if idx := strings.Index(value, " #"); idx >= 0 {
+			value = value[:idx]
+		}
+		return strings.TrimSpace(value), 0, true
+	}
+
+	var b strings.Builder
+	for i := 1; i < len(raw); i++ {
+		c := raw[i]
+		if c == quote {
+			return b.String(), quote, true
+		}
+		if quote == '"' && c == '\\' && i+1 < len(raw) {
+			switch raw[i+1] {
+			case 'n':
+				b.WriteByte('\n')
+				i++
+				continue
+			case '"', '\\':
+				b.WriteByte(raw[i+1])
+				i++
+				continue
+			}
+		}
+		b.WriteByte(c)
+	}
+	return b.String(), quote, false
+}
+
+// isValidEnvKey はキーが [A-Za-z_][A-Za-z0-9_.]* に一致するかを判定します。
+func isValidEnvKey(key string) bool {
+	if key == "" {
+		return false
+	}
+	for i, r := range key {
+		switch {
+		case r == '_' || (r >= 'A' && r <= 'Z') || (r >= 'a' && r <= 'z'):
+		case i > 0 && ((r >= '0' && r <= '9') || r == '.'):
+		default:
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,67 @@
+package core
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// =============================================================================
+// parseEnvLine のテスト
+// =============================================================================
+
+// 正常系: 各種クォートとインラインコメント
+func TestParseEnvLine_Values(t *testing.T) {
+	tests := []struct {
+		line  string
+		key   string
+		value string
+	}{
+		{"KEY=value", "KEY", "value"},
+		{"export KEY=value", "KEY", "value"},
+		{`KEY="quoted value"`, "KEY", "quoted value"},
+		{`KEY='single # not comment'`, "KEY", "single # not comment"},
+		{`KEY="line\nbreak"`, "KEY", "line\nbreak"},
+		{"KEY=value # comment", "KEY", "value"},
+		{"KEY=", "KEY", ""},
+		{"KEY=value\r", "KEY", "value"},
+	}
+
+	for _, tt := range tests {
+		entry, ok := parseEnvLine(tt.line)
+		assert.True(t, ok, tt.line)
+		assert.Equal(t, tt.key, entry.Key, tt.line)
+		assert.Equal(t, tt.value, entry.Value, tt.line)
+	}
+}
+
+// 正常系: コメント・空行・KEY=VALUE でない行は対象外
+func TestParseEnvLine_NotAssignment(t *testing.T) {
+	for _, line := range []string{"", "   ", "# comment", "JUSTTEXT"} {
+		_, ok := parseEnvLine(line)
+		assert.False(t, ok, line)
+	}
+}
+
+// 正常系: 閉じていないクォートを検出
+func TestParseEnvLine_UnclosedQuote(t *testing.T) {
+	entry, ok := parseEnvLine(`KEY="unterminated`)
+
+	assert.True(t, ok)
+	assert.False(t, entry.Closed)
+	assert.Equal(t, byte('"'), entry.Quote)
+}
+
+// =============================================================================
+// isValidEnvKey のテスト
+// =============================================================================
+
+func TestIsValidEnvKey(t *testing.T) {
+	assert.True(t, isValidEnvKey("DATABASE_URL"))
+	assert.True(t, isValidEnvKey("_private"))
+	assert.True(t, isValidEnvKey("app.name2"))
+	assert.False(t, isValidEnvKey(""))
+	assert.False(t, isValidEnvKey("1KEY"))
+	assert.False(t, isValidEnvKey("MY-KEY"))
+	assert.False(t, isValidEnvKey("MY KEY"))
+}
@@ -0,0 +1,198 @@
+package core
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"path/filepath"
+	"sync"
+
+	"bwsf/src/config"
+)
+
+// 一時ファイルの提供方法
+const (
+	EphemeralTmpfs = "tmpfs" // 非公開の一時ディレクトリ（Linux では /dev/shm）に書き出す
+	EphemeralFIFO  = "fifo"  // 名前付きパイプで指定回数だけ読ませる
+)
+
+// EphemeralDirEnv は一時ファイルのディレクトリをラップしたコマンドに伝える環境変数です。
+const EphemeralDirEnv = "BWSF_EPHEMERAL_DIR"
+
+// EphemeralOptions は PullEphemeralCore の設定です。
+type EphemeralOptions struct {
+	// Pull は書き出すファイルの選択です（Files / As / Layer / MergeInto / Interpolate / Audit）。
+	// 同期状態と Git ガードはプロジェクトのディレクトリに書き出さないため使いません。
+	Pull PullOptions
+	// BaseDir は一時ディレクトリを作る場所です。
+	BaseDir string
+	// Mode は EphemeralTmpfs または EphemeralFIFO です。空の場合は EphemeralTmpfs です。
+	Mode string
+	// Reads は名前付きパイプを読める回数です。0 以下の場合は 1 回です。
+	Reads int
+}
+
+// EphemeralFiles は PullEphemeralCore で作った一時ファイルです。Cleanup で削除します。
+type EphemeralFiles struct {
+	// Dir はファイルを置いた非公開ディレクトリです。
+	Dir string
+	// Paths は提供中のファイルのパスです。
+	Paths []string
+
+	fs    FileSystem
+	pipes []NamedPipe
+	extra []string // addFile で置いた、シークレットを含まないファイル
+	done  chan struct{}
+
+	mu      sync.Mutex
+	cleaned bool
+}
+
+// PullEphemeralCore はプロジェクトのファイルを BaseDir 下の非公開ディレクトリ（0700）に置きます。
+// プロジェクトのディレクトリには何も書き出しません。呼び出し側はコマンドの終了時や TTL 経過後に Cleanup を呼びます。
+func PullEphemeralCore(
+	projectDir, projectName string,
+	fs FileSystem,
+	bw BwClient,
+	cfg *config.Config,
+	promptPassword func() (string, error),
+	logger Logger,
+	opts EphemeralOptions,
+) (*EphemeralFiles, error) {
+	mode := opts.Mode
+	if mode == "" {
+		mode = EphemeralTmpfs
+	}
+	if mode != EphemeralTmpfs && mode != EphemeralFIFO {
+		return nil, fmt.Errorf("invalid ephemeral mode: %s (expected %s or %s)", mode, EphemeralTmpfs, EphemeralFIFO)
+	}
+	reads := opts.Reads
+	if reads <= 0 {
+		reads = 1
+	}
+
+	// 内容はメモリ上に書き出し、プロジェクトのディレクトリには触れない
+	memFS := &memoryOutputFS{FileSystem: fs, files: make(map[string][]byte)}
+	pullOpts := opts.Pull
+	pullOpts.StatePath = ""
+	pullOpts.Git = nil
+	pullOpts.ConfirmGitignore = nil
+	pullOpts.InMemory = true
+	overwrite := func(path string) (bool, error) { return true, nil }
+	if err := PullEnvCoreWithOptions(projectDir, projectName, memFS, bw, cfg, promptPassword, overwrite, logger, pullOpts); err != nil {
+		return nil, err
+	}
+	if len(memFS.files) == 0 {
+		return nil, fmt.Errorf("no env files to serve for %s", projectName)
+	}
+
+	var names []string
+	contents := make(map[string][]byte, len(memFS.files))
+	for path, data := range memFS.files {
+		name := filepath.Base(path)
+		names = append(names, name)
+		contents[name] = data
+	}
+	sortFileNames(names)
+
+	dir, err := ephemeralDirName(opts.BaseDir)
+	if err != nil {
+		return nil, err
+	}
+	if info, err := fs.Stat(dir); err != nil || !info.IsNotExist() {
+		return nil, fmt.Errorf("refusing to use existing directory %s", dir)
+	}
+	if err := fs.MkdirAll(dir, 0700); err != nil {
+		return nil, fmt.Errorf("failed to create ephemeral directory: %w", err)
+	}
+
+	e := &EphemeralFiles{Dir: dir, fs: fs}
+	for _, name := range names {
+		path := filepath.Join(dir, name)
+		if mode == EphemeralFIFO {
+			pipe, err := fs.ServeNamedPipe(path, contents[name], reads)
+			if err != nil {
+				e.Cleanup()
+				return nil, fmt.Errorf("failed to serve %s: %w", name, err)
+			}
+			e.pipes = append(e.pipes, pipe)
+		} else if err := fs.WriteFile(path, contents[name], 0600); err != nil {
+			e.Paths = append(e.Paths, path)
+			e.Cleanup()
+			return nil, fmt.Errorf("failed to write %s: %w", name, err)
+		}
+		e.Paths = append(e.Paths, path)
+	}
+
+	// 名前付きパイプはすべて読み終わったら Done を閉じる
+	if len(e.pipes) > 0 {
+		e.done = make(chan struct{})
+		go func() {
+			for _, pipe := range e.pipes {
+				<-pipe.Done()
+			}
+			close(e.done)
+		}()
+	}
+	return e, nil
+}
+
+// Done は名前付きパイプがすべて指定回数読まれたときに閉じられます。tmpfs の場合は閉じられません。
+func (e *EphemeralFiles) Done() <-chan struct{} {
+	return e.done
+}
+
+// Cleanup はパイプの提供を止め、ファイルとディレクトリを削除します。2 回目以降は何もしません。
+func (e *EphemeralFiles) Cleanup() error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if e.cleaned {
+		return nil
+	}
+	e.cleaned = true
+
+	var firstErr error
+	keep := func(err error) {
+		if err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+	for _, pipe := range e.pipes {
+		keep(pipe.Close())
+	}
+	if len(e.pipes) == 0 {
+		for _, path := range e.Paths {
+			keep(e.fs.Remove(path))
+		}
+	}
+	for _, path := range e.extra {
+		keep(e.fs.Remove(path))
+	}
+	keep(e.fs.Remove(e.Dir))
+	if firstErr != nil {
+		return fmt.Errorf("failed to remove ephemeral files in %s: %w", e.Dir, firstErr)
+	}
+	return nil
+}
+
+// addFile は一時ディレクトリに name のファイルを置き、パスを返します。Cleanup で一緒に削除します。
+// 名前付きパイプの場合も通常のファイルとして置くため、シークレットを含まない内容（override ファイルなど）に使います。
+func (e *EphemeralFiles) addFile(name string, data []byte) (string, error) {
+	path := filepath.Join(e.Dir, name)
+	if err := e.fs.WriteFile(path, data, 0600); err != nil {
+		return "", fmt.Errorf("failed to write %s: %w", name, err)
+	}
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.extra = append(e.extra, path)
+	return path, nil
+}
+
+// ephemeralDirName は推測できない名前の一時ディレクトリのパスを返します。
+func ephemeralDirName(baseDir string) (string, error) {
+	var b [8]byte
+	if _, err := rand.Read(b[:]); err != nil {
+		return "", fmt.Errorf("failed to generate directory name: %w", err)
+	}
+	return filepath.Join(baseDir, "bwsf-"+hex.EncodeToString(b[:])), nil
+}
@@ -0,0 +1,91 @@
+package core
+
+import (
+	"errors"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"bwsf/src/config"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// =============================================================================
+// PullEphemeralCore のテスト
+// =============================================================================
+
+// ephemeralFiles は ephemeral のテストで保存しておく .env と .env.local です。
+var ephemeralFiles = MultiEnvData{
+	".env":       {Lines: []string{"A=1"}},
+	".env.local": {Lines: []string{"B=2"}},
+}
+
+// 正常系: 非公開ディレクトリに 0600 で書き出し、プロジェクトのディレクトリには書かない。Cleanup で削除する
+func TestPullEphemeralCore_Tmpfs(t *testing.T) {
+	fs := &mockFileSystem{}
+
+	files, err := PullEphemeralCore("/work/web", "web", fs, newTestBwClient(t, ephemeralFiles), &config.Config{}, nil, &mockLogger{},
+		EphemeralOptions{BaseDir: "/dev/shm", Pull: PullOptions{StatePath: "/state.json"}})
+
+	require.NoError(t, err)
+	assert.True(t, strings.HasPrefix(files.Dir, "/dev/shm/bwsf-"), files.Dir)
+	assert.Contains(t, fs.calls, "MkdirAll("+files.Dir+")")
+	assert.Equal(t, []string{filepath.Join(files.Dir, ".env"), filepath.Join(files.Dir, ".env.local")}, files.Paths)
+	assert.Equal(t, "A=1", string(fs.writtenFiles[files.Paths[0]]))
+	assert.Len(t, fs.writtenFiles, 2, "nothing is written to the project directory or the state file")
+	assert.Nil(t, files.Done())
+
+	require.NoError(t, files.Cleanup())
+	require.NoError(t, files.Cleanup())
+	assert.Equal(t, append(files.Paths, files.Dir), fs.removedPaths)
+}
+
+// 正常系: 名前付きパイプで提供し、すべて読まれたら Done を閉じる
+func TestPullEphemeralCore_FIFO(t *testing.T) {
+	fs := &mockFileSystem{}
+
+	files, err := PullEphemeralCore("/work/web", "web", fs, newTestBwClient(t, ephemeralFiles), &config.Config{}, nil, &mockLogger{},
+		EphemeralOptions{BaseDir: "/tmp", Mode: EphemeralFIFO, Reads: 2, Pull: PullOptions{Files: []string{".env.local"}, As: ".env"}})
+
+	require.NoError(t, err)
+	require.Len(t, fs.pipes, 1)
+	pipe := fs.pipes[filepath.Join(files.Dir, ".env")]
+	require.NotNil(t, pipe)
+	assert.Equal(t, "B=2", string(pipe.data))
+	assert.Equal(t, 2, pipe.reads)
+	assert.Empty(t, fs.writtenFiles)
+
+	close(pipe.done)
+	pipe.closed = true
+	<-files.Done()
+
+	require.NoError(t, files.Cleanup())
+	assert.Equal(t, []string{files.Dir}, fs.removedPaths)
+}
+
+// 異常系: パイプを作れない場合は作ったディレクトリを削除する
+func TestPullEphemeralCore_PipeError(t *testing.T) {
+	fs := &mockFileSystem{pipeErr: errors.New("operation not permitted")}
+
+	_, err := PullEphemeralCore("/work/web", "web", fs, newTestBwClient(t, ephemeralFiles), &config.Config{}, nil, &mockLogger{},
+		EphemeralOptions{BaseDir: "/tmp", Mode: EphemeralFIFO})
+
+	assert.ErrorContains(t, err, "failed to serve .env: operation not permitted")
+	require.Len(t, fs.removedPaths, 1)
+	assert.True(t, strings.HasPrefix(fs.removedPaths[0], "/tmp/bwsf-"))
+}
+
+// 異常系: 不正なモードとプロジェクトが見つからない場合
+func TestPullEphemeralCore_Errors(t *testing.T) {
+	_, err := PullEphemeralCore("/work/web", "web", &mockFileSystem{}, newTestBwClient(t, ephemeralFiles), &config.Config{}, nil, &mockLogger{},
+		EphemeralOptions{BaseDir: "/tmp", Mode: "disk"})
+	assert.ErrorContains(t, err, "invalid ephemeral mode: disk")
+
+	fs := &mockFileSystem{}
+	_, err = PullEphemeralCore("/work/web", "web", fs, &mockBwClient{folderID: "folder-123"}, &config.Config{}, nil, &mockLogger{},
+		EphemeralOptions{BaseDir: "/tmp"})
+	assert.ErrorContains(t, err, "item 'web' not found")
+	assert.Empty(t, fs.writtenFiles)
+}
@@ -0,0 +1,275 @@
+package core
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"bwsf/src/config"
+)
+
+const (
+	// DefaultFollowInterval は保管庫を確認する間隔の既定値です。
+	DefaultFollowInterval = 30 * time.Second
+	// DefaultFollowMaxBackoff は確認に失敗した際に間隔を延ばす上限の既定値です。
+	DefaultFollowMaxBackoff = 5 * time.Minute
+)
+
+// FollowOptions は FollowEnvCore の設定です。
+type FollowOptions struct {
+	// Interval は保管庫のアイテムの更新日時を確認する間隔です。0 の場合は DefaultFollowInterval です。
+	Interval time.Duration
+	// MaxBackoff は確認に失敗するたびに倍にする間隔の上限です。0 の場合は DefaultFollowMaxBackoff です。
+	MaxBackoff time.Duration
+	// Pull は書き出しの設定です。StatePath が空でなければ、前回の同期以降にローカルで編集されたファイルは上書きしません。
+	Pull PullOptions
+	// Start は最初の pull の後に 1 回呼ばれます（子プロセスの起動など）。nil の場合は呼びません。
+	Start func() error
+	// Reload は変更を書き出した後に、書き出したファイル名を渡して呼ばれます。エラーを返すと追従を終了します。
+	Reload func(files []string) error
+	// After は時間の経過を待つ関数です。nil の場合は time.After を使います（テスト用）。
+	After func(time.Duration) <-chan time.Time
+}
+
+// follower は追従中の保管庫の状態です。
+type follower struct {
+	dir, projectName string
+	fs               FileSystem
+	bw               BwClient
+	cfg              *config.Config
+	promptPassword   func() (string, error)
+	logger           Logger
+	opts             FollowOptions
+
+	itemID   string       // 最後に取得したアイテムの ID（空の場合は名前で探す）
+	revision string       // 最後に反映したアイテムの更新日時
+	current  MultiEnvData // 最後に反映した保管庫の内容
+}
+
+// FollowEnvCore は dir にプロジェクトの .env ファイルを pull し、保管庫のアイテムの更新日時を定期的に確認して、
+// 変わったファイルを確認なしで書き出し直します。変更はキー名のみをログに出力します。
+// 確認に失敗した場合は間隔を倍にして（MaxBackoff まで）続け、成功すると元の間隔に戻します。
+// 最初の pull に失敗した場合はエラーを返します。ctx が終了すると nil を返します。
+func FollowEnvCore(
+	ctx context.Context,
+	dir, projectName string,
+	fs FileSystem,
+	bw BwClient,
+	cfg *config.Config,
+	promptPassword func() (string, error),
+	logger Logger,
+	opts FollowOptions,
+) error {
+	after := opts.After
+	if after == nil {
+		after = time.After
+	}
+	interval := opts.Interval
+	if interval <= 0 {
+		interval = DefaultFollowInterval
+	}
+	maxBackoff := opts.MaxBackoff
+	if maxBackoff <= 0 {
+		maxBackoff = DefaultFollowMaxBackoff
+	}
+	maxBackoff = max(maxBackoff, interval)
+
+	f := &follower{
+		dir:            dir,
+		projectName:    projectName,
+		fs:             fs,
+		bw:             bw,
+		cfg:            cfg,
+		promptPassword: promptPassword,
+		logger:         logger,
+		opts:           opts,
+		current:        make(MultiEnvData),
+	}
+	written, err := f.poll(true)
+	if err != nil {
+		return err
+	}
+	logger.Info("Pulled ", len(written), " env file(s) from ", projectName)
+	if opts.Start != nil {
+		if err := opts.Start(); err != nil {
+			return err
+		}
+	}
+
+	wait := interval
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-after(wait):
+		}
+
+		written, err := f.poll(false)
+		if err != nil {
+			wait = min(wait*2, maxBackoff)
+			logger.Error(err.Error(), "; retrying in ", wait)
+			continue
+		}
+		wait = interval
+		if len(written) > 0 && opts.Reload != nil {
+			if err := opts.Reload(written); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+// poll は保管庫を同期してアイテムの更新日時を確認し、変わっていれば変更のあったファイルを書き出します。
+// first が true の場合は保管庫のすべてのファイルを書き出し、キーの一覧は出力しません。
+func (f *follower) poll(first bool) ([]string, error) {
+	item, err := f.fetchItem()
+	if err != nil {
+		return nil, err
+	}
+	if !first && item.RevisionDate == f.revision {
+		return nil, nil
+	}
+	next, err := decodeStoredNotes(item.Notes)
+	if err != nil {
+		return nil, err
+	}
+
+	changes := diffKeys(f.current, next)
+	var names []string
+	for name := range changes {
+		names = append(names, name)
+	}
+	if first {
+		names = names[:0]
+		for name := range next {
+			names = append(names, name)
+		}
+	}
+	sortFileNames(names)
+
+	if !first {
+		if len(names) == 0 {
+			f.logger.Info("The vault item changed, but no env keys did")
+		}
+		for _, line := range watchSummary(changes) {
+			f.logger.Info("Changed in the vault: ", line)
+		}
+		var removed []string
+		for name := range f.current {
+			if _, ok := next[name]; !ok {
+				removed = append(removed, name)
+			}
+		}
+		if len(removed) > 0 {
+			sortFileNames(removed)
+			f.logger.Warning("Removed from the vault (the local files are kept): ", strings.Join(removed, ", "))
+		}
+	}
+
+	targets, err := f.skipLocalEdits(names, next)
+	if err != nil {
+		return nil, err
+	}
+	if len(targets) > 0 {
+		pullOpts := f.opts.Pull
+		pullOpts.Files = targets
+		if !first {
+			// 追従中は子プロセスが端末を使っているため、.gitignore への追記は最初の pull でのみ確認する
+			pullOpts.ConfirmGitignore = nil
+		}
+		overwrite := func(path string) (bool, error) { return true, nil }
+		// 取得済みのアイテムから書き出す（保管庫から取得し直さない）
+		if err := writePulledFiles(f.dir, f.projectName, next, f.fs, f.bw, f.cfg, f.promptPassword, overwrite, f.logger, pullOpts); err != nil {
+			return nil, fmt.Errorf("pull failed: %w", err)
+		}
+		if !first {
+			f.logger.Info("Updated ", strings.Join(targets, ", "))
+		}
+	}
+
+	f.revision = item.RevisionDate
+	f.current = next
+	return targets, nil
+}
+
+// fetchItem は確認 1 回につき 1 度だけ保管庫を同期して、プロジェクトのアイテムを取得します。
+// ID が分かっていれば同期してから ID で取得し、分からなければ名前で探します（名前での取得は自身で同期するため、先に同期しません）。
+// ID で取得できない場合は、次の確認で名前から探し直します。
+func (f *follower) fetchItem() (*FullItem, error) {
+	if f.itemID == "" {
+		_, item, err := fetchProjectItem(f.projectName, f.bw, f.cfg, f.promptPassword, f.logger)
+		if err != nil {
+			return nil, err
+		}
+		if item == nil {
+			return nil, fmt.Errorf("item '%s' not found in dotenvs folder", f.projectName)
+		}
+		f.itemID = item.ID
+		return item, nil
+	}
+
+	if err := WithUnlockRetry(f.bw, f.cfg, f.promptPassword, f.logger, f.bw.Sync); err != nil {
+		return nil, fmt.Errorf("failed to sync: %w", err)
+	}
+	var item *FullItem
+	err := WithUnlockRetry(f.bw, f.cfg, f.promptPassword, f.logger, func() error {
+		var innerErr error
+		item, innerErr = f.bw.GetItemByID(f.itemID)
+		return innerErr
+	})
+	if err != nil || item == nil || item.Name != f.projectName {
+		f.itemID = ""
+		if err != nil {
+			return nil, fmt.Errorf("failed to get item: %w", err)
+		}
+		return nil, fmt.Errorf("item '%s' not found in dotenvs folder", f.projectName)
+	}
+	return item, nil
+}
+
+// skipLocalEdits は前回の同期以降にローカルで編集されたファイルを除いた names を返します。
+// 参照を展開して書き出したファイルは保管庫の内容と比べられないため、編集の有無を判定しません。
+func (f *follower) skipLocalEdits(names []string, next MultiEnvData) ([]string, error) {
+	if f.opts.Pull.StatePath == "" || len(names) == 0 {
+		return names, nil
+	}
+	state, err := LoadSyncState(f.fs, f.opts.Pull.StatePath)
+	if err != nil {
+		return nil, err
+	}
+	entry, ok := state.Dirs[filepath.Clean(f.dir)]
+	if !ok || entry.Project != f.projectName {
+		return names, nil
+	}
+	expanded := make(map[string]bool, len(entry.Expanded))
+	for _, name := range entry.Expanded {
+		expanded[name] = true
+	}
+
+	var targets, edited []string
+	for _, name := range names {
+		base, hasBase := entry.Files[name]
+		if hasBase && !expanded[name] {
+			path := filepath.Join(f.dir, name)
+			if info, err := f.fs.Stat(path); err == nil && !info.IsNotExist() {
+				content, err := f.fs.ReadFile(path)
+				if err != nil {
+					return nil, fmt.Errorf("failed to read %s: %w", path, err)
+				}
+				local := hashEnvData(stripInheritedBlocks(*parseEnvContent(content)))
+				if local != base && local != hashEnvData(next[name]) {
+					edited = append(edited, name)
+					continue
+				}
+			}
+		}
+		targets = append(targets, name)
+	}
+	if len(edited) > 0 {
+		f.logger.Warning("Not overwriting files edited locally since the last sync: ", strings.Join(edited, ", "),
+			". Run `bwsf status` and `bwsf pull` to resolve them")
+	}
+	return targets, nil
+}
@@ -0,0 +1,209 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"bwsf/src/config"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// followBwClient はアイテムを取得するたびに revisions の次のアイテムを返すモックです（最後のアイテムはそのまま返し続けます）。
+// syncErrs に Sync の回数（1 始まり）を指定すると、その回の Sync がエラーを返します。
+type followBwClient struct {
+	*mockBwClient
+	revisions []*FullItem
+	syncErrs  map[int]error
+	syncs     int
+	fetches   int
+}
+
+func (b *followBwClient) Sync() error {
+	b.syncs++
+	b.calls = append(b.calls, "Sync")
+	return b.syncErrs[b.syncs]
+}
+
+func (b *followBwClient) GetItemByName(folderID, name string) (*FullItem, error) {
+	b.calls = append(b.calls, "GetItemByName("+folderID+","+name+")")
+	return b.nextRevision(), nil
+}
+
+func (b *followBwClient) GetItemByID(id string) (*FullItem, error) {
+	b.calls = append(b.calls, "GetItemByID("+id+")")
+	return b.nextRevision(), nil
+}
+
+func (b *followBwClient) nextRevision() *FullItem {
+	b.fetches++
+	return b.revisions[min(b.fetches, len(b.revisions))-1]
+}
+
+func followItem(revision, notes string) *FullItem {
+	return &FullItem{ID: "item-1", Name: "web", Notes: notes, RevisionDate: revision}
+}
+
+// runFollow は FollowEnvCore を起動し、drive の後に停止して結果を返します。
+func runFollow(t *testing.T, fs FileSystem, bw BwClient, logger *mockLogger, opts FollowOptions, drive func(clock *fakeClock)) *fakeClock {
+	t.Helper()
+	clock := newFakeClock()
+	opts.After = clock.After
+	ctx, cancel := context.WithCancel(context.Background())
+	result := make(chan error, 1)
+	go func() {
+		result <- FollowEnvCore(ctx, "/work/web", "web", fs, bw, &config.Config{}, nil, logger, opts)
+	}()
+
+	drive(clock)
+	cancel()
+	select {
+	case err := <-result:
+		require.NoError(t, err)
+	case <-time.After(5 * time.Second):
+		t.Fatal("FollowEnvCore did not stop")
+	}
+	return clock
+}
+
+// 正常系: 起動時にすべて書き出し、更新日時が変わった時だけ変更のあったファイルを書き出して再読み込みする
+func TestFollowEnvCore_PullsChangedFiles(t *testing.T) {
+	fs := &mockFileSystem{}
+	bw := &followBwClient{mockBwClient: &mockBwClient{folderID: "folder-123"}, revisions: []*FullItem{
+		followItem("2026-01-01T00:00:00Z", `{".env":{"lines":["A=1"]},".env.test":{"lines":["T=1"]}}`),
+		followItem("2026-01-01T00:00:00Z", `{".env":{"lines":["A=1"]},".env.test":{"lines":["T=1"]}}`),
+		followItem("2026-01-02T00:00:00Z", `{".env":{"lines":["A=secret-two","B=secret-three"]},".env.test":{"lines":["T=1"]}}`),
+	}}
+	logger := &mockLogger{}
+	var events []string
+	opts := FollowOptions{
+		Interval: 10 * time.Second,
+		Start:    func() error { events = append(events, "start"); return nil },
+		Reload: func(files []string) error {
+			events = append(events, "reload "+strings.Join(files, ","))
+			return nil
+		},
+	}
+
+	clock := runFollow(t, fs, bw, logger, opts, func(clock *fakeClock) {
+		clock.tick <- time.Now()
+		clock.tick <- time.Now()
+	})
+
+	assert.Equal(t, []string{"start", "reload .env"}, events)
+	assert.Equal(t, []time.Duration{10 * time.Second, 10 * time.Second, 10 * time.Second}, clock.waits)
+	assert.Equal(t, "A=secret-two\nB=secret-three", string(fs.writtenFiles["/work/web/.env"]))
+	assert.Equal(t, "T=1", string(fs.writtenFiles["/work/web/.env.test"]))
+	assert.Contains(t, logger.infos, "Pulled 2 env file(s) from web")
+	assert.Contains(t, logger.infos, "Changed in the vault: .env: +B ~A")
+	assert.Contains(t, logger.infos, "Updated .env")
+	var checks []string
+	for _, call := range bw.calls {
+		if call == "Sync" || strings.HasPrefix(call, "GetItemBy") {
+			checks = append(checks, call)
+		}
+	}
+	// GetItemByName は自身で同期するため、最初の確認では Sync を呼ばない
+	assert.Equal(t, []string{
+		"GetItemByName(folder-123,web)",
+		"Sync", "GetItemByID(item-1)",
+		"Sync", "GetItemByID(item-1)",
+	}, checks, "each check syncs once and fetches the item once")
+	for _, line := range append(logger.infos, logger.warnings...) {
+		assert.NotContains(t, line, "secret", "values must not be logged")
+	}
+}
+
+// 正常系: 確認に失敗すると間隔を倍にして上限まで延ばし、成功すると元の間隔に戻す
+func TestFollowEnvCore_Backoff(t *testing.T) {
+	syncErr := errors.New("network is unreachable")
+	bw := &followBwClient{
+		mockBwClient: &mockBwClient{folderID: "folder-123"},
+		revisions:    []*FullItem{followItem("2026-01-01T00:00:00Z", `{".env":{"lines":["A=1"]}}`)},
+		syncErrs:     map[int]error{1: syncErr, 2: syncErr, 3: syncErr},
+	}
+	logger := &mockLogger{}
+	reloads := 0
+
+	clock := runFollow(t, &mockFileSystem{}, bw, logger, FollowOptions{
+		Interval:   10 * time.Second,
+		MaxBackoff: 30 * time.Second,
+		Reload:     func([]string) error { reloads++; return nil },
+	}, func(clock *fakeClock) {
+		for range 4 {
+			clock.tick <- time.Now()
+		}
+	})
+
+	assert.Equal(t, []time.Duration{10 * time.Second, 20 * time.Second, 30 * time.Second, 30 * time.Second, 10 * time.Second}, clock.waits)
+	require.Len(t, logger.errors, 3)
+	assert.Equal(t, "failed to sync: network is unreachable; retrying in 20s", logger.errors[0])
+	assert.Zero(t, reloads, "an unchanged revision does not reload")
+}
+
+// 正常系: 前回の同期以降にローカルで編集されたファイルは上書きせず、再読み込みもしない
+func TestFollowEnvCore_KeepsLocalEdits(t *testing.T) {
+	const notes = `{".env":{"lines":["A=1"]}}`
+	stored, err := decodeStoredNotes(notes)
+	require.NoError(t, err)
+	scratch := &mockFileSystem{}
+	require.NoError(t, recordSyncState(scratch, "/state.json", "/work/web", "web", stored, false))
+
+	fs := &mockFileSystem{
+		readContentMap: map[string][]byte{"/work/web/.env": []byte("A=local\n"), "/state.json": scratch.writtenFiles["/state.json"]},
+		statInfoMap:    map[string]FileInfo{"/state.json": &mockFileInfo{}, "/work/web/.env": &mockFileInfo{}},
+	}
+	bw := &followBwClient{mockBwClient: &mockBwClient{folderID: "folder-123"}, revisions: []*FullItem{
+		followItem("2026-01-01T00:00:00Z", notes),
+		followItem("2026-01-02T00:00:00Z", `{".env":{"lines":["A=2"]}}`),
+	}}
+	logger := &mockLogger{}
+	reloads := 0
+
+	runFollow(t, fs, bw, logger, FollowOptions{
+		Pull:   PullOptions{StatePath: "/state.json"},
+		Reload: func([]string) error { reloads++; return nil },
+	}, func(clock *fakeClock) {
+		clock.tick <- time.Now()
+	})
+
+	checkWarning(t, logger, "Not overwriting files edited locally since the last sync: .env")
+	assert.NotContains(t, fs.writtenFiles, "/work/web/.env")
+	assert.Zero(t, reloads)
+}
+
+// 異常系: pull と同じく Git ガードを適用し、abort モードでは追跡中のファイルを書き出さない
+func TestFollowEnvCore_GitGuard(t *testing.T) {
+	fs := &mockFileSystem{}
+	bw := &followBwClient{mockBwClient: &mockBwClient{folderID: "folder-123"}, revisions: []*FullItem{
+		followItem("2026-01-01T00:00:00Z", `{".env":{"lines":["A=1"]}}`),
+	}}
+	git := &mockGitInspector{root: "/work/web", tracked: map[string]bool{"/work/web/.env": true}}
+	started := false
+
+	err := FollowEnvCore(context.Background(), "/work/web", "web", fs, bw, &config.Config{}, nil, &mockLogger{}, FollowOptions{
+		Pull:  PullOptions{Git: git, GitGuard: GitGuardAbort},
+		Start: func() error { started = true; return nil },
+	})
+
+	assert.ErrorIs(t, err, ErrGitGuard)
+	assert.Empty(t, fs.writtenFiles)
+	assert.False(t, started)
+}
+
+// 異常系: 最初の pull に失敗した場合は子プロセスを起動せずにエラーを返す
+func TestFollowEnvCore_NotFound(t *testing.T) {
+	bw := &followBwClient{mockBwClient: &mockBwClient{folderID: "folder-123"}, revisions: []*FullItem{nil}}
+	started := false
+
+	err := FollowEnvCore(context.Background(), "/work/web", "web", &mockFileSystem{}, bw, &config.Config{}, nil, &mockLogger{}, FollowOptions{
+		Start: func() error { started = true; return nil },
+	})
+
+	assert.ErrorContains(t, err, "item 'web' not found in dotenvs folder")
+	assert.False(t, started)
+}
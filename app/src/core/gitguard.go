@@ -0,0 +1,219 @@
+package core
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"bwsf/src/config"
+)
+
+// GitInspector は Git 作業ツリーの状態を調べるインターフェースです。
+type GitInspector interface {
+	// WorkTreeRoot は dir を含む作業ツリーのルートを返します。作業ツリー外の場合は "" を返します。
+	WorkTreeRoot(dir string) (string, error)
+	// IsTracked は path が現在インデックスで追跡されているかを返します。
+	IsTracked(path string) (bool, error)
+	// IsIgnored は path が .gitignore 等で無視されているかを返します。
+	IsIgnored(path string) (bool, error)
+	// InHistory は path がいずれかのコミットに含まれたことがあるかを返します。
+	InHistory(path string) (bool, error)
+}
+
+// Git ガードのモード
+const (
+	GitGuardAbort = "abort" // 追跡・未無視のファイルがあれば中断
+	GitGuardWarn  = "warn"  // 警告のみ（デフォルト）
+	GitGuardOff   = "off"   // チェックしない
+)
+
+// ErrGitGuard は Git ガードにより処理が中断された場合に返されます。
+var ErrGitGuard = fmt.Errorf("refusing to write env files")
+
+// resolveGitGuardMode はプロジェクト設定からガードのモードを決定します。
+// 既存の pull を壊さないよう、未設定の場合は警告のみです（abort は .bwsf.json か --git-guard で明示）。
+func resolveGitGuardMode(projectCfg *config.ProjectConfig) string {
+	if projectCfg == nil || projectCfg.GitGuard == "" {
+		return GitGuardWarn
+	}
+	return projectCfg.GitGuard
+}
+
+// guardPullTargets は pull で書き出すファイルが Git に追跡されていないか、
+// .gitignore で無視されているかを確認します。
+// 無視されていないファイルがある場合は confirmGitignore で .gitignore へのパターン追記を提案します。
+func guardPullTargets(
+	outputDir string,
+	fileNames []string,
+	mode string,
+	envPatterns []string,
+	git GitInspector,
+	fs FileSystem,
+	confirmGitignore func(gitignorePath string, patterns []string) (bool, error),
+	logger Logger,
+) error {
+	if git == nil || mode == GitGuardOff {
+		return nil
+	}
+
+	root, err := git.WorkTreeRoot(outputDir)
+	if err != nil {
+		return fmt.Errorf("failed to inspect git work tree: %w", err)
+	}
+	if root == "" {
+		return nil // Git 管理外
+	}
+
+	var tracked, unignored []string
+	for _, name := range fileNames {
+		path := filepath.Join(outputDir, name)
+
+		isTracked, err := git.IsTracked(path)
+		if err != nil {
+			return fmt.Errorf("failed to check whether %s is tracked: %w", name, err)
+		}
+		if isTracked {
+			tracked = append(tracked, name)
+			continue
+		}
+
+		isIgnored, err := git.IsIgnored(path)
+		if err != nil {
+			return fmt.Errorf("failed to check whether %s is ignored: %w", name, err)
+		}
+		if !isIgnored {
+			unignored = append(unignored, name)
+		}
+	}
+
+	if len(tracked) > 0 {
+		msg := fmt.Sprintf("%s tracked by git; remove with `git rm --cached` first", strings.Join(tracked, ", "))
+		if mode == GitGuardAbort {
+			return fmt.Errorf("%w: %s", ErrGitGuard, msg)
+		}
+		logger.Warning("[GIT] ", msg)
+	}
+
+	if len(unignored) == 0 {
+		return nil
+	}
+
+	gitignorePath := filepath.Join(root, ".gitignore")
+	patterns := gitignorePatterns(root, outputDir, envPatterns)
+	if confirmGitignore != nil {
+		confirmed, err := confirmGitignore(gitignorePath, patterns)
+		if err != nil {
+			return fmt.Errorf("failed to confirm .gitignore update: %w", err)
+		}
+		if confirmed {
+			if err := appendGitignore(fs, gitignorePath, patterns); err != nil {
+				return err
+			}
+			logger.Info("Added ", strings.Join(patterns, " "), " to ", gitignorePath)
+			return nil
+		}
+	}
+
+	msg := fmt.Sprintf("%s not covered by .gitignore", strings.Join(unignored, ", "))
+	if mode == GitGuardAbort {
+		return fmt.Errorf("%w: %s", ErrGitGuard, msg)
+	}
+	logger.Warning("[GIT] ", msg)
+	return nil
+}
+
+// warnTrackedPushFiles は push するファイルが Git に追跡されている、
+// または履歴に含まれている場合に警告します。push 自体は中断しません。
+func warnTrackedPushFiles(fromDir string, fileNames []string, git GitInspector, logger Logger) {
+	if git == nil {
+		return
+	}
+	root, err := git.WorkTreeRoot(fromDir)
+	if err != nil || root == "" {
+		return
+	}
+
+	for _, name := range fileNames {
+		path := filepath.Join(fromDir, name)
+		if tracked, err := git.IsTracked(path); err == nil && tracked {
+			logger.Warning("[GIT] ", name, " is tracked by git. Its secrets may already be in the repository.")
+			continue
+		}
+		if inHistory, err := git.InHistory(path); err == nil && inHistory {
+			logger.Warning("[GIT] ", name, " appears in git history. Consider rotating the secrets it contained.")
+		}
+	}
+}
+
+// gitignorePatterns は outputDir 内の .env* と .bwsf.json の env_patterns を無視し、.example ファイルは残すパターンを返します。
+func gitignorePatterns(root, outputDir string, envPatterns []string) []string {
+	prefix := ""
+	if rel, err := filepath.Rel(root, outputDir); err == nil && rel != "." && !strings.HasPrefix(rel, "..") {
+		prefix = "/" + filepath.ToSlash(rel) + "/"
+	}
+	patterns := []string{prefix + DefaultEnvPattern}
+	for _, pattern := range envPatterns {
+		switch {
+		case pattern == DefaultEnvPattern:
+			continue
+		case strings.Contains(pattern, "/"):
+			// "/" を含むパターンは env_patterns と同じくプロジェクトのディレクトリからのパス
+			dir := prefix
+			if dir == "" {
+				dir = "/"
+			}
+			patterns = append(patterns, dir+strings.TrimPrefix(pattern, "/"))
+		case prefix == "":
+			patterns = append(patterns, pattern)
+		default:
+			patterns = append(patterns, prefix+"**/"+pattern)
+		}
+	}
+	return append(patterns, "!"+prefix+".env*.example*")
+}
+
+// appendGitignore は .gitignore に patterns のうち未記載のものを追記します。
+func appendGitignore(fs FileSystem, gitignorePath string, patterns []string) error {
+	var content string
+	info, err := fs.Stat(gitignorePath)
+	if err != nil {
+		return fmt.Errorf("failed to stat .gitignore: %w", err)
+	}
+	if !info.IsNotExist() {
+		data, err := fs.ReadFile(gitignorePath)
+		if err != nil {
+			return fmt.Errorf("failed to read .gitignore: %w", err)
+		}
+		content = string(data)
+	}
+
+	existing := make(map[string]bool)
+	for _, line := range strings.Split(content, "\n") {
+		existing[strings.TrimSpace(line)] = true
+	}
+
+	var b strings.Builder
+	b.WriteString(content)
+	if content != "" && !strings.HasSuffix(content, "\n") {
+		b.WriteString("\n")
+	}
+	added := false
+	for _, p := range patterns {
+		if existing[p] {
+			continue
+		}
+		if !added {
+			b.WriteString("# bwsf: keep env files out of git\n")
+			added = true
+		}
+		b.WriteString(p + "\n")
+	}
+	if !added {
+		return nil
+	}
+
+	if err := fs.WriteFile(gitignorePath, []byte(b.String()), 0644); err != nil {
+		return fmt.Errorf("failed to write .gitignore: %w", err)
+	}
+	return nil
+}
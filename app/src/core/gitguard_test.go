@@ -0,0 +1,235 @@
+package core
+
+import (
+	"errors"
+	"testing"
+
+	"bwsf/src/config"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// mockGitInspector はテスト用の GitInspector 実装です。
+type mockGitInspector struct {
+	root    string
+	rootErr error
+	tracked map[string]bool
+	ignored map[string]bool
+	history map[string]bool
+}
+
+func (g *mockGitInspector) WorkTreeRoot(dir string) (string, error) {
+	return g.root, g.rootErr
+}
+
+func (g *mockGitInspector) IsTracked(path string) (bool, error) {
+	return g.tracked[path], nil
+}
+
+func (g *mockGitInspector) IsIgnored(path string) (bool, error) {
+	return g.ignored[path], nil
+}
+
+func (g *mockGitInspector) InHistory(path string) (bool, error) {
+	return g.history[path], nil
+}
+
+func pullBwClient() *mockBwClient {
+	return &mockBwClient{
+		folderID:   "folder-123",
+		itemByName: &FullItem{ID: "item-456", Name: "my-project", Notes: `{".env":{"lines":["A=1"]},".env.local":{"lines":["B=2"]}}`},
+	}
+}
+
+func noConfirm(path string) (bool, error) { return true, nil }
+
+// =============================================================================
+// guardPullTargets / PullEnvCoreWithOptions の Git ガードのテスト
+// =============================================================================
+
+// 正常系: Git 管理外のディレクトリでは何もチェックしない
+func TestPullGitGuard_OutsideWorkTree(t *testing.T) {
+	fs := &mockFileSystem{}
+	git := &mockGitInspector{}
+
+	err := PullEnvCoreWithOptions("/work/app", "my-project", fs, pullBwClient(), &config.Config{}, nil, noConfirm, &mockLogger{}, PullOptions{Git: git})
+
+	require.NoError(t, err)
+	assert.Len(t, fs.writtenFiles, 2)
+}
+
+// 正常系: すべてのファイルが無視されていれば書き出す
+func TestPullGitGuard_AllIgnored(t *testing.T) {
+	fs := &mockFileSystem{}
+	git := &mockGitInspector{
+		root:    "/work/app",
+		ignored: map[string]bool{"/work/app/.env": true, "/work/app/.env.local": true},
+	}
+
+	err := PullEnvCoreWithOptions("/work/app", "my-project", fs, pullBwClient(), &config.Config{}, nil, noConfirm, &mockLogger{}, PullOptions{Git: git})
+
+	require.NoError(t, err)
+	assert.Len(t, fs.writtenFiles, 2)
+}
+
+// 異常系: abort モードでは追跡中のファイルがあれば中断する
+func TestPullGitGuard_TrackedAborts(t *testing.T) {
+	fs := &mockFileSystem{}
+	git := &mockGitInspector{
+		root:    "/work/app",
+		tracked: map[string]bool{"/work/app/.env": true},
+		ignored: map[string]bool{"/work/app/.env.local": true},
+	}
+
+	err := PullEnvCoreWithOptions("/work/app", "my-project", fs, pullBwClient(), &config.Config{}, nil, noConfirm, &mockLogger{}, PullOptions{Git: git, GitGuard: GitGuardAbort})
+
+	require.Error(t, err)
+	assert.True(t, errors.Is(err, ErrGitGuard))
+	assert.Contains(t, err.Error(), ".env tracked by git")
+	assert.Empty(t, fs.writtenFiles)
+}
+
+// 正常系: 設定がなければ warn モードで、警告して書き出す
+func TestPullGitGuard_DefaultWarns(t *testing.T) {
+	fs := &mockFileSystem{}
+	logger := &mockLogger{}
+	git := &mockGitInspector{root: "/work/app", tracked: map[string]bool{"/work/app/.env": true}}
+
+	err := PullEnvCoreWithOptions("/work/app", "my-project", fs, pullBwClient(), &config.Config{}, nil, noConfirm, logger, PullOptions{Git: git})
+
+	require.NoError(t, err)
+	assert.Len(t, fs.writtenFiles, 2)
+	require.Len(t, logger.warnings, 2)
+	assert.Contains(t, logger.warnings[0], "tracked by git")
+	assert.Contains(t, logger.warnings[1], ".env.local not covered by .gitignore")
+}
+
+// 異常系: .bwsf.json の git_guard: abort で中断を有効にする
+func TestPullGitGuard_AbortFromProjectConfig(t *testing.T) {
+	fs := &mockFileSystem{
+		statInfoMap:    map[string]FileInfo{"/work/app/.bwsf.json": &mockFileInfo{notExist: false}},
+		readContentMap: map[string][]byte{"/work/app/.bwsf.json": []byte(`{"git_guard":"abort"}`)},
+	}
+	git := &mockGitInspector{root: "/work/app", ignored: map[string]bool{"/work/app/.env": true}}
+
+	err := PullEnvCoreWithOptions("/work/app", "my-project", fs, pullBwClient(), &config.Config{}, nil, noConfirm, &mockLogger{}, PullOptions{Git: git})
+
+	require.Error(t, err)
+	assert.True(t, errors.Is(err, ErrGitGuard))
+	assert.Contains(t, err.Error(), ".env.local not covered by .gitignore")
+	assert.Empty(t, fs.writtenFiles)
+}
+
+// 正常系: .bwsf.json の git_guard: off でチェックを無効にする
+func TestPullGitGuard_OffFromProjectConfig(t *testing.T) {
+	fs := &mockFileSystem{
+		statInfoMap:    map[string]FileInfo{"/work/app/.bwsf.json": &mockFileInfo{notExist: false}},
+		readContentMap: map[string][]byte{"/work/app/.bwsf.json": []byte(`{"git_guard":"off"}`)},
+	}
+	git := &mockGitInspector{root: "/work/app", tracked: map[string]bool{"/work/app/.env": true}}
+
+	err := PullEnvCoreWithOptions("/work/app", "my-project", fs, pullBwClient(), &config.Config{}, nil, noConfirm, &mockLogger{}, PullOptions{Git: git})
+
+	require.NoError(t, err)
+	assert.Len(t, fs.writtenFiles, 2)
+}
+
+// 正常系: 確認で承諾すると .gitignore にパターンを追記して書き出す
+func TestPullGitGuard_AppendGitignore(t *testing.T) {
+	fs := &mockFileSystem{
+		statInfoMap:    map[string]FileInfo{"/work/.gitignore": &mockFileInfo{notExist: false}},
+		readContentMap: map[string][]byte{"/work/.gitignore": []byte("node_modules")},
+	}
+	git := &mockGitInspector{root: "/work"}
+	var askedPath string
+	confirm := func(path string, patterns []string) (bool, error) {
+		askedPath = path
+		return true, nil
+	}
+
+	err := PullEnvCoreWithOptions("/work/app", "my-project", fs, pullBwClient(), &config.Config{}, nil, noConfirm, &mockLogger{}, PullOptions{Git: git, ConfirmGitignore: confirm})
+
+	require.NoError(t, err)
+	assert.Equal(t, "/work/.gitignore", askedPath)
+	assert.Equal(t, "node_modules\n# bwsf: keep env files out of git\n/app/.env*\n!/app/.env*.example*\n", string(fs.writtenFiles["/work/.gitignore"]))
+	assert.Contains(t, fs.writtenFiles, "/work/app/.env")
+}
+
+// 異常系: abort モードで確認を拒否すると中断する
+func TestPullGitGuard_DeclineGitignore(t *testing.T) {
+	fs := &mockFileSystem{}
+	git := &mockGitInspector{root: "/work/app"}
+	confirm := func(path string, patterns []string) (bool, error) { return false, nil }
+
+	err := PullEnvCoreWithOptions("/work/app", "my-project", fs, pullBwClient(), &config.Config{}, nil, noConfirm, &mockLogger{}, PullOptions{Git: git, GitGuard: GitGuardAbort, ConfirmGitignore: confirm})
+
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "not covered by .gitignore")
+	assert.Empty(t, fs.writtenFiles)
+}
+
+// 正常系: warn モードで確認を拒否すると警告して書き出す
+func TestPullGitGuard_DeclineGitignoreWarns(t *testing.T) {
+	fs := &mockFileSystem{}
+	logger := &mockLogger{}
+	git := &mockGitInspector{root: "/work/app"}
+	confirm := func(path string, patterns []string) (bool, error) { return false, nil }
+
+	err := PullEnvCoreWithOptions("/work/app", "my-project", fs, pullBwClient(), &config.Config{}, nil, noConfirm, logger, PullOptions{Git: git, ConfirmGitignore: confirm})
+
+	require.NoError(t, err)
+	assert.Len(t, fs.writtenFiles, 2)
+	checkWarning(t, logger, "not covered by .gitignore")
+}
+
+// =============================================================================
+// gitignorePatterns / appendGitignore のテスト
+// =============================================================================
+
+// 正常系: ルート直下ではプレフィックスなし
+func TestGitignorePatterns_Root(t *testing.T) {
+	assert.Equal(t, []string{".env*", "!.env*.example*"}, gitignorePatterns("/work", "/work", []string{DefaultEnvPattern}))
+}
+
+// 正常系: env_patterns も無視し、"/" を含むパターンはプロジェクトのディレクトリからのパスにする
+func TestGitignorePatterns_EnvPatterns(t *testing.T) {
+	patterns := []string{DefaultEnvPattern, "secrets.env", "config/*.key"}
+
+	assert.Equal(t, []string{".env*", "secrets.env", "/config/*.key", "!.env*.example*"}, gitignorePatterns("/work", "/work", patterns))
+	assert.Equal(t, []string{"/apps/web/.env*", "/apps/web/**/secrets.env", "/apps/web/config/*.key", "!/apps/web/.env*.example*"},
+		gitignorePatterns("/work", "/work/apps/web", patterns))
+}
+
+// 正常系: 既に記載済みのパターンは追記しない
+func TestAppendGitignore_AlreadyPresent(t *testing.T) {
+	fs := &mockFileSystem{
+		statInfoMap:    map[string]FileInfo{"/work/.gitignore": &mockFileInfo{notExist: false}},
+		readContentMap: map[string][]byte{"/work/.gitignore": []byte(".env*\n!.env*.example*\n")},
+	}
+
+	err := appendGitignore(fs, "/work/.gitignore", []string{".env*", "!.env*.example*"})
+
+	require.NoError(t, err)
+	assert.Empty(t, fs.writtenFiles)
+}
+
+// =============================================================================
+// warnTrackedPushFiles のテスト
+// =============================================================================
+
+// 正常系: 追跡中・履歴に含まれるファイルを警告する
+func TestWarnTrackedPushFiles(t *testing.T) {
+	logger := &mockLogger{}
+	git := &mockGitInspector{
+		root:    "/work",
+		tracked: map[string]bool{"/work/.env": true},
+		history: map[string]bool{"/work/.env.staging": true},
+	}
+
+	warnTrackedPushFiles("/work", []string{".env", ".env.local", ".env.staging"}, git, logger)
+
+	require.Len(t, logger.warnings, 2)
+	assert.Contains(t, logger.warnings[0], ".env is tracked by git")
+	assert.Contains(t, logger.warnings[1], ".env.staging appears in git history")
+}
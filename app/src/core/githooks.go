@@ -0,0 +1,360 @@
+package core
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"bwsf/src/config"
+)
+
+// GitRepository は git フックで使うリポジトリの操作を抽象化するインターフェースです。
+type GitRepository interface {
+	// HooksDir は dir を含むリポジトリのフックディレクトリを返します（core.hooksPath を考慮）。
+	HooksDir(dir string) (string, error)
+	// StagedFiles はインデックスで追加・変更されたファイルを作業ツリーのルートからの相対パスで返します。
+	StagedFiles(dir string) ([]string, error)
+	// StagedContent はインデックス上のファイルの内容を返します。path はルートからの相対パスです。
+	StagedContent(dir, path string) ([]byte, error)
+}
+
+// bwsf が管理する git フック
+const (
+	GitHookPreCommit    = "pre-commit"
+	GitHookPostCheckout = "post-checkout"
+	GitHookPostMerge    = "post-merge"
+)
+
+// gitHookMarker は bwsf が書き出したフックを識別する行です。
+const gitHookMarker = "# bwsf managed hook"
+
+// chainedHookSuffix は既存のフックを退避する際の接尾辞です。bwsf のフックから先に実行されます。
+const chainedHookSuffix = ".bwsf-chained"
+
+// ErrHookLocked は git フックの実行中に保管庫がロックされている場合に返されます。
+// git commit / checkout を止めないよう、フックではマスターパスワードを尋ねません。
+var ErrHookLocked = errors.New("vault is locked; git hooks do not prompt for the master password (export BW_SESSION from `bw unlock --raw` to enable the check)")
+
+// HookPasswordPrompt は git フックで使うパスワード入力の代わりです。入力を求めずに ErrHookLocked を返すため、
+// ロック中は PreCommitCore がファイル名の検査のみ行い、post-checkout の確認は警告して省きます。
+func HookPasswordPrompt() (string, error) {
+	return "", ErrHookLocked
+}
+
+// minSecretLength より短い値は誤検出が多いため、コミット時の検査対象にしません。
+const minSecretLength = 8
+
+// GitHooksOptions は InstallGitHooksCore の設定です。
+type GitHooksOptions struct {
+	// Project はフックが検査するプロジェクト名です。
+	Project string
+	// Dir は .env ファイルのあるディレクトリで、作業ツリーのルートからの相対パスです。
+	Dir string
+	// StatusCheck が true の場合、post-checkout / post-merge で保管庫の方が新しいファイルを警告します。
+	StatusCheck bool
+}
+
+// InstallGitHooksCore は hooksDir に bwsf のフックを書き出し、書き出したフック名を返します。
+// bwsf 以外の既存のフックは <name>.bwsf-chained に退避し、bwsf のフックから先に実行します。
+func InstallGitHooksCore(fs FileSystem, hooksDir string, opts GitHooksOptions, logger Logger) ([]string, error) {
+	names := []string{GitHookPreCommit}
+	if opts.StatusCheck {
+		names = append(names, GitHookPostCheckout, GitHookPostMerge)
+	}
+
+	if err := fs.MkdirAll(hooksDir, 0755); err != nil {
+		return nil, fmt.Errorf("failed to create hooks directory: %w", err)
+	}
+
+	for _, name := range names {
+		hookPath := filepath.Join(hooksDir, name)
+		existing, managed, err := readGitHook(fs, hookPath)
+		if err != nil {
+			return nil, err
+		}
+		if existing != nil && !managed {
+			chainedPath := hookPath + chainedHookSuffix
+			if info, err := fs.Stat(chainedPath); err == nil && !info.IsNotExist() {
+				return nil, fmt.Errorf("cannot install %s: both %s and %s exist", name, name, filepath.Base(chainedPath))
+			}
+			if err := fs.WriteFile(chainedPath, existing, 0755); err != nil {
+				return nil, fmt.Errorf("failed to keep the existing %s hook: %w", name, err)
+			}
+			logger.Info("Existing ", name, " hook kept as ", filepath.Base(chainedPath), " and run first")
+		}
+		if err := fs.WriteFile(hookPath, []byte(gitHookScript(name, opts)), 0755); err != nil {
+			return nil, fmt.Errorf("failed to write %s hook: %w", name, err)
+		}
+	}
+	return names, nil
+}
+
+// UninstallGitHooksCore は bwsf のフックを削除し、退避していたフックを元に戻します。削除したフック名を返します。
+func UninstallGitHooksCore(fs FileSystem, hooksDir string, logger Logger) ([]string, error) {
+	var removed []string
+	for _, name := range []string{GitHookPreCommit, GitHookPostCheckout, GitHookPostMerge} {
+		hookPath := filepath.Join(hooksDir, name)
+		_, managed, err := readGitHook(fs, hookPath)
+		if err != nil {
+			return nil, err
+		}
+		if !managed {
+			continue
+		}
+
+		chainedPath := hookPath + chainedHookSuffix
+		chained, _, err := readGitHook(fs, chainedPath)
+		if err != nil {
+			return nil, err
+		}
+		if chained != nil {
+			if err := fs.WriteFile(hookPath, chained, 0755); err != nil {
+				return nil, fmt.Errorf("failed to restore %s hook: %w", name, err)
+			}
+			if err := fs.Remove(chainedPath); err != nil {
+				return nil, fmt.Errorf("failed to remove %s: %w", filepath.Base(chainedPath), err)
+			}
+			logger.Info("Restored the previous ", name, " hook")
+		} else if err := fs.Remove(hookPath); err != nil {
+			return nil, fmt.Errorf("failed to remove %s hook: %w", name, err)
+		}
+		removed = append(removed, name)
+	}
+	return removed, nil
+}
+
+// readGitHook はフックの内容と bwsf が書き出したものかを返します。存在しない場合は nil を返します。
+func readGitHook(fs FileSystem, hookPath string) ([]byte, bool, error) {
+	info, err := fs.Stat(hookPath)
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to stat %s: %w", hookPath, err)
+	}
+	if info.IsNotExist() {
+		return nil, false, nil
+	}
+	content, err := fs.ReadFile(hookPath)
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to read %s: %w", hookPath, err)
+	}
+	return content, bytes.Contains(content, []byte(gitHookMarker)), nil
+}
+
+// gitHookScript はフックのスクリプトです。git はフックを作業ツリーのルートで実行します。
+func gitHookScript(name string, opts GitHooksOptions) string {
+	dir := opts.Dir
+	if dir == "" {
+		dir = "."
+	}
+	return fmt.Sprintf(`#!/bin/sh
+%[1]s: %[2]s (remove with: bwsf hooks uninstall)
+chained="$(dirname "$0")/%[2]s%[3]s"
+if [ -x "$chained" ]; then
+  "$chained" "$@" || exit $?
+fi
+if ! command -v bwsf >/dev/null 2>&1; then
+  echo "bwsf: command not found, skipping the %[2]s check" >&2
+  exit 0
+fi
+exec bwsf hooks run %[2]s --project %[4]s --dir %[5]s -- "$@"
+`, gitHookMarker, name, chainedHookSuffix, ShellQuote(opts.Project), ShellQuote(filepath.ToSlash(dir)))
+}
+
+// =============================================================================
+// pre-commit
+// =============================================================================
+
+// StagedSecret はコミットを止める理由です。Key が空の場合は .env ファイル自体がステージされています。
+type StagedSecret struct {
+	Path string // ルートからの相対パス
+	Line int
+	File string // 値が保存されているファイル名
+	Key  string
+}
+
+// String は値を含まない説明を返します。
+func (s StagedSecret) String() string {
+	if s.Key == "" {
+		return fmt.Sprintf("%s: env files must not be committed", s.Path)
+	}
+	return fmt.Sprintf("%s:%d: contains the value of %s (%s)", s.Path, s.Line, s.Key, s.File)
+}
+
+// secretRef は保存された値の所在です。
+type secretRef struct {
+	File string
+	Key  string
+}
+
+// PreCommitCore はステージされたファイルを検査し、コミットしてはいけないものを返します。
+// push と同じパターン（.env* と dir の .bwsf.json の env_patterns、.example を除く）に一致するファイルと、
+// 保存されたプロジェクトの値を含むファイルが対象です。dir は root からのプロジェクトのディレクトリです。
+// 値は SHA-256 のハッシュ同士で比較し、平文のまま検索や表示はしません。
+// 保管庫を読めない場合（ロック中など）は警告してファイル名の検査のみ行います。
+func PreCommitCore(
+	root, dir, projectName string,
+	repo GitRepository,
+	fs FileSystem,
+	bw BwClient,
+	cfg *config.Config,
+	promptPassword func() (string, error),
+	logger Logger,
+) ([]StagedSecret, error) {
+	staged, err := repo.StagedFiles(root)
+	if err != nil {
+		return nil, err
+	}
+	if len(staged) == 0 {
+		return nil, nil
+	}
+	projectCfg, err := LoadProjectConfig(fs, filepath.Join(root, dir))
+	if err != nil {
+		return nil, err
+	}
+	patterns := envFilePatterns(projectCfg)
+
+	var findings []StagedSecret
+	var scan []string
+	for _, p := range staged {
+		if isStagedEnvFile(p, dir, patterns) {
+			findings = append(findings, StagedSecret{Path: p})
+			continue
+		}
+		scan = append(scan, p)
+	}
+
+	hashes, err := storedSecretHashes(projectName, bw, cfg, promptPassword, logger)
+	if err != nil {
+		logger.Warning("Skipping the secret value check: ", err.Error())
+		return findings, nil
+	}
+	if len(hashes) == 0 {
+		return findings, nil
+	}
+
+	for _, p := range scan {
+		content, err := repo.StagedContent(root, p)
+		if err != nil {
+			return nil, err
+		}
+		findings = append(findings, scanStagedContent(p, content, hashes)...)
+	}
+	return findings, nil
+}
+
+// isStagedEnvFile は root からの相対パス p が env ファイルかを返します。
+// 既定の .env* はリポジトリのどこでも、env_patterns はプロジェクトのディレクトリ（dir）の中でのみ一致させます。
+func isStagedEnvFile(p, dir string, patterns []string) bool {
+	if matchEnvFile(p, []string{DefaultEnvPattern}) {
+		return true
+	}
+	rel := p
+	if prefix := path.Clean(filepath.ToSlash(dir)); prefix != "." {
+		if !strings.HasPrefix(p, prefix+"/") {
+			return false
+		}
+		rel = strings.TrimPrefix(p, prefix+"/")
+	}
+	return matchEnvFile(rel, patterns)
+}
+
+// storedSecretHashes は保存されたプロジェクトの値のハッシュを返します。プロジェクトが無い場合は空です。
+func storedSecretHashes(
+	projectName string,
+	bw BwClient,
+	cfg *config.Config,
+	promptPassword func() (string, error),
+	logger Logger,
+) (map[[sha256.Size]byte]secretRef, error) {
+	_, item, err := fetchProjectItem(projectName, bw, cfg, promptPassword, logger)
+	if err != nil || item == nil {
+		return nil, err
+	}
+	multiData, err := decodeStoredNotes(item.Notes)
+	if err != nil {
+		return nil, err
+	}
+
+	var fileNames []string
+	for fileName := range multiData {
+		fileNames = append(fileNames, fileName)
+	}
+	sortFileNames(fileNames)
+
+	hashes := make(map[[sha256.Size]byte]secretRef)
+	for _, fileName := range fileNames {
+		for _, line := range multiData[fileName].Lines {
+			entry, ok := parseEnvLine(line)
+			if !ok || len(entry.Value) < minSecretLength || strings.Contains(entry.Value, "${") || strings.HasPrefix(entry.Value, "bw://") {
+				continue
+			}
+			hash := sha256.Sum256([]byte(entry.Value))
+			if _, seen := hashes[hash]; !seen {
+				hashes[hash] = secretRef{File: fileName, Key: entry.Key}
+			}
+		}
+	}
+	return hashes, nil
+}
+
+// scanStagedContent は content の各行をトークンに分け、ハッシュが保存された値と一致するものを返します。
+// 1 行につき 1 件のみ報告します。バイナリファイルは対象外です。
+func scanStagedContent(p string, content []byte, hashes map[[sha256.Size]byte]secretRef) []StagedSecret {
+	if bytes.IndexByte(content, 0) >= 0 {
+		return nil
+	}
+	var findings []StagedSecret
+	for i, line := range strings.Split(string(content), "\n") {
+		for _, candidate := range secretCandidates(line) {
+			if ref, ok := hashes[sha256.Sum256([]byte(candidate))]; ok {
+				findings = append(findings, StagedSecret{Path: p, Line: i + 1, File: ref.File, Key: ref.Key})
+				break
+			}
+		}
+	}
+	return findings
+}
+
+// secretCandidates は行を空白・引用符・括弧・区切り記号で分けたトークンと、
+// トークン内の "=" や ":" より後ろの部分（KEY=value、user:pass@host など）を返します。
+func secretCandidates(line string) []string {
+	tokens := strings.FieldsFunc(line, func(r rune) bool {
+		switch r {
+		case ' ', '\t', '\r', '"', '\'', '`', ',', ';', '(', ')', '[', ']', '{', '}', '<', '>':
+			return true
+		}
+		return false
+	})
+
+	var candidates []string
+	for _, token := range tokens {
+		if len(token) < minSecretLength {
+			continue
+		}
+		candidates = append(candidates, token)
+		for i := 0; i < len(token); i++ {
+			if (token[i] == '=' || token[i] == ':') && len(token)-i-1 >= minSecretLength {
+				candidates = append(candidates, token[i+1:])
+			}
+		}
+	}
+	return candidates
+}
+
+// =============================================================================
+// post-checkout / post-merge
+// =============================================================================
+
+// VaultNewerFiles は保管庫の方が新しい（ローカルに無い・保管庫のみ変更された）ファイル名を返します。
+func VaultNewerFiles(statuses []EnvFileStatus) []string {
+	var names []string
+	for _, s := range statuses {
+		if s.Status == StatusRemoteModified || s.Status == StatusRemoteOnly {
+			names = append(names, s.FileName)
+		}
+	}
+	return names
+}
@@ -0,0 +1,268 @@
+package core
+
+import (
+	"errors"
+	"testing"
+
+	"bwsf/src/config"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// mockGitRepository は GitRepository インターフェースのモック実装です。
+type mockGitRepository struct {
+	staged    []string
+	stagedErr error
+	contents  map[string][]byte
+}
+
+func (r *mockGitRepository) HooksDir(dir string) (string, error) {
+	return dir + "/.git/hooks", nil
+}
+
+func (r *mockGitRepository) StagedFiles(dir string) ([]string, error) {
+	return r.staged, r.stagedErr
+}
+
+func (r *mockGitRepository) StagedContent(dir, path string) ([]byte, error) {
+	return r.contents[path], nil
+}
+
+// =============================================================================
+// InstallGitHooksCore / UninstallGitHooksCore のテスト
+// =============================================================================
+
+// 正常系: pre-commit のみ書き出し、プロジェクトとディレクトリをクォートして埋め込む
+func TestInstallGitHooksCore(t *testing.T) {
+	fs := &mockFileSystem{}
+
+	names, err := InstallGitHooksCore(fs, "/repo/.git/hooks", GitHooksOptions{Project: "it's", Dir: "apps/web"}, &mockLogger{})
+
+	require.NoError(t, err)
+	assert.Equal(t, []string{GitHookPreCommit}, names)
+	script := string(fs.writtenFiles["/repo/.git/hooks/pre-commit"])
+	assert.Contains(t, script, gitHookMarker)
+	assert.Contains(t, script, `exec bwsf hooks run pre-commit --project 'it'\''s' --dir 'apps/web' -- "$@"`)
+	assert.NotContains(t, fs.writtenFiles, "/repo/.git/hooks/pre-commit.bwsf-chained")
+}
+
+// 正常系: --status-check では post-checkout / post-merge も書き出す
+func TestInstallGitHooksCore_StatusCheck(t *testing.T) {
+	fs := &mockFileSystem{}
+
+	names, err := InstallGitHooksCore(fs, "/repo/.git/hooks", GitHooksOptions{Project: "web", StatusCheck: true}, &mockLogger{})
+
+	require.NoError(t, err)
+	assert.Equal(t, []string{GitHookPreCommit, GitHookPostCheckout, GitHookPostMerge}, names)
+	assert.Contains(t, string(fs.writtenFiles["/repo/.git/hooks/post-merge"]), "--dir '.'")
+}
+
+// 正常系: 既存のフックは退避して先に実行し、再インストールでは退避し直さない
+func TestInstallGitHooksCore_ChainsExisting(t *testing.T) {
+	hook := "/repo/.git/hooks/pre-commit"
+	fs := &mockFileSystem{
+		statInfoMap:    map[string]FileInfo{hook: &mockFileInfo{}},
+		readContentMap: map[string][]byte{hook: []byte("#!/bin/sh\nlint\n")},
+	}
+	logger := &mockLogger{}
+
+	_, err := InstallGitHooksCore(fs, "/repo/.git/hooks", GitHooksOptions{Project: "web"}, logger)
+
+	require.NoError(t, err)
+	assert.Equal(t, "#!/bin/sh\nlint\n", string(fs.writtenFiles[hook+chainedHookSuffix]))
+	assert.Contains(t, string(fs.writtenFiles[hook]), gitHookMarker)
+	assert.Contains(t, logger.infos[0], "kept as pre-commit.bwsf-chained")
+
+	managed := &mockFileSystem{
+		statInfoMap:    map[string]FileInfo{hook: &mockFileInfo{}},
+		readContentMap: map[string][]byte{hook: fs.writtenFiles[hook]},
+	}
+	_, err = InstallGitHooksCore(managed, "/repo/.git/hooks", GitHooksOptions{Project: "web"}, &mockLogger{})
+	require.NoError(t, err)
+	assert.NotContains(t, managed.writtenFiles, hook+chainedHookSuffix)
+}
+
+// 異常系: 退避先が既にある場合は上書きしない
+func TestInstallGitHooksCore_ChainedExists(t *testing.T) {
+	hook := "/repo/.git/hooks/pre-commit"
+	fs := &mockFileSystem{
+		statInfoMap:    map[string]FileInfo{hook: &mockFileInfo{}, hook + chainedHookSuffix: &mockFileInfo{}},
+		readContentMap: map[string][]byte{hook: []byte("#!/bin/sh\nlint\n")},
+	}
+
+	_, err := InstallGitHooksCore(fs, "/repo/.git/hooks", GitHooksOptions{Project: "web"}, &mockLogger{})
+
+	assert.ErrorContains(t, err, "both pre-commit and pre-commit.bwsf-chained exist")
+	assert.Empty(t, fs.writtenFiles)
+}
+
+// 正常系: 退避したフックは元に戻し、bwsf のみのフックは削除、他のフックは触らない
+func TestUninstallGitHooksCore(t *testing.T) {
+	dir := "/repo/.git/hooks"
+	managed := []byte(gitHookScript(GitHookPreCommit, GitHooksOptions{Project: "web"}))
+	fs := &mockFileSystem{
+		statInfoMap: map[string]FileInfo{
+			dir + "/pre-commit":                     &mockFileInfo{},
+			dir + "/pre-commit" + chainedHookSuffix: &mockFileInfo{},
+			dir + "/post-merge":                     &mockFileInfo{},
+			dir + "/post-checkout":                  &mockFileInfo{},
+		},
+		readContentMap: map[string][]byte{
+			dir + "/pre-commit":                     managed,
+			dir + "/pre-commit" + chainedHookSuffix: []byte("#!/bin/sh\nlint\n"),
+			dir + "/post-merge":                     managed,
+			dir + "/post-checkout":                  []byte("#!/bin/sh\nnpm install\n"),
+		},
+	}
+
+	removed, err := UninstallGitHooksCore(fs, dir, &mockLogger{})
+
+	require.NoError(t, err)
+	assert.Equal(t, []string{GitHookPreCommit, GitHookPostMerge}, removed)
+	assert.Equal(t, "#!/bin/sh\nlint\n", string(fs.writtenFiles[dir+"/pre-commit"]))
+	assert.Equal(t, []string{dir + "/pre-commit" + chainedHookSuffix, dir + "/post-merge"}, fs.removedPaths)
+}
+
+// 異常系: 削除に失敗
+func TestUninstallGitHooksCore_RemoveError(t *testing.T) {
+	hook := "/repo/.git/hooks/pre-commit"
+	fs := &mockFileSystem{
+		statInfoMap:    map[string]FileInfo{hook: &mockFileInfo{}},
+		readContentMap: map[string][]byte{hook: []byte(gitHookMarker)},
+		removeErr:      errors.New("permission denied"),
+	}
+
+	_, err := UninstallGitHooksCore(fs, "/repo/.git/hooks", &mockLogger{})
+
+	assert.ErrorContains(t, err, "failed to remove pre-commit hook: permission denied")
+}
+
+// =============================================================================
+// PreCommitCore のテスト
+// =============================================================================
+
+// hooksFiles は pre-commit のテストで保存しておく .env と .env.production です。
+var hooksFiles = MultiEnvData{
+	".env":            {Lines: []string{"API_KEY=sk_live_abcdef123", "PORT=3000", "URL=${HOST}/api/v1/x"}},
+	".env.production": {Lines: []string{"DB_URL=postgres://u:hunter2hunter2@db/app"}},
+}
+
+// 正常系: .env ファイルと保存された値を含む行を報告し、.example や短い値は対象外
+func TestPreCommitCore(t *testing.T) {
+	repo := &mockGitRepository{
+		staged: []string{".env", "apps/.env.local", ".env.example", "src/config.go", "README.md"},
+		contents: map[string][]byte{
+			".env.example":  []byte("API_KEY=sk_live_abcdef123\n"),
+			"src/config.go": []byte("package config\n\nconst key = \"sk_live_abcdef123\"\nconst port = 3000\n"),
+			"README.md":     []byte("Connect with postgres://u:hunter2hunter2@db/app\nURL=${HOST}/api/v1/x\n"),
+		},
+	}
+
+	findings, err := PreCommitCore("/repo", ".", "web", repo, &mockFileSystem{}, newTestBwClient(t, hooksFiles), &config.Config{}, nil, &mockLogger{})
+
+	require.NoError(t, err)
+	assert.Equal(t, []StagedSecret{
+		{Path: ".env"},
+		{Path: "apps/.env.local"},
+		{Path: ".env.example", Line: 1, File: ".env", Key: "API_KEY"},
+		{Path: "src/config.go", Line: 3, File: ".env", Key: "API_KEY"},
+		{Path: "README.md", Line: 1, File: ".env.production", Key: "DB_URL"},
+	}, findings)
+	assert.Equal(t, "src/config.go:3: contains the value of API_KEY (.env)", findings[3].String())
+	assert.Equal(t, ".env: env files must not be committed", findings[0].String())
+}
+
+// 正常系: push と同じく .bwsf.json の env_patterns に一致するファイルも止める（プロジェクトのディレクトリ内のみ）
+func TestPreCommitCore_EnvPatterns(t *testing.T) {
+	repo := &mockGitRepository{
+		staged: []string{"apps/web/config/secrets.env", "apps/web/config/prod.key", "config/prod.key", "apps/web/secrets.example.env", "apps/web/main.go"},
+	}
+	fs := &mockFileSystem{
+		statInfoMap:    map[string]FileInfo{"/repo/apps/web/.bwsf.json": &mockFileInfo{}},
+		readContentMap: map[string][]byte{"/repo/apps/web/.bwsf.json": []byte(`{"env_patterns":["secrets*.env","config/*.key"]}`)},
+	}
+	bw := &mockBwClient{folderIDErr: errors.New("bw unreachable")}
+
+	findings, err := PreCommitCore("/repo", "apps/web", "web", repo, fs, bw, &config.Config{}, nil, &mockLogger{})
+
+	require.NoError(t, err)
+	assert.Equal(t, []StagedSecret{{Path: "apps/web/config/secrets.env"}, {Path: "apps/web/config/prod.key"}}, findings)
+}
+
+// 正常系: ステージされたファイルがなければ保管庫を読まない
+func TestPreCommitCore_NothingStaged(t *testing.T) {
+	bw := newTestBwClient(t, hooksFiles)
+
+	findings, err := PreCommitCore("/repo", ".", "web", &mockGitRepository{}, &mockFileSystem{}, bw, &config.Config{}, nil, &mockLogger{})
+
+	require.NoError(t, err)
+	assert.Empty(t, findings)
+	assert.Empty(t, bw.calls)
+}
+
+// 正常系: 保管庫を読めない場合は警告してファイル名のみ検査する
+func TestPreCommitCore_VaultUnavailable(t *testing.T) {
+	repo := &mockGitRepository{
+		staged:   []string{".env.production", "main.go"},
+		contents: map[string][]byte{"main.go": []byte("sk_live_abcdef123")},
+	}
+	bw := &mockBwClient{folderIDErr: errors.New("bw unreachable")}
+	logger := &mockLogger{}
+
+	findings, err := PreCommitCore("/repo", ".", "web", repo, &mockFileSystem{}, bw, &config.Config{}, nil, logger)
+
+	require.NoError(t, err)
+	assert.Equal(t, []StagedSecret{{Path: ".env.production"}}, findings)
+	checkWarning(t, logger, "Skipping the secret value check")
+}
+
+// 正常系: ロック中はパスワードを求めず、警告してファイル名のみ検査する
+func TestPreCommitCore_LockedDoesNotPrompt(t *testing.T) {
+	repo := &mockGitRepository{
+		staged:   []string{".env", "main.go"},
+		contents: map[string][]byte{"main.go": []byte("sk_live_abcdef123")},
+	}
+	bw := &mockBwClient{folderIDErr: ErrBitwardenLocked}
+	logger := &mockLogger{}
+
+	findings, err := PreCommitCore("/repo", ".", "web", repo, &mockFileSystem{}, bw, &config.Config{}, HookPasswordPrompt, logger)
+
+	require.NoError(t, err)
+	assert.Equal(t, []StagedSecret{{Path: ".env"}}, findings)
+	checkWarning(t, logger, "git hooks do not prompt for the master password")
+	assert.NotContains(t, bw.calls, "Unlock")
+}
+
+// 異常系: ステージされたファイルを取得できない
+func TestPreCommitCore_StagedError(t *testing.T) {
+	repo := &mockGitRepository{stagedErr: errors.New("not a git repository")}
+
+	_, err := PreCommitCore("/repo", ".", "web", repo, &mockFileSystem{}, newTestBwClient(t, hooksFiles), &config.Config{}, nil, &mockLogger{})
+
+	assert.ErrorContains(t, err, "not a git repository")
+}
+
+// 正常系: トークンと "=" / ":" 以降の部分を候補にする
+func TestSecretCandidates(t *testing.T) {
+	assert.Equal(t,
+		[]string{"TOKEN=abcdefgh123", "abcdefgh123", "https://example.com/a", "//example.com/a"},
+		secretCandidates(`  TOKEN=abcdefgh123, fetch("https://example.com/a") ok`))
+	assert.Empty(t, secretCandidates("short x=1"))
+}
+
+// =============================================================================
+// VaultNewerFiles のテスト
+// =============================================================================
+
+// 正常系: 保管庫のみ変更・保管庫のみのファイルを返す
+func TestVaultNewerFiles(t *testing.T) {
+	statuses := []EnvFileStatus{
+		{FileName: ".env", Status: StatusRemoteModified},
+		{FileName: ".env.local", Status: StatusLocalModified},
+		{FileName: ".env.production", Status: StatusRemoteOnly},
+		{FileName: ".env.test", Status: StatusInSync},
+	}
+
+	assert.Equal(t, []string{".env", ".env.production"}, VaultNewerFiles(statuses))
+}
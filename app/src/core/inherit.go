@@ -0,0 +1,221 @@
+package core
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"bwsf/src/config"
+)
+
+// 親アイテムから継承したキーを囲むマーカーです。
+// マーカーで囲まれた行は pull のたびに再生成され、push 時には取り除かれます。
+const (
+	inheritedBlockStart = "# >>> bwsf: inherited from "
+	inheritedBlockEnd   = "# <<< bwsf"
+)
+
+// KeySource は実際に使われる値がどのアイテムから来たかを表します。
+type KeySource struct {
+	File       string
+	Key        string
+	Source     string   // 値の取得元のアイテム名
+	Overridden []string // 同じキーを持つが上書きされたアイテム名（優先度の高い順）
+}
+
+// Inherited はキーが親アイテムから継承されたものかを返します。
+func (s KeySource) Inherited(projectName string) bool {
+	return s.Source != projectName
+}
+
+// projectParents は各ファイルの親アイテム名を返します。
+// .bwsf.json の extends が設定されていればそれを優先し、無ければ保存内容の extends を使います。
+func projectParents(data MultiEnvData, projectCfg *config.ProjectConfig) map[string][]string {
+	parents := make(map[string][]string)
+	for fileName, envData := range data {
+		switch {
+		case projectCfg != nil && len(projectCfg.Extends) > 0:
+			parents[fileName] = projectCfg.Extends
+		case len(envData.Extends) > 0:
+			parents[fileName] = envData.Extends
+		}
+	}
+	return parents
+}
+
+// fetchParents は親アイテムを取得します。同じ親は 1 回だけ取得します。
+// 親の親は継承しません。
+func fetchParents(
+	parentsByFile map[string][]string,
+	projectName string,
+	bw BwClient,
+	cfg *config.Config,
+	promptPassword func() (string, error),
+	logger Logger,
+) (map[string]MultiEnvData, error) {
+	fetched := make(map[string]MultiEnvData)
+	for _, names := range parentsByFile {
+		for _, name := range names {
+			if _, ok := fetched[name]; ok {
+				continue
+			}
+			if name == projectName {
+				return nil, fmt.Errorf("project '%s' cannot extend itself", projectName)
+			}
+			_, item, err := fetchProjectItem(name, bw, cfg, promptPassword, logger)
+			if err != nil {
+				return nil, err
+			}
+			if item == nil {
+				return nil, fmt.Errorf("parent item '%s' not found in dotenvs folder", name)
+			}
+			data, err := decodeStoredNotes(item.Notes)
+			if err != nil {
+				return nil, fmt.Errorf("failed to decode parent item '%s': %w", name, err)
+			}
+			fetched[name] = data
+		}
+	}
+	return fetched, nil
+}
+
+// mergeParents は親アイテムのキーをプロジェクト自身のキーの下に重ねた内容と、各キーの取得元を返します。
+//
+// 優先順位はプロジェクト自身 > 後に書いた親 > 先に書いた親です。
+// 親からは同名のファイル、無ければ .env を使います。プロジェクトに無いファイルは作りません。
+// 継承したキーはマーカーで囲んでファイル末尾に追加します。
+func mergeParents(projectName string, data MultiEnvData, parentsByFile map[string][]string, parents map[string]MultiEnvData) (MultiEnvData, []KeySource) {
+	merged := make(MultiEnvData, len(data))
+	var sources []KeySource
+
+	var fileNames []string
+	for fileName := range data {
+		fileNames = append(fileNames, fileName)
+	}
+	sortFileNames(fileNames)
+
+	for _, fileName := range fileNames {
+		own := data[fileName]
+
+		// 各キーについて、値を持つアイテムを優先度の高い順に並べる
+		holders := make(map[string][]string)
+		var order []string
+		addHolder := func(key, source string) {
+			if _, ok := holders[key]; !ok {
+				order = append(order, key)
+			}
+			for _, h := range holders[key] {
+				if h == source {
+					return
+				}
+			}
+			holders[key] = append(holders[key], source)
+		}
+		for _, line := range own.Lines {
+			if entry, ok := parseEnvLine(line); ok {
+				addHolder(entry.Key, projectName)
+			}
+		}
+
+		names := parentsByFile[fileName]
+		parentLines := make(map[string][]string)
+		for i := len(names) - 1; i >= 0; i-- {
+			parentFile, ok := parents[names[i]][fileName]
+			if !ok {
+				parentFile, ok = parents[names[i]][".env"]
+			}
+			if !ok {
+				continue
+			}
+			for _, line := range parentFile.Lines {
+				entry, ok := parseEnvLine(line)
+				if !ok {
+					continue
+				}
+				addHolder(entry.Key, names[i])
+				if holders[entry.Key][0] == names[i] {
+					parentLines[names[i]] = append(parentLines[names[i]], line)
+				}
+			}
+		}
+
+		lines := append([]string(nil), own.Lines...)
+		for _, name := range names {
+			if len(parentLines[name]) == 0 {
+				continue
+			}
+			if len(lines) > 0 && strings.TrimSpace(lines[len(lines)-1]) != "" {
+				lines = append(lines, "")
+			}
+			lines = append(lines, inheritedBlockStart+name)
+			lines = append(lines, parentLines[name]...)
+			lines = append(lines, inheritedBlockEnd)
+		}
+		merged[fileName] = EnvData{Lines: lines, Extends: own.Extends}
+
+		sort.Strings(order)
+		for _, key := range order {
+			h := holders[key]
+			sources = append(sources, KeySource{File: fileName, Key: key, Source: h[0], Overridden: h[1:]})
+		}
+	}
+
+	return merged, sources
+}
+
+// stripInheritedBlocks は pull 時に追加した継承ブロックを取り除きます。
+// ブロックの直前に追加した空行も取り除きます。
+func stripInheritedBlocks(data EnvData) EnvData {
+	var lines []string
+	inBlock := false
+	for _, line := range data.Lines {
+		trimmed := strings.TrimSpace(line)
+		switch {
+		case strings.HasPrefix(trimmed, inheritedBlockStart):
+			inBlock = true
+			if n := len(lines); n > 0 && strings.TrimSpace(lines[n-1]) == "" {
+				lines = lines[:n-1]
+			}
+		case inBlock && trimmed == inheritedBlockEnd:
+			inBlock = false
+		case !inBlock:
+			lines = append(lines, line)
+		}
+	}
+	return EnvData{Lines: lines, Extends: data.Extends}
+}
+
+// SourcesEnvCore は保存されたプロジェクトと親アイテムを重ねたときの、各キーの取得元を返します。
+func SourcesEnvCore(
+	dir, projectName string,
+	fs FileSystem,
+	bw BwClient,
+	cfg *config.Config,
+	promptPassword func() (string, error),
+	logger Logger,
+) ([]KeySource, error) {
+	_, item, err := fetchProjectItem(projectName, bw, cfg, promptPassword, logger)
+	if err != nil {
+		return nil, err
+	}
+	if item == nil {
+		return nil, fmt.Errorf("item '%s' not found in dotenvs folder", projectName)
+	}
+	data, err := decodeStoredNotes(item.Notes)
+	if err != nil {
+		return nil, err
+	}
+
+	projectCfg, err := LoadProjectConfig(fs, dir)
+	if err != nil {
+		return nil, err
+	}
+	parentsByFile := projectParents(data, projectCfg)
+	parents, err := fetchParents(parentsByFile, projectName, bw, cfg, promptPassword, logger)
+	if err != nil {
+		return nil, err
+	}
+
+	_, sources := mergeParents(projectName, data, parentsByFile, parents)
+	return sources, nil
+}
@@ -0,0 +1,169 @@
+package core
+
+import (
+	"testing"
+
+	"bwsf/src/config"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// =============================================================================
+// mergeParents のテスト
+// =============================================================================
+
+// 正常系: 親のキーをプロジェクトのキーの下に重ね、優先順位に従う
+func TestMergeParents_Precedence(t *testing.T) {
+	data := MultiEnvData{
+		".env": {Lines: []string{"APP=web", "SMTP_HOST=smtp.local"}},
+	}
+	parentsByFile := map[string][]string{".env": {"_shared/observability", "_shared/smtp"}}
+	parents := map[string]MultiEnvData{
+		"_shared/observability": {".env": {Lines: []string{"# sentry", "SENTRY_DSN=https://a@sentry", "LOG_LEVEL=info"}}},
+		"_shared/smtp":          {".env": {Lines: []string{"SMTP_HOST=smtp.example.com", "SMTP_USER=mailer", "LOG_LEVEL=debug"}}},
+	}
+
+	merged, sources := mergeParents("web", data, parentsByFile, parents)
+
+	assert.Equal(t, []string{
+		"APP=web",
+		"SMTP_HOST=smtp.local",
+		"",
+		"# >>> bwsf: inherited from _shared/observability",
+		"SENTRY_DSN=https://a@sentry",
+		"# <<< bwsf",
+		"",
+		"# >>> bwsf: inherited from _shared/smtp",
+		"SMTP_USER=mailer",
+		"LOG_LEVEL=debug",
+		"# <<< bwsf",
+	}, merged[".env"].Lines)
+
+	got := make(map[string]KeySource)
+	for _, s := range sources {
+		got[s.Key] = s
+	}
+	assert.Equal(t, "web", got["APP"].Source)
+	assert.Equal(t, []string{"_shared/smtp"}, got["SMTP_HOST"].Overridden)
+	assert.Equal(t, "_shared/smtp", got["LOG_LEVEL"].Source)
+	assert.Equal(t, []string{"_shared/observability"}, got["LOG_LEVEL"].Overridden)
+	assert.True(t, got["SENTRY_DSN"].Inherited("web"))
+	assert.False(t, got["APP"].Inherited("web"))
+}
+
+// 正常系: 同名ファイルが親にあればそれを使い、無ければ .env を使う
+func TestMergeParents_FileFallback(t *testing.T) {
+	data := MultiEnvData{
+		".env.staging":    {Lines: []string{"A=1"}},
+		".env.production": {Lines: []string{"A=2"}},
+	}
+	parentsByFile := map[string][]string{".env.staging": {"p"}, ".env.production": {"p"}}
+	parents := map[string]MultiEnvData{"p": {
+		".env":         {Lines: []string{"B=base"}},
+		".env.staging": {Lines: []string{"B=staging"}},
+	}}
+
+	merged, _ := mergeParents("web", data, parentsByFile, parents)
+
+	assert.Contains(t, merged[".env.staging"].Lines, "B=staging")
+	assert.Contains(t, merged[".env.production"].Lines, "B=base")
+	assert.NotContains(t, merged, ".env")
+}
+
+// =============================================================================
+// stripInheritedBlocks のテスト
+// =============================================================================
+
+// 正常系: mergeParents で追加したブロックを取り除くと元に戻る
+func TestStripInheritedBlocks_RoundTrip(t *testing.T) {
+	own := EnvData{Lines: []string{"APP=web", "# own comment"}}
+	merged, _ := mergeParents("web", MultiEnvData{".env": own},
+		map[string][]string{".env": {"p"}},
+		map[string]MultiEnvData{"p": {".env": {Lines: []string{"X=1"}}}})
+
+	assert.Equal(t, own.Lines, stripInheritedBlocks(merged[".env"]).Lines)
+}
+
+// =============================================================================
+// PullEnvCoreWithOptions / PushEnvCoreWithOptions / SourcesEnvCore の継承のテスト
+// =============================================================================
+
+// 正常系: 保存内容の extends に従って親を重ねて書き出す
+func TestPullEnvCore_Extends(t *testing.T) {
+	bw := &mockBwClient{
+		folderID: "folder-123",
+		itemsByName: map[string]*FullItem{
+			"web":     {ID: "item-1", Name: "web", Notes: `{".env":{"lines":["APP=web"],"extends":["_shared"]}}`},
+			"_shared": {ID: "item-2", Name: "_shared", Notes: `{".env":{"lines":["SENTRY_DSN=dsn","APP=shared"]}}`},
+		},
+	}
+	fs := &mockFileSystem{}
+
+	err := PullEnvCoreWithOptions("/web", "web", fs, bw, &config.Config{}, nil, noConfirm, &mockLogger{}, PullOptions{})
+
+	require.NoError(t, err)
+	assert.Equal(t, "APP=web\n\n# >>> bwsf: inherited from _shared\nSENTRY_DSN=dsn\n# <<< bwsf", string(fs.writtenFiles["/web/.env"]))
+}
+
+// 異常系: 親アイテムが存在しない
+func TestPullEnvCore_ExtendsMissingParent(t *testing.T) {
+	bw := &mockBwClient{
+		folderID: "folder-123",
+		itemsByName: map[string]*FullItem{
+			"web": {ID: "item-1", Name: "web", Notes: `{".env":{"lines":["APP=web"],"extends":["_gone"]}}`},
+		},
+	}
+	fs := &mockFileSystem{}
+
+	err := PullEnvCoreWithOptions("/web", "web", fs, bw, &config.Config{}, nil, noConfirm, &mockLogger{}, PullOptions{})
+
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "parent item '_gone' not found")
+	assert.Empty(t, fs.writtenFiles)
+}
+
+// 正常系: push 時は継承ブロックを取り除き、.bwsf.json の extends を保存する
+func TestPushEnvCore_StripsInheritedAndStoresExtends(t *testing.T) {
+	fs := &mockFileSystem{
+		dirEntries:  []DirEntry{&mockDirEntry{name: ".env"}},
+		statInfoMap: map[string]FileInfo{"/web/.bwsf.json": &mockFileInfo{notExist: false}},
+		readContentMap: map[string][]byte{
+			"/web/.env":       []byte("APP=web\n\n# >>> bwsf: inherited from _shared\nSENTRY_DSN=dsn\n# <<< bwsf\n"),
+			"/web/.bwsf.json": []byte(`{"extends":["_shared"]}`),
+		},
+	}
+	bw := &mockBwClient{folderID: "folder-123"}
+
+	err := PushEnvCoreWithOptions("/web", "web", fs, bw, &config.Config{}, nil, &mockLogger{}, PushOptions{})
+
+	require.NoError(t, err)
+	stored, err := restoreMultiEnvFromJSON(bw.createdNotes["web"])
+	require.NoError(t, err)
+	assert.Equal(t, []string{"APP=web"}, stored[".env"].Lines)
+	assert.Equal(t, []string{"_shared"}, stored[".env"].Extends)
+}
+
+// 正常系: 各キーの取得元を返す（.bwsf.json の extends が優先）
+func TestSourcesEnvCore(t *testing.T) {
+	bw := &mockBwClient{
+		folderID: "folder-123",
+		itemsByName: map[string]*FullItem{
+			"web": {ID: "item-1", Name: "web", Notes: `{".env":{"lines":["A=1"],"extends":["old"]}}`},
+			"new": {ID: "item-2", Name: "new", Notes: `{".env":{"lines":["A=0","B=2"]}}`},
+			"old": {ID: "item-3", Name: "old", Notes: `{".env":{"lines":["C=3"]}}`},
+		},
+	}
+	fs := &mockFileSystem{
+		statInfoMap:    map[string]FileInfo{"/web/.bwsf.json": &mockFileInfo{notExist: false}},
+		readContentMap: map[string][]byte{"/web/.bwsf.json": []byte(`{"extends":["new"]}`)},
+	}
+
+	sources, err := SourcesEnvCore("/web", "web", fs, bw, &config.Config{}, nil, &mockLogger{})
+
+	require.NoError(t, err)
+	assert.Equal(t, []KeySource{
+		{File: ".env", Key: "A", Source: "web", Overridden: []string{"new"}},
+		{File: ".env", Key: "B", Source: "new", Overridden: []string{}},
+	}, sources)
+}
@@ -0,0 +1,366 @@
+package core
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"regexp"
+	"strings"
+	"time"
+
+	"bwsf/src/config"
+)
+
+// ErrUnresolvedReference は ${KEY} や bw:// 参照を解決できない場合に返されます。
+var ErrUnresolvedReference = errors.New("unresolved reference")
+
+// ErrCyclicReference は ${KEY} の参照が循環している場合に返されます。
+var ErrCyclicReference = errors.New("cyclic reference")
+
+// vaultRefPrefix は Bitwarden アイテムを参照する値のプレフィックスです。
+//
+//	bw://item/<name-or-id>/password
+//	bw://item/<name-or-id>/username
+//	bw://item/<name-or-id>/totp
+//	bw://item/<name-or-id>/notes
+//	bw://item/<name-or-id>/field/<field-name>
+const vaultRefPrefix = "bw://item/"
+
+// itemIDPattern は Bitwarden のアイテム ID（UUID）に一致します。
+var itemIDPattern = regexp.MustCompile(`^[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}$`)
+
+// VaultResolver は bw:// 参照を値に解決する関数です。
+type VaultResolver func(ref string) (string, error)
+
+// NewVaultResolver は BwClient でアイテムを取得して bw:// 参照を解決する VaultResolver を返します。
+// 同じアイテムへの参照は 1 回の取得で済むようキャッシュします。
+func NewVaultResolver(
+	bw BwClient,
+	cfg *config.Config,
+	promptPassword func() (string, error),
+	logger Logger,
+	now func() time.Time,
+) VaultResolver {
+	cache := make(map[string]*FullItem)
+
+	return func(ref string) (string, error) {
+		itemRef, selector, fieldName, err := parseVaultRef(ref)
+		if err != nil {
+			return "", err
+		}
+
+		item, cached := cache[itemRef]
+		if !cached {
+			err := WithUnlockRetry(bw, cfg, promptPassword, logger, func() error {
+				var innerErr error
+				if itemIDPattern.MatchString(itemRef) {
+					item, innerErr = bw.GetItemByID(itemRef)
+					if innerErr != nil || item != nil {
+						return innerErr
+					}
+				}
+				item, innerErr = bw.GetItemByName("", itemRef)
+				return innerErr
+			})
+			if err != nil {
+				return "", fmt.Errorf("failed to get item for %s: %w", ref, err)
+			}
+			cache[itemRef] = item
+		}
+		if item == nil {
+			return "", fmt.Errorf("%w: %s: item %q not found", ErrUnresolvedReference, ref, itemRef)
+		}
+
+		switch selector {
+		case "password", "username", "totp":
+			if item.Login == nil {
+				return "", fmt.Errorf("%w: %s: item %q is not a login item", ErrUnresolvedReference, ref, item.Name)
+			}
+			switch selector {
+			case "password":
+				return item.Login.Password, nil
+			case "username":
+				return item.Login.Username, nil
+			}
+			if item.Login.TOTP == "" {
+				return "", fmt.Errorf("%w: %s: item %q has no TOTP secret", ErrUnresolvedReference, ref, item.Name)
+			}
+			return generateTOTP(item.Login.TOTP, now())
+		case "notes":
+			return item.Notes, nil
+		default: // field
+			for _, field := range item.Fields {
+				if field.Name == fieldName {
+					return field.Value, nil
+				}
+			}
+			return "", fmt.Errorf("%w: %s: item %q has no field %q", ErrUnresolvedReference, ref, item.Name, fieldName)
+		}
+	}
+}
+
+// parseVaultRef は bw:// 参照をアイテム名（または ID）、種類、フィールド名に分解します。
+// アイテム名に "/" を含めることができます（例: bw://item/_shared/smtp/password）。
+func parseVaultRef(ref string) (itemRef, selector, fieldName string, err error) {
+	rest := strings.TrimPrefix(ref, vaultRefPrefix)
+	parts := strings.Split(rest, "/")
+	for i, part := range parts {
+		if parts[i], err = url.PathUnescape(part); err != nil {
+			return "", "", "", fmt.Errorf("invalid reference %s: %w", ref, err)
+		}
+	}
+
+	n := len(parts)
+	switch {
+	case n >= 3 && parts[n-2] == "field":
+		itemRef, selector, fieldName = strings.Join(parts[:n-2], "/"), "field", parts[n-1]
+	case n >= 2:
+		itemRef, selector = strings.Join(parts[:n-1], "/"), parts[n-1]
+	}
+
+	switch selector {
+	case "password", "username", "totp", "notes", "field":
+	default:
+		return "", "", "", fmt.Errorf("invalid reference %s: expected bw://item/<name-or-id>/password|username|totp|notes|field/<name>", ref)
+	}
+	if itemRef == "" || (selector == "field" && fieldName == "") {
+		return "", "", "", fmt.Errorf("invalid reference %s: item name and field name must not be empty", ref)
+	}
+	return itemRef, selector, fieldName, nil
+}
+
+// isVaultRef は値が bw:// 参照かどうかを判定します。
+func isVaultRef(value string) bool {
+	return strings.HasPrefix(value, vaultRefPrefix)
+}
+
+// hasInterpolation は値に ${...} または bw:// 参照が含まれるかを判定します。
+func hasInterpolation(entry envEntry) bool {
+	return entry.Quote != '\'' && (strings.Contains(entry.Value, "${") || isVaultRef(entry.Value))
+}
+
+// InterpolateMultiEnvData は各ファイルの ${KEY}、${KEY:-default}、bw:// 参照を展開したコピーを返します。
+//
+// ${KEY} はそのファイル自身と、名前がプレフィックスになっている下位レイヤーのファイルから解決します
+// （例: .env.staging.local は .env → .env.staging → .env.staging.local の順に重ね、後のファイルが優先）。
+// シングルクォートの値は展開しません。元の data は変更しません。
+func InterpolateMultiEnvData(data MultiEnvData, resolve VaultResolver) (MultiEnvData, error) {
+	result := make(MultiEnvData, len(data))
+	for fileName := range data {
+		expanded, err := interpolateFile(fileName, data, resolve)
+		if err != nil {
+			return nil, err
+		}
+		result[fileName] = expanded
+	}
+	return result, nil
+}
+
+// envDef は展開前の値の定義元です。
+type envDef struct {
+	entry envEntry
+	file  string
+	line  int
+}
+
+// interpolator は 1 ファイル分の展開状態を保持します。
+type interpolator struct {
+	defs     map[string]envDef
+	resolved map[string]string
+	visiting []string
+	resolve  VaultResolver
+}
+
+// interpolateFile は fileName のファイルを展開します。
+func interpolateFile(fileName string, data MultiEnvData, resolve VaultResolver) (EnvData, error) {
+	ip := &interpolator{
+		defs:     make(map[string]envDef),
+		resolved: make(map[string]string),
+		resolve:  resolve,
+	}
+	for _, layer := range layerChain(fileName, data) {
+		for i, line := range data[layer].Lines {
+			if entry, ok := parseEnvLine(line); ok {
+				ip.defs[entry.Key] = envDef{entry: entry, file: layer, line: i + 1}
+			}
+		}
+	}
+
+	lines := make([]string, len(data[fileName].Lines))
+	for i, line := range data[fileName].Lines {
+		lines[i] = line
+
+		entry, ok := parseEnvLine(line)
+		if !ok || !hasInterpolation(entry) {
+			continue
+		}
+
+		ip.visiting = []string{entry.Key}
+		value, err := ip.expandValue(envDef{entry: entry, file: fileName, line: i + 1})
+		if err != nil {
+			return EnvData{}, err
+		}
+		lines[i] = replaceEnvValue(line, entry, value)
+	}
+	return EnvData{Lines: lines}, nil
+}
+
+// layerChain は fileName に重ねるファイルを下位から順に返します。
+func layerChain(fileName string, data MultiEnvData) []string {
+	var chain []string
+	parts := strings.Split(strings.TrimPrefix(fileName, ".env"), ".")
+	name := ".env"
+	for i, part := range parts {
+		if i > 0 {
+			name += "." + part
+		}
+		if _, ok := data[name]; ok && name != fileName {
+			chain = append(chain, name)
+		}
+	}
+	return append(chain, fileName)
+}
+
+// expandKey は key の展開後の値を返します。key が未定義の場合は ok=false を返します。
+func (ip *interpolator) expandKey(key string) (string, bool, error) {
+	if value, ok := ip.resolved[key]; ok {
+		return value, true, nil
+	}
+	for _, k := range ip.visiting {
+		if k == key {
+			return "", false, fmt.Errorf("%w: %s -> %s", ErrCyclicReference, strings.Join(ip.visiting, " -> "), key)
+		}
+	}
+	def, ok := ip.defs[key]
+	if !ok {
+		return "", false, nil
+	}
+
+	ip.visiting = append(ip.visiting, key)
+	value, err := ip.expandValue(def)
+	ip.visiting = ip.visiting[:len(ip.visiting)-1]
+	if err != nil {
+		return "", false, err
+	}
+	ip.resolved[key] = value
+	return value, true, nil
+}
+
+// expandValue は定義の値を展開し、bw:// 参照であれば解決します。
+func (ip *interpolator) expandValue(def envDef) (string, error) {
+	if def.entry.Quote == '\'' {
+		return def.entry.Value, nil
+	}
+	value, err := ip.expandString(def.entry.Value, def)
+	if err != nil {
+		return "", err
+	}
+	if isVaultRef(value) {
+		if ip.resolve == nil {
+			return "", fmt.Errorf("%w: %s:%d: %s (vault references are not available)", ErrUnresolvedReference, def.file, def.line, value)
+		}
+		resolved, err := ip.resolve(value)
+		if err != nil {
+			return "", fmt.Errorf("%s:%d: %w", def.file, def.line, err)
+		}
+		return resolved, nil
+	}
+	return value, nil
+}
+
+// expandString は s の中の ${KEY} と ${KEY:-default} を展開します。
+func (ip *interpolator) expandString(s string, def envDef) (string, error) {
+	var b strings.Builder
+	for {
+		start := strings.Index(s, "${")
+		if start < 0 {
+			b.WriteString(s)
+			return b.String(), nil
+		}
+		end := matchingBrace(s, start+2)
+		if end < 0 {
+			return "", fmt.Errorf("%s:%d: unterminated ${ in value of %s", def.file, def.line, def.entry.Key)
+		}
+		b.WriteString(s[:start])
+
+		expr := s[start+2 : end]
+		key, fallback, hasDefault := strings.Cut(expr, ":-")
+		if !isValidEnvKey(key) {
+			return "", fmt.Errorf("%s:%d: invalid reference ${%s} in value of %s", def.file, def.line, expr, def.entry.Key)
+		}
+
+		value, ok, err := ip.expandKey(key)
+		if err != nil {
+			return "", err
+		}
+		switch {
+		case hasDefault && value == "":
+			if value, err = ip.expandString(fallback, def); err != nil {
+				return "", err
+			}
+		case !ok:
+			return "", fmt.Errorf("%w: %s:%d: ${%s} is not defined", ErrUnresolvedReference, def.file, def.line, key)
+		}
+		b.WriteString(value)
+		s = s[end+1:]
+	}
+}
+
+// matchingBrace は s[from:] 以降で対応する "}" の位置を返します（${...} の入れ子に対応）。
+func matchingBrace(s string, from int) int {
+	depth := 1
+	for i := from; i < len(s); i++ {
+		switch {
+		case s[i] == '$' && i+1 < len(s) && s[i+1] == '{':
+			depth++
+			i++
+		case s[i] == '}':
+			depth--
+			if depth == 0 {
+				return i
+			}
+		}
+	}
+	return -1
+}
+
+// replaceEnvValue は行の値部分を value に置き換えます。
+// キー・export・インラインコメントはそのまま残し、必要に応じてダブルクォートで囲みます。
+func replaceEnvValue(line string, entry envEntry, value string) string {
+	eq := strings.Index(line, "=")
+	prefix := line[:eq+1]
+	rest := line[eq+1:]
+	leading := rest[:len(rest)-len(strings.TrimLeft(rest, " \t"))]
+
+	suffix := ""
+	if entry.Quote == 0 {
+		if idx := strings.Index(entry.RawValue, " #"); idx >= 0 {
+			suffix = entry.RawValue[idx:]
+		}
+	} else if end := closingQuoteIndex(entry.RawValue, entry.Quote); end >= 0 {
+		suffix = entry.RawValue[end+1:]
+	}
+	if strings.HasSuffix(line, "\r") {
+		suffix += "\r"
+	}
+
+	if entry.Quote == '"' || strings.ContainsAny(value, " \t#\"'\\\n") {
+		escaped := strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`).Replace(value)
+		return prefix + leading + `"` + escaped + `"` + suffix
+	}
+	return prefix + leading + value + suffix
+}
+
+// closingQuoteIndex は raw（先頭がクォート）の閉じクォートの位置を返します。
+func closingQuoteIndex(raw string, quote byte) int {
+	for i := 1; i < len(raw); i++ {
+		if quote == '"' && raw[i] == '\\' {
+			i++
+			continue
+		}
+		if raw[i] == quote {
+			return i
+		}
+	}
+	return -1
+}
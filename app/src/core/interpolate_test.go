@@ -0,0 +1,272 @@
+package core
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"bwsf/src/config"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// staticResolver は固定の対応表で bw:// 参照を解決します。
+func staticResolver(values map[string]string) VaultResolver {
+	return func(ref string) (string, error) {
+		if v, ok := values[ref]; ok {
+			return v, nil
+		}
+		return "", errors.New("not found: " + ref)
+	}
+}
+
+// =============================================================================
+// InterpolateMultiEnvData のテスト
+// =============================================================================
+
+// 正常系: ファイル内の ${KEY} と ${KEY:-default} を展開する
+func TestInterpolate_WithinFile(t *testing.T) {
+	data := MultiEnvData{".env": {Lines: []string{
+		"# database",
+		"DB_HOST=db.internal",
+		"DB_URL=postgres://${DB_HOST}:${DB_PORT:-5432}/app # primary",
+		`GREETING="hello ${NAME:-world}"`,
+		"LITERAL='${DB_HOST}'",
+		"export READ_URL=${DB_URL}",
+	}}}
+
+	result, err := InterpolateMultiEnvData(data, nil)
+
+	require.NoError(t, err)
+	assert.Equal(t, []string{
+		"# database",
+		"DB_HOST=db.internal",
+		"DB_URL=postgres://db.internal:5432/app # primary",
+		`GREETING="hello world"`,
+		"LITERAL='${DB_HOST}'",
+		"export READ_URL=postgres://db.internal:5432/app",
+	}, result[".env"].Lines)
+	// 元のデータは変更しない
+	assert.Equal(t, "DB_URL=postgres://${DB_HOST}:${DB_PORT:-5432}/app # primary", data[".env"].Lines[2])
+}
+
+// 正常系: 下位レイヤーのキーを参照し、上位レイヤーが優先される
+func TestInterpolate_AcrossLayers(t *testing.T) {
+	data := MultiEnvData{
+		".env":               {Lines: []string{"HOST=localhost", "URL=http://${HOST}"}},
+		".env.staging":       {Lines: []string{"HOST=staging.example.com", "API=${URL}/api"}},
+		".env.staging.local": {Lines: []string{"DEBUG_URL=${API}?debug=1"}},
+		".env.production":    {Lines: []string{"X=${API:-none}"}},
+	}
+
+	result, err := InterpolateMultiEnvData(data, nil)
+
+	require.NoError(t, err)
+	assert.Equal(t, []string{"HOST=localhost", "URL=http://localhost"}, result[".env"].Lines)
+	assert.Equal(t, []string{"HOST=staging.example.com", "API=http://staging.example.com/api"}, result[".env.staging"].Lines)
+	assert.Equal(t, []string{"DEBUG_URL=http://staging.example.com/api?debug=1"}, result[".env.staging.local"].Lines)
+	assert.Equal(t, []string{"X=none"}, result[".env.production"].Lines)
+}
+
+// 正常系: bw:// 参照を解決し、必要に応じてクォートする
+func TestInterpolate_VaultReference(t *testing.T) {
+	data := MultiEnvData{".env": {Lines: []string{
+		"DB_USER=bw://item/prod-db/username",
+		"DB_PASS=bw://item/prod-db/password",
+		"DSN=${DB_USER}:${DB_PASS}@db",
+	}}}
+	resolve := staticResolver(map[string]string{
+		"bw://item/prod-db/username": "app",
+		"bw://item/prod-db/password": `p@ss "word"`,
+	})
+
+	result, err := InterpolateMultiEnvData(data, resolve)
+
+	require.NoError(t, err)
+	assert.Equal(t, []string{
+		"DB_USER=app",
+		`DB_PASS="p@ss \"word\""`,
+		`DSN="app:p@ss \"word\"@db"`,
+	}, result[".env"].Lines)
+}
+
+// 異常系: 循環参照を検出する
+func TestInterpolate_Cycle(t *testing.T) {
+	data := MultiEnvData{".env": {Lines: []string{"A=${B}", "B=${C}", "C=${A}"}}}
+
+	_, err := InterpolateMultiEnvData(data, nil)
+
+	require.Error(t, err)
+	assert.ErrorIs(t, err, ErrCyclicReference)
+	assert.Contains(t, err.Error(), "A -> B -> C -> A")
+}
+
+// 異常系: 未定義の参照はファイル名と行番号付きのエラー
+func TestInterpolate_Unresolved(t *testing.T) {
+	data := MultiEnvData{".env.staging": {Lines: []string{"", "URL=${MISSING}"}}}
+
+	_, err := InterpolateMultiEnvData(data, nil)
+
+	require.Error(t, err)
+	assert.ErrorIs(t, err, ErrUnresolvedReference)
+	assert.Contains(t, err.Error(), ".env.staging:2: ${MISSING} is not defined")
+}
+
+// 異常系: 解決できない bw:// 参照
+func TestInterpolate_UnresolvedVaultReference(t *testing.T) {
+	data := MultiEnvData{".env": {Lines: []string{"A=bw://item/nope/password"}}}
+
+	_, err := InterpolateMultiEnvData(data, staticResolver(nil))
+
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), ".env:1")
+}
+
+// =============================================================================
+// parseVaultRef のテスト
+// =============================================================================
+
+// 正常系/異常系: 参照の分解
+func TestParseVaultRef(t *testing.T) {
+	tests := []struct {
+		ref      string
+		item     string
+		selector string
+		field    string
+		wantErr  bool
+	}{
+		{"bw://item/prod-db/password", "prod-db", "password", "", false},
+		{"bw://item/_shared/smtp/username", "_shared/smtp", "username", "", false},
+		{"bw://item/stripe/field/Secret%20Key", "stripe", "field", "Secret Key", false},
+		{"bw://item/github/totp", "github", "totp", "", false},
+		{"bw://item/prod-db/ssn", "", "", "", true},
+		{"bw://item/password", "", "", "", true},
+		{"bw://item/x/field/", "", "", "", true},
+	}
+
+	for _, tt := range tests {
+		item, selector, field, err := parseVaultRef(tt.ref)
+		if tt.wantErr {
+			assert.Error(t, err, tt.ref)
+			continue
+		}
+		require.NoError(t, err, tt.ref)
+		assert.Equal(t, tt.item, item, tt.ref)
+		assert.Equal(t, tt.selector, selector, tt.ref)
+		assert.Equal(t, tt.field, field, tt.ref)
+	}
+}
+
+// =============================================================================
+// NewVaultResolver のテスト
+// =============================================================================
+
+// 正常系: 名前で検索したアイテムのフィールドを返し、同じアイテムは 1 回だけ取得する
+func TestNewVaultResolver_ByName(t *testing.T) {
+	bw := &mockBwClient{
+		itemsByName: map[string]*FullItem{
+			"prod-db": {
+				ID:     "item-1",
+				Name:   "prod-db",
+				Login:  &ItemLogin{Username: "app", Password: "secret"},
+				Fields: []ItemField{{Name: "host", Value: "db.internal"}},
+			},
+		},
+	}
+	resolve := NewVaultResolver(bw, &config.Config{}, nil, &mockLogger{}, time.Now)
+
+	user, err := resolve("bw://item/prod-db/username")
+	require.NoError(t, err)
+	host, err := resolve("bw://item/prod-db/field/host")
+	require.NoError(t, err)
+
+	assert.Equal(t, "app", user)
+	assert.Equal(t, "db.internal", host)
+	assert.Equal(t, []string{"GetItemByName(,prod-db)"}, bw.calls)
+}
+
+// 正常系: UUID 形式は ID で取得する
+func TestNewVaultResolver_ByID(t *testing.T) {
+	id := "0f8fad5b-d9cb-469f-a165-70867728950e"
+	bw := &mockBwClient{itemByID: &FullItem{ID: id, Name: "x", Login: &ItemLogin{Password: "pw"}}}
+	resolve := NewVaultResolver(bw, &config.Config{}, nil, &mockLogger{}, time.Now)
+
+	pw, err := resolve("bw://item/" + id + "/password")
+
+	require.NoError(t, err)
+	assert.Equal(t, "pw", pw)
+	assert.Equal(t, []string{"GetItemByID(" + id + ")"}, bw.calls)
+}
+
+// 正常系: TOTP コードを生成する
+func TestNewVaultResolver_TOTP(t *testing.T) {
+	bw := &mockBwClient{itemByName: &FullItem{Name: "gh", Login: &ItemLogin{TOTP: "GEZDGNBVGY3TQOJQGEZDGNBVGY3TQOJQ"}}}
+	resolve := NewVaultResolver(bw, &config.Config{}, nil, &mockLogger{}, func() time.Time { return time.Unix(59, 0) })
+
+	code, err := resolve("bw://item/gh/totp")
+
+	require.NoError(t, err)
+	assert.Equal(t, "287082", code)
+}
+
+// 異常系: アイテムが見つからない、フィールドが無い
+func TestNewVaultResolver_Errors(t *testing.T) {
+	bw := &mockBwClient{itemsByName: map[string]*FullItem{"note": {Name: "note"}}}
+	resolve := NewVaultResolver(bw, &config.Config{}, nil, &mockLogger{}, time.Now)
+
+	_, err := resolve("bw://item/missing/password")
+	assert.ErrorIs(t, err, ErrUnresolvedReference)
+	assert.Contains(t, err.Error(), "not found")
+
+	_, err = resolve("bw://item/note/password")
+	assert.ErrorIs(t, err, ErrUnresolvedReference)
+	assert.Contains(t, err.Error(), "not a login item")
+
+	_, err = resolve("bw://item/note/field/x")
+	assert.ErrorIs(t, err, ErrUnresolvedReference)
+}
+
+// =============================================================================
+// PullEnvCoreWithOptions / PushEnvCoreWithOptions の展開のテスト
+// =============================================================================
+
+// 正常系: 展開した内容を書き出し、同期状態には保存内容のハッシュと展開済みの印を記録する
+func TestPullEnvCore_Interpolate(t *testing.T) {
+	notes := `{".env":{"lines":["HOST=db","URL=pg://${HOST}"]}}`
+	bw := &mockBwClient{folderID: "folder-123", itemByName: &FullItem{ID: "item-1", Name: "app", Notes: notes}}
+	fs := &mockFileSystem{}
+
+	err := PullEnvCoreWithOptions("/app", "app", fs, bw, &config.Config{}, nil, noConfirm, &mockLogger{},
+		PullOptions{Interpolate: true, StatePath: "/state.json"})
+
+	require.NoError(t, err)
+	assert.Equal(t, "HOST=db\nURL=pg://db", string(fs.writtenFiles["/app/.env"]))
+
+	var state SyncState
+	require.NoError(t, json.Unmarshal(fs.writtenFiles["/state.json"], &state))
+	entry := state.Dirs["/app"]
+	assert.Equal(t, hashEnvData(EnvData{Lines: []string{"HOST=db", "URL=pg://${HOST}"}}), entry.Files[".env"])
+	assert.Equal(t, []string{".env"}, entry.Expanded)
+}
+
+// 異常系: 展開して pull したファイルは push しない
+func TestPushEnvCore_RefusesExpandedFiles(t *testing.T) {
+	fs := &mockFileSystem{
+		dirEntries:  []DirEntry{&mockDirEntry{name: ".env"}},
+		statInfoMap: map[string]FileInfo{"/state.json": &mockFileInfo{notExist: false}},
+		readContentMap: map[string][]byte{
+			"/app/.env":   []byte("URL=pg://db\n"),
+			"/state.json": []byte(`{"dirs":{"/app":{"project":"app","files":{".env":"x"},"expanded":[".env"]}}}`),
+		},
+	}
+	bw := &mockBwClient{folderID: "folder-123"}
+
+	err := PushEnvCoreWithOptions("/app", "app", fs, bw, &config.Config{}, nil, &mockLogger{}, PushOptions{StatePath: "/state.json"})
+
+	require.Error(t, err)
+	assert.ErrorIs(t, err, ErrExpandedPush)
+	assert.Empty(t, bw.createdNotes)
+	assert.Empty(t, bw.updatedNotes)
+}
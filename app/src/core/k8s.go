@@ -0,0 +1,312 @@
+package core
+
+import (
+	"encoding/base64"
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"bwsf/src/config"
+
+	"gopkg.in/yaml.v3"
+)
+
+// K8sSecret は Kubernetes の Secret マニフェストのうち bwsf が扱う部分です。Data の値はデコード済みです。
+type K8sSecret struct {
+	Name      string
+	Namespace string
+	Type      string
+	Data      map[string]string
+}
+
+// K8sApplyOptions は ApplyK8sSecretCore の設定です。
+type K8sApplyOptions struct {
+	Namespace string
+	Name      string
+	// File は Secret にする保存済みのファイルです。空の場合は .env です。
+	File string
+	// Interpolate が true の場合、${KEY} と bw:// 参照を展開します（.bwsf.json の interpolate でも有効化）。
+	Interpolate bool
+	// Manifest はマニフェストを書き出すパスです。既存のマニフェストがあれば差分を示し、変更がなければ書き出しません。
+	// 空の場合は書き出さず、呼び出し側が K8sApplyResult.Manifest を出力します。
+	Manifest string
+	// DryRun が true の場合は差分を示すのみで書き出しません。
+	DryRun bool
+}
+
+// K8sApplyResult は ApplyK8sSecretCore の結果です。
+type K8sApplyResult struct {
+	// Manifest は生成した Secret マニフェストです（シークレットの値を含みます）。
+	Manifest string
+	// Changes は既存のマニフェストからの変更です（キー名のみ）。
+	Changes KeyChanges
+	// Existing は既存のマニフェストがあったかです。
+	Existing bool
+	// Written はマニフェストを書き出したかです。
+	Written bool
+}
+
+// K8sImportOptions は ImportK8sSecretCore の設定です。
+type K8sImportOptions struct {
+	// Output は .env を書き出すパスです。空の場合は書き出さず、内容を返すのみです。
+	Output string
+	// Force が true の場合は既存のファイルを上書きします。
+	Force bool
+}
+
+// k8sSecretDocument は Secret マニフェストの YAML の構造です。
+type k8sSecretDocument struct {
+	APIVersion string `yaml:"apiVersion"`
+	Kind       string `yaml:"kind"`
+	Metadata   struct {
+		Name      string `yaml:"name"`
+		Namespace string `yaml:"namespace"`
+	} `yaml:"metadata"`
+	Type       string            `yaml:"type"`
+	Data       map[string]string `yaml:"data"`
+	StringData map[string]string `yaml:"stringData"`
+}
+
+// ParseK8sSecret は Secret マニフェストを読みます。data は base64 をデコードし、stringData の値を優先します。
+func ParseK8sSecret(data []byte) (*K8sSecret, error) {
+	var doc k8sSecretDocument
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return nil, fmt.Errorf("failed to parse manifest: %w", err)
+	}
+	if doc.Kind != "Secret" {
+		return nil, fmt.Errorf("manifest is not a Secret (kind: %q)", doc.Kind)
+	}
+
+	secret := &K8sSecret{Name: doc.Metadata.Name, Namespace: doc.Metadata.Namespace, Type: doc.Type, Data: make(map[string]string)}
+	for key, encoded := range doc.Data {
+		value, err := base64.StdEncoding.DecodeString(encoded)
+		if err != nil {
+			return nil, fmt.Errorf("failed to decode data.%s: %w", key, err)
+		}
+		secret.Data[key] = string(value)
+	}
+	for key, value := range doc.StringData {
+		secret.Data[key] = value
+	}
+	return secret, nil
+}
+
+// RenderK8sSecret は Secret マニフェストを生成します。値は data に base64 で入れ、キーは名前順に並べます。
+// kubeseal などの入力にもそのまま使えます。
+func RenderK8sSecret(secret *K8sSecret, source string) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "# Generated by bwsf from %s. It contains secret values; do not commit it unencrypted.\n", source)
+	b.WriteString("apiVersion: v1\nkind: Secret\nmetadata:\n")
+	fmt.Fprintf(&b, "  name: %s\n", yamlQuote(secret.Name))
+	if secret.Namespace != "" {
+		fmt.Fprintf(&b, "  namespace: %s\n", yamlQuote(secret.Namespace))
+	}
+	secretType := secret.Type
+	if secretType == "" {
+		secretType = "Opaque"
+	}
+	fmt.Fprintf(&b, "type: %s\n", yamlQuote(secretType))
+	if len(secret.Data) == 0 {
+		b.WriteString("data: {}\n")
+		return b.String()
+	}
+	b.WriteString("data:\n")
+	for _, key := range sortedKeys(secret.Data) {
+		fmt.Fprintf(&b, "  %s: %s\n", yamlQuote(key), base64.StdEncoding.EncodeToString([]byte(secret.Data[key])))
+	}
+	return b.String()
+}
+
+// DiffK8sSecret は before から after で追加・変更・削除されたキー名を返します。
+func DiffK8sSecret(before, after *K8sSecret) KeyChanges {
+	var changes KeyChanges
+	for _, key := range sortedKeys(after.Data) {
+		old, ok := before.Data[key]
+		switch {
+		case !ok:
+			changes.Added = append(changes.Added, key)
+		case old != after.Data[key]:
+			changes.Changed = append(changes.Changed, key)
+		}
+	}
+	for _, key := range sortedKeys(before.Data) {
+		if _, ok := after.Data[key]; !ok {
+			changes.Removed = append(changes.Removed, key)
+		}
+	}
+	return changes
+}
+
+// ApplyK8sSecretCore は保存されたファイルから Secret マニフェストを生成し、既存のマニフェストとの差分をキー名のみで示して書き出します。
+// クラスタには接続しません。
+func ApplyK8sSecretCore(
+	projectDir, projectName string,
+	fs FileSystem,
+	bw BwClient,
+	cfg *config.Config,
+	promptPassword func() (string, error),
+	logger Logger,
+	opts K8sApplyOptions,
+) (*K8sApplyResult, error) {
+	if opts.Name == "" {
+		return nil, fmt.Errorf("a Secret name is required")
+	}
+	fileName := opts.File
+	if fileName == "" {
+		fileName = ".env"
+	}
+
+	memFS := &memoryOutputFS{FileSystem: fs, files: make(map[string][]byte)}
+	pullOpts := PullOptions{Interpolate: opts.Interpolate, Files: []string{fileName}, InMemory: true}
+	overwrite := func(path string) (bool, error) { return true, nil }
+	if err := PullEnvCoreWithOptions(projectDir, projectName, memFS, bw, cfg, promptPassword, overwrite, logger, pullOpts); err != nil {
+		return nil, err
+	}
+
+	secret := &K8sSecret{Name: opts.Name, Namespace: opts.Namespace, Data: make(map[string]string)}
+	for _, line := range parseEnvContent(memFS.files[filepath.Join(projectDir, fileName)]).Lines {
+		entry, ok := parseEnvLine(line)
+		if !ok {
+			continue
+		}
+		if !isValidSecretKey(entry.Key) {
+			return nil, fmt.Errorf("%s: %s is not a valid Secret key (allowed: letters, digits, '-', '_' and '.')", fileName, entry.Key)
+		}
+		secret.Data[entry.Key] = entry.Value
+	}
+	source := projectName + "/" + fileName
+	result := &K8sApplyResult{Manifest: RenderK8sSecret(secret, source)}
+	if opts.Manifest == "" {
+		return result, nil
+	}
+
+	info, err := fs.Stat(opts.Manifest)
+	if err != nil {
+		return nil, fmt.Errorf("failed to stat %s: %w", opts.Manifest, err)
+	}
+	if !info.IsNotExist() {
+		data, err := fs.ReadFile(opts.Manifest)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read %s: %w", opts.Manifest, err)
+		}
+		existing, err := ParseK8sSecret(data)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", opts.Manifest, err)
+		}
+		result.Existing = true
+		result.Changes = DiffK8sSecret(existing, secret)
+
+		// 既存の type（kubernetes.io/tls など）は引き継ぐ
+		if existing.Type != "" {
+			secret.Type = existing.Type
+			result.Manifest = RenderK8sSecret(secret, source)
+		}
+		var metadata []string
+		if existing.Name != secret.Name {
+			metadata = append(metadata, fmt.Sprintf("name %q -> %q", existing.Name, secret.Name))
+		}
+		if existing.Namespace != secret.Namespace {
+			metadata = append(metadata, fmt.Sprintf("namespace %q -> %q", existing.Namespace, secret.Namespace))
+		}
+		if len(metadata) > 0 {
+			logger.Warning("The manifest changes ", strings.Join(metadata, ", "))
+		}
+		changed := len(result.Changes.Added)+len(result.Changes.Changed)+len(result.Changes.Removed) > 0
+		if !changed && len(metadata) == 0 {
+			logger.Info(opts.Manifest, " is up to date")
+			return result, nil
+		}
+		if changed {
+			for _, line := range watchSummary(map[string]KeyChanges{filepath.Base(opts.Manifest): result.Changes}) {
+				logger.Info("  ", line)
+			}
+		}
+	}
+
+	if opts.DryRun {
+		return result, nil
+	}
+	if err := fs.WriteFile(opts.Manifest, []byte(result.Manifest), 0600); err != nil {
+		return nil, fmt.Errorf("failed to write %s: %w", opts.Manifest, err)
+	}
+	result.Written = true
+	return result, nil
+}
+
+// ImportK8sSecretCore は Secret マニフェストの値を .env の内容にして返します。Output を指定した場合は書き出します。
+func ImportK8sSecretCore(fs FileSystem, manifestPath string, logger Logger, opts K8sImportOptions) (string, error) {
+	data, err := fs.ReadFile(manifestPath)
+	if err != nil {
+		return "", fmt.Errorf("failed to read %s: %w", manifestPath, err)
+	}
+	secret, err := ParseK8sSecret(data)
+	if err != nil {
+		return "", fmt.Errorf("%s: %w", manifestPath, err)
+	}
+
+	var lines []string
+	for _, key := range sortedKeys(secret.Data) {
+		if !isValidEnvKey(key) {
+			logger.Warning("Skipping ", key, ": not a valid env key")
+			continue
+		}
+		lines = append(lines, key+"="+formatEnvValue(secret.Data[key]))
+	}
+	content := strings.Join(lines, "\n") + "\n"
+	if opts.Output == "" {
+		return content, nil
+	}
+
+	info, err := fs.Stat(opts.Output)
+	if err != nil {
+		return "", fmt.Errorf("failed to stat %s: %w", opts.Output, err)
+	}
+	if !info.IsNotExist() && !opts.Force {
+		return "", fmt.Errorf("%s already exists (use --force to overwrite it)", opts.Output)
+	}
+	if err := fs.WriteFile(opts.Output, []byte(content), 0600); err != nil {
+		return "", fmt.Errorf("failed to write %s: %w", opts.Output, err)
+	}
+	return content, nil
+}
+
+// isValidSecretKey は Secret の data のキーとして使えるか（[-._a-zA-Z0-9]+）を判定します。
+func isValidSecretKey(key string) bool {
+	if key == "" {
+		return false
+	}
+	for _, r := range key {
+		switch {
+		case r == '-' || r == '_' || r == '.':
+		case (r >= 'A' && r <= 'Z') || (r >= 'a' && r <= 'z') || (r >= '0' && r <= '9'):
+		default:
+			return false
+		}
+	}
+	return true
+}
+
+// formatEnvValue は値を .env に書ける形にします。そのまま書けない値はシングルクォート、
+// シングルクォートや改行を含む値はダブルクォート（\\ \" \n をエスケープ）で囲みます。
+func formatEnvValue(value string) string {
+	plain := value == strings.TrimSpace(value) && !strings.ContainsAny(value, " #'\"\\\n\r\t$`")
+	if plain {
+		return value
+	}
+	if !strings.ContainsAny(value, "'\n\r") {
+		return "'" + value + "'"
+	}
+	replacer := strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+	return `"` + replacer.Replace(value) + `"`
+}
+
+// sortedKeys は map のキーを名前順に返します。
+func sortedKeys(m map[string]string) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
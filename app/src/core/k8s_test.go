@@ -0,0 +1,157 @@
+package core
+
+import (
+	"testing"
+
+	"bwsf/src/config"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// k8sFiles は k8s のテストで保存しておく .env と .env.production です。
+var k8sFiles = MultiEnvData{
+	".env":            {Lines: []string{"A=dev"}},
+	".env.production": {Lines: []string{"# prod", "DB_URL=postgres://db", `API_KEY="k #1"`}},
+}
+
+const k8sManifest = `# Generated by bwsf from web/.env.production. It contains secret values; do not commit it unencrypted.
+apiVersion: v1
+kind: Secret
+metadata:
+  name: "web-env"
+  namespace: "prod"
+type: "Opaque"
+data:
+  "API_KEY": ayAjMQ==
+  "DB_URL": cG9zdGdyZXM6Ly9kYg==
+`
+
+// =============================================================================
+// ApplyK8sSecretCore のテスト
+// =============================================================================
+
+// 正常系: 保存されたファイルから Secret を生成する（Manifest を指定しない場合は書き出さない）
+func TestApplyK8sSecretCore_Render(t *testing.T) {
+	fs := &mockFileSystem{}
+
+	result, err := ApplyK8sSecretCore("/work/web", "web", fs, newTestBwClient(t, k8sFiles), &config.Config{}, nil, &mockLogger{},
+		K8sApplyOptions{Namespace: "prod", Name: "web-env", File: ".env.production"})
+
+	require.NoError(t, err)
+	assert.Equal(t, k8sManifest, result.Manifest)
+	assert.False(t, result.Written)
+	assert.Empty(t, fs.writtenFiles)
+}
+
+// 正常系: 既存のマニフェストとの差分をキー名のみで示し、type を引き継いで書き出す
+func TestApplyK8sSecretCore_Diff(t *testing.T) {
+	existing := "apiVersion: v1\nkind: Secret\nmetadata:\n  name: web-env\n  namespace: prod\ntype: example.com/env\ndata:\n  DB_URL: b2xk\n  OLD: eA==\n"
+	fs := &mockFileSystem{
+		readContentMap: map[string][]byte{"/work/web/k8s/secret.yaml": []byte(existing)},
+		statInfoMap:    map[string]FileInfo{"/work/web/k8s/secret.yaml": &mockFileInfo{}},
+	}
+	logger := &mockLogger{}
+
+	result, err := ApplyK8sSecretCore("/work/web", "web", fs, newTestBwClient(t, k8sFiles), &config.Config{}, nil, logger,
+		K8sApplyOptions{Namespace: "prod", Name: "web-env", File: ".env.production", Manifest: "/work/web/k8s/secret.yaml"})
+
+	require.NoError(t, err)
+	assert.True(t, result.Existing)
+	assert.True(t, result.Written)
+	assert.Equal(t, KeyChanges{Added: []string{"API_KEY"}, Changed: []string{"DB_URL"}, Removed: []string{"OLD"}}, result.Changes)
+	assert.Contains(t, logger.infos, "  secret.yaml: +API_KEY ~DB_URL -OLD")
+	assert.Contains(t, string(fs.writtenFiles["/work/web/k8s/secret.yaml"]), `type: "example.com/env"`)
+	for _, line := range logger.infos {
+		assert.NotContains(t, line, "postgres")
+	}
+}
+
+// 正常系: 変更がなければ書き出さない。--dry-run では差分のみ示す
+func TestApplyK8sSecretCore_UpToDateAndDryRun(t *testing.T) {
+	fs := &mockFileSystem{
+		readContentMap: map[string][]byte{"/work/web/secret.yaml": []byte(k8sManifest)},
+		statInfoMap:    map[string]FileInfo{"/work/web/secret.yaml": &mockFileInfo{}},
+	}
+	logger := &mockLogger{}
+	opts := K8sApplyOptions{Namespace: "prod", Name: "web-env", File: ".env.production", Manifest: "/work/web/secret.yaml"}
+
+	result, err := ApplyK8sSecretCore("/work/web", "web", fs, newTestBwClient(t, k8sFiles), &config.Config{}, nil, logger, opts)
+	require.NoError(t, err)
+	assert.False(t, result.Written)
+	assert.Contains(t, logger.infos, "/work/web/secret.yaml is up to date")
+
+	opts.Namespace, opts.DryRun = "staging", true
+	result, err = ApplyK8sSecretCore("/work/web", "web", fs, newTestBwClient(t, k8sFiles), &config.Config{}, nil, logger, opts)
+	require.NoError(t, err)
+	assert.False(t, result.Written)
+	checkWarning(t, logger, `The manifest changes namespace "prod" -> "staging"`)
+	assert.Empty(t, fs.writtenFiles)
+}
+
+// 異常系: Secret 名がない、または保存されていないファイル
+func TestApplyK8sSecretCore_Errors(t *testing.T) {
+	_, err := ApplyK8sSecretCore("/work/web", "web", &mockFileSystem{}, newTestBwClient(t, k8sFiles), &config.Config{}, nil, &mockLogger{}, K8sApplyOptions{})
+	assert.ErrorContains(t, err, "a Secret name is required")
+
+	_, err = ApplyK8sSecretCore("/work/web", "web", &mockFileSystem{}, newTestBwClient(t, k8sFiles), &config.Config{}, nil, &mockLogger{},
+		K8sApplyOptions{Name: "web-env", File: ".env.staging"})
+	assert.ErrorContains(t, err, "file '.env.staging' not found in project")
+}
+
+// =============================================================================
+// ParseK8sSecret / ImportK8sSecretCore のテスト
+// =============================================================================
+
+// 正常系: data をデコードし、stringData を優先する
+func TestParseK8sSecret(t *testing.T) {
+	secret, err := ParseK8sSecret([]byte("kind: Secret\nmetadata:\n  name: s\ndata:\n  A: MQ==\n  B: Mg==\nstringData:\n  B: two\n"))
+
+	require.NoError(t, err)
+	assert.Equal(t, "s", secret.Name)
+	assert.Equal(t, map[string]string{"A": "1", "B": "two"}, secret.Data)
+}
+
+// 異常系: Secret でない、または base64 として不正
+func TestParseK8sSecret_Invalid(t *testing.T) {
+	_, err := ParseK8sSecret([]byte("kind: ConfigMap\n"))
+	assert.ErrorContains(t, err, `manifest is not a Secret (kind: "ConfigMap")`)
+
+	_, err = ParseK8sSecret([]byte("kind: Secret\ndata:\n  A: '!!'\n"))
+	assert.ErrorContains(t, err, "failed to decode data.A")
+}
+
+// 正常系: 値を .env として読み戻せる形で書き出し、env のキーにできないものは警告して除く
+func TestImportK8sSecretCore(t *testing.T) {
+	manifest := "kind: Secret\nstringData:\n  PLAIN: abc\n  SPACED: 'a b #c'\n  QUOTE: it's\n  MULTI: \"l1\\nl2\"\n  tls.crt: x\n  bad-key: y\n"
+	fs := &mockFileSystem{readContentMap: map[string][]byte{"/in/secret.yaml": []byte(manifest)}}
+	logger := &mockLogger{}
+
+	content, err := ImportK8sSecretCore(fs, "/in/secret.yaml", logger, K8sImportOptions{Output: "/work/web/.env.production"})
+
+	require.NoError(t, err)
+	assert.Equal(t, "MULTI=\"l1\\nl2\"\nPLAIN=abc\nQUOTE=\"it's\"\nSPACED='a b #c'\ntls.crt=x\n", content)
+	assert.Equal(t, content, string(fs.writtenFiles["/work/web/.env.production"]))
+	checkWarning(t, logger, "Skipping bad-key")
+
+	values := make(map[string]string)
+	for _, line := range parseEnvContent([]byte(content)).Lines {
+		if entry, ok := parseEnvLine(line); ok {
+			values[entry.Key] = entry.Value
+		}
+	}
+	assert.Equal(t, map[string]string{"MULTI": "l1\nl2", "PLAIN": "abc", "QUOTE": "it's", "SPACED": "a b #c", "tls.crt": "x"}, values)
+}
+
+// 異常系: 既存のファイルは --force なしでは上書きしない
+func TestImportK8sSecretCore_Exists(t *testing.T) {
+	fs := &mockFileSystem{
+		readContentMap: map[string][]byte{"/in/secret.yaml": []byte("kind: Secret\nstringData:\n  A: b\n")},
+		statInfoMap:    map[string]FileInfo{"/work/web/.env": &mockFileInfo{}},
+	}
+
+	_, err := ImportK8sSecretCore(fs, "/in/secret.yaml", &mockLogger{}, K8sImportOptions{Output: "/work/web/.env"})
+
+	assert.ErrorContains(t, err, "/work/web/.env already exists (use --force to overwrite it)")
+	assert.Empty(t, fs.writtenFiles)
+}
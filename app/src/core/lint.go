@@ -0,0 +1,228 @@
+package core
+
+import (
+	"errors"
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"bwsf/src/config"
+)
+
+// Lint ルール名
+const (
+	LintRuleDuplicateKey       = "duplicate-key"
+	LintRuleInvalidKey         = "invalid-key"
+	LintRuleMalformedLine      = "malformed-line"
+	LintRuleUnquotedValue      = "unquoted-value"
+	LintRuleUnbalancedQuotes   = "unbalanced-quotes"
+	LintRuleTrailingWhitespace = "trailing-whitespace"
+	LintRuleBOM                = "bom"
+	LintRuleCRLF               = "crlf"
+	LintRuleEmptyRequired      = "empty-required"
+	LintRulePlaceholder        = "placeholder"
+)
+
+// defaultLintSeverities は各ルールのデフォルトの重大度です。
+var defaultLintSeverities = map[string]string{
+	LintRuleDuplicateKey:       config.SeverityError,
+	LintRuleInvalidKey:         config.SeverityError,
+	LintRuleMalformedLine:      config.SeverityError,
+	LintRuleUnquotedValue:      config.SeverityWarning,
+	LintRuleUnbalancedQuotes:   config.SeverityError,
+	LintRuleTrailingWhitespace: config.SeverityWarning,
+	LintRuleBOM:                config.SeverityError,
+	LintRuleCRLF:               config.SeverityWarning,
+	LintRuleEmptyRequired:      config.SeverityError,
+	LintRulePlaceholder:        config.SeverityWarning,
+	CheckRuleMissingFile:       config.SeverityWarning,
+	CheckRuleMissingKey:        config.SeverityWarning,
+	CheckRuleMissingRequired:   config.SeverityError,
+	CheckRuleExtraKey:          config.SeverityWarning,
+	CheckRuleTypeMismatch:      config.SeverityWarning,
+}
+
+// placeholderPattern は仮の値として使われがちな文字列に一致します。
+var placeholderPattern = regexp.MustCompile(`(?i)^(change[-_]?me|x{3,}|todo|fixme|tbd|placeholder|replace[-_]?me|dummy|your[-_].*[-_]here|<.*>)$`)
+
+// ErrLintFailed は lint でエラーが検出された場合に返されます。
+var ErrLintFailed = errors.New("lint failed")
+
+// LintIssue は lint で検出された 1 件の問題です。
+type LintIssue struct {
+	File     string
+	Line     int // 1 始まり。ファイル全体の問題の場合は 0
+	Rule     string
+	Severity string
+	Message  string
+}
+
+// String は "file:line: [rule] message" 形式の文字列を返します。
+func (i LintIssue) String() string {
+	if i.Line > 0 {
+		return fmt.Sprintf("%s:%d: [%s] %s", i.File, i.Line, i.Rule, i.Message)
+	}
+	return fmt.Sprintf("%s: [%s] %s", i.File, i.Rule, i.Message)
+}
+
+// LintEnvCore はディレクトリ内の .env* ファイルを lint します。
+// ルールの重大度と必須キーは .bwsf.json の lint 設定に従います。
+func LintEnvCore(dir string, fs FileSystem) ([]LintIssue, error) {
+	envFiles, err := findEnvFilesFromFS(fs, dir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find .env files: %w", err)
+	}
+
+	projectCfg, err := LoadProjectConfig(fs, dir)
+	if err != nil {
+		return nil, err
+	}
+
+	multiData := make(MultiEnvData)
+	for _, envPath := range envFiles {
+		content, err := fs.ReadFile(envPath)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read %s: %w", envPath, err)
+		}
+		multiData[filepath.Base(envPath)] = *parseEnvContent(content)
+	}
+
+	return lintMultiEnvData(multiData, projectCfg.Lint), nil
+}
+
+// HasLintErrors は issues に重大度 error の問題が含まれるかを返します。
+func HasLintErrors(issues []LintIssue) bool {
+	for _, issue := range issues {
+		if issue.Severity == config.SeverityError {
+			return true
+		}
+	}
+	return false
+}
+
+// lintMultiEnvData はすべてのファイルを .env 優先の順で lint します。
+func lintMultiEnvData(data MultiEnvData, cfg config.LintConfig) []LintIssue {
+	var names []string
+	for name := range data {
+		names = append(names, name)
+	}
+	sortFileNames(names)
+
+	var issues []LintIssue
+	for _, name := range names {
+		issues = append(issues, lintEnvData(name, data[name], cfg)...)
+	}
+	return issues
+}
+
+// lintEnvData は 1 ファイル分の EnvData を lint します。
+func lintEnvData(fileName string, data EnvData, cfg config.LintConfig) []LintIssue {
+	var issues []LintIssue
+	add := func(line int, rule, format string, args ...interface{}) {
+		severity := lintSeverity(cfg, rule)
+		if severity == config.SeverityOff {
+			return
+		}
+		issues = append(issues, LintIssue{
+			File:     fileName,
+			Line:     line,
+			Rule:     rule,
+			Severity: severity,
+			Message:  fmt.Sprintf(format, args...),
+		})
+	}
+
+	required := make(map[string]bool)
+	for _, key := range cfg.Required {
+		required[key] = true
+	}
+
+	seen := make(map[string]int)
+	crlfLines := 0
+	for i, rawLine := range data.Lines {
+		lineNo := i + 1
+
+		if i == 0 && strings.HasPrefix(rawLine, "\ufeff") {
+			add(lineNo, LintRuleBOM, "file starts with a UTF-8 byte order mark")
+		}
+		if strings.HasSuffix(rawLine, "\r") {
+			crlfLines++
+		}
+
+		line := strings.TrimSuffix(rawLine, "\r")
+		if strings.TrimRight(line, " \t") != line {
+			add(lineNo, LintRuleTrailingWhitespace, "trailing whitespace")
+		}
+
+		trimmed := strings.TrimSpace(strings.TrimPrefix(line, "\ufeff"))
+		if trimmed == "" || strings.HasPrefix(trimmed, "#") {
+			continue
+		}
+
+		entry, ok := parseEnvLine(line)
+		if !ok {
+			add(lineNo, LintRuleMalformedLine, "line is not in KEY=VALUE form")
+			continue
+		}
+
+		if !isValidEnvKey(entry.Key) {
+			add(lineNo, LintRuleInvalidKey, "invalid key %q", entry.Key)
+		}
+
+		if first, dup := seen[entry.Key]; dup {
+			add(lineNo, LintRuleDuplicateKey, "duplicate key %s (first defined on line %d)", entry.Key, first)
+		} else {
+			seen[entry.Key] = lineNo
+		}
+
+		switch {
+		case entry.Quote != 0 && !entry.Closed:
+			add(lineNo, LintRuleUnbalancedQuotes, "unterminated %c quote in value of %s", entry.Quote, entry.Key)
+		// クォートなしの値は " #" 以降のインラインコメントを除いた値で判定する
+		case entry.Quote == 0 && (strings.HasSuffix(entry.Value, `"`) || strings.HasSuffix(entry.Value, "'")):
+			add(lineNo, LintRuleUnbalancedQuotes, "value of %s ends with a quote that is never opened", entry.Key)
+		case entry.Quote == 0 && strings.ContainsAny(entry.Value, " \t#"):
+			add(lineNo, LintRuleUnquotedValue, "value of %s contains spaces or '#' and should be quoted", entry.Key)
+		}
+
+		if required[entry.Key] && entry.Value == "" {
+			add(lineNo, LintRuleEmptyRequired, "required key %s has an empty value", entry.Key)
+		}
+
+		if entry.Value != "" && placeholderPattern.MatchString(entry.Value) {
+			add(lineNo, LintRulePlaceholder, "value of %s looks like a placeholder (%s)", entry.Key, entry.Value)
+		}
+	}
+
+	if crlfLines > 0 {
+		add(0, LintRuleCRLF, "file uses CRLF line endings (%d line(s))", crlfLines)
+	}
+
+	return issues
+}
+
+// lintSeverity はプロジェクト設定を考慮したルールの重大度を返します。
+func lintSeverity(cfg config.LintConfig, rule string) string {
+	if severity, ok := cfg.Rules[rule]; ok {
+		return severity
+	}
+	return defaultLintSeverities[rule]
+}
+
+// reportLintIssues は lint 結果をログに出力し、エラーがあれば ErrLintFailed を返します。
+func reportLintIssues(issues []LintIssue, logger Logger) error {
+	errorCount := 0
+	for _, issue := range issues {
+		if issue.Severity == config.SeverityError {
+			errorCount++
+			logger.Error("[LINT] ", issue.String())
+		} else {
+			logger.Warning("[LINT] ", issue.String())
+		}
+	}
+	if errorCount > 0 {
+		return fmt.Errorf("%w: %d error(s) found (use --no-lint to push anyway)", ErrLintFailed, errorCount)
+	}
+	return nil
+}
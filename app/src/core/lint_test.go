@@ -0,0 +1,195 @@
+package core
+
+import (
+	"errors"
+	"testing"
+
+	"bwsf/src/config"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// issueRules は LintIssue のルール名一覧を返します。
+func issueRules(issues []LintIssue) []string {
+	var rules []string
+	for _, issue := range issues {
+		rules = append(rules, issue.Rule)
+	}
+	return rules
+}
+
+// =============================================================================
+// lintEnvData のテスト
+// =============================================================================
+
+// 正常系: 問題のないファイル
+func TestLintEnvData_Clean(t *testing.T) {
+	data := EnvData{Lines: []string{
+		"# comment",
+		"",
+		"DATABASE_URL=postgres://localhost/db",
+		`GREETING="hello world"`,
+		"export DEBUG=true",
+	}}
+
+	issues := lintEnvData(".env", data, config.LintConfig{})
+
+	assert.Empty(t, issues)
+}
+
+// 正常系: 各ルールが検出される
+func TestLintEnvData_AllRules(t *testing.T) {
+	data := EnvData{Lines: []string{
+		"\ufeffFIRST=1",
+		"DUP=a",
+		"DUP=b",
+		"MY-KEY=x",
+		"NOT_AN_ASSIGNMENT",
+		"SPACED=hello world",
+		`OPEN="never closed`,
+		"TRAILING=value  ",
+		"CRLF=value\r",
+		"REQUIRED=",
+		"SECRET=changeme",
+	}}
+	cfg := config.LintConfig{Required: []string{"REQUIRED"}}
+
+	issues := lintEnvData(".env", data, cfg)
+	rules := issueRules(issues)
+
+	assert.Contains(t, rules, LintRuleBOM)
+	assert.Contains(t, rules, LintRuleDuplicateKey)
+	assert.Contains(t, rules, LintRuleInvalidKey)
+	assert.Contains(t, rules, LintRuleMalformedLine)
+	assert.Contains(t, rules, LintRuleUnquotedValue)
+	assert.Contains(t, rules, LintRuleUnbalancedQuotes)
+	assert.Contains(t, rules, LintRuleTrailingWhitespace)
+	assert.Contains(t, rules, LintRuleCRLF)
+	assert.Contains(t, rules, LintRuleEmptyRequired)
+	assert.Contains(t, rules, LintRulePlaceholder)
+}
+
+// 正常系: 重複キーは最初の行番号を含む
+func TestLintEnvData_DuplicateKeyMessage(t *testing.T) {
+	data := EnvData{Lines: []string{"A=1", "B=2", "A=3"}}
+
+	issues := lintEnvData(".env", data, config.LintConfig{})
+
+	require.Len(t, issues, 1)
+	assert.Equal(t, 3, issues[0].Line)
+	assert.Equal(t, config.SeverityError, issues[0].Severity)
+	assert.Equal(t, ".env:3: [duplicate-key] duplicate key A (first defined on line 1)", issues[0].String())
+}
+
+// 正常系: クォートなしの値のインラインコメントは unquoted-value として報告しない
+func TestLintEnvData_InlineComment(t *testing.T) {
+	data := EnvData{Lines: []string{"KEY=value # comment", "QUOTED=\"a b\" # it's quoted", "BAD=a b # comment"}}
+
+	issues := lintEnvData(".env", data, config.LintConfig{})
+
+	require.Len(t, issues, 1)
+	assert.Equal(t, 3, issues[0].Line)
+	assert.Equal(t, LintRuleUnquotedValue, issues[0].Rule)
+}
+
+// 正常系: プロジェクト設定で重大度を変更・無効化できる
+func TestLintEnvData_SeverityOverride(t *testing.T) {
+	data := EnvData{Lines: []string{"A=1", "A=2", "TOKEN=xxx"}}
+	cfg := config.LintConfig{Rules: map[string]string{
+		LintRuleDuplicateKey: config.SeverityOff,
+		LintRulePlaceholder:  config.SeverityError,
+	}}
+
+	issues := lintEnvData(".env", data, cfg)
+
+	require.Len(t, issues, 1)
+	assert.Equal(t, LintRulePlaceholder, issues[0].Rule)
+	assert.Equal(t, config.SeverityError, issues[0].Severity)
+	assert.True(t, HasLintErrors(issues))
+}
+
+// =============================================================================
+// LintEnvCore / PushEnvCore 連携のテスト
+// =============================================================================
+
+// 正常系: .bwsf.json の設定が反映される
+func TestLintEnvCore_UsesProjectConfig(t *testing.T) {
+	fs := &mockFileSystem{
+		dirEntries: []DirEntry{
+			&mockDirEntry{name: ".env", isDir: false},
+		},
+		readContentMap: map[string][]byte{
+			".env":       []byte("A=1\nA=2\n"),
+			".bwsf.json": []byte(`{"lint":{"rules":{"duplicate-key":"warning"}}}`),
+		},
+		statInfoMap: map[string]FileInfo{
+			".bwsf.json": &mockFileInfo{notExist: false},
+		},
+	}
+
+	issues, err := LintEnvCore(".", fs)
+
+	require.NoError(t, err)
+	require.Len(t, issues, 1)
+	assert.Equal(t, config.SeverityWarning, issues[0].Severity)
+}
+
+// 異常系: lint エラーがあると push されない
+func TestPushEnvCore_LintErrorBlocksPush(t *testing.T) {
+	bw := &mockBwClient{folderID: "folder-123"}
+	fs := &mockFileSystem{
+		dirEntries: []DirEntry{
+			&mockDirEntry{name: ".env", isDir: false},
+		},
+		readContentMap: map[string][]byte{
+			".env": []byte("A=1\nA=2\n"),
+		},
+	}
+	logger := &mockLogger{}
+
+	err := PushEnvCore(".", "my-project", fs, bw, &config.Config{}, func() (string, error) { return "pwd", nil }, logger)
+
+	assert.Error(t, err)
+	assert.True(t, errors.Is(err, ErrLintFailed))
+	assert.Empty(t, bw.calls, "no Bitwarden calls should be made when lint fails")
+	assert.NotEmpty(t, logger.errors)
+}
+
+// 正常系: SkipLint を指定すると lint エラーがあっても push される
+func TestPushEnvCoreWithOptions_SkipLint(t *testing.T) {
+	bw := &mockBwClient{folderID: "folder-123"}
+	fs := &mockFileSystem{
+		dirEntries: []DirEntry{
+			&mockDirEntry{name: ".env", isDir: false},
+		},
+		readContentMap: map[string][]byte{
+			".env": []byte("A=1\nA=2\n"),
+		},
+	}
+
+	err := PushEnvCoreWithOptions(".", "my-project", fs, bw, &config.Config{}, func() (string, error) { return "pwd", nil }, &mockLogger{},
+		PushOptions{SkipLint: true})
+
+	assert.NoError(t, err)
+	assert.Contains(t, bw.calls, "CreateNoteItem(folder-123,my-project)")
+}
+
+// 正常系: 警告のみの場合は push される
+func TestPushEnvCore_LintWarningsDoNotBlock(t *testing.T) {
+	bw := &mockBwClient{folderID: "folder-123"}
+	fs := &mockFileSystem{
+		dirEntries: []DirEntry{
+			&mockDirEntry{name: ".env", isDir: false},
+		},
+		readContentMap: map[string][]byte{
+			".env": []byte("TOKEN=changeme\n"),
+		},
+	}
+	logger := &mockLogger{}
+
+	err := PushEnvCore(".", "my-project", fs, bw, &config.Config{}, func() (string, error) { return "pwd", nil }, logger)
+
+	assert.NoError(t, err)
+	assert.NotEmpty(t, logger.warnings)
+}
@@ -0,0 +1,356 @@
+package core
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"bwsf/src/config"
+)
+
+// KeyMeta はキーごとのメタデータです。.env ファイルには書き出しません。
+type KeyMeta struct {
+	Owner       string   `json:"owner,omitempty"`
+	Description string   `json:"description,omitempty"`
+	Tags        []string `json:"tags,omitempty"`
+	CreatedAt   string   `json:"created_at,omitempty"` // push で初めて保存された日時（RFC 3339）
+	RotatedAt   string   `json:"rotated_at,omitempty"` // 最後に値が変わった日時（RFC 3339）
+	ExpiresAt   string   `json:"expires_at,omitempty"` // 有効期限（RFC 3339）
+}
+
+// LastChanged は最後に値が変わった日時を返します。記録がない場合は ok=false です。
+func (m KeyMeta) LastChanged() (time.Time, bool) {
+	for _, value := range []string{m.RotatedAt, m.CreatedAt} {
+		if value == "" {
+			continue
+		}
+		if t, err := time.Parse(time.RFC3339, value); err == nil {
+			return t, true
+		}
+	}
+	return time.Time{}, false
+}
+
+// isZero はメタデータが何も記録されていないかを返します。
+func (m KeyMeta) isZero() bool {
+	return m.Owner == "" && m.Description == "" && len(m.Tags) == 0 &&
+		m.CreatedAt == "" && m.RotatedAt == "" && m.ExpiresAt == ""
+}
+
+// mergeKeyMeta は before に保存されていたメタデータのうち、after に残っているキーの分を after に引き継ぎます。
+// 新しいキーには created_at、値が変わったキーには rotated_at として now を記録します。
+// KeyMeta に無い項目（以前の rotate が記録した "previous" など）は引き継がないため、保存し直すと消えます。
+func mergeKeyMeta(before, after MultiEnvData, now time.Time) {
+	stamp := now.UTC().Format(time.RFC3339)
+	for fileName, envData := range after {
+		stored := before[fileName].Meta
+		oldValues := envValues(before[fileName])
+		newValues := envValues(envData)
+
+		meta := make(map[string]KeyMeta)
+		for key, value := range newValues {
+			m := stored[key]
+			old, existed := oldValues[key]
+			switch {
+			case !existed && m.CreatedAt == "":
+				m.CreatedAt = stamp
+			case existed && old != value:
+				m.RotatedAt = stamp
+			}
+			if !m.isZero() {
+				meta[key] = m
+			}
+		}
+		envData.Meta = nil
+		if len(meta) > 0 {
+			envData.Meta = meta
+		}
+		after[fileName] = envData
+	}
+}
+
+// KeyMetaEntry はキー名とそのメタデータです。
+type KeyMetaEntry struct {
+	Key string `json:"key"`
+	KeyMeta
+}
+
+// KeyMetaUpdate は bwsf meta で変更する項目です。nil の項目は変更しません（空文字列は削除）。
+type KeyMetaUpdate struct {
+	Owner       *string
+	Description *string
+	Tags        *[]string
+	ExpiresAt   *string // RFC 3339（ParseExpiry で変換済み）
+}
+
+// ParseExpiry は有効期限を解釈します。日付・RFC 3339 の日時・"90d" のような now からの期間を受け付けます。
+func ParseExpiry(value string, now time.Time) (time.Time, error) {
+	value = strings.TrimSpace(value)
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t, nil
+	}
+	if t, err := time.ParseInLocation("2006-01-02", value, now.Location()); err == nil {
+		return t, nil
+	}
+	if d, err := ParseAge(value); err == nil {
+		return now.Add(d), nil
+	}
+	return time.Time{}, fmt.Errorf("invalid expiry %q: use a date (2026-12-31), an RFC3339 time, or a duration such as 90d", value)
+}
+
+// ListKeyMetaCore はプロジェクトの 1 ファイルのキーとメタデータを行の順に返します。
+// メタデータのないキーも含めます。
+func ListKeyMetaCore(
+	projectName, fileName string,
+	bw BwClient,
+	cfg *config.Config,
+	promptPassword func() (string, error),
+	logger Logger,
+) ([]KeyMetaEntry, error) {
+	_, _, envData, err := loadStoredEnvFile(projectName, fileName, bw, cfg, promptPassword, logger)
+	if err != nil {
+		return nil, err
+	}
+
+	var entries []KeyMetaEntry
+	seen := make(map[string]bool)
+	for _, line := range envData.Lines {
+		entry, ok := parseEnvLine(line)
+		if !ok || seen[entry.Key] {
+			continue
+		}
+		seen[entry.Key] = true
+		entries = append(entries, KeyMetaEntry{Key: entry.Key, KeyMeta: envData.Meta[entry.Key]})
+	}
+	return entries, nil
+}
+
+// UpdateKeyMetaCore はプロジェクトの 1 ファイルのキーのメタデータを変更し、変更後のメタデータを返します。
+func UpdateKeyMetaCore(
+	projectName, fileName, key string,
+	update KeyMetaUpdate,
+	bw BwClient,
+	cfg *config.Config,
+	promptPassword func() (string, error),
+	logger Logger,
+) (KeyMeta, error) {
+	if err := syncBeforeWrite(bw, cfg, promptPassword, logger); err != nil {
+		return KeyMeta{}, err
+	}
+	item, multiData, envData, err := loadStoredEnvFile(projectName, fileName, bw, cfg, promptPassword, logger)
+	if err != nil {
+		return KeyMeta{}, err
+	}
+	if _, ok := envValues(envData)[key]; !ok {
+		return KeyMeta{}, fmt.Errorf("key %s not found in %s/%s", key, projectName, fileName)
+	}
+
+	meta := envData.Meta[key]
+	if update.Owner != nil {
+		meta.Owner = strings.TrimSpace(*update.Owner)
+	}
+	if update.Description != nil {
+		meta.Description = strings.TrimSpace(*update.Description)
+	}
+	if update.Tags != nil {
+		meta.Tags = nil
+		for _, tag := range *update.Tags {
+			if tag = strings.TrimSpace(tag); tag != "" {
+				meta.Tags = append(meta.Tags, tag)
+			}
+		}
+	}
+	if update.ExpiresAt != nil {
+		meta.ExpiresAt = *update.ExpiresAt
+	}
+
+	if envData.Meta == nil {
+		envData.Meta = make(map[string]KeyMeta)
+	}
+	envData.Meta[key] = meta
+	multiData[fileName] = envData
+	jsonData, err := multiEnvDataToJSON(multiData)
+	if err != nil {
+		return KeyMeta{}, fmt.Errorf("failed to convert to JSON: %w", err)
+	}
+	err = WithUnlockRetry(bw, cfg, promptPassword, logger, func() error {
+		return bw.UpdateNoteItem(item.ID, jsonData)
+	})
+	if err != nil {
+		return KeyMeta{}, fmt.Errorf("failed to update item: %w", err)
+	}
+	return meta, nil
+}
+
+// loadStoredEnvFile はプロジェクトのアイテム、保存された内容、その 1 ファイル分のデータを返します。
+func loadStoredEnvFile(
+	projectName, fileName string,
+	bw BwClient,
+	cfg *config.Config,
+	promptPassword func() (string, error),
+	logger Logger,
+) (*FullItem, MultiEnvData, EnvData, error) {
+	_, item, err := fetchProjectItem(projectName, bw, cfg, promptPassword, logger)
+	if err != nil {
+		return nil, nil, EnvData{}, err
+	}
+	if item == nil {
+		return nil, nil, EnvData{}, fmt.Errorf("item '%s' not found in dotenvs folder", projectName)
+	}
+	multiData, err := decodeStoredNotes(item.Notes)
+	if err != nil {
+		return nil, nil, EnvData{}, err
+	}
+	envData, ok := multiData[fileName]
+	if !ok {
+		return nil, nil, EnvData{}, fmt.Errorf("file '%s' not found in stored item '%s'", fileName, projectName)
+	}
+	return item, multiData, envData, nil
+}
+
+// レポートでの状態
+const (
+	ReportStale    = "stale"    // 最後の変更から --stale 以上経過（記録がない場合を含む）
+	ReportExpiring = "expiring" // --expiring 以内に期限切れ
+	ReportExpired  = "expired"  // 期限切れ
+)
+
+// ReportOptions は ReportCore の条件です。
+// Stale と Expiring がどちらも 0 の場合は、すべてのキーを返します。
+type ReportOptions struct {
+	Stale    time.Duration
+	Expiring time.Duration
+	Now      time.Time
+}
+
+// ReportRow はレポートの 1 行（1 キー）です。値は含めません。
+type ReportRow struct {
+	Project     string   `json:"project"`
+	File        string   `json:"file"`
+	Key         string   `json:"key"`
+	Owner       string   `json:"owner,omitempty"`
+	Description string   `json:"description,omitempty"`
+	Tags        []string `json:"tags,omitempty"`
+	LastChanged string   `json:"last_changed,omitempty"` // rotated_at、なければ created_at
+	ExpiresAt   string   `json:"expires_at,omitempty"`
+	Status      []string `json:"status,omitempty"` // ReportStale / ReportExpiring / ReportExpired
+}
+
+// ReportCore はフォルダ内のすべてのアイテムのキーを調べ、条件に一致するキーを返します。
+// bwsf の形式でないノートのアイテムは警告してスキップします。
+func ReportCore(
+	opts ReportOptions,
+	bw BwClient,
+	cfg *config.Config,
+	promptPassword func() (string, error),
+	logger Logger,
+) ([]ReportRow, error) {
+	var folderID string
+	err := WithUnlockRetry(bw, cfg, promptPassword, logger, func() error {
+		var innerErr error
+		folderID, innerErr = bw.GetDotenvsFolderID()
+		return innerErr
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get dotenvs folder: %w", err)
+	}
+
+	var items []Item
+	err = WithUnlockRetry(bw, cfg, promptPassword, logger, func() error {
+		var innerErr error
+		items, innerErr = bw.ListItemsInFolder(folderID)
+		return innerErr
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list items: %w", err)
+	}
+
+	var rows []ReportRow
+	for _, item := range items {
+		var full *FullItem
+		err = WithUnlockRetry(bw, cfg, promptPassword, logger, func() error {
+			var innerErr error
+			full, innerErr = bw.GetItemByID(item.ID)
+			return innerErr
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to get item %s: %w", item.Name, err)
+		}
+		if full == nil {
+			continue
+		}
+		multiData, err := decodeStoredNotes(full.Notes)
+		if err != nil {
+			logger.Warning("Skipping ", item.Name, ": ", err.Error())
+			continue
+		}
+		rows = append(rows, reportRows(item.Name, multiData, opts)...)
+	}
+
+	sort.SliceStable(rows, func(i, j int) bool {
+		if rows[i].Project != rows[j].Project {
+			return rows[i].Project < rows[j].Project
+		}
+		return rows[i].File < rows[j].File
+	})
+	return rows, nil
+}
+
+// reportRows は 1 アイテム分のキーのうち条件に一致するものを行の順に返します。
+func reportRows(project string, multiData MultiEnvData, opts ReportOptions) []ReportRow {
+	all := opts.Stale == 0 && opts.Expiring == 0
+
+	var fileNames []string
+	for name := range multiData {
+		fileNames = append(fileNames, name)
+	}
+	sortFileNames(fileNames)
+
+	var rows []ReportRow
+	for _, fileName := range fileNames {
+		envData := multiData[fileName]
+		seen := make(map[string]bool)
+		for _, line := range envData.Lines {
+			entry, ok := parseEnvLine(line)
+			if !ok || seen[entry.Key] {
+				continue
+			}
+			seen[entry.Key] = true
+
+			meta := envData.Meta[entry.Key]
+			row := ReportRow{
+				Project:     project,
+				File:        fileName,
+				Key:         entry.Key,
+				Owner:       meta.Owner,
+				Description: meta.Description,
+				Tags:        meta.Tags,
+				ExpiresAt:   meta.ExpiresAt,
+			}
+
+			changed, known := meta.LastChanged()
+			if known {
+				row.LastChanged = changed.UTC().Format(time.RFC3339)
+			}
+			if opts.Stale > 0 && (!known || opts.Now.Sub(changed) >= opts.Stale) {
+				row.Status = append(row.Status, ReportStale)
+			}
+			if opts.Expiring > 0 && meta.ExpiresAt != "" {
+				if expires, err := time.Parse(time.RFC3339, meta.ExpiresAt); err == nil {
+					switch {
+					case !expires.After(opts.Now):
+						row.Status = append(row.Status, ReportExpired)
+					case expires.Sub(opts.Now) <= opts.Expiring:
+						row.Status = append(row.Status, ReportExpiring)
+					}
+				}
+			}
+
+			if all || len(row.Status) > 0 {
+				rows = append(rows, row)
+			}
+		}
+	}
+	return rows
+}
@@ -0,0 +1,183 @@
+package core
+
+import (
+	"testing"
+	"time"
+
+	"bwsf/src/config"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var metaNow = time.Date(2026, 6, 1, 0, 0, 0, 0, time.UTC)
+
+// =============================================================================
+// push でのメタデータの引き継ぎのテスト
+// =============================================================================
+
+// 正常系: 残っているキーのメタデータを引き継ぎ、新しいキーと値が変わったキーに日時を記録する
+func TestPushEnvCore_MergesKeyMeta(t *testing.T) {
+	bw := newTestBwClient(t, nil)
+	// previous は KeyMeta に無い項目のため、ノートを直接書く
+	bw.itemByName.Notes = `{".env":{"lines":["KEEP=1","CHANGED=old","LEGACY=x","GONE=2"],"meta":{"KEEP":{"owner":"alice","rotated_at":"2026-01-01T00:00:00Z","previous":"0"},"CHANGED":{"created_at":"2025-01-01T00:00:00Z"},"GONE":{"owner":"bob"}}}}`
+	fs := &mockFileSystem{
+		dirEntries:     []DirEntry{&mockDirEntry{name: ".env"}},
+		readContentMap: map[string][]byte{"/web/.env": []byte("KEEP=1\nCHANGED=new\nLEGACY=x\nNEW=3\n")},
+	}
+
+	err := PushEnvCoreWithOptions("/web", "web", fs, bw, &config.Config{}, nil, &mockLogger{}, PushOptions{SkipLint: true, Now: func() time.Time { return metaNow }})
+
+	require.NoError(t, err)
+	stored, err := restoreMultiEnvFromJSON(bw.updatedNotes["item-1"])
+	require.NoError(t, err)
+	assert.Equal(t, map[string]KeyMeta{
+		"KEEP":    {Owner: "alice", RotatedAt: "2026-01-01T00:00:00Z"},
+		"CHANGED": {CreatedAt: "2025-01-01T00:00:00Z", RotatedAt: "2026-06-01T00:00:00Z"},
+		"NEW":     {CreatedAt: "2026-06-01T00:00:00Z"},
+	}, stored[".env"].Meta)
+}
+
+// 正常系: 以前の rotate が記録した前の値は、値が変わったキーでも変わらないキーでも保存し直すと消える
+func TestPushEnvCore_DropsPreviousValues(t *testing.T) {
+	bw := newTestBwClient(t, nil)
+	bw.itemByName.Notes = `{".env":{"lines":["KEEP=1","CHANGED=new-secret"],"meta":{"KEEP":{"previous":"kept-secret"},"CHANGED":{"rotated_at":"2026-01-01T00:00:00Z","previous":"old-secret"}}}}`
+	fs := &mockFileSystem{
+		dirEntries:     []DirEntry{&mockDirEntry{name: ".env"}},
+		readContentMap: map[string][]byte{"/web/.env": []byte("KEEP=1\nCHANGED=newer-secret\n")},
+	}
+
+	err := PushEnvCoreWithOptions("/web", "web", fs, bw, &config.Config{}, nil, &mockLogger{}, PushOptions{SkipLint: true, Now: func() time.Time { return metaNow }})
+
+	require.NoError(t, err)
+	assert.NotContains(t, bw.updatedNotes["item-1"], "previous")
+	assert.NotContains(t, bw.updatedNotes["item-1"], "old-secret")
+	assert.NotContains(t, bw.updatedNotes["item-1"], "kept-secret")
+	stored, err := restoreMultiEnvFromJSON(bw.updatedNotes["item-1"])
+	require.NoError(t, err)
+	assert.Equal(t, map[string]KeyMeta{"CHANGED": {RotatedAt: "2026-06-01T00:00:00Z"}}, stored[".env"].Meta)
+}
+
+// 異常系: 保存されている内容が読めない場合は上書きしない
+func TestPushEnvCore_UndecodableNotes(t *testing.T) {
+	bw := newTestBwClient(t, nil)
+	bw.itemByName.Notes = "not bwsf notes"
+	fs := &mockFileSystem{
+		dirEntries:     []DirEntry{&mockDirEntry{name: ".env"}},
+		readContentMap: map[string][]byte{"/web/.env": []byte("A=1\n")},
+	}
+
+	err := PushEnvCoreWithOptions("/web", "web", fs, bw, &config.Config{}, nil, &mockLogger{}, PushOptions{SkipLint: true})
+
+	assert.ErrorContains(t, err, "cannot read the stored notes of 'web'")
+	assert.Empty(t, bw.updatedNotes)
+}
+
+// =============================================================================
+// ListKeyMetaCore / UpdateKeyMetaCore のテスト
+// =============================================================================
+
+// 正常系: 行の順にキーを返し、以前の rotate が記録した前の値は含めない
+func TestListKeyMetaCore(t *testing.T) {
+	bw := newTestBwClient(t, nil)
+	bw.itemByName.Notes = `{".env":{"lines":["# c","B=1","A=2","B=3"],"meta":{"A":{"owner":"alice","previous":"secret"}}}}`
+
+	entries, err := ListKeyMetaCore("web", ".env", bw, &config.Config{}, nil, &mockLogger{})
+
+	require.NoError(t, err)
+	assert.Equal(t, []KeyMetaEntry{{Key: "B"}, {Key: "A", KeyMeta: KeyMeta{Owner: "alice"}}}, entries)
+}
+
+// 正常系: 指定した項目のみ変更し、他のメタデータは保つ（以前の rotate が記録した前の値は消す）
+func TestUpdateKeyMetaCore(t *testing.T) {
+	bw := newTestBwClient(t, nil)
+	bw.itemByName.Notes = `{".env":{"lines":["TOKEN=a"],"meta":{"TOKEN":{"description":"old","rotated_at":"2026-01-01T00:00:00Z","previous":"z"}}}}`
+	owner, expires := "payments-team", "2026-12-31T00:00:00Z"
+	tags := []string{"pci", " ", "stripe"}
+
+	meta, err := UpdateKeyMetaCore("web", ".env", "TOKEN", KeyMetaUpdate{Owner: &owner, Tags: &tags, ExpiresAt: &expires}, bw, &config.Config{}, nil, &mockLogger{})
+
+	require.NoError(t, err)
+	expected := KeyMeta{Owner: "payments-team", Description: "old", Tags: []string{"pci", "stripe"}, RotatedAt: "2026-01-01T00:00:00Z", ExpiresAt: expires}
+	assert.Equal(t, expected, meta)
+
+	stored, err := restoreMultiEnvFromJSON(bw.updatedNotes["item-1"])
+	require.NoError(t, err)
+	assert.Equal(t, expected, stored[".env"].Meta["TOKEN"])
+	assert.NotContains(t, bw.updatedNotes["item-1"], "previous")
+	assert.Equal(t, []string{"TOKEN=a"}, stored[".env"].Lines)
+}
+
+// 異常系: 存在しないキーは変更しない
+func TestUpdateKeyMetaCore_UnknownKey(t *testing.T) {
+	bw := newTestBwClient(t, MultiEnvData{".env": {Lines: []string{"TOKEN=a"}}})
+	owner := "alice"
+
+	_, err := UpdateKeyMetaCore("web", ".env", "MISSING", KeyMetaUpdate{Owner: &owner}, bw, &config.Config{}, nil, &mockLogger{})
+
+	assert.ErrorContains(t, err, "key MISSING not found in web/.env")
+	assert.Empty(t, bw.updatedNotes)
+}
+
+// =============================================================================
+// ParseExpiry のテスト
+// =============================================================================
+
+// 正常系 / 異常系: 日付・日時・期間
+func TestParseExpiry(t *testing.T) {
+	got, err := ParseExpiry("2026-12-31", metaNow)
+	require.NoError(t, err)
+	assert.Equal(t, time.Date(2026, 12, 31, 0, 0, 0, 0, time.UTC), got)
+
+	got, err = ParseExpiry("30d", metaNow)
+	require.NoError(t, err)
+	assert.Equal(t, metaNow.Add(30*24*time.Hour), got)
+
+	_, err = ParseExpiry("next year", metaNow)
+	assert.Error(t, err)
+}
+
+// =============================================================================
+// ReportCore のテスト
+// =============================================================================
+
+// reportBw は web と api の 2 つのプロジェクトと、bwsf の形式でないアイテムを保存したモックを返します。
+func reportBw(t *testing.T) *mockBwClient {
+	bw := newTestBwClient(t, MultiEnvData{
+		".env": {Lines: []string{"SOON=1", "GONE=2"}, Meta: map[string]KeyMeta{
+			"SOON": {CreatedAt: "2026-05-30T00:00:00Z", ExpiresAt: "2026-06-20T00:00:00Z"},
+			"GONE": {CreatedAt: "2026-05-30T00:00:00Z", ExpiresAt: "2026-05-01T00:00:00Z"},
+		}},
+	})
+	bw.items = append(bw.items, Item{ID: "item-2", Name: "api"}, Item{ID: "item-3", Name: "notes"})
+	bw.itemsByID["item-2"] = &FullItem{ID: "item-2", Name: "api", Notes: `{".env":{"lines":["FRESH=1","OLD=2","LEGACY=3"],"meta":{"FRESH":{"created_at":"2026-05-01T00:00:00Z"},"OLD":{"owner":"bob","created_at":"2025-01-01T00:00:00Z","rotated_at":"2026-01-01T00:00:00Z"}}}}`}
+	bw.itemsByID["item-3"] = &FullItem{ID: "item-3", Name: "notes", Notes: "not bwsf"}
+	return bw
+}
+
+// 正常系: 古いキー・記録のないキー・期限が近いキー・期限切れのキーを返す
+func TestReportCore_StaleAndExpiring(t *testing.T) {
+	logger := &mockLogger{}
+
+	rows, err := ReportCore(ReportOptions{Stale: 90 * 24 * time.Hour, Expiring: 30 * 24 * time.Hour, Now: metaNow}, reportBw(t), &config.Config{}, nil, logger)
+
+	require.NoError(t, err)
+	assert.Equal(t, []ReportRow{
+		{Project: "api", File: ".env", Key: "OLD", Owner: "bob", LastChanged: "2026-01-01T00:00:00Z", Status: []string{ReportStale}},
+		{Project: "api", File: ".env", Key: "LEGACY", Status: []string{ReportStale}},
+		{Project: "web", File: ".env", Key: "SOON", LastChanged: "2026-05-30T00:00:00Z", ExpiresAt: "2026-06-20T00:00:00Z", Status: []string{ReportExpiring}},
+		{Project: "web", File: ".env", Key: "GONE", LastChanged: "2026-05-30T00:00:00Z", ExpiresAt: "2026-05-01T00:00:00Z", Status: []string{ReportExpired}},
+	}, rows)
+	checkWarning(t, logger, "Skipping notes")
+}
+
+// 正常系: 条件がなければすべてのキーを返す
+func TestReportCore_All(t *testing.T) {
+	rows, err := ReportCore(ReportOptions{Now: metaNow}, reportBw(t), &config.Config{}, nil, &mockLogger{})
+
+	require.NoError(t, err)
+	assert.Len(t, rows, 5)
+	for _, row := range rows {
+		assert.Empty(t, row.Status)
+	}
+}
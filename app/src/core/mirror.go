@@ -0,0 +1,499 @@
+package core
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"filippo.io/age"
+)
+
+// Keyring は OS のキーリング（macOS のキーチェーン、Linux の Secret Service）を抽象化するインターフェースです。
+type Keyring interface {
+	// Get は保存された値を返します。存在しない場合は "" を返します。
+	Get(service, account string) (string, error)
+	Set(service, account, secret string) error
+	Delete(service, account string) error
+}
+
+// オフラインミラーの復号鍵の保管方法
+const (
+	MirrorKeyPassword = "password" // マスターパスワード（scrypt）で暗号化してミラーに保存
+	MirrorKeyKeyring  = "keyring"  // OS のキーリングに保存
+)
+
+// ミラーの復号鍵をキーリングに保存する際のサービス名・アカウント名
+const (
+	MirrorKeyringService = "bwsf"
+	MirrorKeyringAccount = "offline-mirror"
+)
+
+// mirrorVersion はミラーファイルの形式のバージョンです。
+const mirrorVersion = 2
+
+// mirrorScryptWorkFactor は秘密鍵をマスターパスワードで暗号化する scrypt の作業係数（log2）です（テストでは減らします）。
+var mirrorScryptWorkFactor = 18
+
+var (
+	// ErrMirrorDisabled はオフラインミラーが有効化されていない場合に返されます。
+	ErrMirrorDisabled = errors.New("offline mirror is not enabled (run 'bwsf offline enable')")
+	// ErrMirrorWrongPassword はミラーの鍵を復号できない場合に返されます。
+	ErrMirrorWrongPassword = errors.New("incorrect master password for the offline mirror")
+	// ErrMirrorNotFound はプロジェクトがミラーに保存されていない場合に返されます。
+	ErrMirrorNotFound = errors.New("not stored in the offline mirror")
+)
+
+// mirrorFile はミラーファイルの内容です。
+// プロジェクト名と保存日時は平文、ノートの内容は age の X25519 受信者宛てに暗号化して保存します。
+// 書き込みには公開鍵のみを使うため、オンライン時はパスワードなしで更新できます。
+type mirrorFile struct {
+	Version   int    `json:"version"`
+	KeySource string `json:"key_source"`
+	Recipient string `json:"recipient"` // age の公開鍵（age1...）
+	// SealedIdentity は KeySource が password の場合の秘密鍵です（マスターパスワードの scrypt 受信者宛てに age で暗号化）。
+	SealedIdentity []byte                 `json:"sealed_identity,omitempty"`
+	Projects       map[string]mirrorEntry `json:"projects"`
+	Queue          []queuedPush           `json:"queue,omitempty"`
+}
+
+// mirrorEntry は最後に push/pull したプロジェクトの内容です。Notes は age で暗号化したノートです。
+type mirrorEntry struct {
+	ItemID  string    `json:"item_id,omitempty"`
+	SavedAt time.Time `json:"saved_at"`
+	Notes   []byte    `json:"notes"`
+}
+
+// queuedPush はオフライン中に行った push です。
+type queuedPush struct {
+	Project  string    `json:"project"`
+	QueuedAt time.Time `json:"queued_at"`
+	BaseHash string    `json:"base_hash,omitempty"` // push 元にした保存内容のハッシュ（新規作成の場合は空）
+	Notes    []byte    `json:"notes"`
+}
+
+// MirrorProject はミラーに保存されたプロジェクトの情報です（表示用）。
+type MirrorProject struct {
+	Name     string
+	ItemID   string
+	SavedAt  time.Time
+	QueuedAt time.Time // オフライン中の push が未反映の場合に設定
+}
+
+// MirrorOptions はミラーの復号鍵の取得方法などの設定です。
+type MirrorOptions struct {
+	Keyring        Keyring
+	PromptPassword func() (string, error)
+	Now            func() time.Time // テスト用（nil の場合は time.Now）
+}
+
+// Mirror は push/pull した内容を暗号化して保存するオフラインミラーです。
+type Mirror struct {
+	fs   FileSystem
+	path string
+	opts MirrorOptions
+
+	mu        sync.Mutex
+	data      *mirrorFile
+	recipient *age.X25519Recipient
+	identity  *age.X25519Identity // 復号が必要になった時点で取得
+}
+
+// EnableMirror は新しい age の鍵ペアを作成してミラーファイルを作成します。
+// keySource が password の場合は秘密鍵を password（scrypt）で暗号化してミラーに保存し、keyring の場合はキーリングに保存します。
+func EnableMirror(fs FileSystem, path, keySource, password string, keyring Keyring) error {
+	info, err := fs.Stat(path)
+	if err != nil {
+		return fmt.Errorf("failed to stat offline mirror: %w", err)
+	}
+	if !info.IsNotExist() {
+		return fmt.Errorf("offline mirror is already enabled (%s)", path)
+	}
+
+	identity, err := age.GenerateX25519Identity()
+	if err != nil {
+		return fmt.Errorf("failed to generate mirror key: %w", err)
+	}
+	data := &mirrorFile{
+		Version:   mirrorVersion,
+		KeySource: keySource,
+		Recipient: identity.Recipient().String(),
+		Projects:  make(map[string]mirrorEntry),
+	}
+
+	switch keySource {
+	case MirrorKeyPassword:
+		if password == "" {
+			return errors.New("master password is required")
+		}
+		data.SealedIdentity, err = sealIdentity(identity, password)
+		if err != nil {
+			return err
+		}
+	case MirrorKeyKeyring:
+		if keyring == nil {
+			return errors.New("OS keyring is not available")
+		}
+		if err := keyring.Set(MirrorKeyringService, MirrorKeyringAccount, identity.String()); err != nil {
+			return fmt.Errorf("failed to store mirror key in the OS keyring: %w", err)
+		}
+	default:
+		return fmt.Errorf("unknown key source '%s'", keySource)
+	}
+
+	return saveMirrorFile(fs, path, data)
+}
+
+// OpenMirror はミラーファイルを読み込みます。有効化されていない場合は ErrMirrorDisabled を返します。
+func OpenMirror(fs FileSystem, path string, opts MirrorOptions) (*Mirror, error) {
+	if opts.Now == nil {
+		opts.Now = time.Now
+	}
+	info, err := fs.Stat(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to stat offline mirror: %w", err)
+	}
+	if info.IsNotExist() {
+		return nil, ErrMirrorDisabled
+	}
+	raw, err := fs.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read offline mirror: %w", err)
+	}
+	var data mirrorFile
+	if err := json.Unmarshal(raw, &data); err != nil {
+		return nil, fmt.Errorf("failed to parse offline mirror: %w", err)
+	}
+	if data.Version != mirrorVersion {
+		return nil, fmt.Errorf("offline mirror %s has an unsupported format (version %d); delete it and run 'bwsf offline enable' again", path, data.Version)
+	}
+	recipient, err := age.ParseX25519Recipient(data.Recipient)
+	if err != nil {
+		return nil, fmt.Errorf("invalid offline mirror key: %w", err)
+	}
+	if data.Projects == nil {
+		data.Projects = make(map[string]mirrorEntry)
+	}
+	return &Mirror{fs: fs, path: path, opts: opts, data: &data, recipient: recipient}, nil
+}
+
+// KeySource はミラーの復号鍵の保管方法を返します。
+func (m *Mirror) KeySource() string {
+	return m.data.KeySource
+}
+
+// Projects はミラーに保存されたプロジェクトを名前順に返します。復号は行いません。
+func (m *Mirror) Projects() []MirrorProject {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	queued := make(map[string]time.Time)
+	for _, q := range m.data.Queue {
+		queued[q.Project] = q.QueuedAt
+	}
+	var projects []MirrorProject
+	for name, entry := range m.data.Projects {
+		projects = append(projects, MirrorProject{Name: name, ItemID: entry.ItemID, SavedAt: entry.SavedAt, QueuedAt: queued[name]})
+	}
+	sort.Slice(projects, func(i, j int) bool { return projects[i].Name < projects[j].Name })
+	return projects
+}
+
+// Pending はオフライン中に行い、まだ反映していない push のプロジェクト名を返します。
+func (m *Mirror) Pending() []string {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	names := make([]string, 0, len(m.data.Queue))
+	for _, q := range m.data.Queue {
+		names = append(names, q.Project)
+	}
+	return names
+}
+
+// Record は project の保存内容を記録します。公開鍵のみで暗号化するためパスワードは不要です。
+func (m *Mirror) Record(project, itemID, notes string) error {
+	box, err := ageEncrypt(m.recipient, []byte(notes))
+	if err != nil {
+		return err
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if itemID == "" {
+		itemID = m.data.Projects[project].ItemID
+	}
+	m.data.Projects[project] = mirrorEntry{ItemID: itemID, SavedAt: m.opts.Now(), Notes: box}
+	return m.saveLocked()
+}
+
+// Forget は project をミラーから削除します（未反映の push は残します）。
+func (m *Mirror) Forget(project string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if _, ok := m.data.Projects[project]; !ok {
+		return nil
+	}
+	delete(m.data.Projects, project)
+	return m.saveLocked()
+}
+
+// Lookup は project の保存内容と保存日時を返します。必要であれば復号鍵を取得します。
+// オフライン中の push がある場合は、その内容を返します。
+func (m *Mirror) Lookup(project string) (itemID, notes string, savedAt time.Time, err error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	entry, ok := m.data.Projects[project]
+	if !ok {
+		return "", "", time.Time{}, fmt.Errorf("'%s' %w", project, ErrMirrorNotFound)
+	}
+	box := entry.Notes
+	if i := m.queueIndexLocked(project); i >= 0 {
+		box = m.data.Queue[i].Notes
+	}
+	plain, err := m.openLocked(box)
+	if err != nil {
+		return "", "", time.Time{}, err
+	}
+	return entry.ItemID, string(plain), entry.SavedAt, nil
+}
+
+// Enqueue はオフライン中の push を記録します。
+// 同じプロジェクトの push が既にあれば内容のみ置き換え、push 元（BaseHash）は最初のものを保ちます。
+func (m *Mirror) Enqueue(project, notes string) error {
+	box, err := ageEncrypt(m.recipient, []byte(notes))
+	if err != nil {
+		return err
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if i := m.queueIndexLocked(project); i >= 0 {
+		m.data.Queue[i].Notes = box
+		m.data.Queue[i].QueuedAt = m.opts.Now()
+		return m.saveLocked()
+	}
+
+	q := queuedPush{Project: project, QueuedAt: m.opts.Now(), Notes: box}
+	if entry, ok := m.data.Projects[project]; ok {
+		base, err := m.openLocked(entry.Notes)
+		if err != nil {
+			return err
+		}
+		q.BaseHash = hashNotes(string(base))
+	} else {
+		// 保存内容が無いプロジェクトも、オフライン中の pull で参照できるように記録する
+		m.data.Projects[project] = mirrorEntry{SavedAt: m.opts.Now(), Notes: box}
+	}
+	m.data.Queue = append(m.data.Queue, q)
+	return m.saveLocked()
+}
+
+// Discard は project のオフライン中の push を取り消します。
+func (m *Mirror) Discard(project string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	i := m.queueIndexLocked(project)
+	if i < 0 {
+		return fmt.Errorf("no queued push for '%s'", project)
+	}
+	m.data.Queue = append(m.data.Queue[:i], m.data.Queue[i+1:]...)
+	return m.saveLocked()
+}
+
+// queued は project のオフライン中の push の内容と push 元のハッシュを返します。
+func (m *Mirror) queued(project string) (notes, baseHash string, err error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	i := m.queueIndexLocked(project)
+	if i < 0 {
+		return "", "", fmt.Errorf("no queued push for '%s'", project)
+	}
+	plain, err := m.openLocked(m.data.Queue[i].Notes)
+	if err != nil {
+		return "", "", err
+	}
+	return string(plain), m.data.Queue[i].BaseHash, nil
+}
+
+// applied は反映した push を取り除き、反映後の内容を記録します。
+func (m *Mirror) applied(project, itemID, notes string) error {
+	box, err := ageEncrypt(m.recipient, []byte(notes))
+	if err != nil {
+		return err
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if i := m.queueIndexLocked(project); i >= 0 {
+		m.data.Queue = append(m.data.Queue[:i], m.data.Queue[i+1:]...)
+	}
+	m.data.Projects[project] = mirrorEntry{ItemID: itemID, SavedAt: m.opts.Now(), Notes: box}
+	return m.saveLocked()
+}
+
+func (m *Mirror) queueIndexLocked(project string) int {
+	for i, q := range m.data.Queue {
+		if q.Project == project {
+			return i
+		}
+	}
+	return -1
+}
+
+func (m *Mirror) saveLocked() error {
+	return saveMirrorFile(m.fs, m.path, m.data)
+}
+
+// openLocked は box を復号します。初回は復号鍵をキーリングまたはマスターパスワードから取得します。
+func (m *Mirror) openLocked(box []byte) ([]byte, error) {
+	if m.identity == nil {
+		identity, err := m.loadIdentity()
+		if err != nil {
+			return nil, err
+		}
+		m.identity = identity
+	}
+	plain, err := ageDecrypt(m.identity, box)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decrypt mirror entry: %w", err)
+	}
+	return plain, nil
+}
+
+// loadIdentity は復号鍵を取得します。
+func (m *Mirror) loadIdentity() (*age.X25519Identity, error) {
+	var secret string
+	switch m.data.KeySource {
+	case MirrorKeyKeyring:
+		if m.opts.Keyring == nil {
+			return nil, errors.New("OS keyring is not available")
+		}
+		var err error
+		if secret, err = m.opts.Keyring.Get(MirrorKeyringService, MirrorKeyringAccount); err != nil {
+			return nil, fmt.Errorf("failed to read mirror key from the OS keyring: %w", err)
+		}
+		if secret == "" {
+			return nil, errors.New("mirror key not found in the OS keyring")
+		}
+	default:
+		if len(m.data.SealedIdentity) == 0 {
+			return nil, errors.New("offline mirror has no key")
+		}
+		if m.opts.PromptPassword == nil {
+			return nil, errors.New("master password is required to read the offline mirror")
+		}
+		password, err := m.opts.PromptPassword()
+		if err != nil {
+			return nil, fmt.Errorf("failed to get master password: %w", err)
+		}
+		if secret, err = openIdentity(m.data.SealedIdentity, password); err != nil {
+			return nil, err
+		}
+	}
+
+	identity, err := age.ParseX25519Identity(secret)
+	if err != nil {
+		return nil, fmt.Errorf("invalid mirror key: %w", err)
+	}
+	return identity, nil
+}
+
+// saveMirrorFile はミラーファイルを書き出します。
+func saveMirrorFile(fs FileSystem, path string, data *mirrorFile) error {
+	raw, err := json.MarshalIndent(data, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal offline mirror: %w", err)
+	}
+	if err := fs.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return fmt.Errorf("failed to create offline mirror directory: %w", err)
+	}
+	if err := fs.WriteFile(path, raw, 0600); err != nil {
+		return fmt.Errorf("failed to write offline mirror: %w", err)
+	}
+	return nil
+}
+
+// hashNotes はノートの内容のハッシュを返します（オフライン中の push の競合検出に使います）。
+func hashNotes(notes string) string {
+	sum := sha256.Sum256([]byte(notes))
+	return hex.EncodeToString(sum[:])
+}
+
+// ageEncrypt は plain を recipient 宛てに age で暗号化します。
+func ageEncrypt(recipient age.Recipient, plain []byte) ([]byte, error) {
+	var buf bytes.Buffer
+	w, err := age.Encrypt(&buf, recipient)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encrypt offline mirror: %w", err)
+	}
+	if _, err := w.Write(plain); err != nil {
+		return nil, fmt.Errorf("failed to encrypt offline mirror: %w", err)
+	}
+	if err := w.Close(); err != nil {
+		return nil, fmt.Errorf("failed to encrypt offline mirror: %w", err)
+	}
+	return buf.Bytes(), nil
+}
+
+// ageDecrypt は ageEncrypt で暗号化したデータを identity で復号します。
+func ageDecrypt(identity age.Identity, data []byte) ([]byte, error) {
+	r, err := age.Decrypt(bytes.NewReader(data), identity)
+	if err != nil {
+		return nil, err
+	}
+	return io.ReadAll(r)
+}
+
+// sealIdentity は秘密鍵を password の scrypt 受信者宛てに暗号化します。
+func sealIdentity(identity *age.X25519Identity, password string) ([]byte, error) {
+	recipient, err := age.NewScryptRecipient(password)
+	if err != nil {
+		return nil, err
+	}
+	recipient.SetWorkFactor(mirrorScryptWorkFactor)
+	return ageEncrypt(recipient, []byte(identity.String()))
+}
+
+// openIdentity は password で秘密鍵を復号します。
+func openIdentity(sealed []byte, password string) (string, error) {
+	identity, err := age.NewScryptIdentity(password)
+	if err != nil {
+		return "", err
+	}
+	plain, err := ageDecrypt(identity, sealed)
+	if err != nil {
+		var noMatch *age.NoIdentityMatchError
+		if errors.As(err, &noMatch) {
+			return "", ErrMirrorWrongPassword
+		}
+		return "", fmt.Errorf("failed to decrypt mirror key: %w", err)
+	}
+	return strings.TrimSpace(string(plain)), nil
+}
+
+// FormatAge は経過時間を分単位に丸めて表示用に整形します（例: "3h12m", "2d4h"）。
+func FormatAge(d time.Duration) string {
+	if d < time.Minute {
+		return "less than a minute"
+	}
+	d = d.Round(time.Minute)
+	days := int(d / (24 * time.Hour))
+	hours := int(d % (24 * time.Hour) / time.Hour)
+	minutes := int(d % time.Hour / time.Minute)
+	switch {
+	case days > 0:
+		return fmt.Sprintf("%dd%dh", days, hours)
+	case hours > 0:
+		return fmt.Sprintf("%dh%dm", hours, minutes)
+	}
+	return fmt.Sprintf("%dm", minutes)
+}
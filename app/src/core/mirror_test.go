@@ -0,0 +1,197 @@
+package core
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func init() {
+	// テストでは鍵導出を軽くする
+	mirrorScryptWorkFactor = 10
+}
+
+// mockKeyring はメモリ上のキーリングです。
+type mockKeyring struct {
+	secrets map[string]string
+	setErr  error
+}
+
+func (k *mockKeyring) Get(service, account string) (string, error) {
+	return k.secrets[service+"/"+account], nil
+}
+
+func (k *mockKeyring) Set(service, account, secret string) error {
+	if k.setErr != nil {
+		return k.setErr
+	}
+	if k.secrets == nil {
+		k.secrets = make(map[string]string)
+	}
+	k.secrets[service+"/"+account] = secret
+	return nil
+}
+
+func (k *mockKeyring) Delete(service, account string) error {
+	delete(k.secrets, service+"/"+account)
+	return nil
+}
+
+// passwordPrompt は password を返し、呼び出し回数を数えるプロンプトです。
+func passwordPrompt(password string, count *int) func() (string, error) {
+	return func() (string, error) {
+		*count++
+		return password, nil
+	}
+}
+
+// openTestMirror はパスワード方式のミラーを有効化して開きます。
+func openTestMirror(t *testing.T, opts MirrorOptions) (*Mirror, *mockFileSystem) {
+	t.Helper()
+	fs := &mockFileSystem{}
+	require.NoError(t, EnableMirror(fs, "/cfg/mirror.json", MirrorKeyPassword, "master", nil))
+	fs = nextRunFS(fs)
+	m, err := OpenMirror(fs, "/cfg/mirror.json", opts)
+	require.NoError(t, err)
+	return m, fs
+}
+
+// =============================================================================
+// EnableMirror / OpenMirror のテスト
+// =============================================================================
+
+// 正常系: マスターパスワードで復号でき、ファイルには平文を書き込まない
+func TestMirror_PasswordRoundTrip(t *testing.T) {
+	prompts := 0
+	m, fs := openTestMirror(t, MirrorOptions{PromptPassword: passwordPrompt("master", &prompts)})
+
+	require.NoError(t, m.Record("web", "item-1", `{".env":{"lines":["SECRET=hunter2"]}}`))
+	require.NoError(t, m.Record("api", "item-2", `{".env":{"lines":["SECRET=swordfish"]}}`))
+	assert.Equal(t, 0, prompts, "recording needs only the public key")
+	assert.NotContains(t, string(fs.writtenFiles["/cfg/mirror.json"]), "hunter2")
+	assert.Contains(t, string(fs.writtenFiles["/cfg/mirror.json"]), `"recipient": "age1`)
+
+	id, notes, _, err := m.Lookup("web")
+	require.NoError(t, err)
+	assert.Equal(t, "item-1", id)
+	assert.Contains(t, notes, "SECRET=hunter2")
+	_, notes, _, err = m.Lookup("api")
+	require.NoError(t, err)
+	assert.Contains(t, notes, "SECRET=swordfish")
+	assert.Equal(t, 1, prompts)
+
+	// 書き出したファイルを開き直しても読める
+	reopened, err := OpenMirror(nextRunFS(fs), "/cfg/mirror.json", MirrorOptions{PromptPassword: passwordPrompt("master", &prompts)})
+	require.NoError(t, err)
+	_, notes, _, err = reopened.Lookup("web")
+	require.NoError(t, err)
+	assert.Contains(t, notes, "SECRET=hunter2")
+}
+
+// 異常系: パスワードが違う場合は復号できない
+func TestMirror_WrongPassword(t *testing.T) {
+	prompts := 0
+	m, _ := openTestMirror(t, MirrorOptions{PromptPassword: passwordPrompt("wrong", &prompts)})
+	require.NoError(t, m.Record("web", "item-1", "{}"))
+
+	_, _, _, err := m.Lookup("web")
+
+	assert.ErrorIs(t, err, ErrMirrorWrongPassword)
+}
+
+// 正常系: キーリング方式ではパスワードを求めない
+func TestMirror_Keyring(t *testing.T) {
+	keyring := &mockKeyring{}
+	fs := &mockFileSystem{}
+	require.NoError(t, EnableMirror(fs, "/cfg/mirror.json", MirrorKeyKeyring, "", keyring))
+	assert.Contains(t, keyring.secrets[MirrorKeyringService+"/"+MirrorKeyringAccount], "AGE-SECRET-KEY-1")
+
+	m, err := OpenMirror(nextRunFS(fs), "/cfg/mirror.json", MirrorOptions{Keyring: keyring})
+	require.NoError(t, err)
+	assert.Equal(t, MirrorKeyKeyring, m.KeySource())
+	require.NoError(t, m.Record("web", "item-1", "NOTES"))
+
+	_, notes, _, err := m.Lookup("web")
+	require.NoError(t, err)
+	assert.Equal(t, "NOTES", notes)
+}
+
+// 異常系: 有効化済み・未有効化・キーリングへの保存失敗
+func TestMirror_EnableErrors(t *testing.T) {
+	fs := &mockFileSystem{statInfo: &mockFileInfo{notExist: false}}
+	assert.ErrorContains(t, EnableMirror(fs, "/cfg/mirror.json", MirrorKeyPassword, "master", nil), "already enabled")
+
+	_, err := OpenMirror(&mockFileSystem{}, "/cfg/mirror.json", MirrorOptions{})
+	assert.ErrorIs(t, err, ErrMirrorDisabled)
+
+	keyring := &mockKeyring{setErr: errors.New("no secret service")}
+	err = EnableMirror(&mockFileSystem{}, "/cfg/mirror.json", MirrorKeyKeyring, "", keyring)
+	assert.ErrorContains(t, err, "no secret service")
+}
+
+// 異常系: 以前の形式のミラーは開かない
+func TestMirror_UnsupportedVersion(t *testing.T) {
+	fs := &mockFileSystem{
+		statInfo:       &mockFileInfo{notExist: false},
+		readContentMap: map[string][]byte{"/cfg/mirror.json": []byte(`{"version":1,"key_source":"password","public_key":"AAAA"}`)},
+	}
+
+	_, err := OpenMirror(fs, "/cfg/mirror.json", MirrorOptions{})
+
+	assert.ErrorContains(t, err, "unsupported format (version 1)")
+}
+
+// =============================================================================
+// Enqueue / Discard のテスト
+// =============================================================================
+
+// 正常系: 同じプロジェクトの push は 1 件にまとめ、push 元のハッシュは最初のものを保つ
+func TestMirror_Enqueue(t *testing.T) {
+	prompts := 0
+	now := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	m, _ := openTestMirror(t, MirrorOptions{PromptPassword: passwordPrompt("master", &prompts), Now: func() time.Time { return now }})
+	require.NoError(t, m.Record("web", "item-1", "v1"))
+
+	require.NoError(t, m.Enqueue("web", "v2"))
+	require.NoError(t, m.Enqueue("web", "v3"))
+	require.NoError(t, m.Enqueue("worker", "w1"))
+
+	assert.Equal(t, []string{"web", "worker"}, m.Pending())
+	notes, base, err := m.queued("web")
+	require.NoError(t, err)
+	assert.Equal(t, "v3", notes)
+	assert.Equal(t, hashNotes("v1"), base)
+	_, base, err = m.queued("worker")
+	require.NoError(t, err)
+	assert.Empty(t, base)
+
+	// オフライン中の pull では push した内容を返す
+	_, notes, _, err = m.Lookup("web")
+	require.NoError(t, err)
+	assert.Equal(t, "v3", notes)
+
+	require.NoError(t, m.Discard("web"))
+	assert.Equal(t, []string{"worker"}, m.Pending())
+	assert.Error(t, m.Discard("web"))
+
+	projects := m.Projects()
+	require.Len(t, projects, 2)
+	assert.Equal(t, "web", projects[0].Name)
+	assert.True(t, projects[0].QueuedAt.IsZero())
+	assert.Equal(t, now, projects[1].QueuedAt)
+}
+
+// =============================================================================
+// FormatAge のテスト
+// =============================================================================
+
+// 正常系: 経過時間の表示
+func TestFormatAge(t *testing.T) {
+	assert.Equal(t, "less than a minute", FormatAge(30*time.Second))
+	assert.Equal(t, "5m", FormatAge(5*time.Minute))
+	assert.Equal(t, "3h12m", FormatAge(3*time.Hour+12*time.Minute))
+	assert.Equal(t, "2d4h", FormatAge(52*time.Hour))
+}
@@ -0,0 +1,154 @@
+package core
+
+import (
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// NameCache はシェル補完用にプロジェクト名とファイル名を保持するディスク上のキャッシュです。
+// 補完はパスワードを求められないため、保管庫には問い合わせずにこのキャッシュのみを読みます。
+// 値やキー名は含めません。list / pull / push などが成功するたびに更新されます。
+type NameCache struct {
+	Projects map[string][]string `json:"projects"` // プロジェクト名 -> 保存されているファイル名
+}
+
+// nameCacheMu は名前キャッシュの読み書きを直列化します（ワークスペース処理で並列に更新されるため）。
+var nameCacheMu sync.Mutex
+
+// LoadNameCache は名前キャッシュを読み込みます。ファイルがない場合は空のキャッシュを返します。
+func LoadNameCache(fs FileSystem, path string) (*NameCache, error) {
+	cache := &NameCache{Projects: make(map[string][]string)}
+
+	info, err := fs.Stat(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to stat name cache: %w", err)
+	}
+	if info.IsNotExist() {
+		return cache, nil
+	}
+
+	data, err := fs.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read name cache: %w", err)
+	}
+	if len(strings.TrimSpace(string(data))) == 0 {
+		return cache, nil
+	}
+	if err := json.Unmarshal(data, cache); err != nil {
+		return nil, fmt.Errorf("failed to parse name cache: %w", err)
+	}
+	if cache.Projects == nil {
+		cache.Projects = make(map[string][]string)
+	}
+	return cache, nil
+}
+
+// UpdateNameCache は名前キャッシュを読み込み、update で変更して書き戻します。
+func UpdateNameCache(fs FileSystem, path string, update func(*NameCache)) error {
+	nameCacheMu.Lock()
+	defer nameCacheMu.Unlock()
+
+	cache, err := LoadNameCache(fs, path)
+	if err != nil {
+		return err
+	}
+	update(cache)
+
+	data, err := json.MarshalIndent(cache, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal name cache: %w", err)
+	}
+	if err := fs.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("failed to create name cache directory: %w", err)
+	}
+	if err := fs.WriteFile(path, data, 0600); err != nil {
+		return fmt.Errorf("failed to write name cache: %w", err)
+	}
+	return nil
+}
+
+// SetProjects は保管庫にあるプロジェクトの一覧で置き換えます（list の結果）。
+// 残るプロジェクトのファイル名は維持し、一覧にないプロジェクトは削除します。
+func (c *NameCache) SetProjects(names []string) {
+	projects := make(map[string][]string, len(names))
+	for _, name := range names {
+		projects[name] = c.Projects[name]
+	}
+	c.Projects = projects
+}
+
+// SetFiles はプロジェクトに保存されているファイル名を置き換えます（pull で保管庫から取得した一覧）。
+func (c *NameCache) SetFiles(project string, files []string) {
+	c.Projects[project] = uniqueSortedFiles(files)
+}
+
+// AddFiles はプロジェクトにファイル名を追加します（push・cp で保存したファイル）。
+func (c *NameCache) AddFiles(project string, files []string) {
+	c.Projects[project] = uniqueSortedFiles(append(append([]string(nil), c.Projects[project]...), files...))
+}
+
+// RemoveProject はプロジェクトを削除します。
+func (c *NameCache) RemoveProject(project string) {
+	delete(c.Projects, project)
+}
+
+// RenameProject はプロジェクト名を変更します。
+func (c *NameCache) RenameProject(oldName, newName string) {
+	files := c.Projects[oldName]
+	delete(c.Projects, oldName)
+	c.Projects[newName] = files
+}
+
+// CompleteProjects は prefix で始まるプロジェクト名を返します。
+func (c *NameCache) CompleteProjects(prefix string) []string {
+	var names []string
+	for name := range c.Projects {
+		if strings.HasPrefix(name, prefix) {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+// CompleteFiles は project に保存されている prefix で始まるファイル名を返します。
+func (c *NameCache) CompleteFiles(project, prefix string) []string {
+	var names []string
+	for _, name := range c.Projects[project] {
+		if strings.HasPrefix(name, prefix) {
+			names = append(names, name)
+		}
+	}
+	return names
+}
+
+// CompleteEnvs は project のファイル名から prefix で始まる環境名（".env.staging" -> "staging"）を返します。
+func (c *NameCache) CompleteEnvs(project, prefix string) []string {
+	var names []string
+	for _, name := range c.Projects[project] {
+		if !strings.HasPrefix(name, ".env.") {
+			continue
+		}
+		if env := strings.TrimPrefix(name, ".env."); strings.HasPrefix(env, prefix) {
+			names = append(names, env)
+		}
+	}
+	return names
+}
+
+func uniqueSortedFiles(files []string) []string {
+	seen := make(map[string]bool, len(files))
+	var result []string
+	for _, name := range files {
+		if !seen[name] {
+			seen[name] = true
+			result = append(result, name)
+		}
+	}
+	sortFileNames(result)
+	return result
+}
@@ -0,0 +1,77 @@
+package core
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// =============================================================================
+// LoadNameCache / UpdateNameCache のテスト
+// =============================================================================
+
+// 正常系: ファイルがない場合は空、更新内容が保存され次回読み込める
+func TestUpdateNameCache(t *testing.T) {
+	fs := &mockFileSystem{}
+	path := filepath.Join("/home", ".config", "bwsf", "names.json")
+
+	cache, err := LoadNameCache(fs, path)
+	require.NoError(t, err)
+	assert.Empty(t, cache.Projects)
+
+	err = UpdateNameCache(fs, path, func(c *NameCache) {
+		c.SetFiles("web", []string{".env.local", ".env", ".env.local"})
+	})
+	require.NoError(t, err)
+	assert.Contains(t, fs.calls, "MkdirAll(/home/.config/bwsf)")
+
+	fs.readContentMap = map[string][]byte{path: fs.writtenFiles[path]}
+	fs.statInfoMap = map[string]FileInfo{path: &mockFileInfo{notExist: false}}
+	cache, err = LoadNameCache(fs, path)
+	require.NoError(t, err)
+	assert.Equal(t, map[string][]string{"web": {".env", ".env.local"}}, cache.Projects)
+}
+
+// 異常系: 壊れたキャッシュ
+func TestLoadNameCache_Invalid(t *testing.T) {
+	fs := &mockFileSystem{readContent: []byte("{"), statInfo: &mockFileInfo{notExist: false}}
+
+	_, err := LoadNameCache(fs, "/names.json")
+
+	assert.ErrorContains(t, err, "failed to parse name cache")
+}
+
+// =============================================================================
+// NameCache の更新・補完のテスト
+// =============================================================================
+
+// 正常系: list・push・rm・mv に合わせた更新
+func TestNameCache_Updates(t *testing.T) {
+	cache := &NameCache{Projects: map[string][]string{"web": {".env"}, "old": {".env"}}}
+
+	cache.SetProjects([]string{"web", "api"})
+	assert.Equal(t, map[string][]string{"web": {".env"}, "api": nil}, cache.Projects)
+
+	cache.AddFiles("web", []string{".env.staging", ".env"})
+	assert.Equal(t, []string{".env", ".env.staging"}, cache.Projects["web"])
+
+	cache.RenameProject("web", "site")
+	cache.RemoveProject("api")
+	assert.Equal(t, map[string][]string{"site": {".env", ".env.staging"}}, cache.Projects)
+}
+
+// 正常系: 前方一致でプロジェクト名・ファイル名・環境名を返す
+func TestNameCache_Complete(t *testing.T) {
+	cache := &NameCache{Projects: map[string][]string{
+		"web-prod": {".env", ".env.staging", ".env.staging.local"},
+		"web-dev":  nil,
+		"api":      nil,
+	}}
+
+	assert.Equal(t, []string{"web-dev", "web-prod"}, cache.CompleteProjects("web"))
+	assert.Equal(t, []string{".env.staging", ".env.staging.local"}, cache.CompleteFiles("web-prod", ".env."))
+	assert.Equal(t, []string{"staging", "staging.local"}, cache.CompleteEnvs("web-prod", ""))
+	assert.Empty(t, cache.CompleteFiles("unknown", ""))
+}
@@ -0,0 +1,132 @@
+package core
+
+import (
+	"fmt"
+	"path"
+	"sort"
+	"strings"
+	"time"
+)
+
+// PushEvent は push 後のフックに渡す内容です。
+// シークレットの値は含めず、変更されたキー名のみを含めます。
+type PushEvent struct {
+	Event     string                `json:"event"` // 常に "push"
+	Project   string                `json:"project"`
+	Files     []string              `json:"files"`
+	Changes   map[string]KeyChanges `json:"changes"` // ファイル名 -> キーの変更
+	Actor     string                `json:"actor"`
+	Host      string                `json:"host,omitempty"`
+	Timestamp time.Time             `json:"timestamp"`
+}
+
+// Summary はチャット向けの 1 行の要約を返します（例: "alice pushed my-app: .env +NEW ~API_KEY -OLD"）。
+func (e PushEvent) Summary() string {
+	var files []string
+	for fileName := range e.Changes {
+		files = append(files, fileName)
+	}
+	sortFileNames(files)
+
+	var parts []string
+	for _, fileName := range files {
+		changes := e.Changes[fileName]
+		part := []string{fileName}
+		for _, key := range changes.Added {
+			part = append(part, "+"+key)
+		}
+		for _, key := range changes.Changed {
+			part = append(part, "~"+key)
+		}
+		for _, key := range changes.Removed {
+			part = append(part, "-"+key)
+		}
+		parts = append(parts, strings.Join(part, " "))
+	}
+
+	actor := e.Actor
+	if e.Host != "" {
+		actor += "@" + e.Host
+	}
+	return fmt.Sprintf("%s pushed %s: %s", actor, e.Project, strings.Join(parts, "; "))
+}
+
+// PostPushHook は push 成功後に実行されるフックです（Webhook やローカルのコマンド）。
+type PostPushHook interface {
+	// Name はログに表示するフックの名前です。
+	Name() string
+	// Run はイベントを通知します。リトライとタイムアウトは実装側で扱います。
+	Run(event PushEvent) error
+}
+
+// projectHook は対象プロジェクトを名前のパターンで絞り込むフックです。
+type projectHook struct {
+	PostPushHook
+	patterns []string
+}
+
+// FilterHookProjects は patterns（path.Match の形式）に一致するプロジェクトのみで実行するフックを返します。
+// patterns が空の場合は hook をそのまま返します。
+func FilterHookProjects(hook PostPushHook, patterns []string) (PostPushHook, error) {
+	if len(patterns) == 0 {
+		return hook, nil
+	}
+	for _, pattern := range patterns {
+		if _, err := path.Match(pattern, ""); err != nil {
+			return nil, fmt.Errorf("invalid project pattern %q: %w", pattern, err)
+		}
+	}
+	return &projectHook{PostPushHook: hook, patterns: patterns}, nil
+}
+
+func (h *projectHook) matches(project string) bool {
+	for _, pattern := range h.patterns {
+		if ok, _ := path.Match(pattern, project); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// PushNotifier は push の変更内容をフックに通知します。
+type PushNotifier struct {
+	actor AuditActor
+	hooks []PostPushHook
+	now   func() time.Time
+}
+
+// NewPushNotifier は PushNotifier を作成します。now が nil の場合は time.Now を使います。
+func NewPushNotifier(actor AuditActor, now func() time.Time, hooks ...PostPushHook) *PushNotifier {
+	if now == nil {
+		now = time.Now
+	}
+	return &PushNotifier{actor: actor, hooks: hooks, now: now}
+}
+
+// notify は変更があった場合にすべてのフックを実行します。
+// push 自体は成功しているため、失敗したフックは警告に留めます。
+func (n *PushNotifier) notify(project string, files []string, changes map[string]KeyChanges, logger Logger) {
+	if n == nil || len(changes) == 0 {
+		return
+	}
+	files = append([]string(nil), files...)
+	sort.Strings(files)
+	event := PushEvent{
+		Event:     "push",
+		Project:   project,
+		Files:     files,
+		Changes:   changes,
+		Actor:     n.actor.User,
+		Host:      n.actor.Host,
+		Timestamp: n.now(),
+	}
+
+	for _, hook := range n.hooks {
+		if filtered, ok := hook.(*projectHook); ok && !filtered.matches(project) {
+			continue
+		}
+		if err := hook.Run(event); err != nil {
+			logger.Warning("Post-push hook ", hook.Name(), " failed: ", err.Error())
+		}
+	}
+}
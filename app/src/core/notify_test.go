@@ -0,0 +1,102 @@
+package core
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"bwsf/src/config"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// mockHook は受け取ったイベントを保持するフックです。
+type mockHook struct {
+	events []PushEvent
+	err    error
+}
+
+func (h *mockHook) Name() string { return "mock" }
+
+func (h *mockHook) Run(event PushEvent) error {
+	h.events = append(h.events, event)
+	return h.err
+}
+
+// pushWithNotifier は保管庫に notes が保存された状態で content を push します。
+func pushWithNotifier(t *testing.T, project, notes, content string, hooks ...PostPushHook) *mockLogger {
+	t.Helper()
+	bw := &mockBwClient{folderID: "folder-123"}
+	if notes != "" {
+		bw.itemByName = &FullItem{ID: "item-1", Name: project, Notes: notes}
+	}
+	fs := &mockFileSystem{
+		dirEntries:     []DirEntry{&mockDirEntry{name: ".env"}},
+		readContentMap: map[string][]byte{"/" + project + "/.env": []byte(content)},
+	}
+	logger := &mockLogger{}
+	now := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	notifier := NewPushNotifier(AuditActor{User: "alice", Host: "laptop"}, func() time.Time { return now }, hooks...)
+
+	err := PushEnvCoreWithOptions("/"+project, project, fs, bw, &config.Config{}, nil, logger, PushOptions{SkipLint: true, Notify: notifier})
+	require.NoError(t, err)
+	return logger
+}
+
+// =============================================================================
+// PushNotifier のテスト
+// =============================================================================
+
+// 正常系: 追加・変更・削除されたキー名を通知し、値は含めない
+func TestPushNotifier_Notify(t *testing.T) {
+	hook := &mockHook{}
+
+	pushWithNotifier(t, "web", `{".env":{"lines":["KEEP=1","ROTATED=old-secret","DROPPED=x"]}}`, "KEEP=1\nROTATED=new-secret\nADDED=y", hook)
+
+	require.Len(t, hook.events, 1)
+	event := hook.events[0]
+	assert.Equal(t, "push", event.Event)
+	assert.Equal(t, "web", event.Project)
+	assert.Equal(t, []string{".env"}, event.Files)
+	assert.Equal(t, KeyChanges{Added: []string{"ADDED"}, Changed: []string{"ROTATED"}, Removed: []string{"DROPPED"}}, event.Changes[".env"])
+	assert.Equal(t, "alice", event.Actor)
+	assert.Equal(t, time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC), event.Timestamp)
+	assert.Equal(t, "alice@laptop pushed web: .env +ADDED ~ROTATED -DROPPED", event.Summary())
+}
+
+// 正常系: 内容が変わっていない push では通知しない
+func TestPushNotifier_NoChanges(t *testing.T) {
+	hook := &mockHook{}
+
+	pushWithNotifier(t, "web", `{".env":{"lines":["A=1"]}}`, "A=1", hook)
+
+	assert.Empty(t, hook.events)
+}
+
+// 正常系: プロジェクト名のパターンに一致するフックのみ実行する
+func TestPushNotifier_FilterProjects(t *testing.T) {
+	prodHook, allHook := &mockHook{}, &mockHook{}
+	filtered, err := FilterHookProjects(prodHook, []string{"*-prod"})
+	require.NoError(t, err)
+
+	pushWithNotifier(t, "web-staging", "", "A=1", filtered, allHook)
+	pushWithNotifier(t, "web-prod", "", "A=1", filtered, allHook)
+
+	require.Len(t, prodHook.events, 1)
+	assert.Equal(t, "web-prod", prodHook.events[0].Project)
+	assert.Len(t, allHook.events, 2)
+
+	_, err = FilterHookProjects(prodHook, []string{"["})
+	assert.Error(t, err)
+}
+
+// 異常系: フックが失敗しても push は成功し、警告を出して次のフックを実行する
+func TestPushNotifier_HookError(t *testing.T) {
+	broken, next := &mockHook{err: errors.New("connection refused")}, &mockHook{}
+
+	logger := pushWithNotifier(t, "web", "", "A=1", broken, next)
+
+	assert.Len(t, next.events, 1)
+	checkWarning(t, logger, "Post-push hook mock failed: connection refused")
+}
@@ -0,0 +1,477 @@
+package core
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"bwsf/src/config"
+)
+
+// ErrOfflineUnavailable はオフラインでは実行できない操作の場合に返されます。
+var ErrOfflineUnavailable = errors.New("not available offline")
+
+// offlineFolderID はオフライン時に GetDotenvsFolderID が返すフォルダ ID です。
+const offlineFolderID = "offline-mirror"
+
+// unreachablePatterns は Bitwarden サーバーに接続できない場合の bw CLI のエラーに含まれる文字列です。
+var unreachablePatterns = []string{
+	"econnrefused",
+	"enotfound",
+	"etimedout",
+	"eai_again",
+	"econnreset",
+	"ehostunreach",
+	"enetunreach",
+	"getaddrinfo",
+	"fetch failed",
+	"failed to fetch",
+	"socket hang up",
+	"network is unreachable",
+}
+
+// IsUnreachableError はエラーが Bitwarden サーバーに接続できないことを示すかを判定します。
+func IsUnreachableError(err error) bool {
+	if err == nil {
+		return false
+	}
+	msg := strings.ToLower(err.Error())
+	for _, pattern := range unreachablePatterns {
+		if strings.Contains(msg, pattern) {
+			return true
+		}
+	}
+	return false
+}
+
+// OfflineBwClient は BwClient をラップし、オフラインミラーを使って接続できない場合も pull/push できるようにします。
+//
+// オンライン時は dotenvs フォルダのアイテムを取得・保存するたびにミラーへ記録します。
+// オフライン時（--offline、またはサーバーに接続できなかった場合）は読み取りをミラーから返し、
+// push はキューに記録して、後で ReplayOfflinePushesCore で反映します。
+type OfflineBwClient struct {
+	BwClient
+
+	mirror *Mirror
+	logger Logger
+
+	mu       sync.Mutex
+	offline  bool
+	folderID string            // オンライン時の dotenvs フォルダ ID
+	names    map[string]string // アイテム ID -> プロジェクト名
+	reported map[string]bool   // 鮮度を報告済みのプロジェクト
+}
+
+// NewOfflineBwClient は bw をラップした OfflineBwClient を作成します。offline が true の場合は最初からミラーのみを使います。
+func NewOfflineBwClient(bw BwClient, mirror *Mirror, logger Logger, offline bool) *OfflineBwClient {
+	if offline {
+		logger.Warning("Offline mode: serving from the local mirror; pushes are queued")
+	}
+	return &OfflineBwClient{
+		BwClient: bw,
+		mirror:   mirror,
+		logger:   logger,
+		offline:  offline,
+		names:    make(map[string]string),
+		reported: make(map[string]bool),
+	}
+}
+
+// Offline はミラーを使っているかを返します。
+func (c *OfflineBwClient) Offline() bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.offline
+}
+
+// fallback は err がサーバーに接続できないことを示す場合にオフラインに切り替え、true を返します。
+func (c *OfflineBwClient) fallback(err error) bool {
+	if !IsUnreachableError(err) {
+		return false
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if !c.offline {
+		c.offline = true
+		c.logger.Warning("Bitwarden server is unreachable; falling back to the offline mirror")
+	}
+	return true
+}
+
+// remember はアイテム ID とプロジェクト名の対応を記録します。
+func (c *OfflineBwClient) remember(id, name string) {
+	if id == "" {
+		return
+	}
+	c.mu.Lock()
+	c.names[id] = name
+	c.mu.Unlock()
+}
+
+// nameOf はアイテム ID に対応するプロジェクト名を返します。
+func (c *OfflineBwClient) nameOf(id string) (string, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	name, ok := c.names[id]
+	return name, ok
+}
+
+// record はオンラインで取得・保存した内容をミラーに記録します。記録できなくても処理は続けます。
+func (c *OfflineBwClient) record(name, id, notes string) {
+	if err := c.mirror.Record(name, id, notes); err != nil {
+		c.logger.Warning("Failed to update the offline mirror: ", err.Error())
+	}
+}
+
+// lookup はミラーからプロジェクトを返し、初回は保存日時を報告します。
+func (c *OfflineBwClient) lookup(name string) (*FullItem, error) {
+	id, notes, savedAt, err := c.mirror.Lookup(name)
+	if errors.Is(err, ErrMirrorNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if id == "" {
+		id = offlineFolderID + "/" + name
+	}
+	c.remember(id, name)
+
+	c.mu.Lock()
+	report := !c.reported[name]
+	c.reported[name] = true
+	c.mu.Unlock()
+	if report {
+		c.logger.Warning(fmt.Sprintf("Offline: '%s' is served from the mirror saved %s ago (%s)",
+			name, FormatAge(c.mirror.opts.Now().Sub(savedAt)), savedAt.Local().Format(time.RFC3339)))
+	}
+	return &FullItem{ID: id, Name: name, Notes: notes}, nil
+}
+
+// enqueue はオフライン中の push をキューに記録します。
+func (c *OfflineBwClient) enqueue(name, notes string) error {
+	if err := c.mirror.Enqueue(name, notes); err != nil {
+		return err
+	}
+	c.logger.Info(fmt.Sprintf("Queued the push of '%s'; run 'bwsf offline sync' when the server is reachable", name))
+	return nil
+}
+
+func (c *OfflineBwClient) GetDotenvsFolderID() (string, error) {
+	if !c.Offline() {
+		folderID, err := c.BwClient.GetDotenvsFolderID()
+		if !c.fallback(err) {
+			if err == nil {
+				c.mu.Lock()
+				c.folderID = folderID
+				c.mu.Unlock()
+			}
+			return folderID, err
+		}
+	}
+	return offlineFolderID, nil
+}
+
+func (c *OfflineBwClient) DotenvsFolderExists() (bool, error) {
+	if !c.Offline() {
+		exists, err := c.BwClient.DotenvsFolderExists()
+		if !c.fallback(err) {
+			return exists, err
+		}
+	}
+	return true, nil
+}
+
+// isMirrored はフォルダがミラーの対象（dotenvs フォルダ）かを返します。
+func (c *OfflineBwClient) isMirrored(folderID string) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return folderID != "" && (folderID == c.folderID || folderID == offlineFolderID)
+}
+
+func (c *OfflineBwClient) ListItemsInFolder(folderID string) ([]Item, error) {
+	if !c.Offline() {
+		items, err := c.BwClient.ListItemsInFolder(folderID)
+		if !c.fallback(err) {
+			if err == nil && c.isMirrored(folderID) {
+				for _, item := range items {
+					c.remember(item.ID, item.Name)
+				}
+			}
+			return items, err
+		}
+	}
+
+	var items []Item
+	for _, project := range c.mirror.Projects() {
+		item := Item{ID: project.ItemID, Name: project.Name, RevisionDate: project.SavedAt.Format(time.RFC3339)}
+		if item.ID == "" {
+			item.ID = offlineFolderID + "/" + project.Name
+		}
+		c.remember(item.ID, item.Name)
+		items = append(items, item)
+	}
+	return items, nil
+}
+
+func (c *OfflineBwClient) GetItemByName(folderID, name string) (*FullItem, error) {
+	if !c.Offline() {
+		item, err := c.BwClient.GetItemByName(folderID, name)
+		if !c.fallback(err) {
+			if err == nil && item != nil && c.isMirrored(folderID) {
+				c.remember(item.ID, name)
+				c.record(name, item.ID, item.Notes)
+			}
+			return item, err
+		}
+	}
+	if folderID == "" {
+		return nil, fmt.Errorf("looking up '%s' outside the dotenvs folder is %w", name, ErrOfflineUnavailable)
+	}
+	return c.lookup(name)
+}
+
+func (c *OfflineBwClient) GetItemByID(id string) (*FullItem, error) {
+	name, known := c.nameOf(id)
+	if !c.Offline() {
+		item, err := c.BwClient.GetItemByID(id)
+		if !c.fallback(err) {
+			if err == nil && item != nil && known {
+				c.record(name, item.ID, item.Notes)
+			}
+			return item, err
+		}
+	}
+	if !known {
+		return nil, fmt.Errorf("item '%s' is %w", id, ErrOfflineUnavailable)
+	}
+	return c.lookup(name)
+}
+
+func (c *OfflineBwClient) CreateNoteItem(folderID, name, notes string) error {
+	if !c.Offline() {
+		err := c.BwClient.CreateNoteItem(folderID, name, notes)
+		if !c.fallback(err) {
+			if err == nil && c.isMirrored(folderID) {
+				c.record(name, "", notes)
+			}
+			return err
+		}
+	}
+	return c.enqueue(name, notes)
+}
+
+func (c *OfflineBwClient) UpdateNoteItem(id, notes string) error {
+	name, known := c.nameOf(id)
+	if !c.Offline() {
+		err := c.BwClient.UpdateNoteItem(id, notes)
+		if !c.fallback(err) {
+			if err == nil && known {
+				c.record(name, id, notes)
+			}
+			return err
+		}
+	}
+	if !known {
+		return fmt.Errorf("item '%s' is %w", id, ErrOfflineUnavailable)
+	}
+	return c.enqueue(name, notes)
+}
+
+// UpdateNoteItemFields はオンラインでのみ使えます。フィールドはミラーに保存せず、ノートのみを記録します。
+func (c *OfflineBwClient) UpdateNoteItemFields(id, notes string, hidden []ItemField) error {
+	if c.Offline() {
+		return fmt.Errorf("updating item fields is %w", ErrOfflineUnavailable)
+	}
+	if err := c.BwClient.UpdateNoteItemFields(id, notes, hidden); err != nil {
+		return err
+	}
+	if name, ok := c.nameOf(id); ok {
+		c.record(name, id, notes)
+	}
+	return nil
+}
+
+func (c *OfflineBwClient) RenameItem(id, newName string) error {
+	if c.Offline() {
+		return fmt.Errorf("rename is %w", ErrOfflineUnavailable)
+	}
+	if err := c.BwClient.RenameItem(id, newName); err != nil {
+		return err
+	}
+	if name, ok := c.nameOf(id); ok {
+		if err := c.mirror.Forget(name); err != nil {
+			c.logger.Warning("Failed to update the offline mirror: ", err.Error())
+		}
+	}
+	return nil
+}
+
+func (c *OfflineBwClient) DeleteItem(id string, permanent bool) error {
+	if c.Offline() {
+		return fmt.Errorf("delete is %w", ErrOfflineUnavailable)
+	}
+	if err := c.BwClient.DeleteItem(id, permanent); err != nil {
+		return err
+	}
+	if name, ok := c.nameOf(id); ok {
+		if err := c.mirror.Forget(name); err != nil {
+			c.logger.Warning("Failed to update the offline mirror: ", err.Error())
+		}
+	}
+	return nil
+}
+
+func (c *OfflineBwClient) CreateDotenvsFolder() error {
+	if c.Offline() {
+		return nil
+	}
+	return c.BwClient.CreateDotenvsFolder()
+}
+
+func (c *OfflineBwClient) Login(email, password, serverURL string) error {
+	if c.Offline() {
+		return nil
+	}
+	err := c.BwClient.Login(email, password, serverURL)
+	if c.fallback(err) {
+		return nil
+	}
+	return err
+}
+
+func (c *OfflineBwClient) Unlock(masterPassword string) error {
+	if c.Offline() {
+		return nil
+	}
+	return c.BwClient.Unlock(masterPassword)
+}
+
+func (c *OfflineBwClient) Sync() error {
+	if c.Offline() {
+		return nil
+	}
+	err := c.BwClient.Sync()
+	if c.fallback(err) {
+		return nil
+	}
+	return err
+}
+
+// SyncForWrite はオンラインであれば、内側のクライアントの SyncForWrite を呼びます（なければ Sync）。
+func (c *OfflineBwClient) SyncForWrite() error {
+	if c.Offline() {
+		return nil
+	}
+	sync := c.BwClient.Sync
+	if syncer, ok := c.BwClient.(WriteSyncer); ok {
+		sync = syncer.SyncForWrite
+	}
+	err := sync()
+	if c.fallback(err) {
+		return nil
+	}
+	return err
+}
+
+// ReplayResult はオフライン中の push を 1 件反映した結果です。
+type ReplayResult struct {
+	Project  string
+	Conflict bool // 記録後に Bitwarden 側が変更されていたため反映しなかった
+	Err      error
+}
+
+// ReplayOfflinePushesCore はオフライン中の push を記録順に反映します。
+// push 元にした内容から Bitwarden 側が変更されていた場合は反映せず、競合として残します。
+// force が true の場合は競合していても上書きします。
+func ReplayOfflinePushesCore(
+	bw BwClient,
+	mirror *Mirror,
+	cfg *config.Config,
+	promptPassword func() (string, error),
+	logger Logger,
+	force bool,
+) ([]ReplayResult, error) {
+	pending := mirror.Pending()
+	if len(pending) == 0 {
+		return nil, nil
+	}
+
+	// 競合の判定には最新の内容が必要なため、キャッシュが新しくても同期する
+	if err := syncBeforeWrite(bw, cfg, promptPassword, logger); err != nil {
+		return nil, err
+	}
+	var folderID string
+	err := WithUnlockRetry(bw, cfg, promptPassword, logger, func() error {
+		if err := bw.Sync(); err != nil {
+			return err
+		}
+		var innerErr error
+		folderID, innerErr = bw.GetDotenvsFolderID()
+		return innerErr
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get dotenvs folder: %w", err)
+	}
+
+	results := make([]ReplayResult, 0, len(pending))
+	for _, project := range pending {
+		result := ReplayResult{Project: project}
+		result.Conflict, result.Err = replayOfflinePush(bw, mirror, cfg, promptPassword, logger, folderID, project, force)
+		results = append(results, result)
+	}
+	return results, nil
+}
+
+// replayOfflinePush は project のオフライン中の push を 1 件反映します。
+func replayOfflinePush(
+	bw BwClient,
+	mirror *Mirror,
+	cfg *config.Config,
+	promptPassword func() (string, error),
+	logger Logger,
+	folderID, project string,
+	force bool,
+) (bool, error) {
+	notes, baseHash, err := mirror.queued(project)
+	if err != nil {
+		return false, err
+	}
+
+	var remote *FullItem
+	err = WithUnlockRetry(bw, cfg, promptPassword, logger, func() error {
+		var innerErr error
+		remote, innerErr = bw.GetItemByName(folderID, project)
+		return innerErr
+	})
+	if err != nil {
+		return false, fmt.Errorf("failed to get item: %w", err)
+	}
+
+	remoteHash := ""
+	if remote != nil {
+		remoteHash = hashNotes(remote.Notes)
+	}
+	switch {
+	case remote != nil && remote.Notes == notes:
+		// 既に同じ内容が保存されている
+		return false, mirror.applied(project, remote.ID, notes)
+	case remoteHash != baseHash && !force:
+		return true, nil
+	}
+
+	itemID := ""
+	err = WithUnlockRetry(bw, cfg, promptPassword, logger, func() error {
+		if remote != nil {
+			itemID = remote.ID
+			return bw.UpdateNoteItem(remote.ID, notes)
+		}
+		return bw.CreateNoteItem(folderID, project, notes)
+	})
+	if err != nil {
+		return false, fmt.Errorf("failed to push: %w", err)
+	}
+	return false, mirror.applied(project, itemID, notes)
+}
@@ -0,0 +1,186 @@
+package core
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"bwsf/src/config"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// offlineMirror は web の内容を 2 時間前に記録したミラーを返します。
+func offlineMirror(t *testing.T) *Mirror {
+	t.Helper()
+	prompts := 0
+	now := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	m, _ := openTestMirror(t, MirrorOptions{PromptPassword: passwordPrompt("master", &prompts), Now: func() time.Time { return now }})
+	m.opts.Now = func() time.Time { return now.Add(-2 * time.Hour) }
+	require.NoError(t, m.Record("web", "item-1", `{".env":{"lines":["APP=web"]}}`))
+	m.opts.Now = func() time.Time { return now }
+	return m
+}
+
+// =============================================================================
+// IsUnreachableError のテスト
+// =============================================================================
+
+// 正常系: 接続エラーのみを判定する
+func TestIsUnreachableError(t *testing.T) {
+	assert.True(t, IsUnreachableError(errors.New("request to https://vault.example.com failed, reason: connect ECONNREFUSED 10.0.0.1:443")))
+	assert.True(t, IsUnreachableError(errors.New("getaddrinfo ENOTFOUND vault.example.com")))
+	assert.False(t, IsUnreachableError(errors.New("Bitwarden CLI is locked")))
+	assert.False(t, IsUnreachableError(nil))
+}
+
+// =============================================================================
+// OfflineBwClient のテスト
+// =============================================================================
+
+// 正常系: オンラインで pull した内容をミラーに記録する
+func TestOfflineBwClient_RecordsOnline(t *testing.T) {
+	m := offlineMirror(t)
+	mock := &mockBwClient{folderID: "folder-123", itemByName: &FullItem{ID: "item-9", Name: "api", Notes: `{".env":{"lines":["APP=api"]}}`}}
+	bw := NewOfflineBwClient(mock, m, &mockLogger{}, false)
+
+	require.NoError(t, PullEnvCore("/api", "api", &mockFileSystem{}, bw, &config.Config{}, nil, noConfirm, &mockLogger{}))
+
+	id, notes, _, err := m.Lookup("api")
+	require.NoError(t, err)
+	assert.Equal(t, "item-9", id)
+	assert.Contains(t, notes, "APP=api")
+	assert.False(t, bw.Offline())
+}
+
+// 正常系: --offline ではミラーから pull し、鮮度を報告する
+func TestOfflineBwClient_PullOffline(t *testing.T) {
+	mock := &mockBwClient{}
+	logger := &mockLogger{}
+	bw := NewOfflineBwClient(mock, offlineMirror(t), logger, true)
+	fs := &mockFileSystem{}
+
+	require.NoError(t, PullEnvCore("/web", "web", fs, bw, &config.Config{}, nil, noConfirm, logger))
+
+	assert.Equal(t, "APP=web", string(fs.writtenFiles["/web/.env"]))
+	assert.Empty(t, mock.calls)
+	checkWarning(t, logger, "'web' is served from the mirror saved 2h0m ago")
+}
+
+// 正常系: サーバーに接続できない場合は自動でミラーに切り替える
+func TestOfflineBwClient_Fallback(t *testing.T) {
+	mock := &mockBwClient{folderIDErr: errors.New("connect ECONNREFUSED 10.0.0.1:443")}
+	logger := &mockLogger{}
+	bw := NewOfflineBwClient(mock, offlineMirror(t), logger, false)
+	fs := &mockFileSystem{}
+
+	require.NoError(t, PullEnvCore("/web", "web", fs, bw, &config.Config{}, nil, noConfirm, logger))
+
+	assert.Equal(t, "APP=web", string(fs.writtenFiles["/web/.env"]))
+	assert.True(t, bw.Offline())
+	assert.Equal(t, []string{"GetDotenvsFolderID"}, mock.calls)
+	checkWarning(t, logger, "falling back to the offline mirror")
+}
+
+// 正常系: ミラーに無いプロジェクトは見つからない扱い
+func TestOfflineBwClient_NotMirrored(t *testing.T) {
+	bw := NewOfflineBwClient(&mockBwClient{}, offlineMirror(t), &mockLogger{}, true)
+
+	err := PullEnvCore("/api", "api", &mockFileSystem{}, bw, &config.Config{}, nil, noConfirm, &mockLogger{})
+
+	assert.Error(t, err)
+}
+
+// 正常系: オフライン中の push はキューに記録し、Bitwarden には書き込まない
+func TestOfflineBwClient_PushOffline(t *testing.T) {
+	m := offlineMirror(t)
+	mock := &mockBwClient{}
+	bw := NewOfflineBwClient(mock, m, &mockLogger{}, true)
+	fs := &mockFileSystem{
+		dirEntries:     []DirEntry{&mockDirEntry{name: ".env"}},
+		readContentMap: map[string][]byte{"/web/.env": []byte("APP=web2")},
+	}
+
+	require.NoError(t, PushEnvCore("/web", "web", fs, bw, &config.Config{}, nil, &mockLogger{}))
+	require.NoError(t, PushEnvCore("/web", "worker", fs, bw, &config.Config{}, nil, &mockLogger{}))
+
+	assert.Empty(t, mock.calls)
+	assert.Equal(t, []string{"web", "worker"}, m.Pending())
+}
+
+// 異常系: オフラインでは削除できない
+func TestOfflineBwClient_DeleteOffline(t *testing.T) {
+	bw := NewOfflineBwClient(&mockBwClient{}, offlineMirror(t), &mockLogger{}, true)
+
+	assert.ErrorIs(t, bw.DeleteItem("item-1", false), ErrOfflineUnavailable)
+}
+
+// =============================================================================
+// ReplayOfflinePushesCore のテスト
+// =============================================================================
+
+// 正常系: Bitwarden 側が変わっていなければ反映し、キューから取り除く
+func TestReplayOfflinePushes(t *testing.T) {
+	m := offlineMirror(t)
+	require.NoError(t, m.Enqueue("web", "v2"))
+	require.NoError(t, m.Enqueue("worker", "w1"))
+	mock := &mockBwClient{
+		folderID: "folder-123",
+		itemsByName: map[string]*FullItem{
+			"web": {ID: "item-1", Name: "web", Notes: `{".env":{"lines":["APP=web"]}}`},
+		},
+	}
+
+	results, err := ReplayOfflinePushesCore(mock, m, &config.Config{}, nil, &mockLogger{}, false)
+
+	require.NoError(t, err)
+	assert.Equal(t, []ReplayResult{{Project: "web"}, {Project: "worker"}}, results)
+	assert.Equal(t, "v2", mock.updatedNotes["item-1"])
+	assert.Equal(t, "w1", mock.createdNotes["worker"])
+	assert.Empty(t, m.Pending())
+}
+
+// 異常系: 記録後に Bitwarden 側が変更されていれば競合として残す
+func TestReplayOfflinePushes_Conflict(t *testing.T) {
+	m := offlineMirror(t)
+	require.NoError(t, m.Enqueue("web", "v2"))
+	mock := newTestBwClient(t, MultiEnvData{".env": {Lines: []string{"APP=changed-elsewhere"}}})
+
+	results, err := ReplayOfflinePushesCore(mock, m, &config.Config{}, nil, &mockLogger{}, false)
+
+	require.NoError(t, err)
+	assert.Equal(t, []ReplayResult{{Project: "web", Conflict: true}}, results)
+	assert.Empty(t, mock.updatedNotes)
+	assert.Equal(t, []string{"web"}, m.Pending())
+
+	// force では上書きする
+	results, err = ReplayOfflinePushesCore(mock, m, &config.Config{}, nil, &mockLogger{}, true)
+	require.NoError(t, err)
+	assert.Equal(t, []ReplayResult{{Project: "web"}}, results)
+	assert.Equal(t, "v2", mock.updatedNotes["item-1"])
+	assert.Empty(t, m.Pending())
+}
+
+// 正常系: キューが空なら何もしない
+func TestReplayOfflinePushes_Empty(t *testing.T) {
+	mock := &mockBwClient{}
+
+	results, err := ReplayOfflinePushesCore(mock, offlineMirror(t), &config.Config{}, nil, &mockLogger{}, false)
+
+	require.NoError(t, err)
+	assert.Empty(t, results)
+	assert.Empty(t, mock.calls)
+}
+
+// checkWarning は logger に substr を含む警告があることを確認します。
+func checkWarning(t *testing.T, logger *mockLogger, substr string) {
+	t.Helper()
+	for _, w := range logger.warnings {
+		if strings.Contains(w, substr) {
+			return
+		}
+	}
+	t.Errorf("no warning contains %q: %v", substr, logger.warnings)
+}
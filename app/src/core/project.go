@@ -0,0 +1,165 @@
+package core
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"bwsf/src/config"
+)
+
+// RemoveProjectCore は dotenvs フォルダ内のプロジェクトアイテムを削除します。
+// permanent が false の場合はゴミ箱に移動します。
+func RemoveProjectCore(
+	projectName string,
+	permanent bool,
+	bw BwClient,
+	cfg *config.Config,
+	promptPassword func() (string, error),
+	logger Logger,
+) error {
+	_, item, err := fetchProjectItem(projectName, bw, cfg, promptPassword, logger)
+	if err != nil {
+		return err
+	}
+	if item == nil {
+		return fmt.Errorf("item '%s' not found in dotenvs folder", projectName)
+	}
+
+	err = WithUnlockRetry(bw, cfg, promptPassword, logger, func() error {
+		return bw.DeleteItem(item.ID, permanent)
+	})
+	if err != nil {
+		return fmt.Errorf("failed to delete item: %w", err)
+	}
+	return nil
+}
+
+// RenameProjectCore はプロジェクトアイテムの名前を変更します。
+// 変更先の名前が既に存在する場合はエラーを返します。
+func RenameProjectCore(
+	oldName, newName string,
+	bw BwClient,
+	cfg *config.Config,
+	promptPassword func() (string, error),
+	logger Logger,
+) error {
+	if strings.TrimSpace(newName) == "" {
+		return fmt.Errorf("new project name must not be empty")
+	}
+	if oldName == newName {
+		return fmt.Errorf("source and destination are the same: %s", oldName)
+	}
+
+	_, item, err := fetchProjectItem(oldName, bw, cfg, promptPassword, logger)
+	if err != nil {
+		return err
+	}
+	if item == nil {
+		return fmt.Errorf("item '%s' not found in dotenvs folder", oldName)
+	}
+
+	_, existing, err := fetchProjectItem(newName, bw, cfg, promptPassword, logger)
+	if err != nil {
+		return err
+	}
+	if existing != nil {
+		return fmt.Errorf("item '%s' already exists in dotenvs folder", newName)
+	}
+
+	err = WithUnlockRetry(bw, cfg, promptPassword, logger, func() error {
+		return bw.RenameItem(item.ID, newName)
+	})
+	if err != nil {
+		return fmt.Errorf("failed to rename item: %w", err)
+	}
+	return nil
+}
+
+// CopyProjectCore はプロジェクトアイテムを別名で複製します。
+// files が指定された場合はそのファイルのみを複製します。
+// 複製先の名前が既に存在する場合はエラーを返します。
+func CopyProjectCore(
+	srcName, dstName string,
+	files []string,
+	bw BwClient,
+	cfg *config.Config,
+	promptPassword func() (string, error),
+	logger Logger,
+) ([]string, error) {
+	if strings.TrimSpace(dstName) == "" {
+		return nil, fmt.Errorf("destination project name must not be empty")
+	}
+	if srcName == dstName {
+		return nil, fmt.Errorf("source and destination are the same: %s", srcName)
+	}
+
+	folderID, item, err := fetchProjectItem(srcName, bw, cfg, promptPassword, logger)
+	if err != nil {
+		return nil, err
+	}
+	if item == nil {
+		return nil, fmt.Errorf("item '%s' not found in dotenvs folder", srcName)
+	}
+
+	multiData, err := decodeStoredNotes(item.Notes)
+	if err != nil {
+		return nil, err
+	}
+
+	selected, err := selectEnvFiles(multiData, files)
+	if err != nil {
+		return nil, err
+	}
+
+	_, existing, err := fetchProjectItem(dstName, bw, cfg, promptPassword, logger)
+	if err != nil {
+		return nil, err
+	}
+	if existing != nil {
+		return nil, fmt.Errorf("item '%s' already exists in dotenvs folder", dstName)
+	}
+
+	jsonData, err := multiEnvDataToJSON(selected)
+	if err != nil {
+		return nil, fmt.Errorf("failed to convert to JSON: %w", err)
+	}
+
+	err = WithUnlockRetry(bw, cfg, promptPassword, logger, func() error {
+		return bw.CreateNoteItem(folderID, dstName, jsonData)
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create item: %w", err)
+	}
+
+	var names []string
+	for name := range selected {
+		names = append(names, name)
+	}
+	sortFileNames(names)
+	return names, nil
+}
+
+// selectEnvFiles は files に指定されたファイルのみを含む MultiEnvData を返します。
+// files が空の場合はすべてのファイルを返します。存在しないファイルが指定された場合はエラーです。
+func selectEnvFiles(data MultiEnvData, files []string) (MultiEnvData, error) {
+	if len(files) == 0 {
+		return data, nil
+	}
+
+	selected := make(MultiEnvData)
+	var missing []string
+	for _, name := range files {
+		envData, ok := data[name]
+		if !ok {
+			missing = append(missing, name)
+			continue
+		}
+		selected[name] = envData
+	}
+	if len(missing) > 0 {
+		sort.Strings(missing)
+		return nil, fmt.Errorf("file(s) not found in stored item: %s", strings.Join(missing, ", "))
+	}
+	return selected, nil
+}
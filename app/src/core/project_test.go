@@ -0,0 +1,183 @@
+package core
+
+import (
+	"errors"
+	"testing"
+
+	"bwsf/src/config"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// =============================================================================
+// RemoveProjectCore のテスト
+// =============================================================================
+
+// 正常系: アイテムをゴミ箱に移動する
+func TestRemoveProjectCore_SoftDelete(t *testing.T) {
+	bw := &mockBwClient{
+		folderID:   "folder-123",
+		itemByName: &FullItem{ID: "item-456", Name: "my-project"},
+	}
+
+	err := RemoveProjectCore("my-project", false, bw, &config.Config{}, func() (string, error) { return "pwd", nil }, &mockLogger{})
+
+	assert.NoError(t, err)
+	assert.Contains(t, bw.calls, "DeleteItem(item-456,false)")
+}
+
+// 正常系: --permanent で完全削除する
+func TestRemoveProjectCore_Permanent(t *testing.T) {
+	bw := &mockBwClient{
+		folderID:   "folder-123",
+		itemByName: &FullItem{ID: "item-456", Name: "my-project"},
+	}
+
+	err := RemoveProjectCore("my-project", true, bw, &config.Config{}, func() (string, error) { return "pwd", nil }, &mockLogger{})
+
+	assert.NoError(t, err)
+	assert.Contains(t, bw.calls, "DeleteItem(item-456,true)")
+}
+
+// 異常系: アイテムが存在しない
+func TestRemoveProjectCore_NotFound(t *testing.T) {
+	bw := &mockBwClient{folderID: "folder-123"}
+
+	err := RemoveProjectCore("my-project", false, bw, &config.Config{}, func() (string, error) { return "pwd", nil }, &mockLogger{})
+
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "not found")
+}
+
+// 異常系: DeleteItem のエラーが伝播する
+func TestRemoveProjectCore_DeleteError(t *testing.T) {
+	bw := &mockBwClient{
+		folderID:   "folder-123",
+		itemByName: &FullItem{ID: "item-456", Name: "my-project"},
+		deleteErr:  errors.New("boom"),
+	}
+
+	err := RemoveProjectCore("my-project", false, bw, &config.Config{}, func() (string, error) { return "pwd", nil }, &mockLogger{})
+
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "failed to delete item")
+}
+
+// =============================================================================
+// RenameProjectCore のテスト
+// =============================================================================
+
+// 正常系: 名前を変更する
+func TestRenameProjectCore_Success(t *testing.T) {
+	bw := &mockBwClient{
+		folderID: "folder-123",
+		itemsByName: map[string]*FullItem{
+			"old-name": {ID: "item-456", Name: "old-name"},
+		},
+	}
+
+	err := RenameProjectCore("old-name", "new-name", bw, &config.Config{}, func() (string, error) { return "pwd", nil }, &mockLogger{})
+
+	assert.NoError(t, err)
+	assert.Contains(t, bw.calls, "RenameItem(item-456,new-name)")
+}
+
+// 異常系: 変更先が既に存在する
+func TestRenameProjectCore_DestinationExists(t *testing.T) {
+	bw := &mockBwClient{
+		folderID: "folder-123",
+		itemsByName: map[string]*FullItem{
+			"old-name": {ID: "item-1", Name: "old-name"},
+			"new-name": {ID: "item-2", Name: "new-name"},
+		},
+	}
+
+	err := RenameProjectCore("old-name", "new-name", bw, &config.Config{}, func() (string, error) { return "pwd", nil }, &mockLogger{})
+
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "already exists")
+	assert.NotContains(t, bw.calls, "RenameItem(item-1,new-name)")
+}
+
+// 異常系: 同じ名前への変更
+func TestRenameProjectCore_SameName(t *testing.T) {
+	bw := &mockBwClient{}
+
+	err := RenameProjectCore("a", "a", bw, &config.Config{}, func() (string, error) { return "pwd", nil }, &mockLogger{})
+
+	assert.Error(t, err)
+	assert.Empty(t, bw.calls)
+}
+
+// =============================================================================
+// CopyProjectCore のテスト
+// =============================================================================
+
+// 正常系: 一部のファイルのみ複製する
+func TestCopyProjectCore_SelectedFiles(t *testing.T) {
+	bw := &mockBwClient{
+		folderID: "folder-123",
+		itemsByName: map[string]*FullItem{
+			"staging": {ID: "item-1", Name: "staging", Notes: `{".env":{"lines":["A=1"]},".env.staging":{"lines":["B=2"]}}`},
+		},
+	}
+
+	names, err := CopyProjectCore("staging", "preview-42", []string{".env.staging"}, bw, &config.Config{}, func() (string, error) { return "pwd", nil }, &mockLogger{})
+
+	require.NoError(t, err)
+	assert.Equal(t, []string{".env.staging"}, names)
+	assert.Contains(t, bw.calls, "CreateNoteItem(folder-123,preview-42)")
+
+	copied, err := restoreMultiEnvFromJSON(bw.createdNotes["preview-42"])
+	require.NoError(t, err)
+	assert.NotContains(t, copied, ".env")
+	assert.Equal(t, []string{"B=2"}, copied[".env.staging"].Lines)
+}
+
+// 正常系: 旧形式のアイテムも複製できる
+func TestCopyProjectCore_LegacyFormat(t *testing.T) {
+	bw := &mockBwClient{
+		folderID: "folder-123",
+		itemsByName: map[string]*FullItem{
+			"src": {ID: "item-1", Name: "src", Notes: `{"lines":["A=1"]}`},
+		},
+	}
+
+	names, err := CopyProjectCore("src", "dst", nil, bw, &config.Config{}, func() (string, error) { return "pwd", nil }, &mockLogger{})
+
+	require.NoError(t, err)
+	assert.Equal(t, []string{".env"}, names)
+}
+
+// 異常系: 存在しないファイルを指定
+func TestCopyProjectCore_UnknownFile(t *testing.T) {
+	bw := &mockBwClient{
+		folderID: "folder-123",
+		itemsByName: map[string]*FullItem{
+			"src": {ID: "item-1", Name: "src", Notes: `{".env":{"lines":["A=1"]}}`},
+		},
+	}
+
+	_, err := CopyProjectCore("src", "dst", []string{".env.nope"}, bw, &config.Config{}, func() (string, error) { return "pwd", nil }, &mockLogger{})
+
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), ".env.nope")
+	assert.Empty(t, bw.createdNotes)
+}
+
+// 異常系: 複製先が既に存在する
+func TestCopyProjectCore_DestinationExists(t *testing.T) {
+	bw := &mockBwClient{
+		folderID: "folder-123",
+		itemsByName: map[string]*FullItem{
+			"src": {ID: "item-1", Name: "src", Notes: `{".env":{"lines":["A=1"]}}`},
+			"dst": {ID: "item-2", Name: "dst", Notes: `{}`},
+		},
+	}
+
+	_, err := CopyProjectCore("src", "dst", nil, bw, &config.Config{}, func() (string, error) { return "pwd", nil }, &mockLogger{})
+
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "already exists")
+}
@@ -0,0 +1,261 @@
+package core
+
+import (
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"math/big"
+	"strings"
+	"time"
+
+	"bwsf/src/config"
+)
+
+// 値の生成方法
+const (
+	GeneratorPassword = "password" // 英数字
+	GeneratorHex      = "hex"      // 16 進数
+	GeneratorBase64   = "base64"   // ランダムなバイト列の base64
+	GeneratorUUID     = "uuid"     // UUID v4（長さは指定不可）
+)
+
+// DefaultSecretLength は password / hex の文字数、base64 のバイト数の既定値です。
+const DefaultSecretLength = 32
+
+// passwordChars は password で使う文字です（.env でクォートが不要な文字のみ）。
+const passwordChars = "ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz0123456789"
+
+// GenerateSecret は generator に従ってランダムな値を生成します。
+// length が 0 の場合は DefaultSecretLength を使います。
+func GenerateSecret(generator string, length int) (string, error) {
+	if generator == "" {
+		generator = GeneratorPassword
+	}
+	if length < 0 {
+		return "", fmt.Errorf("length must be positive: %d", length)
+	}
+	if generator == GeneratorUUID {
+		if length != 0 {
+			return "", fmt.Errorf("--length cannot be used with the uuid generator")
+		}
+		return newUUID()
+	}
+	if length == 0 {
+		length = DefaultSecretLength
+	}
+
+	switch generator {
+	case GeneratorPassword:
+		var b strings.Builder
+		charCount := big.NewInt(int64(len(passwordChars)))
+		for i := 0; i < length; i++ {
+			n, err := rand.Int(rand.Reader, charCount)
+			if err != nil {
+				return "", fmt.Errorf("failed to generate value: %w", err)
+			}
+			b.WriteByte(passwordChars[n.Int64()])
+		}
+		return b.String(), nil
+	case GeneratorHex:
+		buf := make([]byte, (length+1)/2)
+		if _, err := rand.Read(buf); err != nil {
+			return "", fmt.Errorf("failed to generate value: %w", err)
+		}
+		return hex.EncodeToString(buf)[:length], nil
+	case GeneratorBase64:
+		buf := make([]byte, length)
+		if _, err := rand.Read(buf); err != nil {
+			return "", fmt.Errorf("failed to generate value: %w", err)
+		}
+		return base64.StdEncoding.EncodeToString(buf), nil
+	default:
+		return "", fmt.Errorf("unknown generator %q: use password, hex, base64 or uuid", generator)
+	}
+}
+
+// newUUID はランダムな UUID v4 を生成します。
+func newUUID() (string, error) {
+	buf := make([]byte, 16)
+	if _, err := rand.Read(buf); err != nil {
+		return "", fmt.Errorf("failed to generate value: %w", err)
+	}
+	buf[6] = (buf[6] & 0x0f) | 0x40
+	buf[8] = (buf[8] & 0x3f) | 0x80
+	return fmt.Sprintf("%x-%x-%x-%x-%x", buf[0:4], buf[4:6], buf[6:8], buf[8:10], buf[10:]), nil
+}
+
+// RotateOptions は RotateKeyCore の設定です。
+type RotateOptions struct {
+	// File は対象のファイル名です。空の場合は ".env" です。
+	File string
+	// Generator と Length は GenerateSecret に渡す値の生成方法です。
+	Generator string
+	Length    int
+	// DryRun が true の場合、保管庫を変更せずに結果のみを返します。
+	DryRun bool
+	// Now は rotated_at に記録する現在時刻です。nil の場合は time.Now を使います。
+	Now func() time.Time
+	// ShellCache が設定されていれば、rotate 後にプロジェクトの bwsf export 用キャッシュを削除します。
+	ShellCache Keyring
+}
+
+// RotateResult は rotate の結果です。値そのものは含めません。
+type RotateResult struct {
+	File              string
+	Key               string
+	Lines             int    // 書き換えた行数
+	PreviousRotatedAt string // 前回 rotate した日時（初回は空）
+	RotatedAt         string // 今回の日時（DryRun では空）
+}
+
+// RotateKeyCore は保存されたプロジェクトのキーの値を生成した値で置き換えます。
+// 行の順序・export・クォート・インラインコメントは保ち、rotate した日時をメタデータに記録します。
+// 置き換え前の値はノートには残さず、アイテムの非表示フィールド（RotatedFieldName）に保存します。
+// 次の rotate でこのフィールドが変わると、Bitwarden がそれまでの値をアイテムのパスワード履歴に移します。
+func RotateKeyCore(
+	projectName, key string,
+	opts RotateOptions,
+	bw BwClient,
+	cfg *config.Config,
+	promptPassword func() (string, error),
+	logger Logger,
+) (*RotateResult, error) {
+	fileName := opts.File
+	if fileName == "" {
+		fileName = ".env"
+	}
+	now := time.Now
+	if opts.Now != nil {
+		now = opts.Now
+	}
+
+	// 生成方法の誤りは保管庫にアクセスする前に検出する
+	value, err := GenerateSecret(opts.Generator, opts.Length)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := syncBeforeWrite(bw, cfg, promptPassword, logger); err != nil {
+		return nil, err
+	}
+	item, multiData, envData, err := loadStoredEnvFile(projectName, fileName, bw, cfg, promptPassword, logger)
+	if err != nil {
+		return nil, err
+	}
+
+	previous := envEntryValue(envData.Lines, key)
+	lines, replaced := rotateEnvLines(envData.Lines, key, value)
+	if replaced == 0 {
+		return nil, fmt.Errorf("key %s not found in %s/%s", key, projectName, fileName)
+	}
+
+	result := &RotateResult{
+		File:              fileName,
+		Key:               key,
+		Lines:             replaced,
+		PreviousRotatedAt: envData.Meta[key].RotatedAt,
+	}
+	if opts.DryRun {
+		return result, nil
+	}
+
+	meta := envData.Meta[key]
+	meta.RotatedAt = now().UTC().Format(time.RFC3339)
+	if envData.Meta == nil {
+		envData.Meta = make(map[string]KeyMeta)
+	}
+	envData.Meta[key] = meta
+	envData.Lines = lines
+	multiData[fileName] = envData
+
+	jsonData, err := multiEnvDataToJSON(multiData)
+	if err != nil {
+		return nil, fmt.Errorf("failed to convert to JSON: %w", err)
+	}
+	hidden := []ItemField{{Name: RotatedFieldName(fileName, key), Value: previous}}
+	err = WithUnlockRetry(bw, cfg, promptPassword, logger, func() error {
+		return bw.UpdateNoteItemFields(item.ID, jsonData, hidden)
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to update item: %w", err)
+	}
+	if opts.ShellCache != nil {
+		InvalidateShellEnvCache(opts.ShellCache, projectName, logger)
+	}
+
+	result.RotatedAt = meta.RotatedAt
+	return result, nil
+}
+
+// RotatedFieldName は rotate で置き換えた値を保存する非表示フィールドの名前です。
+func RotatedFieldName(fileName, key string) string {
+	return "rotated:" + fileName + ":" + key
+}
+
+// envEntryValue は key の最初の行の値を返します。
+func envEntryValue(lines []string, key string) string {
+	for _, line := range lines {
+		if entry, ok := parseEnvLine(line); ok && entry.Key == key {
+			return entry.Value
+		}
+	}
+	return ""
+}
+
+// rotateEnvLines は key の行の値を value に置き換えた行と、置き換えた行数を返します。
+// 同じキーが複数行ある場合はすべて置き換えます。
+func rotateEnvLines(lines []string, key, value string) ([]string, int) {
+	result := make([]string, len(lines))
+	copy(result, lines)
+
+	var replaced int
+	for i, line := range lines {
+		entry, ok := parseEnvLine(line)
+		if !ok || entry.Key != key {
+			continue
+		}
+		// "=" の後ろの空白と行末の \r は元の行のまま残す
+		sep := strings.Index(line, "=")
+		rest := line[sep+1:]
+		space := rest[:len(rest)-len(strings.TrimLeft(rest, " \t"))]
+		result[i] = line[:sep+1] + space + quoteLike(entry, value) + envValueSuffix(entry)
+		if strings.HasSuffix(line, "\r") {
+			result[i] += "\r"
+		}
+		replaced++
+	}
+	return result, replaced
+}
+
+// quoteLike は元の値と同じクォートで value を囲みます。
+func quoteLike(entry envEntry, value string) string {
+	if entry.Quote == 0 {
+		return value
+	}
+	return string(entry.Quote) + value + string(entry.Quote)
+}
+
+// envValueSuffix は値の後ろのインラインコメントを返します（先頭の空白を含む）。
+func envValueSuffix(entry envEntry) string {
+	raw := entry.RawValue
+	if entry.Quote == 0 {
+		if idx := strings.Index(raw, " #"); idx >= 0 {
+			return raw[idx:]
+		}
+		return ""
+	}
+	if !entry.Closed {
+		return ""
+	}
+	for i := 1; i < len(raw); i++ {
+		if entry.Quote == '"' && raw[i] == '\\' {
+			i++
+			continue
+		}
+		if raw[i] == entry.Quote {
+			return raw[i+1:]
+		}
+	}
+	return ""
+}
@@ -0,0 +1,136 @@
+package core
+
+import (
+	"encoding/hex"
+	"regexp"
+	"testing"
+	"time"
+
+	"bwsf/src/config"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// =============================================================================
+// GenerateSecret のテスト
+// =============================================================================
+
+// 正常系: 生成方法ごとの形式と長さ
+func TestGenerateSecret(t *testing.T) {
+	password, err := GenerateSecret("", 0)
+	require.NoError(t, err)
+	assert.Regexp(t, `^[A-Za-z0-9]{32}$`, password)
+
+	hexValue, err := GenerateSecret(GeneratorHex, 15)
+	require.NoError(t, err)
+	assert.Len(t, hexValue, 15)
+	_, err = hex.DecodeString(hexValue + "0")
+	assert.NoError(t, err)
+
+	b64, err := GenerateSecret(GeneratorBase64, 24)
+	require.NoError(t, err)
+	assert.Len(t, b64, 32)
+
+	uuid, err := GenerateSecret(GeneratorUUID, 0)
+	require.NoError(t, err)
+	assert.Regexp(t, regexp.MustCompile(`^[0-9a-f]{8}-[0-9a-f]{4}-4[0-9a-f]{3}-[89ab][0-9a-f]{3}-[0-9a-f]{12}$`), uuid)
+
+	again, err := GenerateSecret("", 0)
+	require.NoError(t, err)
+	assert.NotEqual(t, password, again)
+}
+
+// 異常系: 不明な生成方法、負の長さ、uuid への長さ指定
+func TestGenerateSecret_Invalid(t *testing.T) {
+	_, err := GenerateSecret("words", 0)
+	assert.ErrorContains(t, err, "unknown generator")
+
+	_, err = GenerateSecret(GeneratorHex, -1)
+	assert.Error(t, err)
+
+	_, err = GenerateSecret(GeneratorUUID, 16)
+	assert.ErrorContains(t, err, "uuid")
+}
+
+// =============================================================================
+// RotateKeyCore のテスト
+// =============================================================================
+
+var rotateNow = func() time.Time { return time.Date(2026, 3, 1, 9, 0, 0, 0, time.UTC) }
+
+// 正常系: 値のみを置き換え、行の形式と他のファイルを保ち、前の値は非表示フィールドに保存する
+func TestRotateKeyCore_Success(t *testing.T) {
+	bw := newTestBwClient(t, MultiEnvData{
+		".env":      {Lines: []string{"# db", `export DB_PASSWORD="old-secret" # prod`, "API_KEY=abc"}},
+		".env.test": {Lines: []string{"DB_PASSWORD=test"}},
+	})
+
+	result, err := RotateKeyCore("web", "DB_PASSWORD", RotateOptions{Generator: GeneratorHex, Length: 8, Now: rotateNow}, bw, &config.Config{}, nil, &mockLogger{})
+
+	require.NoError(t, err)
+	assert.Equal(t, &RotateResult{File: ".env", Key: "DB_PASSWORD", Lines: 1, RotatedAt: "2026-03-01T09:00:00Z"}, result)
+
+	stored, err := restoreMultiEnvFromJSON(bw.updatedNotes["item-1"])
+	require.NoError(t, err)
+	lines := stored[".env"].Lines
+	assert.Equal(t, "# db", lines[0])
+	assert.Regexp(t, `^export DB_PASSWORD="[0-9a-f]{8}" # prod$`, lines[1])
+	assert.Equal(t, "API_KEY=abc", lines[2])
+	assert.Equal(t, []string{"DB_PASSWORD=test"}, stored[".env.test"].Lines)
+	assert.Equal(t, KeyMeta{RotatedAt: "2026-03-01T09:00:00Z"}, stored[".env"].Meta["DB_PASSWORD"])
+	assert.NotContains(t, bw.updatedNotes["item-1"], "old-secret")
+	assert.Equal(t, []ItemField{{Name: "rotated:.env:DB_PASSWORD", Value: "old-secret"}}, bw.updatedFields)
+	assert.Contains(t, bw.calls, "UpdateNoteItemFields(item-1)")
+}
+
+// 正常系: --dry-run では保管庫を変更せず、前回の日時を返す
+func TestRotateKeyCore_DryRun(t *testing.T) {
+	bw := newTestBwClient(t, MultiEnvData{
+		".env": {Lines: []string{"TOKEN=a"}, Meta: map[string]KeyMeta{"TOKEN": {RotatedAt: "2025-12-01T00:00:00Z"}}},
+	})
+
+	result, err := RotateKeyCore("web", "TOKEN", RotateOptions{DryRun: true}, bw, &config.Config{}, nil, &mockLogger{})
+
+	require.NoError(t, err)
+	assert.Equal(t, "2025-12-01T00:00:00Z", result.PreviousRotatedAt)
+	assert.Empty(t, result.RotatedAt)
+	assert.Empty(t, bw.updatedNotes)
+}
+
+// 異常系: プロジェクト・ファイル・キーが見つからない
+func TestRotateKeyCore_NotFound(t *testing.T) {
+	_, err := RotateKeyCore("web", "TOKEN", RotateOptions{}, &mockBwClient{folderID: "folder-123"}, &config.Config{}, nil, &mockLogger{})
+	assert.ErrorContains(t, err, "item 'web' not found")
+
+	bw := newTestBwClient(t, MultiEnvData{".env": {Lines: []string{"A=1"}}})
+	_, err = RotateKeyCore("web", "TOKEN", RotateOptions{File: ".env.prod"}, bw, &config.Config{}, nil, &mockLogger{})
+	assert.ErrorContains(t, err, "file '.env.prod' not found")
+
+	_, err = RotateKeyCore("web", "TOKEN", RotateOptions{}, bw, &config.Config{}, nil, &mockLogger{})
+	assert.ErrorContains(t, err, "key TOKEN not found in web/.env")
+	assert.Empty(t, bw.updatedNotes)
+}
+
+// =============================================================================
+// rotateEnvLines のテスト
+// =============================================================================
+
+// 正常系: クォート・空白・コメント・CRLF を保ち、重複行はすべて置き換える
+func TestRotateEnvLines(t *testing.T) {
+	lines := []string{
+		"A=plain # comment",
+		"A = 'single'",
+		"B=\"esc\\\"aped\" # keep",
+		"A=last\r",
+	}
+
+	result, replaced := rotateEnvLines(lines, "A", "new")
+
+	assert.Equal(t, 3, replaced)
+	assert.Equal(t, []string{"A=new # comment", "A = 'new'", "B=\"esc\\\"aped\" # keep", "A=new\r"}, result)
+	assert.Equal(t, "A=plain # comment", lines[0])
+
+	result, _ = rotateEnvLines([]string{"B=\"esc\\\"aped\" # keep"}, "B", "x")
+	assert.Equal(t, []string{"B=\"x\" # keep"}, result)
+}
@@ -0,0 +1,232 @@
+package core
+
+import (
+	"errors"
+	"fmt"
+	"path/filepath"
+	"strings"
+)
+
+// ErrInvalidPullSelection は pull のファイル選択オプションの組み合わせが不正な場合に返されます。
+var ErrInvalidPullSelection = errors.New("invalid file selection")
+
+// pullTarget は pull で書き出す 1 ファイルと、その内容の元になる保存済みファイルを表します。
+// Sources が複数の場合は、後のファイルのキーを優先して 1 ファイルに重ねます。
+type pullTarget struct {
+	Name    string
+	Sources []string
+}
+
+// mapped は保存済みファイルと異なる名前・内容で書き出すかを返します。
+func (t pullTarget) mapped() bool {
+	return len(t.Sources) != 1 || t.Sources[0] != t.Name
+}
+
+// EnvFileName は環境名を .env ファイル名に変換します（"staging" -> ".env.staging"）。
+// 既に .env で始まる名前はそのまま返します。
+func EnvFileName(env string) string {
+	if env == ".env" || strings.HasPrefix(env, ".env.") {
+		return env
+	}
+	return ".env." + env
+}
+
+// planPullTargets は PullOptions のファイル選択から書き出すファイルの一覧を決めます。
+// 選択が無い場合は保存されている全ファイルをそのままの名前で書き出します。
+func planPullTargets(available []string, opts PullOptions) ([]pullTarget, error) {
+	exists := make(map[string]bool, len(available))
+	for _, name := range available {
+		exists[name] = true
+	}
+	requireFiles := func(names []string) error {
+		for _, name := range names {
+			if !exists[name] {
+				return fmt.Errorf("file '%s' not found in project (available: %s)", name, strings.Join(available, ", "))
+			}
+		}
+		return nil
+	}
+
+	if opts.As != "" {
+		if err := validateTargetName(opts.As); err != nil {
+			return nil, err
+		}
+	}
+	if opts.MergeInto != "" {
+		if err := validateTargetName(opts.MergeInto); err != nil {
+			return nil, err
+		}
+	}
+
+	switch {
+	case len(opts.Layer) > 0:
+		if len(opts.Files) > 0 || opts.As != "" {
+			return nil, fmt.Errorf("%w: --layer cannot be combined with --env, --file or --as", ErrInvalidPullSelection)
+		}
+		if opts.MergeInto == "" {
+			return nil, fmt.Errorf("%w: --layer requires --merge-into", ErrInvalidPullSelection)
+		}
+		if err := requireFiles(opts.Layer); err != nil {
+			return nil, err
+		}
+		return []pullTarget{{Name: opts.MergeInto, Sources: opts.Layer}}, nil
+
+	case opts.MergeInto != "":
+		return nil, fmt.Errorf("%w: --merge-into requires --layer", ErrInvalidPullSelection)
+
+	case len(opts.Files) > 0:
+		if opts.As != "" && len(opts.Files) != 1 {
+			return nil, fmt.Errorf("%w: --as requires exactly one --env or --file", ErrInvalidPullSelection)
+		}
+		if err := requireFiles(opts.Files); err != nil {
+			return nil, err
+		}
+		var targets []pullTarget
+		seen := make(map[string]bool)
+		for _, name := range opts.Files {
+			if seen[name] {
+				continue
+			}
+			seen[name] = true
+			target := pullTarget{Name: name, Sources: []string{name}}
+			if opts.As != "" {
+				target.Name = opts.As
+			}
+			targets = append(targets, target)
+		}
+		return targets, nil
+
+	case opts.As != "":
+		return nil, fmt.Errorf("%w: --as requires exactly one --env or --file", ErrInvalidPullSelection)
+	}
+
+	targets := make([]pullTarget, 0, len(available))
+	for _, name := range available {
+		targets = append(targets, pullTarget{Name: name, Sources: []string{name}})
+	}
+	return targets, nil
+}
+
+// PullTargetNames は PullOptions のファイル選択に従って書き出されるファイル名を返します（表示用）。
+func PullTargetNames(available []string, opts PullOptions) ([]string, error) {
+	targets, err := planPullTargets(available, opts)
+	if err != nil {
+		return nil, err
+	}
+	names := make([]string, 0, len(targets))
+	for _, target := range targets {
+		names = append(names, target.Name)
+	}
+	return names, nil
+}
+
+// validateTargetName は書き出し先のファイル名が出力ディレクトリ直下のファイル名であることを確認します。
+func validateTargetName(name string) error {
+	if name == "." || name == ".." || filepath.Base(name) != name || strings.ContainsAny(name, `/\`) {
+		return fmt.Errorf("%w: '%s' must be a plain file name", ErrInvalidPullSelection, name)
+	}
+	return nil
+}
+
+// renderPullTarget は target の書き出し内容を返します。
+func renderPullTarget(target pullTarget, data MultiEnvData) EnvData {
+	if len(target.Sources) == 1 {
+		return data[target.Sources[0]]
+	}
+	layers := make([]EnvData, 0, len(target.Sources))
+	for _, name := range target.Sources {
+		layers = append(layers, data[name])
+	}
+	return mergeLayers(layers)
+}
+
+// mergeLayers は複数のファイルを 1 つに重ねます。後のレイヤーのキーが優先されます。
+//
+// 上書きするキーは元の位置で置き換え、直前のコメントはその行の上に差し込みます。
+// 新しいキーとそのコメントは末尾に追加します。コメントはどのレイヤーのものも残します。
+func mergeLayers(layers []EnvData) EnvData {
+	if len(layers) == 0 {
+		return EnvData{}
+	}
+
+	lines := append([]string(nil), layers[0].Lines...)
+	index := make(map[string]int)
+	for i, line := range lines {
+		if entry, ok := parseEnvLine(line); ok {
+			index[entry.Key] = i
+		}
+	}
+
+	for _, layer := range layers[1:] {
+		var pending []string
+		appended := false
+		appendLines := func(add ...string) {
+			if !appended {
+				appended = true
+				for len(add) > 0 && strings.TrimSpace(add[0]) == "" {
+					add = add[1:]
+				}
+				if len(lines) > 0 && strings.TrimSpace(lines[len(lines)-1]) != "" {
+					lines = append(lines, "")
+				}
+			}
+			lines = append(lines, add...)
+		}
+
+		for _, line := range layer.Lines {
+			entry, ok := parseEnvLine(line)
+			if !ok {
+				pending = append(pending, line)
+				continue
+			}
+
+			i, exists := index[entry.Key]
+			if !exists {
+				appendLines(append(pending, line)...)
+				index[entry.Key] = len(lines) - 1
+				pending = nil
+				continue
+			}
+
+			// 空行は位置が変わると意味を持たないため、差し込むのはコメントのみ
+			// 上書きする行の直前に同じコメントが既にあれば重複させない
+			above := make(map[string]bool)
+			for j := i - 1; j >= 0 && strings.TrimSpace(lines[j]) != ""; j-- {
+				if _, ok := parseEnvLine(lines[j]); ok {
+					break
+				}
+				above[strings.TrimSpace(lines[j])] = true
+			}
+			var comments []string
+			for _, p := range pending {
+				if strings.TrimSpace(p) != "" && !above[strings.TrimSpace(p)] {
+					comments = append(comments, p)
+				}
+			}
+			pending = nil
+			lines[i] = line
+			if len(comments) == 0 {
+				continue
+			}
+			lines = append(lines[:i], append(comments, lines[i:]...)...)
+			for key, pos := range index {
+				if pos >= i {
+					index[key] = pos + len(comments)
+				}
+			}
+		}
+
+		// 末尾のコメントも残す
+		var trailing []string
+		for _, p := range pending {
+			if strings.TrimSpace(p) != "" {
+				trailing = append(trailing, p)
+			}
+		}
+		if len(trailing) > 0 {
+			appendLines(trailing...)
+		}
+	}
+
+	return EnvData{Lines: lines}
+}
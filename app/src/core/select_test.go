@@ -0,0 +1,203 @@
+package core
+
+import (
+	"encoding/json"
+	"testing"
+
+	"bwsf/src/config"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// selectFiles は --env / --layer のテストで保存しておく .env / .env.staging / .env.production です。
+var selectFiles = MultiEnvData{
+	".env":            {Lines: []string{"# shared", "APP=web", "DB_HOST=localhost", "DEBUG=true"}},
+	".env.staging":    {Lines: []string{"# staging database", "DB_HOST=staging.db", "# staging only", "SENTRY=on"}},
+	".env.production": {Lines: []string{"DB_HOST=prod.db"}},
+}
+
+// =============================================================================
+// EnvFileName のテスト
+// =============================================================================
+
+// 正常系: 環境名をファイル名に変換する
+func TestEnvFileName(t *testing.T) {
+	assert.Equal(t, ".env.staging", EnvFileName("staging"))
+	assert.Equal(t, ".env.staging", EnvFileName(".env.staging"))
+	assert.Equal(t, ".env", EnvFileName(".env"))
+}
+
+// =============================================================================
+// planPullTargets のテスト
+// =============================================================================
+
+// 正常系: 選択なし・ファイル選択・名前変更・レイヤー
+func TestPlanPullTargets(t *testing.T) {
+	available := []string{".env", ".env.production", ".env.staging"}
+
+	targets, err := planPullTargets(available, PullOptions{})
+	require.NoError(t, err)
+	assert.Len(t, targets, 3)
+	assert.False(t, targets[0].mapped())
+
+	targets, err = planPullTargets(available, PullOptions{Files: []string{".env.staging", ".env.staging"}})
+	require.NoError(t, err)
+	assert.Equal(t, []pullTarget{{Name: ".env.staging", Sources: []string{".env.staging"}}}, targets)
+
+	targets, err = planPullTargets(available, PullOptions{Files: []string{".env.staging"}, As: ".env"})
+	require.NoError(t, err)
+	assert.Equal(t, []pullTarget{{Name: ".env", Sources: []string{".env.staging"}}}, targets)
+	assert.True(t, targets[0].mapped())
+
+	targets, err = planPullTargets(available, PullOptions{Layer: []string{".env", ".env.staging"}, MergeInto: ".env"})
+	require.NoError(t, err)
+	assert.Equal(t, []pullTarget{{Name: ".env", Sources: []string{".env", ".env.staging"}}}, targets)
+	assert.True(t, targets[0].mapped())
+}
+
+// 異常系: 不正な組み合わせや存在しないファイル
+func TestPlanPullTargets_Errors(t *testing.T) {
+	available := []string{".env", ".env.staging"}
+	tests := []struct {
+		name string
+		opts PullOptions
+		want string
+	}{
+		{"as without file", PullOptions{As: ".env"}, "--as requires exactly one"},
+		{"as with two files", PullOptions{Files: []string{".env", ".env.staging"}, As: ".env"}, "--as requires exactly one"},
+		{"layer without merge-into", PullOptions{Layer: []string{".env"}}, "--layer requires --merge-into"},
+		{"merge-into without layer", PullOptions{MergeInto: ".env"}, "--merge-into requires --layer"},
+		{"layer with file", PullOptions{Layer: []string{".env"}, MergeInto: ".env", Files: []string{".env"}}, "cannot be combined"},
+		{"path in as", PullOptions{Files: []string{".env"}, As: "../.env"}, "must be a plain file name"},
+		{"unknown file", PullOptions{Files: []string{".env.qa"}}, "file '.env.qa' not found in project"},
+		{"unknown layer", PullOptions{Layer: []string{".env", ".env.qa"}, MergeInto: ".env"}, "file '.env.qa' not found in project"},
+	}
+
+	for _, tt := range tests {
+		_, err := planPullTargets(available, tt.opts)
+		require.Error(t, err, tt.name)
+		assert.Contains(t, err.Error(), tt.want, tt.name)
+	}
+}
+
+// =============================================================================
+// mergeLayers のテスト
+// =============================================================================
+
+// 正常系: 後のキーが優先され、両方のコメントが残る
+func TestMergeLayers(t *testing.T) {
+	base := EnvData{Lines: []string{"# shared", "APP=web", "# database", "DB_HOST=localhost", "DEBUG=true"}}
+	upper := EnvData{Lines: []string{
+		"# database",
+		"# staging database",
+		"DB_HOST=staging.db",
+		"",
+		"# staging only",
+		"SENTRY=on",
+		"DEBUG=false",
+		"# end of staging",
+	}}
+
+	merged := mergeLayers([]EnvData{base, upper})
+
+	assert.Equal(t, []string{
+		"# shared",
+		"APP=web",
+		"# database",
+		"# staging database",
+		"DB_HOST=staging.db",
+		"DEBUG=false",
+		"",
+		"# staging only",
+		"SENTRY=on",
+		"# end of staging",
+	}, merged.Lines)
+}
+
+// 正常系: 3 層以上でも最後のレイヤーが優先される
+func TestMergeLayers_ThreeLayers(t *testing.T) {
+	merged := mergeLayers([]EnvData{
+		{Lines: []string{"A=1", "B=1"}},
+		{Lines: []string{"B=2", "C=2"}},
+		{Lines: []string{"C=3", "export A=3"}},
+	})
+
+	assert.Equal(t, []string{"export A=3", "B=2", "", "C=3"}, merged.Lines)
+}
+
+// =============================================================================
+// PullEnvCoreWithOptions のファイル選択のテスト
+// =============================================================================
+
+// 正常系: 指定したファイルのみ書き出す
+func TestPullEnvCore_SelectFiles(t *testing.T) {
+	fs := &mockFileSystem{}
+
+	err := PullEnvCoreWithOptions("/web", "web", fs, newTestBwClient(t, selectFiles), &config.Config{}, nil, noConfirm, &mockLogger{},
+		PullOptions{Files: []string{".env.staging"}, StatePath: "/state.json"})
+
+	require.NoError(t, err)
+	assert.Contains(t, fs.writtenFiles, "/web/.env.staging")
+	assert.NotContains(t, fs.writtenFiles, "/web/.env")
+	assert.NotContains(t, fs.writtenFiles, "/web/.env.production")
+
+	var state SyncState
+	require.NoError(t, json.Unmarshal(fs.writtenFiles["/state.json"], &state))
+	assert.Len(t, state.Dirs["/web"].Files, 1)
+	assert.Contains(t, state.Dirs["/web"].Files, ".env.staging")
+}
+
+// 正常系: 1 ファイルを別名で書き出し、同期状態には記録しない
+func TestPullEnvCore_SelectAs(t *testing.T) {
+	fs := &mockFileSystem{}
+	logger := &mockLogger{}
+
+	err := PullEnvCoreWithOptions("/srv", "web", fs, newTestBwClient(t, selectFiles), &config.Config{}, nil, noConfirm, logger,
+		PullOptions{Files: []string{".env.staging"}, As: ".env", StatePath: "/state.json"})
+
+	require.NoError(t, err)
+	assert.Equal(t, "# staging database\nDB_HOST=staging.db\n# staging only\nSENTRY=on", string(fs.writtenFiles["/srv/.env"]))
+	assert.Len(t, fs.writtenFiles, 1)
+	require.Len(t, logger.warnings, 1)
+	assert.Contains(t, logger.warnings[0], "not tracked for sync")
+}
+
+// 正常系: InMemory の場合は書き出し先の FileSystem に関わらず同期の警告を出さない
+func TestPullEnvCore_SelectAsInMemory(t *testing.T) {
+	fs := &mockFileSystem{}
+	logger := &mockLogger{}
+
+	err := PullEnvCoreWithOptions("/srv", "web", fs, newTestBwClient(t, selectFiles), &config.Config{}, nil, noConfirm, logger,
+		PullOptions{Files: []string{".env.staging"}, As: ".env", InMemory: true})
+
+	require.NoError(t, err)
+	assert.Contains(t, fs.writtenFiles, "/srv/.env")
+	assert.Empty(t, logger.warnings)
+}
+
+// 正常系: レイヤーを重ねて 1 ファイルに書き出す
+func TestPullEnvCore_LayerMergeInto(t *testing.T) {
+	fs := &mockFileSystem{}
+
+	err := PullEnvCoreWithOptions("/srv", "web", fs, newTestBwClient(t, selectFiles), &config.Config{}, nil, noConfirm, &mockLogger{},
+		PullOptions{Layer: []string{".env", ".env.staging"}, MergeInto: ".env"})
+
+	require.NoError(t, err)
+	assert.Equal(t,
+		"# shared\nAPP=web\n# staging database\nDB_HOST=staging.db\nDEBUG=true\n\n# staging only\nSENTRY=on",
+		string(fs.writtenFiles["/srv/.env"]))
+	assert.Len(t, fs.writtenFiles, 1)
+}
+
+// 異常系: 保存されていないファイルを選ぶと何も書き出さない
+func TestPullEnvCore_SelectUnknownFile(t *testing.T) {
+	fs := &mockFileSystem{}
+
+	err := PullEnvCoreWithOptions("/srv", "web", fs, newTestBwClient(t, selectFiles), &config.Config{}, nil, noConfirm, &mockLogger{},
+		PullOptions{Files: []string{".env.qa"}})
+
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "available: .env, .env.production, .env.staging")
+	assert.Empty(t, fs.writtenFiles)
+}
@@ -0,0 +1,419 @@
+package core
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"bwsf/src/config"
+)
+
+// 対応するシェル
+const (
+	ShellBash   = "bash"
+	ShellZsh    = "zsh"
+	ShellFish   = "fish"
+	ShellDirenv = "direnv" // direnv の use_bwsf から呼ばれる（bash 構文で、読み込み状態は direnv が管理）
+)
+
+// シェルフックが読み込み状態を保持する環境変数
+const (
+	ShellDirEnv  = "BWSF_SHELL_DIR"  // 変数を読み込んだプロジェクトのディレクトリ
+	ShellKeysEnv = "BWSF_SHELL_KEYS" // 読み込んだキー名（空白区切り）
+)
+
+// ShellEnvKeyringAccountPrefix はシェル用キャッシュのキーリングのアカウント名の接頭辞です。
+// サービス名はミラーと同じ MirrorKeyringService を使います。
+const ShellEnvKeyringAccountPrefix = "shell-env:"
+
+// shellEnvIndexAccountPrefix はプロジェクトごとのキャッシュのアカウント名の一覧を保存するアカウント名の接頭辞です。
+// キーリングは一覧を取得できないため、InvalidateShellEnvCache はこの一覧から削除対象を探します。
+const shellEnvIndexAccountPrefix = "shell-env-index:"
+
+// DefaultShellEnvFile は --env / --file を指定しない場合に読み込むファイルです。
+const DefaultShellEnvFile = ".env"
+
+// shellKeyPattern はシェル変数として export できるキー名です。
+var shellKeyPattern = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// ShellVar はシェルに export する 1 変数です。
+type ShellVar struct {
+	Key   string `json:"key"`
+	Value string `json:"value"`
+}
+
+// ShellEnvOptions は LoadShellEnvCore の設定です。
+type ShellEnvOptions struct {
+	// Files は読み込むファイルです。複数指定した場合は順に重ね、後のキーが優先されます。空の場合は .env です。
+	Files []string
+	// Interpolate が true の場合、${KEY} と bw:// 参照を展開します（.bwsf.json の interpolate でも有効化）。
+	Interpolate bool
+	// Keyring が設定され TTL が正の場合、読み込んだ変数を TTL の間キーリングに保持します。
+	Keyring Keyring
+	TTL     time.Duration
+	// Now は現在時刻です。nil の場合は time.Now を使います。
+	Now func() time.Time
+}
+
+// shellEnvCacheEntry はキーリングに保存するキャッシュの内容です。
+type shellEnvCacheEntry struct {
+	ExpiresAt time.Time  `json:"expires_at"`
+	Vars      []ShellVar `json:"vars"`
+}
+
+// LoadShellEnvCore はプロジェクトの変数を読み込みます。pull と同じ処理（extends・展開・--layer の重ね合わせ）を
+// メモリ上のファイルシステムに対して実行するため、.env ファイルはディスクに書き出しません。
+func LoadShellEnvCore(
+	dir, projectName string,
+	fs FileSystem,
+	bw BwClient,
+	cfg *config.Config,
+	promptPassword func() (string, error),
+	logger Logger,
+	opts ShellEnvOptions,
+) ([]ShellVar, error) {
+	files := opts.Files
+	if len(files) == 0 {
+		files = []string{DefaultShellEnvFile}
+	}
+	now := time.Now
+	if opts.Now != nil {
+		now = opts.Now
+	}
+
+	useCache := opts.Keyring != nil && opts.TTL > 0
+	account := shellEnvCacheAccount(dir, projectName, files, opts.Interpolate)
+	if useCache {
+		if vars, ok := readShellEnvCache(opts.Keyring, account, now(), logger); ok {
+			return vars, nil
+		}
+	}
+
+	// 指定したファイルを 1 つに重ねた内容をメモリ上に書き出す
+	const merged = ".env"
+	memFS := &memoryOutputFS{FileSystem: fs, files: make(map[string][]byte)}
+	pullOpts := PullOptions{
+		Interpolate: opts.Interpolate,
+		Layer:       files,
+		MergeInto:   merged,
+		InMemory:    true,
+	}
+	overwrite := func(path string) (bool, error) { return true, nil }
+	if err := PullEnvCoreWithOptions(dir, projectName, memFS, bw, cfg, promptPassword, overwrite, logger, pullOpts); err != nil {
+		return nil, err
+	}
+
+	content, ok := memFS.files[filepath.Join(dir, merged)]
+	if !ok {
+		return nil, fmt.Errorf("no variables loaded for %s", projectName)
+	}
+	vars := shellVarsFromContent(content, logger)
+
+	if useCache {
+		writeShellEnvCache(opts.Keyring, projectName, account, shellEnvCacheEntry{ExpiresAt: now().Add(opts.TTL), Vars: vars})
+	}
+	return vars, nil
+}
+
+// memoryOutputFS は書き込みをメモリに保持する FileSystem です。読み込みは元の FileSystem に委ねます。
+type memoryOutputFS struct {
+	FileSystem
+	files map[string][]byte
+}
+
+func (m *memoryOutputFS) WriteFile(path string, data []byte, perm uint32) error {
+	m.files[path] = append([]byte(nil), data...)
+	return nil
+}
+
+func (m *memoryOutputFS) MkdirAll(path string, perm uint32) error {
+	return nil
+}
+
+// shellVarsFromContent は .env の内容を変数の一覧にします。同じキーは後の行が優先されます。
+func shellVarsFromContent(content []byte, logger Logger) []ShellVar {
+	index := make(map[string]int)
+	var vars []ShellVar
+	for _, line := range parseEnvContent(content).Lines {
+		entry, ok := parseEnvLine(line)
+		if !ok {
+			continue
+		}
+		if !shellKeyPattern.MatchString(entry.Key) {
+			logger.Warning("Skipping ", entry.Key, ": not a valid shell variable name")
+			continue
+		}
+		if i, seen := index[entry.Key]; seen {
+			vars[i].Value = entry.Value
+			continue
+		}
+		index[entry.Key] = len(vars)
+		vars = append(vars, ShellVar{Key: entry.Key, Value: entry.Value})
+	}
+	return vars
+}
+
+// shellEnvCacheAccount はキャッシュのアカウント名です。パスとファイル名をそのまま残さないようハッシュにします。
+func shellEnvCacheAccount(dir, projectName string, files []string, interpolate bool) string {
+	sum := sha256.Sum256([]byte(strings.Join([]string{dir, projectName, strings.Join(files, ","), fmt.Sprint(interpolate)}, "\n")))
+	return ShellEnvKeyringAccountPrefix + hex.EncodeToString(sum[:16])
+}
+
+// shellEnvIndexAccount はプロジェクトのキャッシュの一覧のアカウント名です。
+func shellEnvIndexAccount(projectName string) string {
+	sum := sha256.Sum256([]byte(projectName))
+	return shellEnvIndexAccountPrefix + hex.EncodeToString(sum[:16])
+}
+
+// readShellEnvCache は期限内のキャッシュを返します。読めない・期限切れの場合は ok=false です。
+// 期限切れのエントリと読めないエントリは、値をキーリングに残さないよう削除します。
+func readShellEnvCache(keyring Keyring, account string, now time.Time, logger Logger) ([]ShellVar, bool) {
+	raw, err := keyring.Get(MirrorKeyringService, account)
+	if err != nil || raw == "" {
+		return nil, false
+	}
+	var entry shellEnvCacheEntry
+	if err := json.Unmarshal([]byte(raw), &entry); err != nil || !now.Before(entry.ExpiresAt) {
+		if err := keyring.Delete(MirrorKeyringService, account); err != nil {
+			logger.Warning("Failed to remove an expired shell cache entry: ", err.Error())
+		}
+		return nil, false
+	}
+	return entry.Vars, true
+}
+
+// writeShellEnvCache はキャッシュを保存し、プロジェクトの一覧に追加します。
+// キーリングが使えない場合は何もしません（毎回取得するだけ）。
+func writeShellEnvCache(keyring Keyring, projectName, account string, entry shellEnvCacheEntry) {
+	data, err := json.Marshal(entry)
+	if err != nil {
+		return
+	}
+	if err := keyring.Set(MirrorKeyringService, account, string(data)); err != nil {
+		return
+	}
+
+	accounts := readShellEnvIndex(keyring, projectName)
+	for _, existing := range accounts {
+		if existing == account {
+			return
+		}
+	}
+	index, err := json.Marshal(append(accounts, account))
+	if err != nil {
+		return
+	}
+	_ = keyring.Set(MirrorKeyringService, shellEnvIndexAccount(projectName), string(index))
+}
+
+// readShellEnvIndex はプロジェクトのキャッシュのアカウント名の一覧を返します。
+func readShellEnvIndex(keyring Keyring, projectName string) []string {
+	raw, err := keyring.Get(MirrorKeyringService, shellEnvIndexAccount(projectName))
+	if err != nil || raw == "" {
+		return nil
+	}
+	var accounts []string
+	if err := json.Unmarshal([]byte(raw), &accounts); err != nil {
+		return nil
+	}
+	return accounts
+}
+
+// InvalidateShellEnvCache はプロジェクトの bwsf export 用キャッシュをすべて削除します。
+// push・pull・rotate の後に呼び、次のシェルで古い値を読み込まないようにします。
+// 一覧が読めない場合（キャッシュしていない・キーリングが使えない）は何もしません。
+func InvalidateShellEnvCache(keyring Keyring, projectName string, logger Logger) {
+	accounts := readShellEnvIndex(keyring, projectName)
+	if len(accounts) == 0 {
+		return
+	}
+	for _, account := range append(accounts, shellEnvIndexAccount(projectName)) {
+		if err := keyring.Delete(MirrorKeyringService, account); err != nil {
+			logger.Warning("Failed to clear the shell cache for ", projectName, ": ", err.Error())
+			return
+		}
+	}
+}
+
+// ShellExportScript は vars を export するスクリプトを返します。
+// bash / zsh / fish では読み込んだキー名と dir を記録し、ShellUnloadScript で戻せるようにします。
+// lookupEnv で既に設定済みと分かる変数は上書きせずにスキップします（direnv では direnv が戻すため上書きします）。
+func ShellExportScript(shell, dir string, vars []ShellVar, lookupEnv func(string) (string, bool), logger Logger) (string, error) {
+	if err := validateShell(shell); err != nil {
+		return "", err
+	}
+
+	var b strings.Builder
+	var keys []string
+	for _, v := range vars {
+		if shell != ShellDirenv && lookupEnv != nil {
+			if _, set := lookupEnv(v.Key); set {
+				logger.Warning("Skipping ", v.Key, ": already set in the environment")
+				continue
+			}
+		}
+		b.WriteString(shellSetLine(shell, v.Key, v.Value))
+		keys = append(keys, v.Key)
+	}
+	if shell != ShellDirenv {
+		sort.Strings(keys)
+		b.WriteString(shellSetLine(shell, ShellDirEnv, dir))
+		b.WriteString(shellSetLine(shell, ShellKeysEnv, strings.Join(keys, " ")))
+	}
+	return b.String(), nil
+}
+
+// ShellUnloadScript は ShellExportScript で読み込んだ keys と記録用の変数を削除するスクリプトを返します。
+func ShellUnloadScript(shell string, keys []string) (string, error) {
+	if err := validateShell(shell); err != nil {
+		return "", err
+	}
+	var b strings.Builder
+	keys = append(append([]string(nil), keys...), ShellDirEnv, ShellKeysEnv)
+	for _, key := range keys {
+		if !shellKeyPattern.MatchString(key) {
+			continue
+		}
+		if shell == ShellFish {
+			fmt.Fprintf(&b, "set -e %s;\n", key)
+		} else {
+			fmt.Fprintf(&b, "unset %s;\n", key)
+		}
+	}
+	return b.String(), nil
+}
+
+// shellSetLine は 1 変数を export する行です。
+func shellSetLine(shell, key, value string) string {
+	if shell == ShellFish {
+		return fmt.Sprintf("set -gx %s %s;\n", key, fishQuote(value))
+	}
+	return fmt.Sprintf("export %s=%s;\n", key, ShellQuote(value))
+}
+
+// ShellQuote は bash / zsh の単一引用符で value を囲みます。
+func ShellQuote(value string) string {
+	return "'" + strings.ReplaceAll(value, "'", `'\''`) + "'"
+}
+
+// fishQuote は fish の単一引用符で value を囲みます（\ と ' のみエスケープが必要）。
+func fishQuote(value string) string {
+	value = strings.ReplaceAll(value, `\`, `\\`)
+	return "'" + strings.ReplaceAll(value, "'", `\'`) + "'"
+}
+
+func validateShell(shell string) error {
+	switch shell {
+	case ShellBash, ShellZsh, ShellFish, ShellDirenv:
+		return nil
+	}
+	return fmt.Errorf("unsupported shell %q: use bash, zsh, fish or direnv", shell)
+}
+
+// ShellHookScript はシェルの rc ファイルで eval するフックを返します。
+// プロンプト表示（fish では PWD の変更）のたびに、.bwsf.json のある最も近い親ディレクトリを探し、
+// プロジェクトが変わったときのみ `bwsf export` を呼ぶため、同じプロジェクト内の cd では bwsf を起動しません。
+// args は `bwsf export` にそのまま渡すフラグです（--env / --file など）。
+func ShellHookScript(shell string, args []string) (string, error) {
+	quoted := make([]string, 0, len(args))
+	for _, arg := range args {
+		if shell == ShellFish {
+			quoted = append(quoted, fishQuote(arg))
+		} else {
+			quoted = append(quoted, ShellQuote(arg))
+		}
+	}
+	extra := ""
+	if len(quoted) > 0 {
+		extra = " " + strings.Join(quoted, " ")
+	}
+
+	switch shell {
+	case ShellBash:
+		return fmt.Sprintf(posixHookFunction, "bash", extra) + bashHookInstall, nil
+	case ShellZsh:
+		return fmt.Sprintf(posixHookFunction, "zsh", extra) + zshHookInstall, nil
+	case ShellFish:
+		return fmt.Sprintf(fishHook, extra), nil
+	case ShellDirenv:
+		if len(args) > 0 {
+			return "", fmt.Errorf("direnv takes its flags from .envrc: use bwsf --env NAME")
+		}
+		return direnvStdlib, nil
+	}
+	return "", validateShell(shell)
+}
+
+const posixHookFunction = `_bwsf_hook() {
+  local previous_exit_status=$?
+  local dir="$PWD" root=""
+  while [ -n "$dir" ]; do
+    if [ -f "$dir/.bwsf.json" ]; then
+      root="$dir"
+      break
+    fi
+    dir="${dir%%/*}"
+  done
+  if [ "$root" != "${BWSF_SHELL_DIR:-}" ]; then
+    if [ -n "${BWSF_SHELL_DIR:-}" ]; then
+      eval "$(command bwsf export %[1]s --unload)"
+    fi
+    if [ -n "$root" ]; then
+      eval "$(command bwsf export %[1]s --dir "$root"%[2]s)"
+      # Remember the directory even when loading failed, so the prompt does not retry on every command
+      export BWSF_SHELL_DIR="$root"
+    fi
+  fi
+  return $previous_exit_status
+}
+`
+
+const bashHookInstall = `if [[ ";${PROMPT_COMMAND[*]:-};" != *";_bwsf_hook;"* ]]; then
+  PROMPT_COMMAND="_bwsf_hook${PROMPT_COMMAND:+;$PROMPT_COMMAND}"
+fi
+`
+
+const zshHookInstall = `typeset -ag precmd_functions
+if (( ! ${precmd_functions[(I)_bwsf_hook]} )); then
+  precmd_functions=(_bwsf_hook $precmd_functions)
+fi
+`
+
+const fishHook = `function _bwsf_hook --on-variable PWD --description 'Load bwsf variables for the current project'
+    set -l dir $PWD
+    set -l root ''
+    while test -n "$dir"
+        if test -f "$dir/.bwsf.json"
+            set root $dir
+            break
+        end
+        set dir (string replace -r '/[^/]*$' '' -- $dir)
+    end
+    if test "$root" != "$BWSF_SHELL_DIR"
+        if test -n "$BWSF_SHELL_DIR"
+            command bwsf export fish --unload | source
+        end
+        if test -n "$root"
+            command bwsf export fish --dir "$root"%s | source
+            # Remember the directory even when loading failed, so it is not retried on every cd
+            set -gx BWSF_SHELL_DIR $root
+        end
+    end
+end
+_bwsf_hook
+`
+
+const direnvStdlib = `# Usage in .envrc: use bwsf [--env NAME] [--file FILE]...
+use_bwsf() {
+  watch_file .bwsf.json
+  local script
+  script="$(command bwsf export direnv --dir "$PWD" "$@")" || return
+  eval "$script"
+}
+`
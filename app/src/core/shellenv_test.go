@@ -0,0 +1,209 @@
+package core
+
+import (
+	"testing"
+	"time"
+
+	"bwsf/src/config"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// =============================================================================
+// LoadShellEnvCore のテスト
+// =============================================================================
+
+var shellNow = func() time.Time { return time.Date(2026, 3, 1, 9, 0, 0, 0, time.UTC) }
+
+// shellFiles は shell のテストで保存しておく .env と .env.local です。
+var shellFiles = MultiEnvData{
+	".env":       {Lines: []string{"A=1", `B="two words"`, "A=2"}},
+	".env.local": {Lines: []string{"B=local", "C=3"}},
+}
+
+// 正常系: 既定では .env を読み込み、後の行が優先され、ファイルは書き出さない
+func TestLoadShellEnvCore_Default(t *testing.T) {
+	fs := &mockFileSystem{}
+
+	vars, err := LoadShellEnvCore("/work/web", "web", fs, newTestBwClient(t, shellFiles), &config.Config{}, nil, &mockLogger{}, ShellEnvOptions{})
+
+	require.NoError(t, err)
+	assert.Equal(t, []ShellVar{{Key: "A", Value: "2"}, {Key: "B", Value: "two words"}}, vars)
+	assert.Empty(t, fs.writtenFiles)
+	assert.NotContains(t, fs.calls, "MkdirAll(/work/web)")
+}
+
+// 正常系: 複数のファイルは指定した順に重ねる
+func TestLoadShellEnvCore_Layered(t *testing.T) {
+	vars, err := LoadShellEnvCore("/work/web", "web", &mockFileSystem{}, newTestBwClient(t, shellFiles), &config.Config{}, nil, &mockLogger{},
+		ShellEnvOptions{Files: []string{".env", ".env.local"}})
+
+	require.NoError(t, err)
+	assert.Equal(t, []ShellVar{{Key: "A", Value: "2"}, {Key: "B", Value: "local"}, {Key: "C", Value: "3"}}, vars)
+}
+
+// 正常系: TTL の間はキーリングのキャッシュを使い、期限切れ後は取得し直す
+func TestLoadShellEnvCore_Cache(t *testing.T) {
+	keyring := &mockKeyring{}
+	now := shellNow()
+	opts := ShellEnvOptions{Keyring: keyring, TTL: time.Minute, Now: func() time.Time { return now }}
+
+	_, err := LoadShellEnvCore("/work/web", "web", &mockFileSystem{}, newTestBwClient(t, shellFiles), &config.Config{}, nil, &mockLogger{}, opts)
+	require.NoError(t, err)
+	require.Len(t, keyring.secrets, 2, "the entry and the project's index")
+
+	cached := &mockBwClient{folderID: "folder-123"}
+	vars, err := LoadShellEnvCore("/work/web", "web", &mockFileSystem{}, cached, &config.Config{}, nil, &mockLogger{}, opts)
+	require.NoError(t, err)
+	assert.Equal(t, "2", vars[0].Value)
+	assert.Empty(t, cached.calls)
+
+	now = now.Add(2 * time.Minute)
+	_, err = LoadShellEnvCore("/work/web", "web", &mockFileSystem{}, cached, &config.Config{}, nil, &mockLogger{}, opts)
+	assert.ErrorContains(t, err, "item 'web' not found")
+}
+
+// 正常系: 期限切れのエントリと読めないエントリはキーリングから削除する
+func TestLoadShellEnvCore_RemovesStaleEntries(t *testing.T) {
+	account := shellEnvCacheAccount("/work/web", "web", []string{DefaultShellEnvFile}, false)
+	stale := map[string]string{
+		"expired":     `{"expires_at":"2026-03-01T08:00:00Z","vars":[{"key":"A","value":"old"}]}`,
+		"undecodable": "not json",
+	}
+	for name, raw := range stale {
+		t.Run(name, func(t *testing.T) {
+			keyring := &mockKeyring{secrets: map[string]string{MirrorKeyringService + "/" + account: raw}}
+			opts := ShellEnvOptions{Keyring: keyring, TTL: time.Minute, Now: shellNow}
+
+			_, err := LoadShellEnvCore("/work/web", "web", &mockFileSystem{}, &mockBwClient{folderID: "folder-123"}, &config.Config{}, nil, &mockLogger{}, opts)
+
+			assert.ErrorContains(t, err, "item 'web' not found")
+			assert.NotContains(t, keyring.secrets, MirrorKeyringService+"/"+account)
+		})
+	}
+}
+
+// 正常系: push・pull・rotate の後はそのプロジェクトのキャッシュのみ削除する
+func TestInvalidateShellEnvCache(t *testing.T) {
+	keyring := &mockKeyring{}
+	opts := ShellEnvOptions{Keyring: keyring, TTL: time.Minute, Now: shellNow}
+	for _, files := range [][]string{nil, {".env.local"}} {
+		opts.Files = files
+		_, err := LoadShellEnvCore("/work/web", "web", &mockFileSystem{}, newTestBwClient(t, shellFiles), &config.Config{}, nil, &mockLogger{}, opts)
+		require.NoError(t, err)
+	}
+	other := newTestBwClient(t, shellFiles)
+	other.itemByName.Name = "api"
+	opts.Files = nil
+	_, err := LoadShellEnvCore("/work/api", "api", &mockFileSystem{}, other, &config.Config{}, nil, &mockLogger{}, opts)
+	require.NoError(t, err)
+	require.Len(t, keyring.secrets, 5)
+
+	writes := map[string]func(bw BwClient) error{
+		"push": func(bw BwClient) error {
+			fs := &mockFileSystem{
+				dirEntries:     []DirEntry{&mockDirEntry{name: ".env"}},
+				readContentMap: map[string][]byte{"/work/web/.env": []byte("A=3\n")},
+			}
+			return PushEnvCoreWithOptions("/work/web", "web", fs, bw, &config.Config{}, nil, &mockLogger{}, PushOptions{SkipLint: true, ShellCache: keyring})
+		},
+		"pull": func(bw BwClient) error {
+			return PullEnvCoreWithOptions("/work/web", "web", &mockFileSystem{}, bw, &config.Config{}, nil, noConfirm, &mockLogger{}, PullOptions{ShellCache: keyring})
+		},
+		"rotate": func(bw BwClient) error {
+			_, err := RotateKeyCore("web", "C", RotateOptions{File: ".env.local", ShellCache: keyring}, bw, &config.Config{}, nil, &mockLogger{})
+			return err
+		},
+	}
+	for name, write := range writes {
+		t.Run(name, func(t *testing.T) {
+			saved := make(map[string]string)
+			for k, v := range keyring.secrets {
+				saved[k] = v
+			}
+			defer func() { keyring.secrets = saved }()
+
+			require.NoError(t, write(newTestBwClient(t, shellFiles)))
+
+			assert.Len(t, keyring.secrets, 2, "only api's entry and index are left")
+			assert.Contains(t, keyring.secrets, MirrorKeyringService+"/"+shellEnvCacheAccount("/work/api", "api", []string{DefaultShellEnvFile}, false))
+		})
+	}
+}
+
+// 異常系: 存在しないファイルを指定
+func TestLoadShellEnvCore_UnknownFile(t *testing.T) {
+	_, err := LoadShellEnvCore("/work/web", "web", &mockFileSystem{}, newTestBwClient(t, shellFiles), &config.Config{}, nil, &mockLogger{},
+		ShellEnvOptions{Files: []string{".env.prod"}})
+
+	assert.ErrorContains(t, err, "file '.env.prod' not found")
+}
+
+// =============================================================================
+// ShellExportScript / ShellUnloadScript のテスト
+// =============================================================================
+
+// 正常系: シェルごとのクォートと読み込み状態の記録、設定済みの変数はスキップ
+func TestShellExportScript(t *testing.T) {
+	vars := []ShellVar{{Key: "Q", Value: `it's \ok`}, {Key: "HOME", Value: "/tmp"}}
+	lookup := func(key string) (string, bool) { return "", key == "HOME" }
+	logger := &mockLogger{}
+
+	script, err := ShellExportScript(ShellBash, "/work/web", vars, lookup, logger)
+	require.NoError(t, err)
+	assert.Equal(t, "export Q='it'\\''s \\ok';\nexport BWSF_SHELL_DIR='/work/web';\nexport BWSF_SHELL_KEYS='Q';\n", script)
+	checkWarning(t, logger, "Skipping HOME")
+
+	script, err = ShellExportScript(ShellFish, "/work/web", vars[:1], lookup, &mockLogger{})
+	require.NoError(t, err)
+	assert.Equal(t, "set -gx Q 'it\\'s \\\\ok';\nset -gx BWSF_SHELL_DIR '/work/web';\nset -gx BWSF_SHELL_KEYS 'Q';\n", script)
+
+	script, err = ShellExportScript(ShellDirenv, "/work/web", vars, lookup, &mockLogger{})
+	require.NoError(t, err)
+	assert.Equal(t, "export Q='it'\\''s \\ok';\nexport HOME='/tmp';\n", script)
+}
+
+// 正常系: 読み込んだキーと記録用の変数を削除し、不正な名前は無視する
+func TestShellUnloadScript(t *testing.T) {
+	script, err := ShellUnloadScript(ShellZsh, []string{"A", "$(rm)"})
+	require.NoError(t, err)
+	assert.Equal(t, "unset A;\nunset BWSF_SHELL_DIR;\nunset BWSF_SHELL_KEYS;\n", script)
+
+	script, err = ShellUnloadScript(ShellFish, nil)
+	require.NoError(t, err)
+	assert.Equal(t, "set -e BWSF_SHELL_DIR;\nset -e BWSF_SHELL_KEYS;\n", script)
+}
+
+// 異常系: 対応していないシェル
+func TestShellScripts_UnsupportedShell(t *testing.T) {
+	_, err := ShellExportScript("tcsh", "/work", nil, nil, &mockLogger{})
+	assert.ErrorContains(t, err, "unsupported shell")
+
+	_, err = ShellHookScript("tcsh", nil)
+	assert.ErrorContains(t, err, "unsupported shell")
+
+	_, err = ShellHookScript(ShellDirenv, []string{"--env", "local"})
+	assert.ErrorContains(t, err, "use bwsf --env")
+}
+
+// =============================================================================
+// ShellHookScript のテスト
+// =============================================================================
+
+// 正常系: export に渡すフラグをクォートして埋め込む
+func TestShellHookScript(t *testing.T) {
+	script, err := ShellHookScript(ShellZsh, []string{"--env", "my env"})
+	require.NoError(t, err)
+	assert.Contains(t, script, `eval "$(command bwsf export zsh --dir "$root" '--env' 'my env')"`)
+	assert.Contains(t, script, `dir="${dir%/*}"`)
+	assert.Contains(t, script, "precmd_functions=(_bwsf_hook $precmd_functions)")
+
+	script, err = ShellHookScript(ShellFish, nil)
+	require.NoError(t, err)
+	assert.Contains(t, script, `command bwsf export fish --dir "$root" | source`)
+
+	script, err = ShellHookScript(ShellDirenv, nil)
+	require.NoError(t, err)
+	assert.Contains(t, script, "use_bwsf() {")
+}
@@ -0,0 +1,175 @@
+package core
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"strings"
+	"sync"
+)
+
+// SyncState は push/pull 時点の各ファイルの内容ハッシュを記録するローカル状態です。
+// キーはプロジェクトのディレクトリパスです。
+type SyncState struct {
+	Dirs map[string]DirSyncState `json:"dirs"`
+}
+
+// DirSyncState は 1 ディレクトリ分の同期状態を表します。
+type DirSyncState struct {
+	Project  string            `json:"project"`
+	Files    map[string]string `json:"files"`              // ファイル名 -> SHA-256
+	Expanded []string          `json:"expanded,omitempty"` // 参照を展開して pull したファイル
+}
+
+// syncStateMu は同期状態ファイルの読み書きを直列化します（ワークスペース処理で並列に更新されるため）。
+var syncStateMu sync.Mutex
+
+// ErrExpandedPush は参照を展開して pull したファイルを push しようとした場合に返されます。
+var ErrExpandedPush = errors.New("refusing to push interpolated files")
+
+// LoadSyncState は同期状態ファイルを読み込みます。ファイルが存在しない場合は空の状態を返します。
+func LoadSyncState(fs FileSystem, path string) (*SyncState, error) {
+	state := &SyncState{Dirs: make(map[string]DirSyncState)}
+
+	info, err := fs.Stat(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to stat sync state: %w", err)
+	}
+	if info.IsNotExist() {
+		return state, nil
+	}
+
+	data, err := fs.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read sync state: %w", err)
+	}
+	if len(strings.TrimSpace(string(data))) == 0 {
+		return state, nil
+	}
+
+	if err := json.Unmarshal(data, state); err != nil {
+		return nil, fmt.Errorf("failed to parse sync state: %w", err)
+	}
+	if state.Dirs == nil {
+		state.Dirs = make(map[string]DirSyncState)
+	}
+	return state, nil
+}
+
+// SaveSyncState は同期状態ファイルを書き出します。
+func SaveSyncState(fs FileSystem, path string, state *SyncState) error {
+	data, err := json.MarshalIndent(state, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal sync state: %w", err)
+	}
+
+	if err := fs.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("failed to create sync state directory: %w", err)
+	}
+	if err := fs.WriteFile(path, data, 0600); err != nil {
+		return fmt.Errorf("failed to write sync state: %w", err)
+	}
+	return nil
+}
+
+// BaseHashes は指定ディレクトリ・プロジェクトの前回同期時のハッシュを返します。
+// 記録がない場合や別プロジェクトとして記録されている場合は nil を返します。
+func (s *SyncState) BaseHashes(dir, projectName string) map[string]string {
+	entry, ok := s.Dirs[filepath.Clean(dir)]
+	if !ok || entry.Project != projectName {
+		return nil
+	}
+	return entry.Files
+}
+
+// ExpandedFiles は指定ディレクトリ・プロジェクトで参照を展開して pull したファイル名の集合を返します。
+// 記録がない場合や別プロジェクトとして記録されている場合は nil を返します。
+func (s *SyncState) ExpandedFiles(dir, projectName string) map[string]bool {
+	entry, ok := s.Dirs[filepath.Clean(dir)]
+	if !ok || entry.Project != projectName {
+		return nil
+	}
+	expanded := make(map[string]bool, len(entry.Expanded))
+	for _, name := range entry.Expanded {
+		expanded[name] = true
+	}
+	return expanded
+}
+
+// recordSyncState は files の内容ハッシュを同期状態に記録します。
+// 記録済みの他ファイルのハッシュは維持されます。
+// expanded が true の場合、files を参照展開済みとして記録し、false の場合は展開済みの記録を外します。
+func recordSyncState(fs FileSystem, statePath, dir, projectName string, files MultiEnvData, expanded bool) error {
+	syncStateMu.Lock()
+	defer syncStateMu.Unlock()
+
+	state, err := LoadSyncState(fs, statePath)
+	if err != nil {
+		return err
+	}
+
+	key := filepath.Clean(dir)
+	entry, ok := state.Dirs[key]
+	if !ok || entry.Project != projectName {
+		entry = DirSyncState{Project: projectName, Files: make(map[string]string)}
+	}
+	if entry.Files == nil {
+		entry.Files = make(map[string]string)
+	}
+	var keep []string
+	for _, fileName := range entry.Expanded {
+		if _, ok := files[fileName]; !ok {
+			keep = append(keep, fileName)
+		}
+	}
+	for fileName, envData := range files {
+		entry.Files[fileName] = hashEnvData(envData)
+		if expanded {
+			keep = append(keep, fileName)
+		}
+	}
+	sortFileNames(keep)
+	entry.Expanded = keep
+	state.Dirs[key] = entry
+
+	return SaveSyncState(fs, statePath, state)
+}
+
+// checkExpandedFiles は push 対象に参照展開済みのファイルが含まれていればエラーを返します。
+// 展開済みのファイルを push すると、参照ではなく解決済みのシークレットが保存されてしまうためです。
+func checkExpandedFiles(fs FileSystem, statePath, dir, projectName string, fileNames []string) error {
+	syncStateMu.Lock()
+	defer syncStateMu.Unlock()
+
+	state, err := LoadSyncState(fs, statePath)
+	if err != nil {
+		return err
+	}
+	entry, ok := state.Dirs[filepath.Clean(dir)]
+	if !ok || entry.Project != projectName {
+		return nil
+	}
+
+	var expanded []string
+	for _, name := range fileNames {
+		for _, e := range entry.Expanded {
+			if e == name {
+				expanded = append(expanded, name)
+			}
+		}
+	}
+	if len(expanded) > 0 {
+		return fmt.Errorf("%w: %s pulled with --interpolate contain resolved values; run `bwsf pull` without --interpolate first", ErrExpandedPush, strings.Join(expanded, ", "))
+	}
+	return nil
+}
+
+// hashEnvData は EnvData の内容ハッシュを返します。
+// ローカルファイルは parseEnvContent を通してから比較するため、末尾改行の有無は無視されます。
+func hashEnvData(data EnvData) string {
+	sum := sha256.Sum256([]byte(restoreEnvContentFromData(data)))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,140 @@
+package core
+
+import (
+	"fmt"
+	"path/filepath"
+
+	"bwsf/src/config"
+)
+
+// SyncStatus はローカルファイルと保管庫の同期状態を表します。
+type SyncStatus string
+
+const (
+	// StatusInSync はローカルと保管庫の内容が一致している状態です。
+	StatusInSync SyncStatus = "in sync"
+	// StatusLocalOnly はローカルにのみ存在する状態です。
+	StatusLocalOnly SyncStatus = "local only"
+	// StatusRemoteOnly は保管庫にのみ存在する状態です。
+	StatusRemoteOnly SyncStatus = "remote only"
+	// StatusLocalModified は前回の同期以降にローカルのみ変更された状態です。
+	StatusLocalModified SyncStatus = "locally modified"
+	// StatusRemoteModified は前回の同期以降に保管庫のみ変更された状態です。
+	StatusRemoteModified SyncStatus = "remotely modified"
+	// StatusBothModified は両方が変更された状態です。前回の同期記録がない場合もこの状態になります。
+	StatusBothModified SyncStatus = "both modified"
+	// StatusExpanded は参照を展開して pull したファイルで、前回の同期以降に保管庫が変わっていない状態です。
+	// ローカルのファイルは解決済みの値を含むため、保管庫の内容とは比べられません。
+	StatusExpanded SyncStatus = "expanded"
+)
+
+// EnvFileStatus は 1 ファイル分の同期状態です。
+type EnvFileStatus struct {
+	FileName string
+	Status   SyncStatus
+}
+
+// StatusEnvCore はローカルの .env* ファイルと保管庫に保存されたファイルを比較し、
+// ファイルごとの同期状態を返します。
+// 変更元の判定には statePath に記録された前回 push/pull 時のハッシュを使います。
+func StatusEnvCore(
+	dir, projectName, statePath string,
+	fs FileSystem,
+	bw BwClient,
+	cfg *config.Config,
+	promptPassword func() (string, error),
+	logger Logger,
+) ([]EnvFileStatus, error) {
+	// ローカルファイルを読み込み
+	envFiles, err := findEnvFilesFromFS(fs, dir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find .env files: %w", err)
+	}
+	local := make(MultiEnvData)
+	for _, envPath := range envFiles {
+		content, err := fs.ReadFile(envPath)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read %s: %w", envPath, err)
+		}
+		// pull 時に追加した継承ブロックは比較対象外
+		local[filepath.Base(envPath)] = stripInheritedBlocks(*parseEnvContent(content))
+	}
+
+	// 保管庫のファイルを取得
+	_, item, err := fetchProjectItem(projectName, bw, cfg, promptPassword, logger)
+	if err != nil {
+		return nil, err
+	}
+	remote := make(MultiEnvData)
+	if item != nil {
+		remote, err = decodeStoredNotes(item.Notes)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	// 前回同期時のハッシュと参照を展開したファイル
+	var base map[string]string
+	var expanded map[string]bool
+	if statePath != "" {
+		state, err := LoadSyncState(fs, statePath)
+		if err != nil {
+			return nil, err
+		}
+		base = state.BaseHashes(dir, projectName)
+		expanded = state.ExpandedFiles(dir, projectName)
+	}
+
+	return compareEnvFiles(local, remote, base, expanded), nil
+}
+
+// compareEnvFiles はローカル・保管庫・前回同期時のハッシュからファイルごとの状態を判定します。
+// expanded のファイルはローカルの内容を比べられないため、保管庫が変わっていなければ StatusExpanded とします。
+func compareEnvFiles(local, remote MultiEnvData, base map[string]string, expanded map[string]bool) []EnvFileStatus {
+	var names []string
+	seen := make(map[string]bool)
+	for name := range local {
+		seen[name] = true
+		names = append(names, name)
+	}
+	for name := range remote {
+		if !seen[name] {
+			names = append(names, name)
+		}
+	}
+	sortFileNames(names)
+
+	result := make([]EnvFileStatus, 0, len(names))
+	for _, name := range names {
+		localData, hasLocal := local[name]
+		remoteData, hasRemote := remote[name]
+
+		var status SyncStatus
+		switch {
+		case hasLocal && !hasRemote:
+			status = StatusLocalOnly
+		case !hasLocal && hasRemote:
+			status = StatusRemoteOnly
+		default:
+			localHash := hashEnvData(localData)
+			remoteHash := hashEnvData(remoteData)
+			baseHash, hasBase := base[name]
+			switch {
+			case expanded[name] && hasBase && baseHash != remoteHash:
+				status = StatusRemoteModified
+			case expanded[name]:
+				status = StatusExpanded
+			case localHash == remoteHash:
+				status = StatusInSync
+			case hasBase && baseHash == remoteHash:
+				status = StatusLocalModified
+			case hasBase && baseHash == localHash:
+				status = StatusRemoteModified
+			default:
+				status = StatusBothModified
+			}
+		}
+		result = append(result, EnvFileStatus{FileName: name, Status: status})
+	}
+	return result
+}
@@ -0,0 +1,215 @@
+package core
+
+import (
+	"encoding/json"
+	"testing"
+
+	"bwsf/src/config"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// =============================================================================
+// compareEnvFiles のテスト
+// =============================================================================
+
+// 正常系: 各状態が正しく判定される
+func TestCompareEnvFiles_AllStates(t *testing.T) {
+	local := MultiEnvData{
+		".env":            {Lines: []string{"A=1"}},
+		".env.local":      {Lines: []string{"L=1"}},
+		".env.staging":    {Lines: []string{"S=local"}},
+		".env.production": {Lines: []string{"P=1"}},
+		".env.test":       {Lines: []string{"T=local"}},
+	}
+	remote := MultiEnvData{
+		".env":            {Lines: []string{"A=1"}},
+		".env.ci":         {Lines: []string{"C=1"}},
+		".env.staging":    {Lines: []string{"S=base"}},
+		".env.production": {Lines: []string{"P=2"}},
+		".env.test":       {Lines: []string{"T=remote"}},
+	}
+	base := map[string]string{
+		".env.staging":    hashEnvData(EnvData{Lines: []string{"S=base"}}),
+		".env.production": hashEnvData(EnvData{Lines: []string{"P=1"}}),
+		".env.test":       hashEnvData(EnvData{Lines: []string{"T=base"}}),
+	}
+
+	result := compareEnvFiles(local, remote, base, nil)
+
+	statuses := make(map[string]SyncStatus)
+	for _, r := range result {
+		statuses[r.FileName] = r.Status
+	}
+	assert.Equal(t, StatusInSync, statuses[".env"])
+	assert.Equal(t, StatusLocalOnly, statuses[".env.local"])
+	assert.Equal(t, StatusRemoteOnly, statuses[".env.ci"])
+	assert.Equal(t, StatusLocalModified, statuses[".env.staging"])
+	assert.Equal(t, StatusRemoteModified, statuses[".env.production"])
+	assert.Equal(t, StatusBothModified, statuses[".env.test"])
+
+	// .env が先頭
+	assert.Equal(t, ".env", result[0].FileName)
+}
+
+// 正常系: 前回の同期記録がなく内容が異なる場合は both modified
+func TestCompareEnvFiles_NoBase(t *testing.T) {
+	local := MultiEnvData{".env": {Lines: []string{"A=1"}}}
+	remote := MultiEnvData{".env": {Lines: []string{"A=2"}}}
+
+	result := compareEnvFiles(local, remote, nil, nil)
+
+	require.Len(t, result, 1)
+	assert.Equal(t, StatusBothModified, result[0].Status)
+}
+
+// =============================================================================
+// StatusEnvCore のテスト
+// =============================================================================
+
+// 正常系: 保管庫にアイテムがない場合はすべて local only
+func TestStatusEnvCore_ItemNotFound(t *testing.T) {
+	bw := &mockBwClient{folderID: "folder-123"}
+	fs := &mockFileSystem{
+		dirEntries: []DirEntry{
+			&mockDirEntry{name: ".env", isDir: false},
+		},
+		readContentMap: map[string][]byte{
+			".env": []byte("KEY=value\n"),
+		},
+	}
+
+	result, err := StatusEnvCore(".", "my-project", "", fs, bw, &config.Config{}, func() (string, error) { return "pwd", nil }, &mockLogger{})
+
+	assert.NoError(t, err)
+	require.Len(t, result, 1)
+	assert.Equal(t, StatusLocalOnly, result[0].Status)
+}
+
+// 正常系: 同期状態ファイルを使って locally modified を判定できる
+func TestStatusEnvCore_WithState(t *testing.T) {
+	remote := EnvData{Lines: []string{"KEY=old"}}
+	state := SyncState{Dirs: map[string]DirSyncState{
+		"/work/app": {Project: "my-project", Files: map[string]string{".env": hashEnvData(remote)}},
+	}}
+	stateJSON, _ := json.Marshal(state)
+
+	bw := &mockBwClient{
+		folderID:   "folder-123",
+		itemByName: &FullItem{ID: "item-456", Name: "my-project", Notes: `{".env":{"lines":["KEY=old"]}}`},
+	}
+	fs := &mockFileSystem{
+		dirEntries: []DirEntry{
+			&mockDirEntry{name: ".env", isDir: false},
+		},
+		readContentMap: map[string][]byte{
+			"/work/app/.env":    []byte("KEY=new\n"),
+			"/state/state.json": stateJSON,
+		},
+		statInfoMap: map[string]FileInfo{
+			"/state/state.json": &mockFileInfo{notExist: false},
+		},
+	}
+
+	result, err := StatusEnvCore("/work/app", "my-project", "/state/state.json", fs, bw, &config.Config{}, func() (string, error) { return "pwd", nil }, &mockLogger{})
+
+	assert.NoError(t, err)
+	require.Len(t, result, 1)
+	assert.Equal(t, StatusLocalModified, result[0].Status)
+}
+
+// 正常系: --interpolate で pull したファイルは locally modified ではなく expanded と判定する
+func TestStatusEnvCore_InterpolatedPull(t *testing.T) {
+	bw := &mockBwClient{
+		folderID:   "folder-123",
+		itemByName: &FullItem{ID: "item-456", Name: "my-project", Notes: `{".env":{"lines":["HOST=db","URL=postgres://${HOST}"]},".env.test":{"lines":["T=1"]}}`},
+	}
+	pulled := &mockFileSystem{}
+	require.NoError(t, PullEnvCoreWithOptions("/work/app", "my-project", pulled, bw, &config.Config{}, nil, noConfirm, &mockLogger{},
+		PullOptions{StatePath: "/state/state.json", Interpolate: true, Files: []string{".env"}}))
+	require.Contains(t, string(pulled.writtenFiles["/work/app/.env"]), "URL=postgres://db")
+
+	fs := &mockFileSystem{
+		dirEntries: []DirEntry{&mockDirEntry{name: ".env"}, &mockDirEntry{name: ".env.test"}},
+		readContentMap: map[string][]byte{
+			"/work/app/.env":      pulled.writtenFiles["/work/app/.env"],
+			"/work/app/.env.test": []byte("T=1\n"),
+			"/state/state.json":   pulled.writtenFiles["/state/state.json"],
+		},
+		statInfoMap: map[string]FileInfo{"/state/state.json": &mockFileInfo{}},
+	}
+
+	result, err := StatusEnvCore("/work/app", "my-project", "/state/state.json", fs, bw, &config.Config{}, nil, &mockLogger{})
+	require.NoError(t, err)
+	assert.Equal(t, []EnvFileStatus{{FileName: ".env", Status: StatusExpanded}, {FileName: ".env.test", Status: StatusInSync}}, result)
+
+	// 保管庫が変わった場合は remotely modified
+	bw.itemByName.Notes = `{".env":{"lines":["HOST=db2","URL=postgres://${HOST}"]},".env.test":{"lines":["T=1"]}}`
+	result, err = StatusEnvCore("/work/app", "my-project", "/state/state.json", fs, bw, &config.Config{}, nil, &mockLogger{})
+	require.NoError(t, err)
+	assert.Equal(t, StatusRemoteModified, result[0].Status)
+}
+
+// 異常系: フォルダ取得エラーが伝播する
+func TestStatusEnvCore_GetFolderIDError(t *testing.T) {
+	bw := &mockBwClient{folderIDErr: assert.AnError}
+	fs := &mockFileSystem{}
+
+	_, err := StatusEnvCore(".", "my-project", "", fs, bw, &config.Config{}, func() (string, error) { return "pwd", nil }, &mockLogger{})
+
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "failed to get dotenvs folder")
+}
+
+// =============================================================================
+// 同期状態の記録のテスト
+// =============================================================================
+
+// 正常系: StatePath を指定した push でハッシュが記録される
+func TestPushEnvCoreWithOptions_RecordsState(t *testing.T) {
+	bw := &mockBwClient{folderID: "folder-123"}
+	fs := &mockFileSystem{
+		dirEntries: []DirEntry{
+			&mockDirEntry{name: ".env", isDir: false},
+		},
+		readContentMap: map[string][]byte{
+			"/work/app/.env": []byte("KEY=value\n"),
+		},
+	}
+
+	err := PushEnvCoreWithOptions("/work/app", "my-project", fs, bw, &config.Config{}, func() (string, error) { return "pwd", nil }, &mockLogger{},
+		PushOptions{StatePath: "/state/state.json"})
+
+	require.NoError(t, err)
+	var state SyncState
+	require.NoError(t, json.Unmarshal(fs.writtenFiles["/state/state.json"], &state))
+	assert.Equal(t, "my-project", state.Dirs["/work/app"].Project)
+	assert.Equal(t, hashEnvData(EnvData{Lines: []string{"KEY=value"}}), state.Dirs["/work/app"].Files[".env"])
+}
+
+// 正常系: pull で上書きをスキップしたファイルは記録されない
+func TestPullEnvCoreWithOptions_RecordsOnlyWrittenFiles(t *testing.T) {
+	bw := &mockBwClient{
+		folderID:   "folder-123",
+		itemByName: &FullItem{ID: "item-456", Name: "my-project", Notes: `{".env":{"lines":["A=1"]},".env.staging":{"lines":["B=1"]}}`},
+	}
+	fs := &mockFileSystem{
+		statInfoMap: map[string]FileInfo{
+			"/work/app/.env": &mockFileInfo{notExist: false},
+		},
+	}
+
+	err := PullEnvCoreWithOptions("/work/app", "my-project", fs, bw, &config.Config{},
+		func() (string, error) { return "pwd", nil },
+		func(path string) (bool, error) { return false, nil },
+		&mockLogger{},
+		PullOptions{StatePath: "/state/state.json"})
+
+	require.NoError(t, err)
+	var state SyncState
+	require.NoError(t, json.Unmarshal(fs.writtenFiles["/state/state.json"], &state))
+	files := state.Dirs["/work/app"].Files
+	assert.NotContains(t, files, ".env")
+	assert.Contains(t, files, ".env.staging")
+}
@@ -0,0 +1,50 @@
+package core
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// =============================================================================
+// generateTOTP のテスト
+// =============================================================================
+
+// 正常系: RFC 6238 のテストベクター（SHA1, 8 桁）
+func TestGenerateTOTP_RFC6238(t *testing.T) {
+	uri := "otpauth://totp/test?secret=GEZDGNBVGY3TQOJQGEZDGNBVGY3TQOJQ&digits=8"
+	tests := []struct {
+		unix int64
+		want string
+	}{
+		{59, "94287082"},
+		{1111111109, "07081804"},
+		{1234567890, "89005924"},
+		{2000000000, "69279037"},
+	}
+
+	for _, tt := range tests {
+		code, err := generateTOTP(uri, time.Unix(tt.unix, 0))
+		require.NoError(t, err)
+		assert.Equal(t, tt.want, code, "t=%d", tt.unix)
+	}
+}
+
+// 正常系: 小文字・空白入りの base32 シークレット
+func TestGenerateTOTP_PlainSecret(t *testing.T) {
+	code, err := generateTOTP("gezd gnbv gy3t qojq gezd gnbv gy3t qojq", time.Unix(59, 0))
+
+	require.NoError(t, err)
+	assert.Equal(t, "287082", code)
+}
+
+// 異常系: 不正なシークレット・アルゴリズム
+func TestGenerateTOTP_Invalid(t *testing.T) {
+	_, err := generateTOTP("not base32!", time.Now())
+	assert.Error(t, err)
+
+	_, err = generateTOTP("otpauth://totp/x?secret=GEZDGNBV&algorithm=MD5", time.Now())
+	assert.Error(t, err)
+}
@@ -0,0 +1,241 @@
+package core
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"bwsf/src/config"
+)
+
+// DirWatcher はディレクトリ内のファイルの変更を通知するインターフェースです。
+type DirWatcher interface {
+	// Events は作成・変更・削除されたファイルの名前（ディレクトリ内のファイル名）を送ります。
+	Events() <-chan string
+	// Errors は監視を続けられないエラーを送ります。
+	Errors() <-chan error
+	// Close は監視を止めます。
+	Close() error
+}
+
+// DefaultWatchDebounce は最後の変更から push するまで待つ時間の既定値です。
+const DefaultWatchDebounce = 500 * time.Millisecond
+
+// WatchOptions は WatchEnvCore の設定です。
+type WatchOptions struct {
+	// Debounce は最後の変更から処理を始めるまで待つ時間です。0 の場合は DefaultWatchDebounce です。
+	Debounce time.Duration
+	// Auto が true の場合は確認せずに push します。
+	Auto bool
+	// Confirm は変更の一覧を示して push してよいか確認します。Auto が false の場合に使います。
+	Confirm func(summary []string) (bool, error)
+	// Push は push の設定です。SkipLint が false の場合、lint エラーのある変更は push しません。
+	Push PushOptions
+	// After は時間の経過を待つ関数です。nil の場合は time.After を使います（テスト用）。
+	After func(time.Duration) <-chan time.Time
+}
+
+// WatchEnvCore は dir の .env* ファイルを監視し、変更が落ち着いたら保管庫との差分を確認して push します。
+// lint エラーがある場合や、前回の同期以降に保管庫側が変わっている場合は push しません。
+// 1 回の push の失敗（ロック解除の取り消しなど）では監視を止めません。ctx が終了すると nil を返します。
+func WatchEnvCore(
+	ctx context.Context,
+	dir, projectName string,
+	watcher DirWatcher,
+	fs FileSystem,
+	bw BwClient,
+	cfg *config.Config,
+	promptPassword func() (string, error),
+	logger Logger,
+	opts WatchOptions,
+) error {
+	after := opts.After
+	if after == nil {
+		after = time.After
+	}
+	debounce := opts.Debounce
+	if debounce <= 0 {
+		debounce = DefaultWatchDebounce
+	}
+	projectCfg, err := LoadProjectConfig(fs, dir)
+	if err != nil {
+		return err
+	}
+	patterns := envFilePatterns(projectCfg)
+
+	pending := make(map[string]bool)
+	var settled <-chan time.Time
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case err, ok := <-watcher.Errors():
+			if !ok {
+				return nil
+			}
+			return fmt.Errorf("failed to watch %s: %w", dir, err)
+		case name, ok := <-watcher.Events():
+			if !ok {
+				return nil
+			}
+			if !matchEnvFile(name, patterns) {
+				continue
+			}
+			// 書き込みが続く間は待ち直す
+			pending[name] = true
+			settled = after(debounce)
+		case <-settled:
+			settled = nil
+			var names []string
+			for name := range pending {
+				names = append(names, name)
+			}
+			sortFileNames(names)
+			pending = make(map[string]bool)
+
+			logger.Info("Changed: ", strings.Join(names, ", "))
+			if err := watchPush(dir, projectName, fs, bw, cfg, promptPassword, logger, opts); err != nil {
+				logger.Error(err.Error())
+			}
+		}
+	}
+}
+
+// watchPush はローカルのファイルを lint し、保管庫との差分を確認してから push します。
+func watchPush(
+	dir, projectName string,
+	fs FileSystem,
+	bw BwClient,
+	cfg *config.Config,
+	promptPassword func() (string, error),
+	logger Logger,
+	opts WatchOptions,
+) error {
+	envFiles, err := findEnvFilesFromFS(fs, dir)
+	if err != nil {
+		return fmt.Errorf("failed to find .env files: %w", err)
+	}
+	if len(envFiles) == 0 {
+		logger.Warning("No .env files left in ", dir, "; nothing to push")
+		return nil
+	}
+	local := make(MultiEnvData)
+	for _, envPath := range envFiles {
+		content, err := fs.ReadFile(envPath)
+		if err != nil {
+			return fmt.Errorf("failed to read %s: %w", envPath, err)
+		}
+		local[filepath.Base(envPath)] = stripInheritedBlocks(*parseEnvContent(content))
+	}
+
+	projectCfg, err := LoadProjectConfig(fs, dir)
+	if err != nil {
+		return err
+	}
+	if !opts.Push.SkipLint {
+		if err := reportLintIssues(lintMultiEnvData(local, projectCfg.Lint), logger); err != nil {
+			logger.Warning("Not pushing: ", err.Error(), "; fix it and save again")
+			return nil
+		}
+	}
+
+	// 保管庫の内容（前回 push した状態）と比べる
+	_, item, err := fetchProjectItem(projectName, bw, cfg, promptPassword, logger)
+	if err != nil {
+		return err
+	}
+	remote := make(MultiEnvData)
+	if item != nil {
+		if remote, err = decodeStoredNotes(item.Notes); err != nil {
+			return err
+		}
+	}
+
+	// push は保管庫の内容を置き換えるため、他の場所からの変更があれば上書きしない
+	var base map[string]string
+	var expanded map[string]bool
+	if opts.Push.StatePath != "" {
+		state, err := LoadSyncState(fs, opts.Push.StatePath)
+		if err != nil {
+			return err
+		}
+		base = state.BaseHashes(dir, projectName)
+		expanded = state.ExpandedFiles(dir, projectName)
+	}
+	var drifted, interpolated []string
+	for _, s := range compareEnvFiles(local, remote, base, expanded) {
+		switch s.Status {
+		case StatusRemoteModified, StatusRemoteOnly, StatusBothModified:
+			drifted = append(drifted, s.FileName+" ("+string(s.Status)+")")
+		case StatusExpanded:
+			interpolated = append(interpolated, s.FileName)
+		}
+	}
+	// 展開済みのファイルは解決済みの値を含むため push しない（ローカルの変更とはみなさない）
+	if len(interpolated) > 0 {
+		logger.Warning("Not pushing: ", strings.Join(interpolated, ", "),
+			" pulled with --interpolate contain resolved values. Run `bwsf pull` without --interpolate first")
+		return nil
+	}
+	if len(drifted) > 0 {
+		logger.Warning("Not pushing: the vault changed since the last sync: ", strings.Join(drifted, ", "),
+			". Run `bwsf status` and `bwsf pull`, then save again")
+		return nil
+	}
+
+	summary := watchSummary(diffKeys(remote, local))
+	if len(summary) == 0 {
+		logger.Info("No changes from the vault")
+		return nil
+	}
+	for _, line := range summary {
+		logger.Info("  ", line)
+	}
+
+	if !opts.Auto {
+		ok, err := opts.Confirm(summary)
+		if err != nil {
+			return err
+		}
+		if !ok {
+			logger.Info("Not pushed; the changes stay local until the next save")
+			return nil
+		}
+	}
+
+	pushOpts := opts.Push
+	pushOpts.SkipLint = true
+	if err := PushEnvCoreWithOptions(dir, projectName, fs, bw, cfg, promptPassword, logger, pushOpts); err != nil {
+		return fmt.Errorf("push failed: %w", err)
+	}
+	logger.Info("Pushed ", len(local), " env file(s) to ", projectName)
+	return nil
+}
+
+// watchSummary は変更をファイルごとに "file: +added ~changed -removed" の形式で返します（キー名のみ）。
+func watchSummary(changes map[string]KeyChanges) []string {
+	var names []string
+	for name := range changes {
+		names = append(names, name)
+	}
+	sortFileNames(names)
+
+	var lines []string
+	for _, name := range names {
+		c := changes[name]
+		var parts []string
+		for _, key := range c.Added {
+			parts = append(parts, "+"+key)
+		}
+		for _, key := range c.Changed {
+			parts = append(parts, "~"+key)
+		}
+		for _, key := range c.Removed {
+			parts = append(parts, "-"+key)
+		}
+		lines = append(lines, name+": "+strings.Join(parts, " "))
+	}
+	return lines
+}
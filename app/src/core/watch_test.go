@@ -0,0 +1,232 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"bwsf/src/config"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeWatcher は DirWatcher インターフェースのモック実装です。
+type fakeWatcher struct {
+	events chan string
+	errors chan error
+}
+
+func newFakeWatcher() *fakeWatcher {
+	return &fakeWatcher{events: make(chan string), errors: make(chan error, 1)}
+}
+
+func (w *fakeWatcher) Events() <-chan string { return w.events }
+func (w *fakeWatcher) Errors() <-chan error  { return w.errors }
+func (w *fakeWatcher) Close() error          { return nil }
+
+// fakeClock は After の呼び出しを記録し、tick を送るまで待ち時間を経過させません。
+type fakeClock struct {
+	tick  chan time.Time
+	waits []time.Duration
+}
+
+func newFakeClock() *fakeClock {
+	return &fakeClock{tick: make(chan time.Time)}
+}
+
+func (c *fakeClock) After(d time.Duration) <-chan time.Time {
+	c.waits = append(c.waits, d)
+	return c.tick
+}
+
+// lockingBwClient は locked の間 GetDotenvsFolderID がロックエラーを返し、Unlock で解除されるモックです。
+type lockingBwClient struct {
+	*mockBwClient
+	locked bool
+}
+
+func (b *lockingBwClient) GetDotenvsFolderID() (string, error) {
+	if b.locked {
+		return "", ErrBitwardenLocked
+	}
+	return b.mockBwClient.GetDotenvsFolderID()
+}
+
+func (b *lockingBwClient) Unlock(password string) error {
+	b.locked = false
+	return b.mockBwClient.Unlock(password)
+}
+
+// watchFixture は /work/web に .env と .env.example があり、保管庫と同期状態に A=1 が記録された状態を作ります。
+func watchFixture(t *testing.T, local string) (*mockFileSystem, *mockBwClient) {
+	t.Helper()
+	stored := MultiEnvData{".env": {Lines: []string{"A=1"}}}
+	scratch := &mockFileSystem{}
+	require.NoError(t, recordSyncState(scratch, "/state.json", "/work/web", "web", stored, false))
+
+	fs := &mockFileSystem{
+		dirEntries:     []DirEntry{&mockDirEntry{name: ".env"}, &mockDirEntry{name: ".env.example"}},
+		readContentMap: map[string][]byte{"/work/web/.env": []byte(local), "/state.json": scratch.writtenFiles["/state.json"]},
+		statInfoMap:    map[string]FileInfo{"/state.json": &mockFileInfo{}},
+	}
+	return fs, newTestBwClient(t, stored)
+}
+
+// runWatch は WatchEnvCore を起動し、drive の後に停止して結果を返します。
+func runWatch(t *testing.T, fs FileSystem, bw BwClient, prompt func() (string, error), logger *mockLogger, opts WatchOptions, drive func(w *fakeWatcher, clock *fakeClock)) *fakeClock {
+	t.Helper()
+	watcher := newFakeWatcher()
+	clock := newFakeClock()
+	opts.After = clock.After
+	ctx, cancel := context.WithCancel(context.Background())
+	result := make(chan error, 1)
+	go func() {
+		result <- WatchEnvCore(ctx, "/work/web", "web", watcher, fs, bw, &config.Config{}, prompt, logger, opts)
+	}()
+
+	drive(watcher, clock)
+	cancel()
+	select {
+	case err := <-result:
+		require.NoError(t, err)
+	case <-time.After(5 * time.Second):
+		t.Fatal("WatchEnvCore did not stop")
+	}
+	return clock
+}
+
+// 正常系: 連続した書き込みは 1 回にまとめ、変更したキー名を示して自動で push する
+func TestWatchEnvCore_AutoPush(t *testing.T) {
+	fs, bw := watchFixture(t, "A=2\nB=3\n")
+	logger := &mockLogger{}
+
+	clock := runWatch(t, fs, bw, nil, logger, WatchOptions{Auto: true, Debounce: time.Second, Push: PushOptions{StatePath: "/state.json"}},
+		func(w *fakeWatcher, clock *fakeClock) {
+			w.events <- ".env"
+			w.events <- ".env.example"
+			w.events <- "notes.txt"
+			w.events <- ".env"
+			clock.tick <- time.Now()
+		})
+
+	assert.Equal(t, []time.Duration{time.Second, time.Second}, clock.waits)
+	assert.Contains(t, bw.calls, "UpdateNoteItem(item-1)")
+	assert.Contains(t, bw.updatedNotes["item-1"], "B=3")
+	assert.Contains(t, logger.infos, "Changed: .env")
+	assert.Contains(t, logger.infos, "  .env: +B ~A")
+	assert.Contains(t, logger.infos, "Pushed 1 env file(s) to web")
+}
+
+// 正常系: 確認で y 以外なら push しない
+func TestWatchEnvCore_ConfirmDeclined(t *testing.T) {
+	fs, bw := watchFixture(t, "A=2\n")
+	var shown []string
+	confirm := func(summary []string) (bool, error) {
+		shown = summary
+		return false, nil
+	}
+
+	runWatch(t, fs, bw, nil, &mockLogger{}, WatchOptions{Confirm: confirm, Push: PushOptions{StatePath: "/state.json"}},
+		func(w *fakeWatcher, clock *fakeClock) {
+			w.events <- ".env"
+			clock.tick <- time.Now()
+		})
+
+	assert.Equal(t, []string{".env: ~A"}, shown)
+	assert.NotContains(t, bw.calls, "UpdateNoteItem(item-1)")
+}
+
+// 正常系: lint エラーがある場合は push せず、監視を続ける
+func TestWatchEnvCore_LintError(t *testing.T) {
+	fs, bw := watchFixture(t, "A=2\nA=3\n")
+	logger := &mockLogger{}
+
+	runWatch(t, fs, bw, nil, logger, WatchOptions{Auto: true, Push: PushOptions{StatePath: "/state.json"}},
+		func(w *fakeWatcher, clock *fakeClock) {
+			w.events <- ".env"
+			clock.tick <- time.Now()
+		})
+
+	checkWarning(t, logger, "Not pushing")
+	assert.Empty(t, bw.calls, "the vault is not read when lint fails")
+}
+
+// 正常系: 前回の同期以降に保管庫が変わっていれば上書きしない
+func TestWatchEnvCore_VaultDrift(t *testing.T) {
+	fs, bw := watchFixture(t, "A=2\n")
+	bw.itemByName.Notes = `{".env":{"lines":["A=teammate"]}}`
+	logger := &mockLogger{}
+
+	runWatch(t, fs, bw, nil, logger, WatchOptions{Auto: true, Push: PushOptions{StatePath: "/state.json"}},
+		func(w *fakeWatcher, clock *fakeClock) {
+			w.events <- ".env"
+			clock.tick <- time.Now()
+		})
+
+	checkWarning(t, logger, "the vault changed since the last sync: .env (both modified)")
+	assert.NotContains(t, bw.calls, "UpdateNoteItem(item-1)")
+}
+
+// 正常系: 参照を展開して pull したファイルは保管庫の変更とはみなさず、push もしない
+func TestWatchEnvCore_ExpandedFile(t *testing.T) {
+	fs, bw := watchFixture(t, "A=resolved\n")
+	stored, err := decodeStoredNotes(bw.itemByName.Notes)
+	require.NoError(t, err)
+	scratch := &mockFileSystem{}
+	require.NoError(t, recordSyncState(scratch, "/state.json", "/work/web", "web", stored, true))
+	fs.readContentMap["/state.json"] = scratch.writtenFiles["/state.json"]
+	logger := &mockLogger{}
+
+	runWatch(t, fs, bw, nil, logger, WatchOptions{Auto: true, Push: PushOptions{StatePath: "/state.json"}},
+		func(w *fakeWatcher, clock *fakeClock) {
+			w.events <- ".env"
+			clock.tick <- time.Now()
+		})
+
+	checkWarning(t, logger, "Not pushing: .env pulled with --interpolate contain resolved values")
+	for _, line := range logger.warnings {
+		assert.NotContains(t, line, "the vault changed")
+	}
+	assert.NotContains(t, bw.calls, "UpdateNoteItem(item-1)")
+}
+
+// 正常系: ロック解除を取り消しても監視を続け、次の保存でロックを解除して push する
+func TestWatchEnvCore_SurvivesLock(t *testing.T) {
+	fs, mock := watchFixture(t, "A=2\n")
+	bw := &lockingBwClient{mockBwClient: mock, locked: true}
+	prompts := 0
+	prompt := func() (string, error) {
+		prompts++
+		if prompts == 1 {
+			return "", errors.New("cancelled")
+		}
+		return "master", nil
+	}
+	logger := &mockLogger{}
+
+	runWatch(t, fs, bw, prompt, logger, WatchOptions{Auto: true, Push: PushOptions{StatePath: "/state.json"}},
+		func(w *fakeWatcher, clock *fakeClock) {
+			w.events <- ".env"
+			clock.tick <- time.Now()
+			w.events <- ".env"
+			clock.tick <- time.Now()
+		})
+
+	assert.Equal(t, 2, prompts)
+	require.NotEmpty(t, logger.errors)
+	assert.Contains(t, logger.errors[0], "cancelled")
+	assert.Contains(t, mock.calls, "Unlock")
+	assert.Contains(t, mock.calls, "UpdateNoteItem(item-1)")
+}
+
+// 異常系: 監視のエラーで終了する
+func TestWatchEnvCore_WatcherError(t *testing.T) {
+	watcher := newFakeWatcher()
+	watcher.errors <- errors.New("the directory was removed")
+
+	err := WatchEnvCore(context.Background(), "/work/web", "web", watcher, &mockFileSystem{}, &mockBwClient{}, &config.Config{}, nil, &mockLogger{}, WatchOptions{})
+
+	assert.ErrorContains(t, err, "failed to watch /work/web: the directory was removed")
+}
@@ -0,0 +1,384 @@
+package core
+
+import (
+	"errors"
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+
+	"bwsf/src/config"
+)
+
+// DefaultWorkspaceWorkers はワークスペース処理の既定の並列数です。
+const DefaultWorkspaceWorkers = 4
+
+// workspaceMaxDepth はプロジェクトを探すディレクトリの深さの上限です（ルート直下が 1）。
+const workspaceMaxDepth = 3
+
+// workspaceSkipDirs は探索しないディレクトリ名です。隠しディレクトリも探索しません。
+var workspaceSkipDirs = map[string]bool{
+	"node_modules": true,
+	"vendor":       true,
+}
+
+// ErrWorkspaceLocked はワークスペース処理の途中で保管庫がロックされた場合に返されます。
+// 並列に動くプロジェクトごとにパスワードを尋ねないよう、途中のアンロックは行いません。
+var ErrWorkspaceLocked = errors.New("vault was locked during the workspace run")
+
+// WorkspaceState はワークスペース内の 1 プロジェクトの処理状態です。
+type WorkspaceState string
+
+const (
+	WorkspacePending WorkspaceState = "pending"
+	WorkspaceRunning WorkspaceState = "running"
+	WorkspaceDone    WorkspaceState = "done"
+	WorkspaceSkipped WorkspaceState = "skipped"
+	WorkspaceFailed  WorkspaceState = "failed"
+)
+
+// WorkspaceProject はワークスペース内で見つかったプロジェクトです。
+type WorkspaceProject struct {
+	Dir  string
+	Name string // ディレクトリ名（通常の pull/push と同じくプロジェクト名として使う）
+}
+
+// WorkspaceResult は 1 プロジェクトの処理結果です。
+type WorkspaceResult struct {
+	Project WorkspaceProject
+	State   WorkspaceState
+	Message string   // 結果の要約、またはスキップ・失敗の理由
+	Err     error    // State が WorkspaceFailed の場合のエラー
+	Logs    []string // 処理中に出力されたログ（並列処理で出力が混ざらないよう保持する）
+}
+
+// WorkspaceOptions はワークスペース処理の設定です。
+type WorkspaceOptions struct {
+	// Workers は同時に処理するプロジェクト数です。0 以下の場合は DefaultWorkspaceWorkers を使います。
+	Workers int
+	// Pull / Push は各プロジェクトの処理に渡すオプションです。
+	Pull PullOptions
+	Push PushOptions
+	// OnUpdate はプロジェクトの状態が変わるたびに呼ばれます。呼び出しは直列化されます。
+	OnUpdate func(index int, result WorkspaceResult)
+}
+
+// DiscoverProjects は root 以下から .env* ファイルまたは .bwsf.json を持つディレクトリを探します。
+// プロジェクトが見つかったディレクトリより下は探索しません。
+func DiscoverProjects(root string, fs FileSystem) ([]WorkspaceProject, error) {
+	var projects []WorkspaceProject
+
+	var walk func(dir string, depth int) error
+	walk = func(dir string, depth int) error {
+		entries, err := fs.ReadDir(dir)
+		if err != nil {
+			return fmt.Errorf("failed to read %s: %w", dir, err)
+		}
+
+		var subdirs []string
+		isProject := false
+		for _, entry := range entries {
+			name := entry.Name()
+			if entry.IsDir() {
+				if !strings.HasPrefix(name, ".") && !workspaceSkipDirs[name] {
+					subdirs = append(subdirs, name)
+				}
+				continue
+			}
+			if name == config.ProjectConfigFile || strings.HasPrefix(name, ".env") {
+				isProject = true
+			}
+		}
+
+		if isProject {
+			projects = append(projects, WorkspaceProject{Dir: dir, Name: filepath.Base(dir)})
+			return nil
+		}
+		if depth >= workspaceMaxDepth {
+			return nil
+		}
+		for _, name := range subdirs {
+			if err := walk(filepath.Join(dir, name), depth+1); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	root = filepath.Clean(root)
+	if err := walk(root, 0); err != nil {
+		return nil, err
+	}
+
+	sort.Slice(projects, func(i, j int) bool { return projects[i].Dir < projects[j].Dir })
+	return projects, nil
+}
+
+// PullWorkspaceCore は複数のプロジェクトを並列に pull します。
+// アンロックとフォルダ一覧の取得は最初に 1 回だけ行い、失敗したプロジェクトがあっても残りの処理を続けます。
+// 上書き確認など対話が必要な処理は confirmOverwrite の結果に従います（並列に呼ばれるため対話しないこと）。
+func PullWorkspaceCore(
+	projects []WorkspaceProject,
+	fs FileSystem,
+	bw BwClient,
+	cfg *config.Config,
+	promptPassword func() (string, error),
+	confirmOverwrite func(path string) (bool, error),
+	logger Logger,
+	opts WorkspaceOptions,
+) ([]WorkspaceResult, error) {
+	return runWorkspace(projects, bw, cfg, promptPassword, logger, opts,
+		func(p WorkspaceProject, shared *workspaceBwClient, log Logger) (WorkspaceState, string, error) {
+			if !shared.hasItem(p.Name) {
+				return WorkspaceSkipped, "not stored in Bitwarden", nil
+			}
+			err := PullEnvCoreWithOptions(p.Dir, p.Name, fs, shared, cfg, workspaceLockedPrompt, confirmOverwrite, log, opts.Pull)
+			if err != nil {
+				return WorkspaceFailed, "", err
+			}
+			return WorkspaceDone, "pulled", nil
+		})
+}
+
+// PushWorkspaceCore は複数のプロジェクトを並列に push します。
+// .env* ファイルの無いプロジェクトはスキップします。
+func PushWorkspaceCore(
+	projects []WorkspaceProject,
+	fs FileSystem,
+	bw BwClient,
+	cfg *config.Config,
+	promptPassword func() (string, error),
+	logger Logger,
+	opts WorkspaceOptions,
+) ([]WorkspaceResult, error) {
+	return runWorkspace(projects, bw, cfg, promptPassword, logger, opts,
+		func(p WorkspaceProject, shared *workspaceBwClient, log Logger) (WorkspaceState, string, error) {
+			envFiles, err := findEnvFilesFromFS(fs, p.Dir)
+			if err != nil {
+				return WorkspaceFailed, "", err
+			}
+			if len(envFiles) == 0 {
+				return WorkspaceSkipped, "no .env files", nil
+			}
+			if err := PushEnvCoreWithOptions(p.Dir, p.Name, fs, shared, cfg, workspaceLockedPrompt, log, opts.Push); err != nil {
+				return WorkspaceFailed, "", err
+			}
+			return WorkspaceDone, fmt.Sprintf("pushed %d file(s)", len(envFiles)), nil
+		})
+}
+
+// workspaceLockedPrompt は処理中にロックされた場合に使うパスワード入力の代わりです。
+func workspaceLockedPrompt() (string, error) {
+	return "", ErrWorkspaceLocked
+}
+
+// runWorkspace はアンロックとフォルダ一覧の取得を 1 回行った後、各プロジェクトに process を並列に適用します。
+func runWorkspace(
+	projects []WorkspaceProject,
+	bw BwClient,
+	cfg *config.Config,
+	promptPassword func() (string, error),
+	logger Logger,
+	opts WorkspaceOptions,
+	process func(p WorkspaceProject, shared *workspaceBwClient, log Logger) (WorkspaceState, string, error),
+) ([]WorkspaceResult, error) {
+	shared := newWorkspaceBwClient(bw)
+	if err := WithUnlockRetry(bw, cfg, promptPassword, logger, shared.prepare); err != nil {
+		return nil, fmt.Errorf("failed to prepare workspace: %w", err)
+	}
+
+	results := make([]WorkspaceResult, len(projects))
+	var mu sync.Mutex
+	update := func(i int, r WorkspaceResult) {
+		mu.Lock()
+		defer mu.Unlock()
+		results[i] = r
+		if opts.OnUpdate != nil {
+			opts.OnUpdate(i, r)
+		}
+	}
+
+	// 同じプロジェクト名のディレクトリが複数ある場合、同じアイテムを取り合うため最初の 1 つ以外はスキップ
+	firstDir := make(map[string]string)
+	var queue []int
+	for i, p := range projects {
+		if dir, ok := firstDir[p.Name]; ok {
+			update(i, WorkspaceResult{Project: p, State: WorkspaceSkipped, Message: "same project name as " + dir})
+			continue
+		}
+		firstDir[p.Name] = p.Dir
+		update(i, WorkspaceResult{Project: p, State: WorkspacePending})
+		queue = append(queue, i)
+	}
+
+	workers := opts.Workers
+	if workers <= 0 {
+		workers = DefaultWorkspaceWorkers
+	}
+
+	jobs := make(chan int)
+	var wg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				p := projects[i]
+				update(i, WorkspaceResult{Project: p, State: WorkspaceRunning})
+
+				log := &bufferedLogger{}
+				state, message, err := process(p, shared, log)
+				result := WorkspaceResult{Project: p, State: state, Message: message, Err: err, Logs: log.lines()}
+				if err != nil {
+					result.Message = err.Error()
+				}
+				update(i, result)
+			}
+		}()
+	}
+	for _, i := range queue {
+		jobs <- i
+	}
+	close(jobs)
+	wg.Wait()
+
+	return results, nil
+}
+
+// SummarizeWorkspace は状態ごとのプロジェクト数を返します。
+func SummarizeWorkspace(results []WorkspaceResult) map[WorkspaceState]int {
+	counts := make(map[WorkspaceState]int)
+	for _, r := range results {
+		counts[r.State]++
+	}
+	return counts
+}
+
+// workspaceBwClient はワークスペース処理中に共有する BwClient です。
+//
+// フォルダ ID とフォルダ内のアイテム一覧は prepare で 1 回だけ取得し、
+// GetItemByName は一覧から ID を引いて GetItemByID で取得します（プロジェクトごとの同期を省く）。
+// bw CLI のローカルデータへの書き込みが競合しないよう、更新系の呼び出しは直列化します。
+type workspaceBwClient struct {
+	BwClient
+
+	writeMu  sync.Mutex
+	folderID string
+	items    map[string]string // name -> ID
+}
+
+func newWorkspaceBwClient(bw BwClient) *workspaceBwClient {
+	return &workspaceBwClient{BwClient: bw}
+}
+
+// prepare はサーバーと同期し、フォルダ ID とアイテム一覧を取得します。
+func (c *workspaceBwClient) prepare() error {
+	if err := c.BwClient.Sync(); err != nil {
+		return err
+	}
+	folderID, err := c.BwClient.GetDotenvsFolderID()
+	if err != nil {
+		return err
+	}
+	items, err := c.BwClient.ListItemsInFolder(folderID)
+	if err != nil {
+		return err
+	}
+
+	c.folderID = folderID
+	c.items = make(map[string]string, len(items))
+	for _, item := range items {
+		if _, ok := c.items[item.Name]; !ok {
+			c.items[item.Name] = item.ID
+		}
+	}
+	return nil
+}
+
+// hasItem は prepare 時点で name のアイテムが存在したかを返します。
+func (c *workspaceBwClient) hasItem(name string) bool {
+	_, ok := c.items[name]
+	return ok
+}
+
+// GetDotenvsFolderID は prepare で取得したフォルダ ID を返します。
+func (c *workspaceBwClient) GetDotenvsFolderID() (string, error) {
+	return c.folderID, nil
+}
+
+// GetItemByName は dotenvs フォルダ内であれば一覧から ID を引いて取得します。
+// それ以外のフォルダ（bw:// 参照など）はそのまま委譲します。
+func (c *workspaceBwClient) GetItemByName(folderID, name string) (*FullItem, error) {
+	if folderID != c.folderID {
+		return c.BwClient.GetItemByName(folderID, name)
+	}
+	id, ok := c.items[name]
+	if !ok {
+		return nil, nil
+	}
+	return c.BwClient.GetItemByID(id)
+}
+
+func (c *workspaceBwClient) CreateNoteItem(folderID, name, notes string) error {
+	c.writeMu.Lock()
+	defer c.writeMu.Unlock()
+	return c.BwClient.CreateNoteItem(folderID, name, notes)
+}
+
+func (c *workspaceBwClient) UpdateNoteItem(id, notes string) error {
+	c.writeMu.Lock()
+	defer c.writeMu.Unlock()
+	return c.BwClient.UpdateNoteItem(id, notes)
+}
+
+func (c *workspaceBwClient) UpdateNoteItemFields(id, notes string, hidden []ItemField) error {
+	c.writeMu.Lock()
+	defer c.writeMu.Unlock()
+	return c.BwClient.UpdateNoteItemFields(id, notes, hidden)
+}
+
+func (c *workspaceBwClient) Sync() error {
+	c.writeMu.Lock()
+	defer c.writeMu.Unlock()
+	return c.BwClient.Sync()
+}
+
+// SyncForWrite は内側のクライアントが WriteSyncer であれば同期します（prepare で同期済みのクライアントでは何もしません）。
+func (c *workspaceBwClient) SyncForWrite() error {
+	syncer, ok := c.BwClient.(WriteSyncer)
+	if !ok {
+		return nil
+	}
+	c.writeMu.Lock()
+	defer c.writeMu.Unlock()
+	return syncer.SyncForWrite()
+}
+
+func (c *workspaceBwClient) Unlock(masterPassword string) error {
+	c.writeMu.Lock()
+	defer c.writeMu.Unlock()
+	return c.BwClient.Unlock(masterPassword)
+}
+
+// bufferedLogger はログを出力せずに保持する Logger です。
+type bufferedLogger struct {
+	mu   sync.Mutex
+	logs []string
+}
+
+func (l *bufferedLogger) add(level string, args ...interface{}) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.logs = append(l.logs, level+fmt.Sprint(args...))
+}
+
+func (l *bufferedLogger) Error(args ...interface{})   { l.add("[ERROR] ", args...) }
+func (l *bufferedLogger) Warning(args ...interface{}) { l.add("[WARN] ", args...) }
+func (l *bufferedLogger) Info(args ...interface{})    { l.add("[INFO] ", args...) }
+
+func (l *bufferedLogger) lines() []string {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return append([]string(nil), l.logs...)
+}
@@ -0,0 +1,164 @@
+package core
+
+import (
+	"errors"
+	"testing"
+
+	"bwsf/src/config"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// =============================================================================
+// DiscoverProjects のテスト
+// =============================================================================
+
+// 正常系: .env* または .bwsf.json を持つディレクトリを探し、その下や隠しディレクトリは探索しない
+func TestDiscoverProjects(t *testing.T) {
+	fs := &mockFileSystem{dirEntriesMap: map[string][]DirEntry{
+		"/src": {
+			&mockDirEntry{name: "api", isDir: true},
+			&mockDirEntry{name: "group", isDir: true},
+			&mockDirEntry{name: "node_modules", isDir: true},
+			&mockDirEntry{name: ".cache", isDir: true},
+			&mockDirEntry{name: "README.md"},
+		},
+		"/src/api": {
+			&mockDirEntry{name: ".env"},
+			&mockDirEntry{name: "sub", isDir: true},
+		},
+		"/src/api/sub":        {&mockDirEntry{name: ".env"}},
+		"/src/group":          {&mockDirEntry{name: "web", isDir: true}, &mockDirEntry{name: "docs", isDir: true}},
+		"/src/group/web":      {&mockDirEntry{name: ".env.example"}},
+		"/src/group/docs":     {&mockDirEntry{name: "index.md"}},
+		"/src/node_modules":   {&mockDirEntry{name: "x", isDir: true}},
+		"/src/node_modules/x": {&mockDirEntry{name: ".env"}},
+		"/src/.cache":         {&mockDirEntry{name: ".bwsf.json"}},
+	}}
+
+	projects, err := DiscoverProjects("/src/", fs)
+
+	require.NoError(t, err)
+	assert.Equal(t, []WorkspaceProject{
+		{Dir: "/src/api", Name: "api"},
+		{Dir: "/src/group/web", Name: "web"},
+	}, projects)
+}
+
+// 異常系: ディレクトリを読めない
+func TestDiscoverProjects_ReadError(t *testing.T) {
+	fs := &mockFileSystem{readDirErr: errors.New("permission denied")}
+
+	_, err := DiscoverProjects("/src", fs)
+
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "permission denied")
+}
+
+// =============================================================================
+// PullWorkspaceCore / PushWorkspaceCore のテスト
+// =============================================================================
+
+// 正常系: 同期とフォルダ一覧の取得は 1 回だけ行い、未保存のプロジェクトはスキップする
+func TestPullWorkspaceCore(t *testing.T) {
+	bw := &mockBwClient{
+		folderID: "folder-123",
+		items:    []Item{{ID: "id-api", Name: "api"}},
+		itemByID: &FullItem{ID: "id-api", Name: "api", Notes: `{".env":{"lines":["A=1"]}}`},
+	}
+	fs := &mockFileSystem{}
+	projects := []WorkspaceProject{{Dir: "/src/api", Name: "api"}, {Dir: "/src/new", Name: "new"}}
+	var updates []WorkspaceState
+
+	results, err := PullWorkspaceCore(projects, fs, bw, &config.Config{}, nil, noConfirm, &mockLogger{}, WorkspaceOptions{
+		Workers:  1,
+		OnUpdate: func(i int, r WorkspaceResult) { updates = append(updates, r.State) },
+	})
+
+	require.NoError(t, err)
+	require.Len(t, results, 2)
+	assert.Equal(t, WorkspaceDone, results[0].State)
+	assert.Equal(t, WorkspaceSkipped, results[1].State)
+	assert.Equal(t, "not stored in Bitwarden", results[1].Message)
+	assert.Equal(t, "A=1", string(fs.writtenFiles["/src/api/.env"]))
+	assert.Equal(t, []string{"Sync", "GetDotenvsFolderID", "ListItemsInFolder(folder-123)", "GetItemByID(id-api)"}, bw.calls)
+	assert.Equal(t, map[WorkspaceState]int{WorkspaceDone: 1, WorkspaceSkipped: 1}, SummarizeWorkspace(results))
+	assert.Contains(t, updates, WorkspaceRunning)
+}
+
+// 正常系: 失敗したプロジェクトがあっても残りを処理し、ログはプロジェクトごとに保持する
+func TestPushWorkspaceCore_ContinuesPastFailures(t *testing.T) {
+	bw := &mockBwClient{folderID: "folder-123"}
+	fs := &mockFileSystem{
+		dirEntriesMap: map[string][]DirEntry{
+			"/src/bad":   {&mockDirEntry{name: ".env"}},
+			"/src/good":  {&mockDirEntry{name: ".env"}},
+			"/src/empty": {&mockDirEntry{name: ".bwsf.json"}},
+		},
+		readContentMap: map[string][]byte{
+			"/src/bad/.env":  []byte("NOT A VALID LINE\n"),
+			"/src/good/.env": []byte("A=1\n"),
+		},
+	}
+	projects := []WorkspaceProject{
+		{Dir: "/src/bad", Name: "bad"},
+		{Dir: "/src/empty", Name: "empty"},
+		{Dir: "/src/good", Name: "good"},
+	}
+	logger := &mockLogger{}
+
+	results, err := PushWorkspaceCore(projects, fs, bw, &config.Config{}, nil, logger, WorkspaceOptions{Workers: 1})
+
+	require.NoError(t, err)
+	assert.Equal(t, WorkspaceFailed, results[0].State)
+	assert.NotEmpty(t, results[0].Logs)
+	assert.Equal(t, WorkspaceSkipped, results[1].State)
+	assert.Equal(t, WorkspaceDone, results[2].State)
+	assert.Equal(t, "pushed 1 file(s)", results[2].Message)
+	assert.Contains(t, bw.createdNotes, "good")
+	assert.NotContains(t, bw.createdNotes, "bad")
+	assert.Empty(t, logger.errors)
+}
+
+// 正常系: 同じプロジェクト名のディレクトリは最初の 1 つのみ処理する
+func TestPullWorkspaceCore_DuplicateNames(t *testing.T) {
+	bw := &mockBwClient{folderID: "folder-123"}
+	projects := []WorkspaceProject{{Dir: "/a/app", Name: "app"}, {Dir: "/b/app", Name: "app"}}
+
+	results, err := PullWorkspaceCore(projects, &mockFileSystem{}, bw, &config.Config{}, nil, noConfirm, &mockLogger{}, WorkspaceOptions{Workers: 1})
+
+	require.NoError(t, err)
+	assert.Equal(t, WorkspaceSkipped, results[0].State) // 未保存
+	assert.Equal(t, WorkspaceSkipped, results[1].State)
+	assert.Equal(t, "same project name as /a/app", results[1].Message)
+}
+
+// 異常系: 準備（同期）に失敗したら何も処理しない
+func TestPullWorkspaceCore_PrepareError(t *testing.T) {
+	bw := &mockBwClient{syncErr: errors.New("network down")}
+
+	results, err := PullWorkspaceCore([]WorkspaceProject{{Dir: "/a", Name: "a"}}, &mockFileSystem{}, bw, &config.Config{}, nil, noConfirm, &mockLogger{}, WorkspaceOptions{})
+
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "network down")
+	assert.Nil(t, results)
+}
+
+// 異常系: 処理中にロックされた場合はパスワードを尋ねずに失敗させる
+func TestPullWorkspaceCore_LockedDuringRun(t *testing.T) {
+	bw := &mockBwClient{
+		folderID:    "folder-123",
+		items:       []Item{{ID: "id-api", Name: "api"}},
+		itemByIDErr: errors.New("Bitwarden CLI is locked"),
+	}
+	prompted := false
+	prompt := func() (string, error) { prompted = true; return "pw", nil }
+
+	results, err := PullWorkspaceCore([]WorkspaceProject{{Dir: "/src/api", Name: "api"}}, &mockFileSystem{}, bw, &config.Config{}, prompt, noConfirm, &mockLogger{}, WorkspaceOptions{Workers: 1})
+
+	require.NoError(t, err)
+	assert.False(t, prompted)
+	assert.Equal(t, WorkspaceFailed, results[0].State)
+	assert.ErrorIs(t, results[0].Err, ErrWorkspaceLocked)
+}
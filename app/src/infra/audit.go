@@ -0,0 +1,33 @@
+package infra
+
+import (
+	"os"
+	"path/filepath"
+)
+
+// FileAuditSink は core.AuditSink インターフェースの実装で、ファイルに 1 行ずつ追記します。
+type FileAuditSink struct {
+	path string
+}
+
+// NewFileAuditSink は FileAuditSink のインスタンスを作成します。
+func NewFileAuditSink(path string) *FileAuditSink {
+	return &FileAuditSink{path: path}
+}
+
+// Append は 1 行を追記します。ファイルは利用者のみ読み書きできる権限で作成します。
+func (s *FileAuditSink) Append(line []byte) error {
+	if err := os.MkdirAll(filepath.Dir(s.path), 0755); err != nil {
+		return err
+	}
+	f, err := os.OpenFile(s.path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0600)
+	if err != nil {
+		return err
+	}
+	// O_APPEND で 1 回の Write にまとめることで、並行する bwsf の行が混ざらないようにする
+	if _, err := f.Write(append(line, '\n')); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
@@ -0,0 +1,18 @@
+//go:build windows || plan9
+
+package infra
+
+import "errors"
+
+// SyslogAuditSink は syslog のない環境では使えません。
+type SyslogAuditSink struct{}
+
+// NewSyslogAuditSink は syslog のない環境では常にエラーを返します。
+func NewSyslogAuditSink() (*SyslogAuditSink, error) {
+	return nil, errors.New("syslog not supported on this platform; audit entries are only written to the log file")
+}
+
+// Append は何もしません（NewSyslogAuditSink がエラーを返すため呼ばれません）。
+func (s *SyslogAuditSink) Append(line []byte) error {
+	return nil
+}
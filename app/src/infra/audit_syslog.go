@@ -0,0 +1,27 @@
+//go:build !windows && !plan9
+
+package infra
+
+import (
+	"fmt"
+	"log/syslog"
+)
+
+// SyslogAuditSink は core.AuditSink インターフェースの実装で、ローカルの syslog に送ります。
+type SyslogAuditSink struct {
+	writer *syslog.Writer
+}
+
+// NewSyslogAuditSink は "bwsf" タグで syslog に接続します。
+func NewSyslogAuditSink() (*SyslogAuditSink, error) {
+	writer, err := syslog.New(syslog.LOG_NOTICE|syslog.LOG_AUTH, "bwsf")
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to syslog: %w", err)
+	}
+	return &SyslogAuditSink{writer: writer}, nil
+}
+
+// Append は 1 行を syslog に送ります。
+func (s *SyslogAuditSink) Append(line []byte) error {
+	return s.writer.Notice(string(line))
+}
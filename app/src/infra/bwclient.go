@@ -40,8 +40,9 @@ func (c *RealBwClient) ListItemsInFolder(folderID string) ([]core.Item, error) {
 	result := make([]core.Item, len(items))
 	for i, item := range items {
 		result[i] = core.Item{
-			ID:   item.ID,
-			Name: item.Name,
+			ID:           item.ID,
+			Name:         item.Name,
+			RevisionDate: item.RevisionDate,
 		}
 	}
 	return result, nil
@@ -57,12 +58,7 @@ func (c *RealBwClient) GetItemByName(folderID, name string) (*core.FullItem, err
 		return nil, nil
 	}
 
-	// utils.FullItem から core.FullItem に変換
-	return &core.FullItem{
-		ID:    item.ID,
-		Name:  item.Name,
-		Notes: item.Notes,
-	}, nil
+	return toCoreFullItem(item), nil
 }
 
 // GetItemByID は指定 ID のアイテムを取得します。
@@ -75,12 +71,7 @@ func (c *RealBwClient) GetItemByID(id string) (*core.FullItem, error) {
 		return nil, nil
 	}
 
-	// utils.FullItem から core.FullItem に変換
-	return &core.FullItem{
-		ID:    item.ID,
-		Name:  item.Name,
-		Notes: item.Notes,
-	}, nil
+	return toCoreFullItem(item), nil
 }
 
 // CreateNoteItem は新しいノートアイテムを作成します。
@@ -93,6 +84,25 @@ func (c *RealBwClient) UpdateNoteItem(id, notes string) error {
 	return utils.UpdateNoteItem(id, notes)
 }
 
+// UpdateNoteItemFields はノートの内容と非表示のカスタムフィールドを 1 回の編集で更新します。
+func (c *RealBwClient) UpdateNoteItemFields(id, notes string, hidden []core.ItemField) error {
+	fields := make([]utils.ItemField, len(hidden))
+	for i, field := range hidden {
+		fields[i] = utils.ItemField{Name: field.Name, Value: field.Value, Type: 1}
+	}
+	return utils.UpdateNoteItemFields(id, notes, fields)
+}
+
+// RenameItem はアイテムの名前を変更します。
+func (c *RealBwClient) RenameItem(id, newName string) error {
+	return utils.RenameItem(id, newName)
+}
+
+// DeleteItem はアイテムを削除します。permanent が false の場合はゴミ箱に移動します。
+func (c *RealBwClient) DeleteItem(id string, permanent bool) error {
+	return utils.DeleteItem(id, permanent)
+}
+
 // Login は Bitwarden CLI にログインします。
 func (c *RealBwClient) Login(email, password, serverURL string) error {
 	success, errorMsg := utils.BwLogin(email, password, serverURL)
@@ -111,6 +121,11 @@ func (c *RealBwClient) Unlock(masterPassword string) error {
 	return nil
 }
 
+// Sync はサーバーと同期します。
+func (c *RealBwClient) Sync() error {
+	return utils.BwSync()
+}
+
 // LoginError はログイン失敗時のエラーです。
 type LoginError struct {
 	Message string
@@ -129,3 +144,24 @@ func (e *UnlockError) Error() string {
 	return e.Message
 }
 
+
+// toCoreFullItem は utils.FullItem を core.FullItem に変換します。
+func toCoreFullItem(item *utils.FullItem) *core.FullItem {
+	result := &core.FullItem{
+		ID:           item.ID,
+		Name:         item.Name,
+		Notes:        item.Notes,
+		RevisionDate: item.RevisionDate,
+	}
+	if item.Login != nil {
+		result.Login = &core.ItemLogin{
+			Username: item.Login.Username,
+			Password: item.Login.Password,
+			TOTP:     item.Login.TOTP,
+		}
+	}
+	for _, field := range item.Fields {
+		result.Fields = append(result.Fields, core.ItemField{Name: field.Name, Value: field.Value})
+	}
+	return result
+}
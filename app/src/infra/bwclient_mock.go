@@ -66,8 +66,50 @@ func (fs *MockFileSystem) MkdirAll(path string, perm uint32) error {
 	return nil
 }
 
+// Remove はファイルを削除します。
+func (fs *MockFileSystem) Remove(path string) error {
+	fs.mu.Lock()
+	defer fs.mu.Unlock()
+
+	if _, ok := fs.files[path]; !ok {
+		return fmt.Errorf("file not found: %s", path)
+	}
+	delete(fs.files, path)
+	return nil
+}
+
+// ServeNamedPipe は data を通常のファイルとして保持します（モックでは読み込み回数を数えない）。
+func (fs *MockFileSystem) ServeNamedPipe(path string, data []byte, reads int) (core.NamedPipe, error) {
+	if err := fs.WriteFile(path, data, 0600); err != nil {
+		return nil, err
+	}
+	return &mockNamedPipe{fs: fs, path: path, done: make(chan struct{})}, nil
+}
+
+// mockNamedPipe は core.NamedPipe インターフェースのモック実装です。
+type mockNamedPipe struct {
+	fs   *MockFileSystem
+	path string
+	done chan struct{}
+	once sync.Once
+}
+
+// Done は Close されたときに閉じられるチャネルを返します。
+func (p *mockNamedPipe) Done() <-chan struct{} {
+	return p.done
+}
+
+// Close はファイルを削除します。
+func (p *mockNamedPipe) Close() error {
+	p.once.Do(func() {
+		_ = p.fs.Remove(p.path)
+		close(p.done)
+	})
+	return nil
+}
+
 // ReadDir はディレクトリ内のエントリを読み込みます。
-// ファイルマップからディレクトリ内のファイルを抽出します。
+// ファイルマップからディレクトリ内のファイルと、ファイルを含むサブディレクトリを抽出します。
 func (fs *MockFileSystem) ReadDir(path string) ([]core.DirEntry, error) {
 	fs.mu.RLock()
 	defer fs.mu.RUnlock()
@@ -85,8 +127,13 @@ func (fs *MockFileSystem) ReadDir(path string) ([]core.DirEntry, error) {
 		// ファイルがこのディレクトリにあるかチェック
 		if strings.HasPrefix(filePath, path) {
 			relativePath := strings.TrimPrefix(filePath, path)
-			// サブディレクトリのファイルは除外（直接の子のみ）
-			if strings.Contains(relativePath, "/") {
+			// サブディレクトリのファイルはディレクトリのエントリとして扱う（直接の子のみ）
+			if idx := strings.Index(relativePath, "/"); idx >= 0 {
+				dirName := relativePath[:idx]
+				if dirName != "" && !seen[dirName] {
+					seen[dirName] = true
+					entries = append(entries, &mockDirEntry{name: dirName, isDir: true})
+				}
 				continue
 			}
 			fileName := filepath.Base(filePath)
@@ -152,16 +199,18 @@ func (fi *mockFileInfo) IsNotExist() bool {
 
 // MockLogger はテスト用のモック Logger 実装です。
 type MockLogger struct {
-	mu       sync.Mutex
-	InfoLogs  []string
-	ErrorLogs []string
+	mu          sync.Mutex
+	InfoLogs    []string
+	WarningLogs []string
+	ErrorLogs   []string
 }
 
 // NewMockLogger は MockLogger の新しいインスタンスを作成します。
 func NewMockLogger() *MockLogger {
 	return &MockLogger{
-		InfoLogs:  []string{},
-		ErrorLogs: []string{},
+		InfoLogs:    []string{},
+		WarningLogs: []string{},
+		ErrorLogs:   []string{},
 	}
 }
 
@@ -172,6 +221,13 @@ func (l *MockLogger) Info(args ...interface{}) {
 	l.InfoLogs = append(l.InfoLogs, fmt.Sprint(args...))
 }
 
+// Warning はWarningログを記録します。
+func (l *MockLogger) Warning(args ...interface{}) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.WarningLogs = append(l.WarningLogs, fmt.Sprint(args...))
+}
+
 // Error はErrorログを記録します。
 func (l *MockLogger) Error(args ...interface{}) {
 	l.mu.Lock()
@@ -191,7 +247,9 @@ type MockBwClient struct {
 	// ストレージ
 	folders map[string]string          // folderName -> folderID
 	items   map[string]*core.FullItem  // itemID -> FullItem
+	trash   map[string]*core.FullItem  // itemID -> FullItem（ゴミ箱）
 	itemsByFolder map[string][]string  // folderID -> []itemID
+	nextItemID    int                  // 削除後も ID が重複しないための連番
 
 	// 認証状態
 	isLoggedIn bool
@@ -199,6 +257,7 @@ type MockBwClient struct {
 	email      string
 	password   string
 	serverURL  string
+	syncCount  int
 
 	// テスト用のフック
 	LoginFunc  func(email, password, serverURL string) error
@@ -210,6 +269,7 @@ func NewMockBwClient() *MockBwClient {
 	m := &MockBwClient{
 		folders:       make(map[string]string),
 		items:         make(map[string]*core.FullItem),
+		trash:         make(map[string]*core.FullItem),
 		itemsByFolder: make(map[string][]string),
 	}
 	return m
@@ -317,6 +377,16 @@ func (m *MockBwClient) GetItemByName(folderID, name string) (*core.FullItem, err
 		return nil, fmt.Errorf("Bitwarden CLI is locked")
 	}
 
+	// folderID が空の場合は全アイテムから検索
+	if folderID == "" {
+		for _, item := range m.items {
+			if item.Name == name {
+				return copyFullItem(item), nil
+			}
+		}
+		return nil, nil
+	}
+
 	itemIDs, ok := m.itemsByFolder[folderID]
 	if !ok {
 		return nil, nil
@@ -324,11 +394,7 @@ func (m *MockBwClient) GetItemByName(folderID, name string) (*core.FullItem, err
 
 	for _, id := range itemIDs {
 		if item, ok := m.items[id]; ok && item.Name == name {
-			return &core.FullItem{
-				ID:    item.ID,
-				Name:  item.Name,
-				Notes: item.Notes,
-			}, nil
+			return copyFullItem(item), nil
 		}
 	}
 	return nil, nil
@@ -347,11 +413,34 @@ func (m *MockBwClient) GetItemByID(id string) (*core.FullItem, error) {
 	if !ok {
 		return nil, nil
 	}
-	return &core.FullItem{
-		ID:    item.ID,
-		Name:  item.Name,
-		Notes: item.Notes,
-	}, nil
+	return copyFullItem(item), nil
+}
+
+// AddLoginItem はログインアイテムを追加し、その ID を返します（テスト用）。
+func (m *MockBwClient) AddLoginItem(name string, login core.ItemLogin, fields []core.ItemField) string {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.nextItemID++
+	itemID := fmt.Sprintf("item-%s-%d", name, m.nextItemID)
+	m.items[itemID] = &core.FullItem{
+		ID:     itemID,
+		Name:   name,
+		Login:  &login,
+		Fields: fields,
+	}
+	return itemID
+}
+
+// copyFullItem は呼び出し側の変更がストレージに影響しないようアイテムを複製します。
+func copyFullItem(item *core.FullItem) *core.FullItem {
+	c := *item
+	if item.Login != nil {
+		login := *item.Login
+		c.Login = &login
+	}
+	c.Fields = append([]core.ItemField(nil), item.Fields...)
+	return &c
 }
 
 // CreateNoteItem は新しいノートアイテムを作成します。
@@ -364,7 +453,8 @@ func (m *MockBwClient) CreateNoteItem(folderID, name, notes string) error {
 	}
 
 	// 新しいIDを生成
-	itemID := fmt.Sprintf("item-%s-%d", name, len(m.items)+1)
+	m.nextItemID++
+	itemID := fmt.Sprintf("item-%s-%d", name, m.nextItemID)
 
 	// アイテムを作成
 	item := &core.FullItem{
@@ -398,6 +488,90 @@ func (m *MockBwClient) UpdateNoteItem(id, notes string) error {
 	return nil
 }
 
+// UpdateNoteItemFields はノートの内容を更新し、hidden を同名のフィールドを置き換えて保存します。
+func (m *MockBwClient) UpdateNoteItemFields(id, notes string, hidden []core.ItemField) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if !m.isUnlocked {
+		return fmt.Errorf("Bitwarden CLI is locked")
+	}
+
+	item, ok := m.items[id]
+	if !ok {
+		return fmt.Errorf("item not found: %s", id)
+	}
+
+	item.Notes = notes
+	for _, field := range hidden {
+		replaced := false
+		for i := range item.Fields {
+			if item.Fields[i].Name == field.Name {
+				item.Fields[i].Value = field.Value
+				replaced = true
+			}
+		}
+		if !replaced {
+			item.Fields = append(item.Fields, field)
+		}
+	}
+	return nil
+}
+
+// RenameItem はアイテムの名前を変更します。
+func (m *MockBwClient) RenameItem(id, newName string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if !m.isUnlocked {
+		return fmt.Errorf("Bitwarden CLI is locked")
+	}
+
+	item, ok := m.items[id]
+	if !ok {
+		return fmt.Errorf("item not found: %s", id)
+	}
+
+	item.Name = newName
+	return nil
+}
+
+// DeleteItem はアイテムを削除します。permanent が false の場合はゴミ箱に移動します。
+func (m *MockBwClient) DeleteItem(id string, permanent bool) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if !m.isUnlocked {
+		return fmt.Errorf("Bitwarden CLI is locked")
+	}
+
+	item, ok := m.items[id]
+	if !ok {
+		return fmt.Errorf("item not found: %s", id)
+	}
+
+	delete(m.items, id)
+	for folderID, itemIDs := range m.itemsByFolder {
+		for i, itemID := range itemIDs {
+			if itemID == id {
+				m.itemsByFolder[folderID] = append(itemIDs[:i:i], itemIDs[i+1:]...)
+				break
+			}
+		}
+	}
+	if !permanent {
+		m.trash[id] = item
+	}
+	return nil
+}
+
+// GetTrashCount はゴミ箱内のアイテム数を返します（テスト用）。
+func (m *MockBwClient) GetTrashCount() int {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return len(m.trash)
+}
+
 // Login は Bitwarden にログインします。
 func (m *MockBwClient) Login(email, password, serverURL string) error {
 	m.mu.Lock()
@@ -433,6 +607,25 @@ func (m *MockBwClient) Unlock(masterPassword string) error {
 	return nil
 }
 
+// Sync はサーバーと同期します（モックでは呼び出し回数のみ記録）。
+func (m *MockBwClient) Sync() error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if !m.isUnlocked {
+		return fmt.Errorf("Bitwarden CLI is locked")
+	}
+	m.syncCount++
+	return nil
+}
+
+// GetSyncCount は Sync の呼び出し回数を返します（テスト用）。
+func (m *MockBwClient) GetSyncCount() int {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return m.syncCount
+}
+
 // SetLoggedIn はログイン状態を設定します（テスト用）。
 func (m *MockBwClient) SetLoggedIn(loggedIn bool) {
 	m.mu.Lock()
@@ -461,6 +654,7 @@ func (m *MockBwClient) Reset() {
 
 	m.folders = make(map[string]string)
 	m.items = make(map[string]*core.FullItem)
+	m.trash = make(map[string]*core.FullItem)
 	m.itemsByFolder = make(map[string][]string)
 	m.isLoggedIn = false
 	m.isUnlocked = false
@@ -469,3 +663,84 @@ func (m *MockBwClient) Reset() {
 	m.serverURL = ""
 }
 
+
+// =============================================================================
+// MockGitInspector - テスト用の GitInspector モック
+// =============================================================================
+
+// MockGitInspector はテスト用のモック GitInspector 実装です。
+// Root が空の場合は Git 管理外として扱います。
+type MockGitInspector struct {
+	Root    string
+	Tracked map[string]bool
+	Ignored map[string]bool
+	History map[string]bool
+}
+
+// NewMockGitInspector は root を作業ツリーのルートとする MockGitInspector を作成します。
+func NewMockGitInspector(root string) *MockGitInspector {
+	return &MockGitInspector{
+		Root:    root,
+		Tracked: make(map[string]bool),
+		Ignored: make(map[string]bool),
+		History: make(map[string]bool),
+	}
+}
+
+// WorkTreeRoot は作業ツリーのルートを返します。
+func (g *MockGitInspector) WorkTreeRoot(dir string) (string, error) {
+	return g.Root, nil
+}
+
+// IsTracked は path が追跡されているかを返します。
+func (g *MockGitInspector) IsTracked(path string) (bool, error) {
+	return g.Tracked[path], nil
+}
+
+// IsIgnored は path が無視されているかを返します。
+func (g *MockGitInspector) IsIgnored(path string) (bool, error) {
+	return g.Ignored[path], nil
+}
+
+// InHistory は path が履歴に含まれるかを返します。
+func (g *MockGitInspector) InHistory(path string) (bool, error) {
+	return g.History[path], nil
+}
+
+// =============================================================================
+// MockKeyring - テスト用のキーリングモック
+// =============================================================================
+
+// MockKeyring はメモリ上に値を保持する core.Keyring 実装です。
+type MockKeyring struct {
+	mu      sync.Mutex
+	secrets map[string]string
+}
+
+// NewMockKeyring は空の MockKeyring を作成します。
+func NewMockKeyring() *MockKeyring {
+	return &MockKeyring{secrets: make(map[string]string)}
+}
+
+// Get は保存された値を返します。存在しない場合は "" を返します。
+func (k *MockKeyring) Get(service, account string) (string, error) {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	return k.secrets[service+"/"+account], nil
+}
+
+// Set は値を保存します。
+func (k *MockKeyring) Set(service, account, secret string) error {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	k.secrets[service+"/"+account] = secret
+	return nil
+}
+
+// Delete は値を削除します。
+func (k *MockKeyring) Delete(service, account string) error {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	delete(k.secrets, service+"/"+account)
+	return nil
+}
@@ -0,0 +1,450 @@
+package infra
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"filippo.io/age"
+
+	"bwsf/src/core"
+)
+
+// fileStoreWorkFactor は scrypt の作業量（2^18）です。age の既定値と同じです。
+const fileStoreWorkFactor = 18
+
+// ErrFileStoreLocked はパスフレーズが未設定の場合に返されます。
+// "Master password" を含むため core.IsLockedError で判定され、WithUnlockRetry がパスフレーズを尋ねます。
+var ErrFileStoreLocked = errors.New("Master password required: the file store is locked (set BWSF_FILE_PASSPHRASE or enter the passphrase)")
+
+// fileStore は暗号化ファイルの中身です。
+type fileStore struct {
+	Version int          `json:"version"`
+	Folders []fileFolder `json:"folders"`
+	Items   []fileItem   `json:"items"`
+}
+
+type fileFolder struct {
+	ID   string `json:"id"`
+	Name string `json:"name"`
+}
+
+type fileItem struct {
+	ID           string      `json:"id"`
+	FolderID     string      `json:"folderId,omitempty"`
+	Name         string      `json:"name"`
+	Notes        string      `json:"notes"`
+	RevisionDate string      `json:"revisionDate"`
+	DeletedDate  string      `json:"deletedDate,omitempty"` // ゴミ箱に移動した日時
+	Fields       []fileField `json:"fields,omitempty"`      // 非表示のカスタムフィールド
+}
+
+type fileField struct {
+	Name  string `json:"name"`
+	Value string `json:"value"`
+}
+
+// FileBwClient は core.BwClient インターフェースの実装で、
+// age（scrypt パスフレーズ）で暗号化した 1 つのファイルにフォルダとノートアイテムを保存します。
+// Bitwarden のサーバーや bw コマンドは使いません。
+type FileBwClient struct {
+	path       string
+	folderName string
+	passphrase string
+	workFactor int
+	now        func() time.Time
+
+	mu      sync.Mutex
+	store   *fileStore
+	modTime time.Time // store を読み込んだ時点のファイルの更新日時とサイズ
+	size    int64
+}
+
+// NewFileBwClient は FileBwClient のインスタンスを作成します。
+// passphrase が空の場合はロック状態で、Unlock でパスフレーズを設定するまで操作できません。
+func NewFileBwClient(path, folderName, passphrase string) *FileBwClient {
+	return &FileBwClient{
+		path:       path,
+		folderName: folderName,
+		passphrase: passphrase,
+		workFactor: fileStoreWorkFactor,
+		now:        time.Now,
+	}
+}
+
+// GetDotenvsFolderID は設定されたフォルダの ID を返します。フォルダが無ければ作成します。
+func (c *FileBwClient) GetDotenvsFolderID() (string, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	store, err := c.load()
+	if err != nil {
+		return "", err
+	}
+	if folder := store.folder(c.folderName); folder != nil {
+		return folder.ID, nil
+	}
+	id, err := c.createFolder(store)
+	if err != nil {
+		return "", err
+	}
+	return id, nil
+}
+
+// DotenvsFolderExists はフォルダが存在するかどうかを返します。
+func (c *FileBwClient) DotenvsFolderExists() (bool, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	store, err := c.load()
+	if err != nil {
+		return false, err
+	}
+	return store.folder(c.folderName) != nil, nil
+}
+
+// CreateDotenvsFolder はフォルダを作成します。
+func (c *FileBwClient) CreateDotenvsFolder() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	store, err := c.load()
+	if err != nil {
+		return err
+	}
+	if store.folder(c.folderName) != nil {
+		return fmt.Errorf("%s folder already exists", c.folderName)
+	}
+	_, err = c.createFolder(store)
+	return err
+}
+
+// ListItemsInFolder は指定フォルダ内のアイテム一覧を返します（ゴミ箱のアイテムを除く）。
+func (c *FileBwClient) ListItemsInFolder(folderID string) ([]core.Item, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	store, err := c.load()
+	if err != nil {
+		return nil, err
+	}
+	items := []core.Item{}
+	for _, item := range store.Items {
+		if item.FolderID == folderID && item.DeletedDate == "" {
+			items = append(items, core.Item{ID: item.ID, Name: item.Name, RevisionDate: item.RevisionDate})
+		}
+	}
+	return items, nil
+}
+
+// GetItemByName は指定フォルダ内のアイテムを名前で検索します。folderID が空の場合は全フォルダから検索します。
+func (c *FileBwClient) GetItemByName(folderID, name string) (*core.FullItem, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	store, err := c.load()
+	if err != nil {
+		return nil, err
+	}
+	for _, item := range store.Items {
+		if item.Name == name && item.DeletedDate == "" && (folderID == "" || item.FolderID == folderID) {
+			return item.toCore(), nil
+		}
+	}
+	return nil, nil
+}
+
+// GetItemByID は指定 ID のアイテムを返します。
+func (c *FileBwClient) GetItemByID(id string) (*core.FullItem, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	store, err := c.load()
+	if err != nil {
+		return nil, err
+	}
+	if item := store.item(id); item != nil && item.DeletedDate == "" {
+		return item.toCore(), nil
+	}
+	return nil, nil
+}
+
+// CreateNoteItem はノートアイテムを作成します。
+func (c *FileBwClient) CreateNoteItem(folderID, name, notes string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	store, err := c.load()
+	if err != nil {
+		return err
+	}
+	store.Items = append(store.Items, fileItem{
+		ID:           newFileStoreID(),
+		FolderID:     folderID,
+		Name:         name,
+		Notes:        notes,
+		RevisionDate: c.revisionDate(),
+	})
+	return c.save(store)
+}
+
+// UpdateNoteItem はノートの内容を更新します。
+func (c *FileBwClient) UpdateNoteItem(id, notes string) error {
+	return c.modify(id, func(item *fileItem) { item.Notes = notes })
+}
+
+// UpdateNoteItemFields はノートの内容を更新し、hidden を同名のフィールドを置き換えて保存します。
+// ファイルには変更履歴がないため、置き換えたフィールドの前の値は残りません。
+func (c *FileBwClient) UpdateNoteItemFields(id, notes string, hidden []core.ItemField) error {
+	return c.modify(id, func(item *fileItem) {
+		item.Notes = notes
+		for _, field := range hidden {
+			replaced := false
+			for i := range item.Fields {
+				if item.Fields[i].Name == field.Name {
+					item.Fields[i].Value = field.Value
+					replaced = true
+				}
+			}
+			if !replaced {
+				item.Fields = append(item.Fields, fileField{Name: field.Name, Value: field.Value})
+			}
+		}
+	})
+}
+
+// RenameItem はアイテムの名前を変更します。
+func (c *FileBwClient) RenameItem(id, newName string) error {
+	return c.modify(id, func(item *fileItem) { item.Name = newName })
+}
+
+// DeleteItem はアイテムを削除します。permanent が false の場合はゴミ箱に移動します。
+func (c *FileBwClient) DeleteItem(id string, permanent bool) error {
+	if !permanent {
+		return c.modify(id, func(item *fileItem) { item.DeletedDate = c.revisionDate() })
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	store, err := c.load()
+	if err != nil {
+		return err
+	}
+	for i, item := range store.Items {
+		if item.ID == id {
+			store.Items = append(store.Items[:i:i], store.Items[i+1:]...)
+			return c.save(store)
+		}
+	}
+	return fmt.Errorf("item not found: %s", id)
+}
+
+// Login はファイルバックエンドでは使いません。
+func (c *FileBwClient) Login(email, password, serverURL string) error {
+	return errors.New("the file backend does not log in to Bitwarden")
+}
+
+// Unlock はパスフレーズを設定します。ストアが既にあれば、復号できることを確認します。
+func (c *FileBwClient) Unlock(masterPassword string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	previous := c.passphrase
+	c.passphrase = masterPassword
+	c.store = nil
+	if _, err := c.load(); err != nil {
+		c.passphrase = previous
+		return err
+	}
+	return nil
+}
+
+// Sync はファイルバックエンドでは何もしません。
+func (c *FileBwClient) Sync() error {
+	return nil
+}
+
+// modify は id のアイテムを fn で変更し、更新日時を付けて保存します。
+func (c *FileBwClient) modify(id string, fn func(item *fileItem)) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	store, err := c.load()
+	if err != nil {
+		return err
+	}
+	item := store.item(id)
+	if item == nil {
+		return fmt.Errorf("item not found: %s", id)
+	}
+	fn(item)
+	item.RevisionDate = c.revisionDate()
+	return c.save(store)
+}
+
+func (c *FileBwClient) createFolder(store *fileStore) (string, error) {
+	id := newFileStoreID()
+	store.Folders = append(store.Folders, fileFolder{ID: id, Name: c.folderName})
+	if err := c.save(store); err != nil {
+		return "", err
+	}
+	return id, nil
+}
+
+func (c *FileBwClient) revisionDate() string {
+	return c.now().UTC().Format(time.RFC3339Nano)
+}
+
+// load はストアを復号して返します。ファイルが無ければ空のストアを返します。
+// 前回読み込んだ後にファイルが変わっていなければ、復号し直しません。
+func (c *FileBwClient) load() (*fileStore, error) {
+	if c.passphrase == "" {
+		return nil, ErrFileStoreLocked
+	}
+
+	info, err := os.Stat(c.path)
+	if os.IsNotExist(err) {
+		if c.store == nil || !c.modTime.IsZero() {
+			c.store, c.modTime, c.size = &fileStore{Version: 1}, time.Time{}, 0
+		}
+		return c.store, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to stat file store: %w", err)
+	}
+	if c.store != nil && info.ModTime().Equal(c.modTime) && info.Size() == c.size {
+		return c.store, nil
+	}
+
+	data, err := os.ReadFile(c.path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read file store: %w", err)
+	}
+	identity, err := age.NewScryptIdentity(c.passphrase)
+	if err != nil {
+		return nil, err
+	}
+	r, err := age.Decrypt(bytes.NewReader(data), identity)
+	if err != nil {
+		var noMatch *age.NoIdentityMatchError
+		if errors.As(err, &noMatch) {
+			return nil, errors.New("incorrect passphrase for the file store")
+		}
+		return nil, fmt.Errorf("failed to decrypt file store: %w", err)
+	}
+	plain, err := io.ReadAll(r)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decrypt file store: %w", err)
+	}
+
+	store := &fileStore{}
+	if err := json.Unmarshal(plain, store); err != nil {
+		return nil, fmt.Errorf("failed to parse file store: %w", err)
+	}
+	c.store, c.modTime, c.size = store, info.ModTime(), info.Size()
+	return store, nil
+}
+
+// save はストアを暗号化し、一時ファイルからの置き換えで書き出します。
+// 書き出せなかった場合は、変更したメモリ上のストアを捨てて次回ファイルから読み直します。
+func (c *FileBwClient) save(store *fileStore) error {
+	if err := c.write(store); err != nil {
+		c.store = nil
+		return err
+	}
+	c.store = store
+	if info, err := os.Stat(c.path); err == nil {
+		c.modTime, c.size = info.ModTime(), info.Size()
+	}
+	return nil
+}
+
+func (c *FileBwClient) write(store *fileStore) error {
+	plain, err := json.Marshal(store)
+	if err != nil {
+		return fmt.Errorf("failed to marshal file store: %w", err)
+	}
+	recipient, err := age.NewScryptRecipient(c.passphrase)
+	if err != nil {
+		return err
+	}
+	recipient.SetWorkFactor(c.workFactor)
+
+	var buf bytes.Buffer
+	w, err := age.Encrypt(&buf, recipient)
+	if err != nil {
+		return fmt.Errorf("failed to encrypt file store: %w", err)
+	}
+	if _, err := w.Write(plain); err != nil {
+		return fmt.Errorf("failed to encrypt file store: %w", err)
+	}
+	if err := w.Close(); err != nil {
+		return fmt.Errorf("failed to encrypt file store: %w", err)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(c.path), 0755); err != nil {
+		return fmt.Errorf("failed to create file store directory: %w", err)
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(c.path), ".store-*.tmp")
+	if err != nil {
+		return fmt.Errorf("failed to write file store: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(buf.Bytes()); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to write file store: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to write file store: %w", err)
+	}
+	if err := os.Chmod(tmp.Name(), 0600); err != nil {
+		return fmt.Errorf("failed to write file store: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), c.path); err != nil {
+		return fmt.Errorf("failed to write file store: %w", err)
+	}
+	return nil
+}
+
+func (s *fileStore) folder(name string) *fileFolder {
+	for i := range s.Folders {
+		if s.Folders[i].Name == name {
+			return &s.Folders[i]
+		}
+	}
+	return nil
+}
+
+func (s *fileStore) item(id string) *fileItem {
+	for i := range s.Items {
+		if s.Items[i].ID == id {
+			return &s.Items[i]
+		}
+	}
+	return nil
+}
+
+func (i fileItem) toCore() *core.FullItem {
+	item := &core.FullItem{ID: i.ID, Name: i.Name, Notes: i.Notes, RevisionDate: i.RevisionDate}
+	for _, field := range i.Fields {
+		item.Fields = append(item.Fields, core.ItemField{Name: field.Name, Value: field.Value})
+	}
+	return item
+}
+
+// newFileStoreID は UUID 形式のランダムな ID を返します。
+func newFileStoreID() string {
+	b := make([]byte, 16)
+	rand.Read(b)
+	h := hex.EncodeToString(b)
+	return h[0:8] + "-" + h[8:12] + "-" + h[12:16] + "-" + h[16:20] + "-" + h[20:32]
+}
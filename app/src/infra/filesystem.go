@@ -1,7 +1,11 @@
 package infra
 
 import (
+	"errors"
+	"fmt"
 	"os"
+	"sync"
+	"syscall"
 
 	"bwsf/src/core"
 )
@@ -60,6 +64,92 @@ func (fs *RealFileSystem) ReadDir(path string) ([]core.DirEntry, error) {
 	return result, nil
 }
 
+// Remove はファイルを削除します。
+func (fs *RealFileSystem) Remove(path string) error {
+	return os.Remove(path)
+}
+
+// ServeNamedPipe は path に名前付きパイプを作り、バックグラウンドで data を reads 回提供します。
+// 書き込み側の open は読み手が現れるまでブロックするため、読み手ごとに 1 回ずつ内容を渡せます。
+func (fs *RealFileSystem) ServeNamedPipe(path string, data []byte, reads int) (core.NamedPipe, error) {
+	if err := mkfifo(path, 0600); err != nil {
+		return nil, fmt.Errorf("failed to create named pipe: %w", err)
+	}
+	p := &namedPipe{path: path, done: make(chan struct{})}
+	go p.serve(data, reads)
+	return p, nil
+}
+
+// namedPipe は core.NamedPipe インターフェースの実装です。
+type namedPipe struct {
+	path string
+	done chan struct{}
+
+	mu     sync.Mutex
+	closed bool
+}
+
+func (p *namedPipe) serve(data []byte, reads int) {
+	defer close(p.done)
+	defer os.Remove(p.path)
+
+	for i := 0; i < reads; i++ {
+		f, err := os.OpenFile(p.path, os.O_WRONLY, 0)
+		if err != nil {
+			return
+		}
+		p.mu.Lock()
+		closed := p.closed
+		p.mu.Unlock()
+		if closed {
+			f.Close()
+			return
+		}
+		// 読み手がつながったパイプは path から外し、次の読み手には新しいパイプを置く。
+		// 同じパイプを開き直すと、まだ読み終えていない前の読み手に 2 回目の内容が渡ってしまう
+		if i < reads-1 {
+			next := p.path + ".next"
+			if err := mkfifo(next, 0600); err == nil {
+				if err := os.Rename(next, p.path); err != nil {
+					os.Remove(next)
+					reads = i + 1
+				}
+			} else {
+				reads = i + 1
+			}
+		}
+		_, _ = f.Write(data)
+		f.Close()
+	}
+}
+
+// Done は提供が終わったときに閉じられるチャネルを返します。
+func (p *namedPipe) Done() <-chan struct{} {
+	return p.done
+}
+
+// Close は提供を止めてパイプを削除します。
+func (p *namedPipe) Close() error {
+	p.mu.Lock()
+	if p.closed {
+		p.mu.Unlock()
+		<-p.done
+		return nil
+	}
+	p.closed = true
+	p.mu.Unlock()
+
+	// 読み手を待っている open を自分で読み手になって解放する（ノンブロッキングで開くため待たない）
+	if r, err := os.OpenFile(p.path, os.O_RDONLY|syscall.O_NONBLOCK, 0); err == nil {
+		defer r.Close()
+	}
+	<-p.done
+	if err := os.Remove(p.path); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+	return nil
+}
+
 // realFileInfo は core.FileInfo インターフェースの実装です。
 type realFileInfo struct {
 	notExist bool
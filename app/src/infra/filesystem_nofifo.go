@@ -0,0 +1,10 @@
+//go:build windows || plan9
+
+package infra
+
+import "errors"
+
+// mkfifo は名前付きパイプのない環境では常にエラーを返します。
+func mkfifo(path string, mode uint32) error {
+	return errors.New("named pipes are not supported on this platform (run without --fifo)")
+}
@@ -0,0 +1,10 @@
+//go:build !windows && !plan9
+
+package infra
+
+import "syscall"
+
+// mkfifo は path に名前付きパイプを作ります。
+func mkfifo(path string, mode uint32) error {
+	return syscall.Mkfifo(path, mode)
+}
@@ -0,0 +1,49 @@
+package infra
+
+import (
+	"bwsf/src/utils"
+)
+
+// RealGitInspector は core.GitInspector インターフェースの実装で、
+// git コマンドを実行して作業ツリーの状態を調べます。
+type RealGitInspector struct{}
+
+// NewGitInspector は RealGitInspector のインスタンスを作成します。
+func NewGitInspector() *RealGitInspector {
+	return &RealGitInspector{}
+}
+
+// WorkTreeRoot は dir を含む作業ツリーのルートを返します。
+func (g *RealGitInspector) WorkTreeRoot(dir string) (string, error) {
+	return utils.GitWorkTreeRoot(dir)
+}
+
+// IsTracked は path が Git に追跡されているかを返します。
+func (g *RealGitInspector) IsTracked(path string) (bool, error) {
+	return utils.GitIsTracked(path)
+}
+
+// IsIgnored は path が無視されているかを返します。
+func (g *RealGitInspector) IsIgnored(path string) (bool, error) {
+	return utils.GitIsIgnored(path)
+}
+
+// InHistory は path がコミット履歴に含まれるかを返します。
+func (g *RealGitInspector) InHistory(path string) (bool, error) {
+	return utils.GitInHistory(path)
+}
+
+// HooksDir はフックディレクトリを返します。
+func (g *RealGitInspector) HooksDir(dir string) (string, error) {
+	return utils.GitHooksDir(dir)
+}
+
+// StagedFiles はインデックスで追加・変更されたファイルを返します。
+func (g *RealGitInspector) StagedFiles(dir string) ([]string, error) {
+	return utils.GitStagedFiles(dir)
+}
+
+// StagedContent はインデックス上のファイルの内容を返します。
+func (g *RealGitInspector) StagedContent(dir, path string) ([]byte, error) {
+	return utils.GitStagedContent(dir, path)
+}
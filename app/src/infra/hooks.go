@@ -0,0 +1,144 @@
+package infra
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"bwsf/src/core"
+	"bwsf/src/utils"
+)
+
+// Webhook の形式
+const (
+	WebhookFormatSlack   = "slack"   // {"text": "..."} の Slack Incoming Webhook 互換
+	WebhookFormatGeneric = "webhook" // PushEvent をそのまま JSON で送る
+)
+
+// WebhookHook は core.PostPushHook インターフェースの実装で、HTTP POST で通知します。
+type WebhookHook struct {
+	url     string
+	format  string
+	headers map[string]string
+	timeout time.Duration
+	retries int
+	backoff time.Duration // 最初のリトライまでの待ち時間（以降は倍々）
+	client  *http.Client
+}
+
+// NewWebhookHook は WebhookHook のインスタンスを作成します。
+// timeout は 1 回の送信の制限時間、retries は失敗後に再送する回数です。
+func NewWebhookHook(url, format string, headers map[string]string, timeout time.Duration, retries int) *WebhookHook {
+	return &WebhookHook{
+		url:     url,
+		format:  format,
+		headers: headers,
+		timeout: timeout,
+		retries: retries,
+		backoff: time.Second,
+		client:  &http.Client{},
+	}
+}
+
+// Name はクエリ文字列を除いたホスト名までの URL を返します（Slack の URL は秘密情報のため）。
+func (h *WebhookHook) Name() string {
+	name := h.url
+	if i := strings.Index(name, "://"); i >= 0 {
+		if j := strings.Index(name[i+3:], "/"); j >= 0 {
+			name = name[:i+3+j]
+		}
+	}
+	return h.format + " " + name
+}
+
+// Run はイベントを送信します。接続エラー・429・5xx の場合はリトライします。
+func (h *WebhookHook) Run(event core.PushEvent) error {
+	var body []byte
+	var err error
+	if h.format == WebhookFormatSlack {
+		body, err = json.Marshal(map[string]string{"text": event.Summary()})
+	} else {
+		body, err = json.Marshal(event)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to marshal payload: %w", err)
+	}
+
+	wait := h.backoff
+	for attempt := 0; ; attempt++ {
+		retry, err := h.post(body)
+		if err == nil {
+			return nil
+		}
+		if !retry || attempt >= h.retries {
+			return err
+		}
+		time.Sleep(wait)
+		wait *= 2
+	}
+}
+
+// post は 1 回送信し、失敗した場合はリトライすべきかどうかを返します。
+func (h *WebhookHook) post(body []byte) (bool, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), h.timeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, h.url, bytes.NewReader(body))
+	if err != nil {
+		return false, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "bwsf")
+	for name, value := range h.headers {
+		req.Header.Set(name, value)
+	}
+
+	resp, err := h.client.Do(req)
+	if err != nil {
+		return true, err
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, io.LimitReader(resp.Body, 64*1024))
+
+	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		return false, nil
+	}
+	retry := resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= 500
+	return retry, fmt.Errorf("unexpected status %s", resp.Status)
+}
+
+// CommandHook は core.PostPushHook インターフェースの実装で、ローカルのコマンドを実行します。
+type CommandHook struct {
+	command string
+	timeout time.Duration
+}
+
+// NewCommandHook は CommandHook のインスタンスを作成します。
+func NewCommandHook(command string, timeout time.Duration) *CommandHook {
+	return &CommandHook{command: command, timeout: timeout}
+}
+
+// Name はコマンドを返します。
+func (h *CommandHook) Name() string {
+	return fmt.Sprintf("%q", h.command)
+}
+
+// Run はイベントの JSON を標準入力に渡してコマンドを実行します。
+// プロジェクト名とファイル名は BWSF_PROJECT / BWSF_FILES 環境変数でも渡します。
+func (h *CommandHook) Run(event core.PushEvent) error {
+	payload, err := json.Marshal(event)
+	if err != nil {
+		return fmt.Errorf("failed to marshal payload: %w", err)
+	}
+	env := []string{
+		"BWSF_EVENT=" + event.Event,
+		"BWSF_PROJECT=" + event.Project,
+		"BWSF_FILES=" + strings.Join(event.Files, " "),
+	}
+	return utils.RunHookCommand(h.command, payload, env, h.timeout)
+}
@@ -1,9 +1,20 @@
 package infra
 
 import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
 	"testing"
+	"time"
+
+	"bwsf/src/core"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 // =============================================================================
@@ -116,3 +127,297 @@ func TestRealFileInfo_IsNotExist_False(t *testing.T) {
 
 
 
+
+// =============================================================================
+// RealKeyring のテスト
+// =============================================================================
+
+// 正常系: RealKeyring と MockKeyring が Keyring インターフェースを実装している
+func TestKeyring_ImplementsInterface(t *testing.T) {
+	var _ core.Keyring = NewKeyring()
+	var keyring core.Keyring = NewMockKeyring()
+
+	assert.NoError(t, keyring.Set("bwsf", "test", "secret"))
+	secret, err := keyring.Get("bwsf", "test")
+	assert.NoError(t, err)
+	assert.Equal(t, "secret", secret)
+
+	assert.NoError(t, keyring.Delete("bwsf", "test"))
+	secret, _ = keyring.Get("bwsf", "test")
+	assert.Empty(t, secret)
+}
+
+// =============================================================================
+// FileAuditSink のテスト
+// =============================================================================
+
+// 正常系: ディレクトリを作成し、1 行ずつ利用者のみ読める権限で追記する
+func TestFileAuditSink_Append(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "logs", "audit.jsonl")
+	var sink core.AuditSink = NewFileAuditSink(path)
+
+	assert.NoError(t, sink.Append([]byte(`{"op":"pull"}`)))
+	assert.NoError(t, sink.Append([]byte(`{"op":"push"}`)))
+
+	data, err := os.ReadFile(path)
+	assert.NoError(t, err)
+	assert.Equal(t, "{\"op\":\"pull\"}\n{\"op\":\"push\"}\n", string(data))
+	info, _ := os.Stat(path)
+	assert.Equal(t, os.FileMode(0600), info.Mode().Perm())
+}
+
+// =============================================================================
+// WebhookHook / CommandHook のテスト
+// =============================================================================
+
+// testPushEvent は値を含まないテスト用のイベントです。
+func testPushEvent() core.PushEvent {
+	return core.PushEvent{
+		Event:   "push",
+		Project: "web",
+		Files:   []string{".env"},
+		Changes: map[string]core.KeyChanges{".env": {Added: []string{"NEW"}, Changed: []string{"API_KEY"}}},
+		Actor:   "alice",
+	}
+}
+
+// 正常系: 汎用 Webhook はイベントを JSON で送り、指定したヘッダーを付ける
+func TestWebhookHook_Generic(t *testing.T) {
+	var got core.PushEvent
+	var auth string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		auth = r.Header.Get("Authorization")
+		json.NewDecoder(r.Body).Decode(&got)
+	}))
+	defer server.Close()
+
+	hook := NewWebhookHook(server.URL+"/hooks/bwsf", WebhookFormatGeneric, map[string]string{"Authorization": "Bearer token"}, time.Second, 0)
+
+	assert.NoError(t, hook.Run(testPushEvent()))
+	assert.Equal(t, "Bearer token", auth)
+	assert.Equal(t, "web", got.Project)
+	assert.Equal(t, []string{"API_KEY"}, got.Changes[".env"].Changed)
+	assert.Equal(t, "webhook "+server.URL, hook.Name())
+}
+
+// 正常系: Slack 形式は text に要約を入れ、5xx はリトライする
+func TestWebhookHook_SlackRetry(t *testing.T) {
+	var calls int32
+	var text string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&calls, 1) < 3 {
+			w.WriteHeader(http.StatusBadGateway)
+			return
+		}
+		var payload map[string]string
+		json.NewDecoder(r.Body).Decode(&payload)
+		text = payload["text"]
+	}))
+	defer server.Close()
+
+	hook := NewWebhookHook(server.URL, WebhookFormatSlack, nil, time.Second, 2)
+	hook.backoff = time.Millisecond
+
+	assert.NoError(t, hook.Run(testPushEvent()))
+	assert.Equal(t, int32(3), calls)
+	assert.Equal(t, "alice pushed web: .env +NEW ~API_KEY", text)
+}
+
+// 異常系: 4xx はリトライせず、タイムアウトはリトライ回数まで再送する
+func TestWebhookHook_Errors(t *testing.T) {
+	var calls int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&calls, 1)
+		if r.URL.Path == "/slow" {
+			time.Sleep(200 * time.Millisecond)
+			return
+		}
+		w.WriteHeader(http.StatusNotFound)
+	}))
+	defer server.Close()
+
+	hook := NewWebhookHook(server.URL+"/missing", WebhookFormatGeneric, nil, time.Second, 3)
+	hook.backoff = time.Millisecond
+	assert.ErrorContains(t, hook.Run(testPushEvent()), "404")
+	assert.Equal(t, int32(1), atomic.LoadInt32(&calls))
+
+	atomic.StoreInt32(&calls, 0)
+	hook = NewWebhookHook(server.URL+"/slow", WebhookFormatGeneric, nil, 20*time.Millisecond, 1)
+	hook.backoff = time.Millisecond
+	assert.Error(t, hook.Run(testPushEvent()))
+	assert.Equal(t, int32(2), atomic.LoadInt32(&calls))
+}
+
+// 正常系: コマンドにイベントの JSON と環境変数を渡す
+func TestCommandHook_Run(t *testing.T) {
+	out := filepath.Join(t.TempDir(), "event.json")
+	var hook core.PostPushHook = NewCommandHook(`cat > "`+out+`"; test "$BWSF_PROJECT" = web`, time.Second)
+
+	assert.NoError(t, hook.Run(testPushEvent()))
+	data, err := os.ReadFile(out)
+	assert.NoError(t, err)
+	assert.Contains(t, string(data), `"project":"web"`)
+}
+
+// =============================================================================
+// FileBwClient のテスト
+// =============================================================================
+
+// newTestFileBwClient は scrypt を軽くした FileBwClient を返します。
+func newTestFileBwClient(path, passphrase string) *FileBwClient {
+	c := NewFileBwClient(path, "dotenvs", passphrase)
+	c.workFactor = 10
+	return c
+}
+
+// 正常系: 作成・更新・名前変更した内容を暗号化して保存し、別のインスタンスから読める
+func TestFileBwClient_RoundTrip(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "store.age")
+	var bw core.BwClient = newTestFileBwClient(path, "correct horse")
+
+	folderID, err := bw.GetDotenvsFolderID()
+	require.NoError(t, err)
+	require.NoError(t, bw.CreateNoteItem(folderID, "web", `{".env":{"lines":["SECRET=hunter2"]}}`))
+	item, err := bw.GetItemByName(folderID, "web")
+	require.NoError(t, err)
+	require.NotNil(t, item)
+	require.NoError(t, bw.UpdateNoteItem(item.ID, `{".env":{"lines":["SECRET=swordfish"]}}`))
+	require.NoError(t, bw.RenameItem(item.ID, "web-app"))
+
+	raw, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.True(t, strings.HasPrefix(string(raw), "age-encryption.org/v1\n-> scrypt "))
+	assert.NotContains(t, string(raw), "swordfish")
+	info, _ := os.Stat(path)
+	assert.Equal(t, os.FileMode(0600), info.Mode().Perm())
+
+	reopened := newTestFileBwClient(path, "correct horse")
+	sameFolder, err := reopened.GetDotenvsFolderID()
+	require.NoError(t, err)
+	assert.Equal(t, folderID, sameFolder)
+	items, err := reopened.ListItemsInFolder(folderID)
+	require.NoError(t, err)
+	require.Len(t, items, 1)
+	assert.Equal(t, "web-app", items[0].Name)
+	assert.NotEmpty(t, items[0].RevisionDate)
+	got, err := reopened.GetItemByID(item.ID)
+	require.NoError(t, err)
+	assert.Contains(t, got.Notes, "swordfish")
+}
+
+// 正常系: ゴミ箱に移動したアイテムは見えなくなり、完全削除はストアから消える
+func TestFileBwClient_Delete(t *testing.T) {
+	bw := newTestFileBwClient(filepath.Join(t.TempDir(), "store.age"), "pass")
+	folderID, _ := bw.GetDotenvsFolderID()
+	require.NoError(t, bw.CreateNoteItem(folderID, "a", "{}"))
+	require.NoError(t, bw.CreateNoteItem(folderID, "b", "{}"))
+	a, _ := bw.GetItemByName(folderID, "a")
+	b, _ := bw.GetItemByName("", "b")
+
+	require.NoError(t, bw.DeleteItem(a.ID, false))
+	require.NoError(t, bw.DeleteItem(b.ID, true))
+
+	items, _ := bw.ListItemsInFolder(folderID)
+	assert.Empty(t, items)
+	trashed, _ := bw.GetItemByID(a.ID)
+	assert.Nil(t, trashed)
+	require.Len(t, bw.store.Items, 1)
+	assert.NotEmpty(t, bw.store.Items[0].DeletedDate)
+	assert.Error(t, bw.DeleteItem(b.ID, true))
+}
+
+// 異常系: パスフレーズが無ければロック扱い、違えば Unlock に失敗する
+func TestFileBwClient_Locked(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "store.age")
+	_, err := newTestFileBwClient(path, "right").GetDotenvsFolderID()
+	require.NoError(t, err)
+
+	bw := newTestFileBwClient(path, "")
+	_, err = bw.GetDotenvsFolderID()
+	assert.True(t, core.IsLockedError(err))
+
+	assert.ErrorContains(t, bw.Unlock("wrong"), "incorrect passphrase")
+	_, err = bw.GetDotenvsFolderID()
+	assert.True(t, core.IsLockedError(err), "a wrong passphrase must not be kept")
+
+	require.NoError(t, bw.Unlock("right"))
+	exists, err := bw.DotenvsFolderExists()
+	require.NoError(t, err)
+	assert.True(t, exists)
+	assert.Error(t, bw.Login("a@example.com", "right", ""))
+	assert.NoError(t, bw.Sync())
+}
+
+// =============================================================================
+// RealFileSystem.ServeNamedPipe のテスト
+// =============================================================================
+
+// 正常系: 指定回数だけ読ませた後にパイプを削除する
+func TestServeNamedPipe_Reads(t *testing.T) {
+	path := filepath.Join(t.TempDir(), ".env")
+
+	pipe, err := NewFileSystem().ServeNamedPipe(path, []byte("A=1\n"), 2)
+	require.NoError(t, err)
+
+	for i := 0; i < 2; i++ {
+		data, err := os.ReadFile(path)
+		require.NoError(t, err)
+		assert.Equal(t, "A=1\n", string(data))
+	}
+
+	select {
+	case <-pipe.Done():
+	case <-time.After(5 * time.Second):
+		t.Fatal("pipe was not removed after the last read")
+	}
+	assert.NoFileExists(t, path)
+	assert.NoError(t, pipe.Close())
+}
+
+// 正常系: 読まれる前に Close しても待たずに削除する
+func TestServeNamedPipe_Close(t *testing.T) {
+	path := filepath.Join(t.TempDir(), ".env")
+
+	pipe, err := NewFileSystem().ServeNamedPipe(path, []byte("A=1\n"), 1)
+	require.NoError(t, err)
+
+	closed := make(chan error, 1)
+	go func() { closed <- pipe.Close() }()
+	select {
+	case err := <-closed:
+		assert.NoError(t, err)
+	case <-time.After(5 * time.Second):
+		t.Fatal("Close blocked waiting for a reader")
+	}
+	_, err = os.Lstat(path)
+	assert.True(t, os.IsNotExist(err))
+}
+
+// =============================================================================
+// NewDirWatcher のテスト
+// =============================================================================
+
+// 正常系: ディレクトリ内のファイルの書き込みを通知し、Close で終了する
+func TestDirWatcher_Events(t *testing.T) {
+	dir := t.TempDir()
+	watcher, err := NewDirWatcher(dir)
+	require.NoError(t, err)
+
+	require.NoError(t, os.WriteFile(filepath.Join(dir, ".env"), []byte("A=1\n"), 0600))
+
+	deadline := time.After(5 * time.Second)
+	for seen := false; !seen; {
+		select {
+		case name := <-watcher.Events():
+			seen = name == ".env"
+		case err := <-watcher.Errors():
+			t.Fatal(err)
+		case <-deadline:
+			t.Fatal("no event for .env")
+		}
+	}
+
+	require.NoError(t, watcher.Close())
+	for range watcher.Events() {
+	}
+}
@@ -0,0 +1,28 @@
+package infra
+
+import (
+	"bwsf/src/utils"
+)
+
+// RealKeyring は core.Keyring インターフェースの実装で、OS のキーリングを利用します。
+type RealKeyring struct{}
+
+// NewKeyring は RealKeyring のインスタンスを作成します。
+func NewKeyring() *RealKeyring {
+	return &RealKeyring{}
+}
+
+// Get はキーリングから値を取得します。
+func (k *RealKeyring) Get(service, account string) (string, error) {
+	return utils.KeyringGet(service, account)
+}
+
+// Set はキーリングに値を保存します。
+func (k *RealKeyring) Set(service, account, secret string) error {
+	return utils.KeyringSet(service, account, secret)
+}
+
+// Delete はキーリングから値を削除します。
+func (k *RealKeyring) Delete(service, account string) error {
+	return utils.KeyringDelete(service, account)
+}
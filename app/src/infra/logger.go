@@ -1,16 +1,28 @@
 package infra
 
 import (
+	"io"
+	"os"
+
 	"bwsf/src/utils"
 )
 
 // RealLogger は core.Logger インターフェースの実装で、
 // utils パッケージのカラー出力関数をラップします。
-type RealLogger struct{}
+// エラー以外のメッセージは out に書き出します。
+type RealLogger struct {
+	out io.Writer
+}
 
-// NewLogger は RealLogger のインスタンスを作成します。
+// NewLogger は標準出力に書き出す RealLogger のインスタンスを作成します。
 func NewLogger() *RealLogger {
-	return &RealLogger{}
+	return &RealLogger{out: os.Stdout}
+}
+
+// NewStderrLogger はすべてのメッセージを標準エラー出力に書き出す RealLogger を作成します。
+// 標準出力をデータ（YAML やシェルスクリプト）に使うコマンド向けです。
+func NewStderrLogger() *RealLogger {
+	return &RealLogger{out: os.Stderr}
 }
 
 // Error はエラーメッセージを出力します。
@@ -20,18 +32,15 @@ func (l *RealLogger) Error(args ...interface{}) {
 
 // Info は情報メッセージを出力します。
 func (l *RealLogger) Info(args ...interface{}) {
-	utils.Infoln(args...)
+	utils.InfolnTo(l.out, args...)
 }
 
 // Success は成功メッセージを出力します。
 func (l *RealLogger) Success(args ...interface{}) {
-	utils.Successln(args...)
+	utils.SuccesslnTo(l.out, args...)
 }
 
 // Warning は警告メッセージを出力します。
 func (l *RealLogger) Warning(args ...interface{}) {
-	utils.Warningln(args...)
+	utils.WarninglnTo(l.out, args...)
 }
-
-
-
@@ -0,0 +1,117 @@
+//go:build linux
+
+package infra
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+
+	"bwsf/src/core"
+
+	"golang.org/x/sys/unix"
+)
+
+// inotifyEvents はディレクトリの監視で受け取るイベントです。
+// エディタは一時ファイルを rename して保存することが多いため、ファイルではなくディレクトリを監視します。
+const inotifyEvents = unix.IN_CLOSE_WRITE | unix.IN_MODIFY | unix.IN_CREATE | unix.IN_DELETE | unix.IN_MOVED_TO | unix.IN_MOVED_FROM
+
+// inotifyWatcher は inotify を使った core.DirWatcher インターフェースの実装です。
+type inotifyWatcher struct {
+	file   *os.File
+	events chan string
+	errors chan error
+	done   chan struct{}
+	once   sync.Once
+}
+
+// NewDirWatcher は dir の変更を inotify で監視します。
+func NewDirWatcher(dir string) (core.DirWatcher, error) {
+	fd, err := unix.InotifyInit1(unix.IN_CLOEXEC | unix.IN_NONBLOCK)
+	if err != nil {
+		return nil, fmt.Errorf("failed to start inotify: %w", err)
+	}
+	if _, err := unix.InotifyAddWatch(fd, dir, inotifyEvents); err != nil {
+		unix.Close(fd)
+		return nil, fmt.Errorf("failed to watch %s: %w", dir, err)
+	}
+
+	// ノンブロッキングの fd はランタイムのポーラーで読むため、Close で Read が戻る
+	w := &inotifyWatcher{
+		file:   os.NewFile(uintptr(fd), "inotify"),
+		events: make(chan string),
+		errors: make(chan error, 1),
+		done:   make(chan struct{}),
+	}
+	go w.read()
+	return w, nil
+}
+
+func (w *inotifyWatcher) read() {
+	defer close(w.events)
+
+	buf := make([]byte, 64*(unix.SizeofInotifyEvent+unix.NAME_MAX+1))
+	for {
+		n, err := w.file.Read(buf)
+		if err != nil {
+			if !errors.Is(err, os.ErrClosed) {
+				w.errors <- err
+			}
+			return
+		}
+
+		for offset := 0; offset+unix.SizeofInotifyEvent <= n; {
+			// struct inotify_event { int wd; uint32_t mask; uint32_t cookie; uint32_t len; char name[]; }
+			mask := binary.NativeEndian.Uint32(buf[offset+4:])
+			nameLen := int(binary.NativeEndian.Uint32(buf[offset+12:]))
+			nameStart := offset + unix.SizeofInotifyEvent
+			offset = nameStart + nameLen
+			if offset > n {
+				break
+			}
+
+			var name string
+			switch {
+			case mask&unix.IN_IGNORED != 0:
+				w.errors <- errors.New("the directory was removed")
+				return
+			case mask&unix.IN_Q_OVERFLOW != 0:
+				// イベントを取りこぼしたため、.env を変更として扱い全体を確認し直す
+				name = ".env"
+			default:
+				name = strings.TrimRight(string(buf[nameStart:offset]), "\x00")
+			}
+			if name == "" {
+				continue
+			}
+			select {
+			case w.events <- name:
+			case <-w.done:
+				return
+			}
+		}
+	}
+}
+
+// Events は変更されたファイル名を送るチャネルを返します。
+func (w *inotifyWatcher) Events() <-chan string {
+	return w.events
+}
+
+// Errors は監視のエラーを送るチャネルを返します。
+func (w *inotifyWatcher) Errors() <-chan error {
+	return w.errors
+}
+
+// Close は監視を止めます。
+func (w *inotifyWatcher) Close() error {
+	var err error
+	w.once.Do(func() {
+		close(w.done)
+		err = w.file.Close()
+	})
+	return err
+}
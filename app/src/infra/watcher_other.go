@@ -0,0 +1,121 @@
+//go:build !linux
+
+package infra
+
+import (
+	"os"
+	"sync"
+	"time"
+
+	"bwsf/src/core"
+)
+
+// watchPollInterval はディレクトリを走査する間隔です。
+const watchPollInterval = 500 * time.Millisecond
+
+// pollWatcher は inotify のない環境（macOS など）でディレクトリを定期的に走査する core.DirWatcher の実装です。
+type pollWatcher struct {
+	dir    string
+	events chan string
+	errors chan error
+	done   chan struct{}
+	once   sync.Once
+}
+
+// fileStamp は変更の判定に使うファイルの更新時刻とサイズです。
+type fileStamp struct {
+	modTime time.Time
+	size    int64
+}
+
+// NewDirWatcher は dir の変更を定期的な走査で監視します。
+func NewDirWatcher(dir string) (core.DirWatcher, error) {
+	stamps, err := scanDir(dir)
+	if err != nil {
+		return nil, err
+	}
+	w := &pollWatcher{
+		dir:    dir,
+		events: make(chan string),
+		errors: make(chan error, 1),
+		done:   make(chan struct{}),
+	}
+	go w.poll(stamps)
+	return w, nil
+}
+
+func (w *pollWatcher) poll(stamps map[string]fileStamp) {
+	defer close(w.events)
+
+	ticker := time.NewTicker(watchPollInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-w.done:
+			return
+		case <-ticker.C:
+		}
+
+		current, err := scanDir(w.dir)
+		if err != nil {
+			w.errors <- err
+			return
+		}
+		var changed []string
+		for name, stamp := range current {
+			if old, ok := stamps[name]; !ok || old != stamp {
+				changed = append(changed, name)
+			}
+		}
+		for name := range stamps {
+			if _, ok := current[name]; !ok {
+				changed = append(changed, name)
+			}
+		}
+		stamps = current
+
+		for _, name := range changed {
+			select {
+			case w.events <- name:
+			case <-w.done:
+				return
+			}
+		}
+	}
+}
+
+// scanDir はディレクトリ内のファイルの更新時刻とサイズを返します。
+func scanDir(dir string) (map[string]fileStamp, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+	stamps := make(map[string]fileStamp, len(entries))
+	for _, entry := range entries {
+		if entry.IsDir() {
+			continue
+		}
+		info, err := entry.Info()
+		if err != nil {
+			continue
+		}
+		stamps[entry.Name()] = fileStamp{modTime: info.ModTime(), size: info.Size()}
+	}
+	return stamps, nil
+}
+
+// Events は変更されたファイル名を送るチャネルを返します。
+func (w *pollWatcher) Events() <-chan string {
+	return w.events
+}
+
+// Errors は監視のエラーを送るチャネルを返します。
+func (w *pollWatcher) Errors() <-chan error {
+	return w.errors
+}
+
+// Close は監視を止めます。
+func (w *pollWatcher) Close() error {
+	w.once.Do(func() { close(w.done) })
+	return nil
+}
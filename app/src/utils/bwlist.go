@@ -24,8 +24,9 @@ type Folder struct {
 
 // Item represents a Bitwarden item
 type Item struct {
-	ID   string `json:"id"`
-	Name string `json:"name"`
+	ID           string `json:"id"`
+	Name         string `json:"name"`
+	RevisionDate string `json:"revisionDate"`
 }
 
 // resolveConfiguredFolderName loads folder_name from config (default: "dotenvs").
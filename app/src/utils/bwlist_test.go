@@ -1,11 +1,15 @@
 package utils
 
 import (
+	"os"
+	"strings"
 	"testing"
+	"time"
 
 	"bwsf/src/core"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 // =============================================================================
@@ -71,3 +75,169 @@ func TestItem_Struct(t *testing.T) {
 	assert.Equal(t, "item-456", item.ID)
 	assert.Equal(t, "my-project", item.Name)
 }
+
+// =============================================================================
+// GetFolderID / CreateFolder のテスト（偽の bw を使用）
+// =============================================================================
+
+// 正常系: 設定したフォルダー名の ID を返す
+func TestGetFolderID_FakeBw(t *testing.T) {
+	bw := newFakeBw(t)
+	bw.addFolder("other")
+	id := bw.addFolder("dotenvs")
+
+	got, err := GetDotenvsFolderID()
+	require.NoError(t, err)
+	assert.Equal(t, id, got)
+
+	exists, err := FolderExists("missing")
+	require.NoError(t, err)
+	assert.False(t, exists)
+}
+
+// 正常系: 応答が遅くても結果を待つ
+func TestGetFolderID_SlowResponse(t *testing.T) {
+	bw := newFakeBw(t)
+	id := bw.addFolder("dotenvs")
+	bw.update(func(state *fakeBwState) { state.Delay = 200 * time.Millisecond })
+
+	got, err := GetFolderID("dotenvs")
+	require.NoError(t, err)
+	assert.Equal(t, id, got)
+}
+
+// 異常系: ロック中・未ログイン・JSON 以外の出力・コマンドの失敗
+func TestGetFolderID_Failures(t *testing.T) {
+	bw := newFakeBw(t)
+	bw.addFolder("dotenvs")
+
+	bw.update(func(state *fakeBwState) { state.Status = "locked" })
+	_, err := GetFolderID("dotenvs")
+	assert.True(t, core.IsLockedError(err), err)
+
+	bw.update(func(state *fakeBwState) { state.Status = "unauthenticated" })
+	_, err = GetFolderID("dotenvs")
+	assert.ErrorContains(t, err, "You are not logged in")
+
+	bw.update(func(state *fakeBwState) { state.Status = "unlocked"; state.Noise = "mac failed.\n" })
+	_, err = GetFolderID("dotenvs")
+	assert.ErrorContains(t, err, "not JSON")
+
+	bw.update(func(state *fakeBwState) { state.Noise = "" })
+	bw.fail("list folders", "")
+	_, err = GetFolderID("dotenvs")
+	assert.ErrorContains(t, err, "failed to list folders: exit status 1")
+}
+
+// 異常系: bw がインストールされていない
+func TestGetFolderID_NotInstalled(t *testing.T) {
+	t.Setenv("PATH", t.TempDir())
+
+	_, err := GetFolderID("dotenvs")
+	assert.EqualError(t, err, "bw command is not installed")
+}
+
+// 正常系: エンコードした JSON でフォルダーを作成して同期する
+func TestCreateFolder_FakeBw(t *testing.T) {
+	bw := newFakeBw(t)
+
+	require.NoError(t, CreateFolder("team-envs"))
+
+	state := bw.state()
+	require.Len(t, state.Folders, 1)
+	assert.Equal(t, "team-envs", state.Folders[0].Name)
+	assert.Equal(t, "sync", state.Calls[len(state.Calls)-1])
+
+	bw.fail("create folder", "Folder name is invalid.")
+	assert.ErrorContains(t, CreateFolder("broken"), "Folder name is invalid.")
+}
+
+// =============================================================================
+// ListItemsInFolder のテスト（偽の bw を使用）
+// =============================================================================
+
+// 正常系: フォルダー内のアイテムのみ返す
+func TestListItemsInFolder_FakeBw(t *testing.T) {
+	bw := newFakeBw(t)
+	folderID := bw.addFolder("dotenvs")
+	id := bw.addNote(folderID, "web", "{}")
+	bw.addNote("", "personal", "{}")
+
+	items, err := ListItemsInFolder(folderID)
+	require.NoError(t, err)
+	assert.Equal(t, []Item{{ID: id, Name: "web", RevisionDate: fakeBwRevision}}, items)
+}
+
+// 異常系: ロック中・JSON 以外の出力
+func TestListItemsInFolder_Failures(t *testing.T) {
+	bw := newFakeBw(t)
+	folderID := bw.addFolder("dotenvs")
+
+	bw.update(func(state *fakeBwState) { state.Noise = "Syncing... " })
+	_, err := ListItemsInFolder(folderID)
+	assert.ErrorContains(t, err, "unexpected output from bw list items (not JSON)")
+
+	bw.update(func(state *fakeBwState) { state.Noise = ""; state.Status = "locked" })
+	_, err = ListItemsInFolder(folderID)
+	assert.True(t, core.IsLockedError(err), err)
+}
+
+// =============================================================================
+// BwUnlock のテスト（偽の bw を使用）
+// =============================================================================
+
+// 正常系: --passwordfile と --raw でセッションキーを取得する
+func TestBwUnlock_Raw(t *testing.T) {
+	bw := newFakeBw(t)
+	bw.update(func(state *fakeBwState) { state.Status = "locked" })
+
+	ok, msg := BwUnlock("master-password")
+
+	require.True(t, ok, msg)
+	assert.Equal(t, fakeBwSession, os.Getenv("BW_SESSION"))
+	assert.Equal(t, "unlocked", bw.state().Status)
+}
+
+// 正常系: --raw が何も出力しない場合は export BW_SESSION="..." から取り出す
+func TestBwUnlock_ExportFallback(t *testing.T) {
+	bw := newFakeBw(t)
+	bw.update(func(state *fakeBwState) { state.Status = "locked"; state.UnlockNoRaw = true })
+
+	ok, msg := BwUnlock("master-password")
+
+	require.True(t, ok, msg)
+	assert.Equal(t, fakeBwSession, os.Getenv("BW_SESSION"))
+	assert.Contains(t, bw.calls(), "status")
+}
+
+// 正常系: --passwordfile が使えない場合は --passwordenv で再試行する
+func TestBwUnlock_PasswordEnvFallback(t *testing.T) {
+	bw := newFakeBw(t)
+	bw.update(func(state *fakeBwState) { state.Status = "locked" })
+	bw.fail("unlock --passwordfile", "error: unknown option '--passwordfile'")
+
+	ok, msg := BwUnlock("master-password")
+
+	require.True(t, ok, msg)
+	assert.Equal(t, fakeBwSession, os.Getenv("BW_SESSION"))
+	assert.Contains(t, bw.calls(), "unlock --raw --passwordenv BW_PASSWORD")
+}
+
+// 異常系: パスワードが違う場合はすべての方法を試して失敗する
+func TestBwUnlock_WrongPassword(t *testing.T) {
+	bw := newFakeBw(t)
+	bw.update(func(state *fakeBwState) { state.Status = "locked" })
+
+	ok, msg := BwUnlock("wrong")
+
+	assert.False(t, ok)
+	assert.Contains(t, msg, "Invalid master password.")
+	assert.Equal(t, "locked", bw.state().Status)
+	var unlocks int
+	for _, call := range bw.calls() {
+		if strings.HasPrefix(call, "unlock") {
+			unlocks++
+		}
+	}
+	assert.Equal(t, 4, unlocks)
+}
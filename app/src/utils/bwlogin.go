@@ -43,7 +43,8 @@ func BwLogin(email, password, serverURL string) (bool, string) {
 			if strings.Contains(errorMsg, "Logout required") {
 				logoutCmd := exec.Command("bw", "logout")
 				logoutCmd.Run() // Ignore errors
-				// Retry config
+				// Retry config (an exec.Cmd cannot be run twice)
+				configCmd = exec.Command("bw", "config", "server", serverURL)
 				configOutput, err = configCmd.CombinedOutput()
 				if err != nil {
 					errorMsg = strings.TrimSpace(string(configOutput))
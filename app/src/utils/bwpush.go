@@ -4,6 +4,7 @@ import (
 	"bytes"
 	"encoding/json"
 	"fmt"
+	"os"
 	"os/exec"
 	"strings"
 )
@@ -24,15 +25,33 @@ type SecureNote struct {
 
 // FullItem represents a full Bitwarden item (for getting item details)
 type FullItem struct {
-	ID         string     `json:"id"`
-	Name       string     `json:"name"`
-	Type       int        `json:"type"`
-	Notes      string     `json:"notes"`
-	FolderID   string     `json:"folderId"`
-	SecureNote SecureNote `json:"secureNote"`
+	ID           string      `json:"id"`
+	Name         string      `json:"name"`
+	Type         int         `json:"type"`
+	Notes        string      `json:"notes"`
+	FolderID     string      `json:"folderId"`
+	RevisionDate string      `json:"revisionDate,omitempty"`
+	SecureNote   SecureNote  `json:"secureNote"`
+	Login        *LoginData  `json:"login,omitempty"`
+	Fields       []ItemField `json:"fields,omitempty"`
 }
 
-// GetItemByName finds an item by name in the specified folder
+// LoginData represents the login part of a Bitwarden login item
+type LoginData struct {
+	Username string `json:"username"`
+	Password string `json:"password"`
+	TOTP     string `json:"totp"`
+}
+
+// ItemField represents a custom field of a Bitwarden item
+type ItemField struct {
+	Name  string `json:"name"`
+	Value string `json:"value"`
+	Type  int    `json:"type"`
+}
+
+// GetItemByName finds an item by name in the specified folder.
+// An empty folderID searches the whole vault.
 func GetItemByName(folderID, itemName string) (*FullItem, error) {
 	// Check if bw command exists
 	_, err := exec.LookPath("bw")
@@ -52,7 +71,7 @@ func GetItemByName(folderID, itemName string) (*FullItem, error) {
 		if syncErrMsg != "" && !strings.Contains(syncErrMsg, "already synced") {
 			// Only log if it's not just "already synced" message
 			StopSpinner()
-			fmt.Printf("[INFO] Sync warning: %s (continuing anyway)\n", syncErrMsg)
+			fmt.Fprintf(os.Stderr, "[INFO] Sync warning: %s (continuing anyway)\n", syncErrMsg)
 		}
 	}
 
@@ -60,7 +79,11 @@ func GetItemByName(folderID, itemName string) (*FullItem, error) {
 	UpdateSpinnerMessage("Fetching items...")
 
 	// Execute bw list items command with folder filter
-	cmd := exec.Command("bw", "list", "items", "--folderid", folderID)
+	listArgs := []string{"list", "items", "--folderid", folderID}
+	if folderID == "" {
+		listArgs = []string{"list", "items", "--search", itemName}
+	}
+	cmd := exec.Command("bw", listArgs...)
 	output, err := cmd.CombinedOutput()
 	if err != nil {
 		errorMsg := strings.TrimSpace(string(output))
@@ -105,6 +128,34 @@ func GetItemByName(folderID, itemName string) (*FullItem, error) {
 	return nil, nil // Item not found, but no error
 }
 
+// BwSync syncs the local vault with the server using bw sync
+func BwSync() error {
+	// Check if bw command exists
+	_, err := exec.LookPath("bw")
+	if err != nil {
+		return fmt.Errorf("bw command is not installed")
+	}
+
+	// Start spinner
+	StartSpinner("Syncing...")
+	defer StopSpinner()
+
+	cmd := exec.Command("bw", "sync")
+	output, err := cmd.CombinedOutput()
+	outputStr := strings.TrimSpace(string(output))
+	if strings.Contains(outputStr, "Master password") || strings.Contains(outputStr, "master password") ||
+		strings.Contains(strings.ToLower(outputStr), "vault is locked") {
+		return ErrBitwardenLocked
+	}
+	if err != nil {
+		if outputStr == "" {
+			outputStr = err.Error()
+		}
+		return fmt.Errorf("failed to sync: %s", outputStr)
+	}
+	return nil
+}
+
 // GetItemByID retrieves a full item by ID using bw get item
 func GetItemByID(itemID string) (*FullItem, error) {
 	// Check if bw command exists
@@ -267,6 +318,102 @@ func UpdateNoteItem(itemID, notes string) error {
 	StartSpinner("Updating item...")
 	defer StopSpinner()
 
+	return editItem(itemID, func(item map[string]interface{}) {
+		item["notes"] = notes
+	})
+}
+
+// UpdateNoteItemFields updates an existing note item's notes and sets hidden custom fields in the same edit.
+// A field with the same name is replaced, so Bitwarden moves its old value into the item's password history.
+func UpdateNoteItemFields(itemID, notes string, hidden []ItemField) error {
+	// Check if bw command exists
+	_, err := exec.LookPath("bw")
+	if err != nil {
+		return fmt.Errorf("bw command is not installed")
+	}
+
+	// Start spinner
+	StartSpinner("Updating item...")
+	defer StopSpinner()
+
+	return editItem(itemID, func(item map[string]interface{}) {
+		item["notes"] = notes
+		item["fields"] = setHiddenFields(item["fields"], hidden)
+	})
+}
+
+// setHiddenFields returns the fields of a bw item JSON with hidden set as hidden fields (type 1), replacing fields of the same name
+func setHiddenFields(existing interface{}, hidden []ItemField) []interface{} {
+	fields, _ := existing.([]interface{})
+	for _, field := range hidden {
+		value := map[string]interface{}{"name": field.Name, "value": field.Value, "type": 1, "linkedId": nil}
+		replaced := false
+		for i, f := range fields {
+			if m, ok := f.(map[string]interface{}); ok && m["name"] == field.Name {
+				fields[i] = value
+				replaced = true
+				break
+			}
+		}
+		if !replaced {
+			fields = append(fields, value)
+		}
+	}
+	return fields
+}
+
+// RenameItem changes the name of an existing item
+func RenameItem(itemID, newName string) error {
+	// Check if bw command exists
+	_, err := exec.LookPath("bw")
+	if err != nil {
+		return fmt.Errorf("bw command is not installed")
+	}
+
+	// Start spinner
+	StartSpinner("Renaming item...")
+	defer StopSpinner()
+
+	return editItem(itemID, func(item map[string]interface{}) {
+		item["name"] = newName
+	})
+}
+
+// DeleteItem moves an item to the trash, or deletes it permanently when permanent is true
+func DeleteItem(itemID string, permanent bool) error {
+	// Check if bw command exists
+	_, err := exec.LookPath("bw")
+	if err != nil {
+		return fmt.Errorf("bw command is not installed")
+	}
+
+	// Start spinner
+	StartSpinner("Deleting item...")
+	defer StopSpinner()
+
+	args := []string{"delete", "item", itemID}
+	if permanent {
+		args = append(args, "--permanent")
+	}
+
+	cmd := exec.Command("bw", args...)
+	output, err := cmd.CombinedOutput()
+	if err != nil {
+		errorMsg := strings.TrimSpace(string(output))
+		if errorMsg == "" {
+			errorMsg = err.Error()
+		}
+		if strings.Contains(errorMsg, "Master password") || strings.Contains(errorMsg, "master password") {
+			return ErrBitwardenLocked
+		}
+		return fmt.Errorf("failed to delete item: %s", errorMsg)
+	}
+
+	return nil
+}
+
+// editItem fetches an item, applies mutate to its JSON representation and saves it with bw edit item
+func editItem(itemID string, mutate func(item map[string]interface{})) error {
 	// Get the existing item
 	getCmd := exec.Command("bw", "get", "item", itemID)
 	output, err := getCmd.CombinedOutput()
@@ -289,8 +436,7 @@ func UpdateNoteItem(itemID, notes string) error {
 		return fmt.Errorf("failed to parse item JSON: %w", err)
 	}
 
-	// Update notes field
-	item["notes"] = notes
+	mutate(item)
 
 	// Marshal updated item
 	updatedJSON, err := json.Marshal(item)
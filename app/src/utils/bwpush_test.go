@@ -3,7 +3,10 @@ package utils
 import (
 	"testing"
 
+	"bwsf/src/core"
+
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 // =============================================================================
@@ -62,8 +65,7 @@ func TestSecureNote_Struct(t *testing.T) {
 // GetItemByName / GetItemByID / CreateNoteItem / UpdateNoteItem の構造テスト
 // =============================================================================
 
-// 注: これらの関数は実際の bw コマンドを呼び出すため、
-// 単体テストではモック化が必要。ここでは関数シグネチャの確認のみ
+// 注: bw コマンドを呼び出す動作は、後半の偽の bw を使ったテストで確認する
 
 // 正常系: GetItemByName の関数シグネチャ確認
 func TestGetItemByName_Signature(t *testing.T) {
@@ -89,7 +91,172 @@ func TestUpdateNoteItem_Signature(t *testing.T) {
 	assert.NotNil(t, fn)
 }
 
+// =============================================================================
+// GetItemByName / GetItemByID / BwSync のテスト（偽の bw を使用）
+// =============================================================================
+
+// 正常系: 同期してからフォルダー内を名前で探し、bw get item で詳細を取得する
+func TestGetItemByName_FakeBw(t *testing.T) {
+	bw := newFakeBw(t)
+	folderID := bw.addFolder("dotenvs")
+	id := bw.addNote(folderID, "web", `{".env":{"lines":["A=1"]}}`)
+
+	item, err := GetItemByName(folderID, "web")
+	require.NoError(t, err)
+	require.NotNil(t, item)
+	assert.Equal(t, id, item.ID)
+	assert.Equal(t, `{".env":{"lines":["A=1"]}}`, item.Notes)
+	assert.Equal(t, []string{"sync", "list items --folderid " + folderID, "get item " + id}, bw.calls())
+
+	missing, err := GetItemByName(folderID, "api")
+	require.NoError(t, err)
+	assert.Nil(t, missing)
+}
+
+// 正常系: フォルダー ID が空なら保管庫全体を検索し、同期の失敗は続行する
+func TestGetItemByName_SearchVault(t *testing.T) {
+	bw := newFakeBw(t)
+	id := bw.addNote("", "shared-db", "{}")
+	bw.fail("sync", "Sync failed: network error")
+
+	item, err := GetItemByName("", "shared-db")
+	require.NoError(t, err)
+	require.NotNil(t, item)
+	assert.Equal(t, id, item.ID)
+	assert.Contains(t, bw.calls(), "list items --search shared-db")
+}
+
+// 異常系: ロック中・JSON 以外の出力・存在しない ID
+func TestGetItemByID_Failures(t *testing.T) {
+	bw := newFakeBw(t)
+	id := bw.addNote("", "web", "{}")
+
+	bw.update(func(state *fakeBwState) { state.Noise = "Warning: update available\n" })
+	_, err := GetItemByID(id)
+	assert.ErrorContains(t, err, "not JSON")
+
+	bw.update(func(state *fakeBwState) { state.Noise = "" })
+	_, err = GetItemByID("item-999")
+	assert.ErrorContains(t, err, "failed to get item: Not found.")
+
+	bw.update(func(state *fakeBwState) { state.Status = "locked" })
+	_, err = GetItemByID(id)
+	assert.True(t, core.IsLockedError(err), err)
+}
+
+// 異常系: ロック中の同期は ErrBitwardenLocked、その他の失敗は出力を含める
+func TestBwSync_FakeBw(t *testing.T) {
+	bw := newFakeBw(t)
+	require.NoError(t, BwSync())
+
+	bw.update(func(state *fakeBwState) { state.Status = "locked" })
+	assert.ErrorIs(t, BwSync(), ErrBitwardenLocked)
+
+	bw.update(func(state *fakeBwState) { state.Status = "unlocked" })
+	bw.fail("sync", "Sync failed: 503")
+	assert.EqualError(t, BwSync(), "failed to sync: Sync failed: 503")
+}
+
+// =============================================================================
+// CreateNoteItem のテスト（偽の bw を使用）
+// =============================================================================
+
+// 正常系: テンプレートを取得し、bw encode してから作成する
+func TestCreateNoteItem_Template(t *testing.T) {
+	bw := newFakeBw(t)
+	folderID := bw.addFolder("dotenvs")
+
+	require.NoError(t, CreateNoteItem(folderID, "web", `{"lines":["A=1"]}`))
+
+	state := bw.state()
+	require.Len(t, state.Items, 1)
+	assert.Equal(t, "web", state.Items[0].Name)
+	assert.Equal(t, 2, state.Items[0].Type)
+	assert.Equal(t, folderID, state.Items[0].FolderID)
+	assert.Equal(t, `{"lines":["A=1"]}`, state.Items[0].Notes)
+	assert.Equal(t, []string{"get template item", "encode"}, state.Calls[:2])
+}
+
+// 正常系: テンプレートまたは encode が失敗した場合は JSON を標準入力で渡して作成する
+func TestCreateNoteItem_DirectFallback(t *testing.T) {
+	for _, command := range []string{"get template", "encode"} {
+		t.Run(command, func(t *testing.T) {
+			bw := newFakeBw(t)
+			bw.fail(command, "unsupported")
 
+			require.NoError(t, CreateNoteItem("folder-1", "web", "notes"))
 
+			state := bw.state()
+			require.Len(t, state.Items, 1)
+			assert.Equal(t, "notes", state.Items[0].Notes)
+			assert.Equal(t, "create item", state.Calls[len(state.Calls)-1])
+		})
+	}
+}
 
+// 異常系: 作成に失敗した場合は bw の出力を含める
+func TestCreateNoteItem_Failure(t *testing.T) {
+	bw := newFakeBw(t)
+	bw.fail("create item", "Organization ID is required.")
+
+	err := CreateNoteItem("folder-1", "web", "notes")
+
+	assert.EqualError(t, err, "failed to create item: Organization ID is required.")
+}
 
+// =============================================================================
+// UpdateNoteItem / RenameItem / DeleteItem のテスト（偽の bw を使用）
+// =============================================================================
+
+// 正常系: 既存のフィールドを保ったまま notes と名前を更新する
+func TestUpdateNoteItem_FakeBw(t *testing.T) {
+	bw := newFakeBw(t)
+	id := bw.addNote("folder-1", "web", "old")
+
+	require.NoError(t, UpdateNoteItem(id, "new"))
+	require.NoError(t, RenameItem(id, "web-prod"))
+
+	item := bw.item(id)
+	require.NotNil(t, item)
+	assert.Equal(t, "new", item.Notes)
+	assert.Equal(t, "web-prod", item.Name)
+	assert.Equal(t, "folder-1", item.FolderID)
+}
+
+// 正常系: encode が失敗した場合は JSON を標準入力で渡して編集する
+func TestUpdateNoteItem_DirectFallback(t *testing.T) {
+	bw := newFakeBw(t)
+	id := bw.addNote("folder-1", "web", "old")
+	bw.fail("encode", "unsupported")
+
+	require.NoError(t, UpdateNoteItem(id, "new"))
+
+	assert.Equal(t, "new", bw.item(id).Notes)
+	assert.Contains(t, bw.calls(), "edit item "+id)
+}
+
+// 異常系: 取得・編集の失敗
+func TestUpdateNoteItem_Failures(t *testing.T) {
+	bw := newFakeBw(t)
+	id := bw.addNote("folder-1", "web", "old")
+
+	assert.ErrorContains(t, UpdateNoteItem("item-999", "new"), "failed to get item: Not found.")
+
+	bw.fail("edit item", "The item was modified by another client.")
+	assert.EqualError(t, UpdateNoteItem(id, "new"), "failed to update item: The item was modified by another client.")
+	assert.Equal(t, "old", bw.item(id).Notes)
+}
+
+// 正常系 / 異常系: 削除と、ロック中の削除
+func TestDeleteItem_FakeBw(t *testing.T) {
+	bw := newFakeBw(t)
+	id := bw.addNote("folder-1", "web", "{}")
+
+	require.NoError(t, DeleteItem(id, true))
+	assert.Nil(t, bw.item(id))
+	assert.Contains(t, bw.calls(), "delete item "+id+" --permanent")
+
+	other := bw.addNote("folder-1", "api", "{}")
+	bw.update(func(state *fakeBwState) { state.Status = "locked" })
+	assert.ErrorIs(t, DeleteItem(other, false), ErrBitwardenLocked)
+}
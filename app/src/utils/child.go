@@ -0,0 +1,34 @@
+package utils
+
+import (
+	"errors"
+	"os"
+	"os/exec"
+)
+
+// StartCommand starts args[0] with the terminal's stdin, stdout and stderr and env in addition to the current environment.
+// It runs in the same process group, so Ctrl-C at the terminal reaches it directly.
+func StartCommand(args []string, env []string) (*exec.Cmd, error) {
+	cmd := exec.Command(args[0], args[1:]...)
+	cmd.Stdin = os.Stdin
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	cmd.Env = append(os.Environ(), env...)
+	if err := cmd.Start(); err != nil {
+		return nil, err
+	}
+	return cmd, nil
+}
+
+// ExitCode returns the exit status to propagate for the error returned by Wait: 0 on success,
+// the child's own code when it exited, and 1 otherwise (e.g. killed by a signal).
+func ExitCode(err error) int {
+	if err == nil {
+		return 0
+	}
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) && exitErr.ExitCode() >= 0 {
+		return exitErr.ExitCode()
+	}
+	return 1
+}
@@ -0,0 +1,33 @@
+package utils
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// =============================================================================
+// StartCommand / ExitCode のテスト
+// =============================================================================
+
+// 正常系: 環境変数を追加して起動し、終了コードを返す
+func TestStartCommand(t *testing.T) {
+	out := filepath.Join(t.TempDir(), "out")
+
+	cmd, err := StartCommand([]string{"sh", "-c", `echo "$BWSF_EPHEMERAL_DIR" > "$OUT"; exit 3`}, []string{"OUT=" + out, "BWSF_EPHEMERAL_DIR=/dev/shm/x"})
+	require.NoError(t, err)
+
+	assert.Equal(t, 3, ExitCode(cmd.Wait()))
+	data, _ := os.ReadFile(out)
+	assert.Equal(t, "/dev/shm/x\n", string(data))
+	assert.Equal(t, 0, ExitCode(nil))
+}
+
+// 異常系: 存在しないコマンド
+func TestStartCommand_NotFound(t *testing.T) {
+	_, err := StartCommand([]string{"bwsf-no-such-command"}, nil)
+	assert.Error(t, err)
+}
@@ -2,6 +2,7 @@ package utils
 
 import (
 	"fmt"
+	"io"
 	"os"
 )
 
@@ -69,9 +70,14 @@ func Success(format string, args ...interface{}) {
 
 // Successln prints a success message in green to stdout with a newline
 func Successln(args ...interface{}) {
+	SuccesslnTo(os.Stdout, args...)
+}
+
+// SuccesslnTo prints a success message in green to w with a newline
+func SuccesslnTo(w io.Writer, args ...interface{}) {
 	message := fmt.Sprint(args...)
 	colored := colorize(message, colorGreen)
-	fmt.Println(colored)
+	fmt.Fprintln(w, colored)
 }
 
 // Warning prints a warning message in yellow to stdout
@@ -83,9 +89,14 @@ func Warning(format string, args ...interface{}) {
 
 // Warningln prints a warning message in yellow to stdout with a newline
 func Warningln(args ...interface{}) {
+	WarninglnTo(os.Stdout, args...)
+}
+
+// WarninglnTo prints a warning message in yellow to w with a newline
+func WarninglnTo(w io.Writer, args ...interface{}) {
 	message := fmt.Sprint(args...)
 	colored := colorize(message, colorYellow)
-	fmt.Println(colored)
+	fmt.Fprintln(w, colored)
 }
 
 // Info prints an info message in cyan to stdout
@@ -97,16 +108,26 @@ func Info(format string, args ...interface{}) {
 
 // Infoln prints an info message in cyan to stdout with a newline
 func Infoln(args ...interface{}) {
+	InfolnTo(os.Stdout, args...)
+}
+
+// InfolnTo prints an info message in cyan to w with a newline
+func InfolnTo(w io.Writer, args ...interface{}) {
 	message := fmt.Sprint(args...)
 	colored := colorize(message, colorCyan)
-	fmt.Println(colored)
+	fmt.Fprintln(w, colored)
 }
 
 // Question prints a question message in magenta to stdout
 func Question(format string, args ...interface{}) {
+	QuestionTo(os.Stdout, format, args...)
+}
+
+// QuestionTo prints a question message in magenta to w
+func QuestionTo(w io.Writer, format string, args ...interface{}) {
 	message := fmt.Sprintf(format, args...)
 	colored := colorize(message, colorMagenta)
-	fmt.Print(colored)
+	fmt.Fprint(w, colored)
 }
 
 // Questionln prints a question message in magenta to stdout with a newline
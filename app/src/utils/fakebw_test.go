@@ -0,0 +1,493 @@
+package utils
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+// =============================================================================
+// 偽の bw コマンド
+// =============================================================================
+//
+// テストバイナリ自身を "bw" という名前で PATH に置き、exec 層から呼ばれたときは
+// fakeBwMain として動作させます。状態は FAKE_BW_STATE の JSON ファイルに保存するため、
+// 呼び出しをまたいでフォルダーやアイテムが保持されます。
+
+// fakeBwStateEnv は状態ファイルのパスを渡す環境変数です。
+const fakeBwStateEnv = "FAKE_BW_STATE"
+
+// fakeBwSession は unlock が返すセッションキーです（BwUnlock の判定に合わせ 40 文字以上）。
+const fakeBwSession = "ZmFrZS1zZXNzaW9uLWtleS1mb3ItYndzZi10ZXN0cw=="
+
+// fakeBwState は偽の bw が保持する保管庫とテストから注入する障害です。
+type fakeBwState struct {
+	Status   string     `json:"status"` // "unlocked", "locked", "unauthenticated"
+	Email    string     `json:"email"`
+	Password string     `json:"password"`
+	Server   string     `json:"server"`
+	Folders  []Folder   `json:"folders"`
+	Items    []FullItem `json:"items"`
+	NextID   int        `json:"nextId"`
+
+	// 障害の注入
+	Failures    map[string]string `json:"failures,omitempty"`    // コマンド（"list items" や "unlock --passwordfile" など）→ 終了コード 1 で出力するメッセージ
+	Noise       string            `json:"noise,omitempty"`       // 一覧・取得の出力の前に混ぜる JSON 以外の文字列
+	Delay       time.Duration     `json:"delay,omitempty"`       // 各コマンドの応答を遅らせる時間
+	UnlockNoRaw bool              `json:"unlockNoRaw,omitempty"` // unlock --raw が何も出力しない（古い bw の挙動）
+
+	Calls []string `json:"calls"` // 実行されたコマンドライン
+}
+
+// TestMain は "bw" として起動された場合に偽の bw として動作します。
+func TestMain(m *testing.M) {
+	if filepath.Base(os.Args[0]) == "bw" {
+		os.Exit(fakeBwMain(os.Args[1:], os.Stdin, os.Stdout))
+	}
+	os.Exit(m.Run())
+}
+
+// fakeBw はテストから偽の bw の状態を操作するハンドルです。
+type fakeBw struct {
+	t    *testing.T
+	path string
+}
+
+// newFakeBw は偽の bw を PATH の先頭に置き、ログイン・アンロック済みの空の保管庫を用意します。
+// HOME も一時ディレクトリに切り替えるため、実際の設定ファイルは読みません。
+func newFakeBw(t *testing.T) *fakeBw {
+	t.Helper()
+	exe, err := os.Executable()
+	require.NoError(t, err)
+
+	dir := t.TempDir()
+	require.NoError(t, os.Symlink(exe, filepath.Join(dir, "bw")))
+	t.Setenv("PATH", dir+string(os.PathListSeparator)+os.Getenv("PATH"))
+	t.Setenv("HOME", t.TempDir())
+	t.Setenv("BW_SESSION", "")
+	t.Setenv("NO_COLOR", "1")
+
+	f := &fakeBw{t: t, path: filepath.Join(dir, "state.json")}
+	t.Setenv(fakeBwStateEnv, f.path)
+	f.save(&fakeBwState{Status: "unlocked", Email: "user@example.com", Password: "master-password", Server: "https://vault.bitwarden.com"})
+	return f
+}
+
+// state は現在の状態を読み込みます。
+func (f *fakeBw) state() *fakeBwState {
+	f.t.Helper()
+	state, err := loadFakeBwState(f.path)
+	require.NoError(f.t, err)
+	return state
+}
+
+// save は状態を書き込みます。
+func (f *fakeBw) save(state *fakeBwState) {
+	f.t.Helper()
+	require.NoError(f.t, saveFakeBwState(f.path, state))
+}
+
+// update は状態を変更して書き込みます。
+func (f *fakeBw) update(mutate func(state *fakeBwState)) {
+	f.t.Helper()
+	state := f.state()
+	mutate(state)
+	f.save(state)
+}
+
+// addFolder はフォルダーを追加して ID を返します。
+func (f *fakeBw) addFolder(name string) string {
+	var id string
+	f.update(func(state *fakeBwState) {
+		id = state.newID("folder")
+		state.Folders = append(state.Folders, Folder{ID: id, Name: name})
+	})
+	return id
+}
+
+// addNote はセキュアノートを追加して ID を返します。
+func (f *fakeBw) addNote(folderID, name, notes string) string {
+	var id string
+	f.update(func(state *fakeBwState) {
+		id = state.newID("item")
+		state.Items = append(state.Items, FullItem{ID: id, Name: name, Type: 2, Notes: notes, FolderID: folderID, RevisionDate: fakeBwRevision})
+	})
+	return id
+}
+
+// fail は指定したコマンドを失敗させます。
+func (f *fakeBw) fail(command, message string) {
+	f.update(func(state *fakeBwState) {
+		if state.Failures == nil {
+			state.Failures = map[string]string{}
+		}
+		state.Failures[command] = message
+	})
+}
+
+// item は ID でアイテムを探します。
+func (f *fakeBw) item(id string) *FullItem {
+	for _, item := range f.state().Items {
+		if item.ID == id {
+			return &item
+		}
+	}
+	return nil
+}
+
+// calls は実行されたコマンドラインを返します。
+func (f *fakeBw) calls() []string {
+	return f.state().Calls
+}
+
+// fakeBwRevision は偽の bw が付けるリビジョン日時です。
+const fakeBwRevision = "2026-01-01T00:00:00.000Z"
+
+func loadFakeBwState(path string) (*fakeBwState, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var state fakeBwState
+	if err := json.Unmarshal(data, &state); err != nil {
+		return nil, err
+	}
+	return &state, nil
+}
+
+func saveFakeBwState(path string, state *fakeBwState) error {
+	data, err := json.MarshalIndent(state, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, 0600)
+}
+
+// newID は連番の ID を発行します。
+func (s *fakeBwState) newID(prefix string) string {
+	s.NextID++
+	return fmt.Sprintf("%s-%d", prefix, s.NextID)
+}
+
+// fakeBwMain は bw の引数を解釈して状態を更新し、終了コードを返します。
+func fakeBwMain(args []string, stdin io.Reader, stdout io.Writer) int {
+	path := os.Getenv(fakeBwStateEnv)
+	state, err := loadFakeBwState(path)
+	if err != nil {
+		fmt.Fprintln(stdout, "fake bw: cannot read state:", err)
+		return 2
+	}
+	state.Calls = append(state.Calls, strings.Join(args, " "))
+	defer saveFakeBwState(path, state)
+
+	if state.Delay > 0 {
+		time.Sleep(state.Delay)
+	}
+
+	command := fakeBwCommand(args)
+	if message, ok := fakeBwFailure(state, command, args); ok {
+		fmt.Fprintln(stdout, message)
+		return 1
+	}
+
+	run := fakeBwCommands[command]
+	if run == nil {
+		fmt.Fprintf(stdout, "unknown command: %s\n", command)
+		return 1
+	}
+	out, code := run(state, fakeBwPositional(args), args, stdin)
+	fmt.Fprint(stdout, out)
+	return code
+}
+
+// fakeBwFailure は注入された障害を探します。"unlock --passwordfile" のようにフラグ付きでも指定できます。
+func fakeBwFailure(state *fakeBwState, command string, args []string) (string, bool) {
+	if message, ok := state.Failures[command]; ok {
+		return message, true
+	}
+	for _, arg := range args {
+		if strings.HasPrefix(arg, "--") {
+			if message, ok := state.Failures[command+" "+arg]; ok {
+				return message, true
+			}
+		}
+	}
+	return "", false
+}
+
+// fakeBwCommand は "list items" のようにサブコマンド部分を返します。
+func fakeBwCommand(args []string) string {
+	positional := fakeBwPositional(args)
+	switch {
+	case len(positional) == 0:
+		return ""
+	case positional[0] == "list" || positional[0] == "get" || positional[0] == "create" ||
+		positional[0] == "edit" || positional[0] == "delete" || positional[0] == "config":
+		if len(positional) > 1 {
+			return positional[0] + " " + positional[1]
+		}
+	}
+	return positional[0]
+}
+
+// fakeBwPositional はフラグとその値を除いた引数を返します。
+func fakeBwPositional(args []string) []string {
+	var positional []string
+	for i := 0; i < len(args); i++ {
+		switch args[i] {
+		case "--folderid", "--search", "--passwordfile", "--passwordenv":
+			i++
+		case "--raw", "--permanent":
+		default:
+			positional = append(positional, args[i])
+		}
+	}
+	return positional
+}
+
+// fakeBwFlag はフラグの値を返します。
+func fakeBwFlag(args []string, name string) (string, bool) {
+	for i, arg := range args {
+		if arg == name {
+			if i+1 < len(args) {
+				return args[i+1], true
+			}
+			return "", true
+		}
+	}
+	return "", false
+}
+
+type fakeBwHandler func(state *fakeBwState, positional, args []string, stdin io.Reader) (string, int)
+
+var fakeBwCommands map[string]fakeBwHandler
+
+func init() {
+	fakeBwCommands = map[string]fakeBwHandler{
+		"status": func(state *fakeBwState, _, _ []string, _ io.Reader) (string, int) {
+			data, _ := json.Marshal(map[string]string{"serverUrl": state.Server, "userEmail": state.Email, "status": state.Status})
+			return string(data), 0
+		},
+		"config server": func(state *fakeBwState, positional, _ []string, _ io.Reader) (string, int) {
+			if len(positional) < 3 {
+				return state.Server, 0
+			}
+			if state.Status != "unauthenticated" {
+				return "Logout required before server config update.", 1
+			}
+			state.Server = positional[2]
+			return "Saved setting `config`.", 0
+		},
+		"logout": func(state *fakeBwState, _, _ []string, _ io.Reader) (string, int) {
+			state.Status = "unauthenticated"
+			return "You have logged out.", 0
+		},
+		"login": func(state *fakeBwState, positional, _ []string, _ io.Reader) (string, int) {
+			if state.Status != "unauthenticated" {
+				return "You are already logged in as " + state.Email + ".", 1
+			}
+			if len(positional) < 3 || positional[1] != state.Email || positional[2] != state.Password {
+				return "Username or password is incorrect. Try again.", 1
+			}
+			state.Status = "unlocked"
+			return "You are logged in!\n\nTo unlock your vault, use the `unlock` command.", 0
+		},
+		"unlock":        fakeBwUnlock,
+		"sync":          fakeBwUnlocked(func(*fakeBwState, []string, []string, io.Reader) (string, int) { return "Syncing complete.", 0 }),
+		"list folders":  fakeBwUnlocked(fakeBwListFolders),
+		"list items":    fakeBwUnlocked(fakeBwListItems),
+		"get item":      fakeBwUnlocked(fakeBwGetItem),
+		"get template":  fakeBwUnlocked(fakeBwGetTemplate),
+		"encode":        fakeBwEncode,
+		"create folder": fakeBwUnlocked(fakeBwCreateFolder),
+		"create item":   fakeBwUnlocked(fakeBwCreateItem),
+		"edit item":     fakeBwUnlocked(fakeBwEditItem),
+		"delete item":   fakeBwUnlocked(fakeBwDeleteItem),
+	}
+}
+
+// fakeBwUnlocked はロック中・未ログイン時に bw と同じメッセージで失敗させます。
+func fakeBwUnlocked(next fakeBwHandler) fakeBwHandler {
+	return func(state *fakeBwState, positional, args []string, stdin io.Reader) (string, int) {
+		switch state.Status {
+		case "unauthenticated":
+			return "You are not logged in.", 1
+		case "locked":
+			return "? Master password: [input is hidden] ", 1
+		}
+		return next(state, positional, args, stdin)
+	}
+}
+
+// fakeBwJSON は障害として注入されたノイズを付けて JSON を出力します。
+func fakeBwJSON(state *fakeBwState, v interface{}) (string, int) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return err.Error(), 1
+	}
+	return state.Noise + string(data), 0
+}
+
+func fakeBwUnlock(state *fakeBwState, positional, args []string, _ io.Reader) (string, int) {
+	if state.Status == "unauthenticated" {
+		return "You are not logged in.", 1
+	}
+
+	var password string
+	if file, ok := fakeBwFlag(args, "--passwordfile"); ok {
+		data, err := os.ReadFile(file)
+		if err != nil {
+			return err.Error(), 1
+		}
+		password = strings.TrimSpace(string(data))
+	} else if name, ok := fakeBwFlag(args, "--passwordenv"); ok {
+		password = os.Getenv(name)
+	} else if len(positional) > 1 {
+		password = positional[1]
+	}
+	if password != state.Password {
+		return "Invalid master password.", 1
+	}
+
+	state.Status = "unlocked"
+	if _, raw := fakeBwFlag(args, "--raw"); raw {
+		if state.UnlockNoRaw {
+			return "", 0
+		}
+		return fakeBwSession, 0
+	}
+	return "Your vault is now unlocked!\n\nTo unlock your vault, set your session key to the `BW_SESSION` environment variable. ex:\n$ export BW_SESSION=\"" + fakeBwSession + "\"\n", 0
+}
+
+func fakeBwListFolders(state *fakeBwState, _, _ []string, _ io.Reader) (string, int) {
+	folders := append([]Folder{}, state.Folders...)
+	return fakeBwJSON(state, folders)
+}
+
+func fakeBwListItems(state *fakeBwState, _, args []string, _ io.Reader) (string, int) {
+	folderID, byFolder := fakeBwFlag(args, "--folderid")
+	search, bySearch := fakeBwFlag(args, "--search")
+	items := []FullItem{}
+	for _, item := range state.Items {
+		if byFolder && item.FolderID != folderID {
+			continue
+		}
+		if bySearch && !strings.Contains(strings.ToLower(item.Name), strings.ToLower(search)) {
+			continue
+		}
+		items = append(items, item)
+	}
+	return fakeBwJSON(state, items)
+}
+
+func fakeBwGetItem(state *fakeBwState, positional, _ []string, _ io.Reader) (string, int) {
+	if len(positional) < 3 {
+		return "`id` argument is required.", 1
+	}
+	for _, item := range state.Items {
+		if item.ID == positional[2] {
+			return fakeBwJSON(state, item)
+		}
+	}
+	return "Not found.", 1
+}
+
+func fakeBwGetTemplate(state *fakeBwState, _, _ []string, _ io.Reader) (string, int) {
+	return fakeBwJSON(state, map[string]interface{}{
+		"organizationId": nil, "folderId": nil, "type": 1, "name": "Item name", "notes": "Some notes about this item.",
+		"favorite": false, "fields": []interface{}{}, "login": nil, "secureNote": nil, "reprompt": 0,
+	})
+}
+
+func fakeBwEncode(_ *fakeBwState, _, _ []string, stdin io.Reader) (string, int) {
+	data, err := io.ReadAll(stdin)
+	if err != nil {
+		return err.Error(), 1
+	}
+	return base64.StdEncoding.EncodeToString(data), 0
+}
+
+// fakeBwDecode は引数のエンコード済みデータ、なければ標準入力（base64 か生の JSON）からオブジェクトを読み込みます。
+func fakeBwDecode(encoded string, stdin io.Reader, v interface{}) error {
+	data := []byte(encoded)
+	if encoded == "" {
+		read, err := io.ReadAll(stdin)
+		if err != nil {
+			return err
+		}
+		data = read
+	}
+	if decoded, err := base64.StdEncoding.DecodeString(strings.TrimSpace(string(data))); err == nil {
+		data = decoded
+	}
+	return json.Unmarshal(data, v)
+}
+
+// fakeBwArg は n 番目の引数を返します（なければ空文字列）。
+func fakeBwArg(positional []string, n int) string {
+	if n < len(positional) {
+		return positional[n]
+	}
+	return ""
+}
+
+func fakeBwCreateFolder(state *fakeBwState, positional, _ []string, stdin io.Reader) (string, int) {
+	var folder Folder
+	if err := fakeBwDecode(fakeBwArg(positional, 2), stdin, &folder); err != nil {
+		return "Error parsing the encoded request data.", 1
+	}
+	folder.ID = state.newID("folder")
+	state.Folders = append(state.Folders, folder)
+	return fakeBwJSON(state, folder)
+}
+
+func fakeBwCreateItem(state *fakeBwState, positional, _ []string, stdin io.Reader) (string, int) {
+	var item FullItem
+	if err := fakeBwDecode(fakeBwArg(positional, 2), stdin, &item); err != nil {
+		return "Error parsing the encoded request data.", 1
+	}
+	item.ID = state.newID("item")
+	item.RevisionDate = fakeBwRevision
+	state.Items = append(state.Items, item)
+	return fakeBwJSON(state, item)
+}
+
+func fakeBwEditItem(state *fakeBwState, positional, _ []string, stdin io.Reader) (string, int) {
+	if len(positional) < 3 {
+		return "`id` argument is required.", 1
+	}
+	var updated FullItem
+	if err := fakeBwDecode(fakeBwArg(positional, 3), stdin, &updated); err != nil {
+		return "Error parsing the encoded request data.", 1
+	}
+	for i, item := range state.Items {
+		if item.ID == positional[2] {
+			updated.ID = item.ID
+			updated.RevisionDate = fakeBwRevision
+			state.Items[i] = updated
+			return fakeBwJSON(state, updated)
+		}
+	}
+	return "Not found.", 1
+}
+
+func fakeBwDeleteItem(state *fakeBwState, positional, _ []string, _ io.Reader) (string, int) {
+	if len(positional) < 3 {
+		return "`id` argument is required.", 1
+	}
+	for i, item := range state.Items {
+		if item.ID == positional[2] {
+			state.Items = append(state.Items[:i], state.Items[i+1:]...)
+			return "", 0
+		}
+	}
+	return "Not found.", 1
+}
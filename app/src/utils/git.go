@@ -0,0 +1,113 @@
+package utils
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+)
+
+// GitWorkTreeRoot returns the top-level directory of the git work tree containing dir.
+// It returns "" when git is not installed or dir is not inside a work tree.
+// dir does not need to exist yet; the nearest existing parent is inspected instead.
+func GitWorkTreeRoot(dir string) (string, error) {
+	if _, err := exec.LookPath("git"); err != nil {
+		return "", nil
+	}
+
+	dir = nearestExistingDir(dir)
+	out, err := exec.Command("git", "-C", dir, "rev-parse", "--show-toplevel").Output()
+	if err != nil {
+		// exit status 128: not a git repository
+		return "", nil
+	}
+	return strings.TrimSpace(string(out)), nil
+}
+
+// GitIsTracked reports whether path is tracked in the git index.
+func GitIsTracked(path string) (bool, error) {
+	cmd := exec.Command("git", "-C", nearestExistingDir(filepath.Dir(path)), "ls-files", "--error-unmatch", "--", path)
+	return gitExitStatus(cmd)
+}
+
+// GitIsIgnored reports whether path is ignored by .gitignore or other exclude files.
+func GitIsIgnored(path string) (bool, error) {
+	cmd := exec.Command("git", "-C", nearestExistingDir(filepath.Dir(path)), "check-ignore", "-q", "--no-index", "--", path)
+	return gitExitStatus(cmd)
+}
+
+// GitInHistory reports whether path appears in any commit reachable from any ref.
+func GitInHistory(path string) (bool, error) {
+	out, err := exec.Command("git", "-C", nearestExistingDir(filepath.Dir(path)), "log", "--all", "-n", "1", "--format=%H", "--", path).Output()
+	if err != nil {
+		return false, fmt.Errorf("git log failed: %w", err)
+	}
+	return strings.TrimSpace(string(out)) != "", nil
+}
+
+// GitHooksDir returns the hooks directory of the repository containing dir, honoring core.hooksPath.
+func GitHooksDir(dir string) (string, error) {
+	out, err := exec.Command("git", "-C", dir, "rev-parse", "--git-path", "hooks").Output()
+	if err != nil {
+		return "", fmt.Errorf("git rev-parse failed: %w", err)
+	}
+	path := strings.TrimSpace(string(out))
+	if !filepath.IsAbs(path) {
+		path = filepath.Join(dir, path)
+	}
+	return filepath.Clean(path), nil
+}
+
+// GitStagedFiles returns the files added, copied, modified or renamed in the index,
+// relative to the root of the work tree containing dir.
+func GitStagedFiles(dir string) ([]string, error) {
+	out, err := exec.Command("git", "-C", dir, "diff", "--cached", "--name-only", "--diff-filter=ACMR", "-z").Output()
+	if err != nil {
+		return nil, fmt.Errorf("git diff --cached failed: %w", err)
+	}
+	var files []string
+	for _, name := range strings.Split(string(out), "\x00") {
+		if name != "" {
+			files = append(files, name)
+		}
+	}
+	return files, nil
+}
+
+// GitStagedContent returns the staged content of path, which is relative to the root of the work tree.
+func GitStagedContent(dir, path string) ([]byte, error) {
+	out, err := exec.Command("git", "-C", dir, "show", ":"+path).Output()
+	if err != nil {
+		return nil, fmt.Errorf("git show :%s failed: %w", path, err)
+	}
+	return out, nil
+}
+
+// gitExitStatus runs a git command where exit status 0 means true and 1 means false.
+func gitExitStatus(cmd *exec.Cmd) (bool, error) {
+	output, err := cmd.CombinedOutput()
+	if err == nil {
+		return true, nil
+	}
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) && exitErr.ExitCode() == 1 {
+		return false, nil
+	}
+	return false, fmt.Errorf("%s failed: %s", strings.Join(cmd.Args[:4], " "), strings.TrimSpace(string(output)))
+}
+
+// nearestExistingDir walks up from dir until it finds a directory that exists.
+func nearestExistingDir(dir string) string {
+	for {
+		if info, err := os.Stat(dir); err == nil && info.IsDir() {
+			return dir
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return dir
+		}
+		dir = parent
+	}
+}
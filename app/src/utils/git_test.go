@@ -0,0 +1,129 @@
+package utils
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// initGitRepo は一時ディレクトリに Git リポジトリを作成します。git が無い場合はスキップします。
+func initGitRepo(t *testing.T) string {
+	t.Helper()
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git is not installed")
+	}
+	dir := t.TempDir()
+	for _, args := range [][]string{
+		{"init", "-q"},
+		{"config", "user.email", "test@example.com"},
+		{"config", "user.name", "test"},
+	} {
+		out, err := exec.Command("git", append([]string{"-C", dir}, args...)...).CombinedOutput()
+		require.NoError(t, err, string(out))
+	}
+	// macOS の /var -> /private/var などを解決しておく
+	resolved, err := filepath.EvalSymlinks(dir)
+	require.NoError(t, err)
+	return resolved
+}
+
+// =============================================================================
+// GitWorkTreeRoot のテスト
+// =============================================================================
+
+// 正常系: 作業ツリー内の存在しないサブディレクトリからもルートを返す
+func TestGitWorkTreeRoot_InsideRepo(t *testing.T) {
+	dir := initGitRepo(t)
+
+	root, err := GitWorkTreeRoot(filepath.Join(dir, "not", "yet", "created"))
+
+	assert.NoError(t, err)
+	assert.Equal(t, dir, root)
+}
+
+// 正常系: 作業ツリー外では空文字を返す
+func TestGitWorkTreeRoot_OutsideRepo(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git is not installed")
+	}
+	dir := t.TempDir()
+	t.Setenv("GIT_CEILING_DIRECTORIES", filepath.Dir(dir))
+
+	root, err := GitWorkTreeRoot(dir)
+
+	assert.NoError(t, err)
+	assert.Equal(t, "", root)
+}
+
+// =============================================================================
+// GitIsTracked / GitIsIgnored / GitInHistory のテスト
+// =============================================================================
+
+// 正常系: 追跡・無視・履歴の状態を判定できる
+func TestGitFileState(t *testing.T) {
+	dir := initGitRepo(t)
+	envPath := filepath.Join(dir, ".env")
+	localPath := filepath.Join(dir, ".env.local")
+	require.NoError(t, os.WriteFile(envPath, []byte("A=1\n"), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, ".gitignore"), []byte(".env.local\n"), 0644))
+
+	tracked, err := GitIsTracked(envPath)
+	require.NoError(t, err)
+	assert.False(t, tracked)
+
+	ignored, err := GitIsIgnored(localPath)
+	require.NoError(t, err)
+	assert.True(t, ignored)
+
+	ignored, err = GitIsIgnored(envPath)
+	require.NoError(t, err)
+	assert.False(t, ignored)
+
+	out, err := exec.Command("git", "-C", dir, "add", ".env").CombinedOutput()
+	require.NoError(t, err, string(out))
+	out, err = exec.Command("git", "-C", dir, "commit", "-q", "-m", "add env").CombinedOutput()
+	require.NoError(t, err, string(out))
+
+	tracked, err = GitIsTracked(envPath)
+	require.NoError(t, err)
+	assert.True(t, tracked)
+
+	inHistory, err := GitInHistory(envPath)
+	require.NoError(t, err)
+	assert.True(t, inHistory)
+
+	inHistory, err = GitInHistory(localPath)
+	require.NoError(t, err)
+	assert.False(t, inHistory)
+}
+
+// =============================================================================
+// GitHooksDir / GitStagedFiles / GitStagedContent のテスト
+// =============================================================================
+
+// 正常系: フックディレクトリ、ステージしたファイルと内容を返す
+func TestGitHooksAndIndex(t *testing.T) {
+	dir := initGitRepo(t)
+	require.NoError(t, os.MkdirAll(filepath.Join(dir, "app"), 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "app", "config.js"), []byte("staged"), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "unstaged.txt"), []byte("x"), 0644))
+	out, err := exec.Command("git", "-C", dir, "add", "app/config.js").CombinedOutput()
+	require.NoError(t, err, string(out))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "app", "config.js"), []byte("changed after staging"), 0644))
+
+	hooksDir, err := GitHooksDir(filepath.Join(dir, "app"))
+	require.NoError(t, err)
+	assert.Equal(t, filepath.Join(dir, ".git", "hooks"), hooksDir)
+
+	files, err := GitStagedFiles(filepath.Join(dir, "app"))
+	require.NoError(t, err)
+	assert.Equal(t, []string{"app/config.js"}, files)
+
+	content, err := GitStagedContent(dir, "app/config.js")
+	require.NoError(t, err)
+	assert.Equal(t, "staged", string(content))
+}
@@ -0,0 +1,37 @@
+package utils
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// =============================================================================
+// RunHookCommand のテスト
+// =============================================================================
+
+// 正常系: 標準入力と環境変数をコマンドに渡す
+func TestRunHookCommand_Success(t *testing.T) {
+	out := filepath.Join(t.TempDir(), "out")
+
+	err := RunHookCommand(`cat > "$OUT"; echo "$BWSF_PROJECT" >> "$OUT"`, []byte("payload\n"), []string{"OUT=" + out, "BWSF_PROJECT=web"}, 5*time.Second)
+
+	require.NoError(t, err)
+	data, _ := os.ReadFile(out)
+	assert.Equal(t, "payload\nweb\n", string(data))
+}
+
+// 異常系: 失敗時は標準エラーを含め、制限時間を超えたら打ち切る
+func TestRunHookCommand_Errors(t *testing.T) {
+	err := RunHookCommand("echo broken >&2; exit 3", nil, nil, 5*time.Second)
+	assert.ErrorContains(t, err, "broken")
+
+	start := time.Now()
+	err = RunHookCommand("sleep 5", nil, nil, 100*time.Millisecond)
+	assert.ErrorContains(t, err, "timed out")
+	assert.Less(t, time.Since(start), 4*time.Second)
+}
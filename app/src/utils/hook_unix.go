@@ -0,0 +1,14 @@
+//go:build !windows
+
+package utils
+
+import (
+	"os/exec"
+	"syscall"
+)
+
+// killOnCancel kills the whole process group on timeout so children of the shell do not keep it running.
+func killOnCancel(cmd *exec.Cmd) {
+	cmd.SysProcAttr = &syscall.SysProcAttr{Setpgid: true}
+	cmd.Cancel = func() error { return syscall.Kill(-cmd.Process.Pid, syscall.SIGKILL) }
+}
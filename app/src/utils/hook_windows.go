@@ -0,0 +1,11 @@
+//go:build windows
+
+package utils
+
+import "os/exec"
+
+// killOnCancel kills the shell on timeout. Windows has no process groups to signal,
+// so children it started may keep running until they exit.
+func killOnCancel(cmd *exec.Cmd) {
+	cmd.Cancel = func() error { return cmd.Process.Kill() }
+}
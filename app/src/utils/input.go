@@ -2,7 +2,9 @@ package utils
 
 import (
 	"bufio"
+	"errors"
 	"fmt"
+	"io"
 	"os"
 	"strings"
 	"syscall"
@@ -65,7 +67,18 @@ func InputEmail() (string, error) {
 
 // InputPassword prompts user to enter password (hidden input)
 func InputPassword() (string, error) {
-	Question("Enter password: ")
+	return inputPassword(os.Stdout)
+}
+
+// InputPasswordTo returns a password prompt that writes to w, for commands whose stdout is data
+func InputPasswordTo(w io.Writer) func() (string, error) {
+	return func() (string, error) {
+		return inputPassword(w)
+	}
+}
+
+func inputPassword(w io.Writer) (string, error) {
+	QuestionTo(w, "Enter password: ")
 
 	// Read password without echoing to terminal
 	passwordBytes, err := term.ReadPassword(int(syscall.Stdin))
@@ -73,7 +86,7 @@ func InputPassword() (string, error) {
 		return "", fmt.Errorf("failed to read password: %w", err)
 	}
 
-	fmt.Println() // Print newline after password input
+	fmt.Fprintln(w) // Print newline after password input
 
 	password := string(passwordBytes)
 	if password == "" {
@@ -97,6 +110,11 @@ func ConfirmOverwrite(message string) (bool, error) {
 	return response == "y" || response == "yes", nil
 }
 
+// StdinIsTerminal reports whether stdin is a terminal, i.e. whether prompts can be answered
+func StdinIsTerminal() bool {
+	return term.IsTerminal(int(syscall.Stdin))
+}
+
 // ConfirmYesNo prompts user with a y/N question
 // Returns true if user answers "y" or "yes" (case insensitive)
 // Returns false for any other input including empty (default is No)
@@ -112,3 +130,37 @@ func ConfirmYesNo(message string) (bool, error) {
 	response := strings.TrimSpace(strings.ToLower(input))
 	return response == "y" || response == "yes", nil
 }
+
+// ErrInterrupted is returned by ConfirmKey when Ctrl-C is pressed at the prompt
+var ErrInterrupted = errors.New("interrupted")
+
+// ConfirmKey prompts user with a y/N question answered by a single key, without Enter.
+// Returns true only for "y" (case insensitive). In raw mode Ctrl-C arrives as a key, so it returns ErrInterrupted.
+// Falls back to ConfirmYesNo when stdin is not a terminal.
+func ConfirmKey(message string) (bool, error) {
+	fd := int(syscall.Stdin)
+	if !term.IsTerminal(fd) {
+		return ConfirmYesNo(message)
+	}
+
+	Question("%s", message)
+	state, err := term.MakeRaw(fd)
+	if err != nil {
+		return false, fmt.Errorf("failed to read input: %w", err)
+	}
+	var key [1]byte
+	_, err = os.Stdin.Read(key[:])
+	_ = term.Restore(fd, state)
+	fmt.Println()
+	if err != nil {
+		return false, fmt.Errorf("failed to read input: %w", err)
+	}
+
+	switch key[0] {
+	case 3: // Ctrl-C
+		return false, ErrInterrupted
+	case 'y', 'Y':
+		return true, nil
+	}
+	return false, nil
+}
@@ -0,0 +1,114 @@
+package utils
+
+import (
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"os/exec"
+	"runtime"
+	"strconv"
+	"strings"
+)
+
+// ErrKeyringUnavailable is returned when no supported OS keyring tool is installed.
+var ErrKeyringUnavailable = errors.New("OS keyring is not available (requires macOS 'security' or Linux 'secret-tool')")
+
+// KeyringGet reads a secret from the OS keyring.
+// It uses the macOS Keychain via `security` and the Secret Service via `secret-tool` on Linux.
+// It returns "" when no secret is stored.
+func KeyringGet(service, account string) (string, error) {
+	var cmd *exec.Cmd
+	switch keyringTool() {
+	case "security":
+		cmd = exec.Command("security", "find-generic-password", "-s", service, "-a", account, "-w")
+	case "secret-tool":
+		cmd = exec.Command("secret-tool", "lookup", "service", service, "account", account)
+	default:
+		return "", ErrKeyringUnavailable
+	}
+
+	out, err := cmd.Output()
+	if err != nil {
+		// Both tools exit non-zero when the secret does not exist
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) {
+			return "", nil
+		}
+		return "", fmt.Errorf("failed to read keyring: %w", err)
+	}
+	return strings.TrimSpace(string(out)), nil
+}
+
+// KeyringSet stores a secret in the OS keyring, replacing any existing one.
+func KeyringSet(service, account, secret string) error {
+	tool := keyringTool()
+	if tool == "" {
+		return ErrKeyringUnavailable
+	}
+
+	out, err := keyringSetCommand(tool, service, account, secret).CombinedOutput()
+	if err != nil {
+		return fmt.Errorf("failed to write keyring: %s", keyringErrorMessage(out, err))
+	}
+	// security -i reports a failed command on its output but may still exit zero
+	if tool == "security" && strings.TrimSpace(string(out)) != "" {
+		return fmt.Errorf("failed to write keyring: %s", strings.TrimSpace(string(out)))
+	}
+	return nil
+}
+
+// keyringSetCommand returns the command that stores secret with tool.
+// Both tools read the secret from stdin so it never appears in the process list:
+// secret-tool reads the secret itself, and security -i reads the whole command with the secret hex-encoded (-X).
+func keyringSetCommand(tool, service, account, secret string) *exec.Cmd {
+	if tool == "security" {
+		cmd := exec.Command("security", "-i")
+		cmd.Stdin = strings.NewReader(fmt.Sprintf("add-generic-password -U -s %s -a %s -X %s\n",
+			strconv.Quote(service), strconv.Quote(account), hex.EncodeToString([]byte(secret))))
+		return cmd
+	}
+	cmd := exec.Command("secret-tool", "store", "--label", service+" "+account, "service", service, "account", account)
+	cmd.Stdin = strings.NewReader(secret)
+	return cmd
+}
+
+// KeyringDelete removes a secret from the OS keyring. Deleting a missing secret is not an error.
+func KeyringDelete(service, account string) error {
+	var cmd *exec.Cmd
+	switch keyringTool() {
+	case "security":
+		cmd = exec.Command("security", "delete-generic-password", "-s", service, "-a", account)
+	case "secret-tool":
+		cmd = exec.Command("secret-tool", "clear", "service", service, "account", account)
+	default:
+		return ErrKeyringUnavailable
+	}
+
+	if out, err := cmd.CombinedOutput(); err != nil {
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) {
+			return nil
+		}
+		return fmt.Errorf("failed to delete from keyring: %s", keyringErrorMessage(out, err))
+	}
+	return nil
+}
+
+// keyringTool returns the keyring command available on this OS, or "" when there is none.
+func keyringTool() string {
+	tool := "secret-tool"
+	if runtime.GOOS == "darwin" {
+		tool = "security"
+	}
+	if _, err := exec.LookPath(tool); err != nil {
+		return ""
+	}
+	return tool
+}
+
+func keyringErrorMessage(output []byte, err error) string {
+	if msg := strings.TrimSpace(string(output)); msg != "" {
+		return msg
+	}
+	return err.Error()
+}
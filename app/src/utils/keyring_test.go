@@ -0,0 +1,40 @@
+package utils
+
+import (
+	"encoding/hex"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// =============================================================================
+// keyringSetCommand のテスト
+// =============================================================================
+
+// 正常系: どちらのツールでもシークレットはコマンドの引数に含めず、標準入力で渡す
+func TestKeyringSetCommand_SecretNotInArgs(t *testing.T) {
+	const secret = "API_KEY=s3cr3t value"
+
+	for _, tool := range []string{"security", "secret-tool"} {
+		cmd := keyringSetCommand(tool, "bwsf", "shell-env:abc", secret)
+
+		assert.Equal(t, tool, cmd.Args[0])
+		for _, arg := range cmd.Args {
+			assert.NotContains(t, arg, "s3cr3t", tool)
+			assert.NotContains(t, arg, hex.EncodeToString([]byte(secret)), tool)
+		}
+		require.NotNil(t, cmd.Stdin, tool)
+		stdin, err := io.ReadAll(cmd.Stdin)
+		require.NoError(t, err)
+		if tool == "security" {
+			assert.Equal(t, []string{"security", "-i"}, cmd.Args)
+			assert.Equal(t, `add-generic-password -U -s "bwsf" -a "shell-env:abc" -X `+hex.EncodeToString([]byte(secret))+"\n", string(stdin))
+		} else {
+			assert.Equal(t, secret, string(stdin))
+			assert.True(t, strings.HasPrefix(strings.Join(cmd.Args, " "), "secret-tool store"))
+		}
+	}
+}
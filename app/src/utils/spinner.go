@@ -1,16 +1,23 @@
 package utils
 
 import (
+	"fmt"
+	"io"
 	"os"
+	"strings"
 	"sync"
 	"time"
 
 	"github.com/briandowns/spinner"
+	"golang.org/x/term"
 )
 
 var (
 	currentSpinner *spinner.Spinner
 	spinnerMutex   sync.Mutex
+
+	// multiSpinnerActive suppresses the single spinner while a MultiSpinner owns the terminal
+	multiSpinnerActive bool
 )
 
 // isSpinnerEnabled checks if spinner should be enabled
@@ -35,6 +42,10 @@ func StartSpinner(message string) bool {
 	spinnerMutex.Lock()
 	defer spinnerMutex.Unlock()
 
+	if multiSpinnerActive {
+		return false
+	}
+
 	// Stop any existing spinner
 	if currentSpinner != nil {
 		currentSpinner.Stop()
@@ -82,7 +93,185 @@ func UpdateSpinnerMessage(message string) {
 	}
 }
 
+// TaskState is the state of one task shown by a MultiSpinner
+type TaskState int
 
+const (
+	TaskPending TaskState = iota
+	TaskRunning
+	TaskDone
+	TaskSkipped
+	TaskFailed
+)
 
+// multiTask is one line of a MultiSpinner
+type multiTask struct {
+	label  string
+	state  TaskState
+	status string
+}
 
+// MultiSpinner shows one line per task and redraws them in place while tasks run.
+// In non-TTY environments (or with NO_COLOR) it prints a line each time a task finishes instead.
+type MultiSpinner struct {
+	mu       sync.Mutex
+	w        io.Writer
+	live     bool
+	tasks    []multiTask
+	width    int
+	frame    int
+	drawn    int // number of lines drawn by the last redraw
+	stopCh   chan struct{}
+	doneCh   chan struct{}
+	stopOnce sync.Once
+}
 
+// StartMultiSpinner starts a multi-task spinner with one line per label.
+// While it runs, StartSpinner is a no-op so concurrent bw calls do not fight over the terminal.
+func StartMultiSpinner(labels []string) *MultiSpinner {
+	// Redrawing more lines than the terminal has rows scrolls and corrupts the output, so fall back to a line per task
+	m := newMultiSpinner(os.Stderr, labels, isSpinnerEnabled() && fitsTerminal(len(labels), terminalRows(os.Stderr)))
+
+	spinnerMutex.Lock()
+	if currentSpinner != nil {
+		currentSpinner.Stop()
+		currentSpinner = nil
+	}
+	multiSpinnerActive = true
+	spinnerMutex.Unlock()
+
+	if m.live {
+		go m.loop()
+	} else {
+		close(m.doneCh)
+	}
+	return m
+}
+
+// terminalRows returns the height of the terminal f is attached to, or 0 when it is unknown
+func terminalRows(f *os.File) int {
+	_, rows, err := term.GetSize(int(f.Fd()))
+	if err != nil {
+		return 0
+	}
+	return rows
+}
+
+// fitsTerminal reports whether lines task lines can be redrawn in place on a terminal with rows rows.
+// One row is left for the cursor below the last line.
+func fitsTerminal(lines, rows int) bool {
+	return rows > 0 && lines < rows
+}
+
+// newMultiSpinner creates a MultiSpinner writing to w without starting it
+func newMultiSpinner(w io.Writer, labels []string, live bool) *MultiSpinner {
+	m := &MultiSpinner{
+		w:      w,
+		live:   live,
+		stopCh: make(chan struct{}),
+		doneCh: make(chan struct{}),
+	}
+	for _, label := range labels {
+		m.tasks = append(m.tasks, multiTask{label: label})
+		if len(label) > m.width {
+			m.width = len(label)
+		}
+	}
+	return m
+}
+
+// Set updates the state and status text of task i
+func (m *MultiSpinner) Set(i int, state TaskState, status string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if i < 0 || i >= len(m.tasks) {
+		return
+	}
+	prev := m.tasks[i].state
+	m.tasks[i].state = state
+	m.tasks[i].status = status
+
+	// Without a live display, print each task once when it finishes
+	if !m.live && state >= TaskDone && prev < TaskDone {
+		fmt.Fprintln(m.w, m.line(i))
+	}
+}
+
+// Stop stops the spinner, leaving the final state of every task on screen
+func (m *MultiSpinner) Stop() {
+	m.stopOnce.Do(func() {
+		close(m.stopCh)
+		<-m.doneCh
+
+		if m.live {
+			m.mu.Lock()
+			m.redraw()
+			m.mu.Unlock()
+		}
+
+		spinnerMutex.Lock()
+		multiSpinnerActive = false
+		spinnerMutex.Unlock()
+	})
+}
+
+// loop redraws the task lines until Stop is called
+func (m *MultiSpinner) loop() {
+	defer close(m.doneCh)
+
+	ticker := time.NewTicker(100 * time.Millisecond)
+	defer ticker.Stop()
+	for {
+		m.mu.Lock()
+		m.redraw()
+		m.frame++
+		m.mu.Unlock()
+
+		select {
+		case <-m.stopCh:
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// redraw moves the cursor back over the previous frame and draws every task line
+func (m *MultiSpinner) redraw() {
+	var b strings.Builder
+	if m.drawn > 0 {
+		fmt.Fprintf(&b, "\033[%dA", m.drawn)
+	}
+	for i := range m.tasks {
+		b.WriteString("\033[2K")
+		b.WriteString(m.line(i))
+		b.WriteString("\n")
+	}
+	m.drawn = len(m.tasks)
+	fmt.Fprint(m.w, b.String())
+}
+
+// line renders task i as "<icon> <label> <status>"
+func (m *MultiSpinner) line(i int) string {
+	t := m.tasks[i]
+	var icon, colorCode string
+	switch t.state {
+	case TaskPending:
+		icon = "·"
+	case TaskRunning:
+		frames := spinner.CharSets[14]
+		icon, colorCode = frames[m.frame%len(frames)], colorCyan
+	case TaskDone:
+		icon, colorCode = "✔", colorGreen
+	case TaskSkipped:
+		icon, colorCode = "-", colorYellow
+	case TaskFailed:
+		icon, colorCode = "✖", colorRed
+	}
+
+	text := strings.TrimRight(fmt.Sprintf("  %s %-*s  %s", icon, m.width, t.label, t.status), " ")
+	if m.live && colorCode != "" {
+		text = colorCode + text + colorReset
+	}
+	return text
+}
@@ -0,0 +1,53 @@
+package utils
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// =============================================================================
+// MultiSpinner のテスト
+// =============================================================================
+
+// 正常系: 非 TTY では終了したタスクを 1 回ずつ出力する
+func TestMultiSpinner_NotLive(t *testing.T) {
+	var buf bytes.Buffer
+	m := newMultiSpinner(&buf, []string{"api", "frontend"}, false)
+	close(m.doneCh)
+
+	m.Set(0, TaskRunning, "pulling")
+	m.Set(1, TaskFailed, "item not found")
+	m.Set(0, TaskDone, "pulled")
+	m.Set(0, TaskDone, "pulled")
+	m.Stop()
+
+	assert.Equal(t, "  ✖ frontend  item not found\n  ✔ api       pulled\n", buf.String())
+}
+
+// 正常系: TTY では全タスクの行を書き直す
+func TestMultiSpinner_Redraw(t *testing.T) {
+	var buf bytes.Buffer
+	m := newMultiSpinner(&buf, []string{"a", "b"}, true)
+
+	m.Set(0, TaskDone, "ok")
+	m.redraw()
+	m.redraw()
+
+	out := buf.String()
+	assert.Equal(t, 1, strings.Count(out, "\033[2A"))
+	assert.Equal(t, 4, strings.Count(out, "\033[2K"))
+	assert.Contains(t, out, "✔ a  ok")
+	assert.Contains(t, out, "· b")
+}
+
+// 正常系: 端末の行数に収まらない場合は書き直さない
+func TestFitsTerminal(t *testing.T) {
+	assert.True(t, fitsTerminal(3, 24))
+	assert.True(t, fitsTerminal(23, 24))
+	assert.False(t, fitsTerminal(24, 24))
+	assert.False(t, fitsTerminal(40, 24))
+	assert.False(t, fitsTerminal(3, 0), "unknown height")
+}